@@ -4,6 +4,9 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"time"
+
+	"golang.org/x/exp/slices"
 )
 
 type Business struct {
@@ -43,9 +46,51 @@ const (
 const ROOT_BUSINESS_ID = 1
 
 type BusinessMeta struct {
-	ShebaNumber    string `json:",omitempty"`
-	AssetsSize     uint64 `json:",omitempty"`
-	BankCardNumber string `json:",omitempty"`
+	ShebaNumber    string               `json:",omitempty"`
+	AssetsSize     uint64               `json:",omitempty"`
+	BankCardNumber string               `json:",omitempty"`
+	Referral       BusinessMetaReferral `json:",omitempty"`
+	Quota          BusinessMetaQuota    `json:",omitempty"`
+	Timezone       string               `json:",omitempty" example:"Europe/Istanbul"` // IANA name, Asia/Tehran when empty
+	FirstDayOfWeek *time.Weekday        `json:",omitempty" example:"6"`               // 0 is Sunday, Saturday when empty
+}
+
+type BusinessMetaReferral struct {
+	Enabled              bool               `json:",omitempty"`
+	RewardType           ReferralRewardType `json:",omitempty" example:"coupon"`
+	InviterReward        float64            `json:",omitempty" example:"20000"`
+	InviteeReward        float64            `json:",omitempty" example:"10000"`
+	MaxRewardsPerInviter int                `json:",omitempty" example:"10"` // 0 means unlimited
+	CouponValidDays      int                `json:",omitempty" example:"30"`
+	MinOrderAmount       float64            `json:",omitempty" example:"5000"`
+}
+
+// BusinessMetaQuota keeps a few users from booking most of the machines, zero values mean unlimited.
+type BusinessMetaQuota struct {
+	MaxActiveReservations int                     `json:",omitempty" example:"3"` // upcoming reservations at the same time
+	MaxWeeklyHours        float64                 `json:",omitempty" example:"6"`
+	MaxPeakSlotsPerDay    int                     `json:",omitempty" example:"1"` // among the machines of the same dormitory taxonomy
+	PeakHours             []ProductMetaDeviceHour `json:",omitempty"`
+	ExemptUserIDs         []uint64                `json:",omitempty"`
+}
+
+func (bq BusinessMetaQuota) IsExempt(userID uint64) bool {
+	return slices.Contains(bq.ExemptUserIDs, userID)
+}
+
+// IsPeak reports whether the slot overlaps any of the peak hours, times are "15:04:05".
+func (bq BusinessMetaQuota) IsPeak(from string, to string) bool {
+	if to == "00:00:00" {
+		to = "24:00:00"
+	}
+
+	for _, hours := range bq.PeakHours {
+		if from < hours.To && to > hours.From {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (bm *BusinessMeta) Scan(value any) error {
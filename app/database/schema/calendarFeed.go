@@ -0,0 +1,17 @@
+package schema
+
+import "time"
+
+// CalendarFeed is the private link a calendar app subscribes to, either to the reservations
+// of a user (UserID) or to the schedule of a business (BusinessID). Revoking deletes it.
+type CalendarFeed struct {
+	ID            uint64     `gorm:"primaryKey" faker:"-"`
+	Token         string     `gorm:"varchar(64);not null;uniqueIndex" faker:"-"`
+	UserID        *uint64    `gorm:"index" faker:"-"`
+	User          *User      `gorm:"foreignKey:UserID" faker:"-"`
+	BusinessID    *uint64    `gorm:"index" faker:"-"`
+	Business      *Business  `gorm:"foreignKey:BusinessID" faker:"-"`
+	CreatorID     uint64     `gorm:"not null" faker:"-"`
+	LastFetchedAt *time.Time `faker:"-"`
+	Base
+}
@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Campaign sends a notification template to the users of an audience at its send time, a few of them each
+// minute. Every user of the audience is a CampaignRecipient with the result of the send.
+type Campaign struct {
+	ID         uint64               `gorm:"primaryKey" faker:"-"`
+	BusinessID uint64               `gorm:"not null;index" faker:"-"`
+	Title      string               `gorm:"varchar(100);not null"`
+	TemplateID uint64               `gorm:"not null" faker:"-"`
+	Template   NotificationTemplate `gorm:"foreignKey:TemplateID" faker:"-"`
+	Channels   pq.StringArray       `gorm:"type:text[];not null" faker:"-"` // Sms, Notification
+	Audience   CampaignAudience     `gorm:"type:jsonb" faker:"-"`
+	SendAt     time.Time            `gorm:"not null;index" faker:"-"`
+	Rate       int                  `gorm:"not null;default:0"` // recipients per minute
+	Status     CampaignStatus       `gorm:"varchar(20);not null;default:scheduled;index"`
+	Total      int                  `gorm:"not null;default:0"`
+	Sent       int                  `gorm:"not null;default:0"`
+	Failed     int                  `gorm:"not null;default:0"`
+	Skipped    int                  `gorm:"not null;default:0"` // opted out or canceled
+	StartedAt  *time.Time           `faker:"-"`
+	FinishedAt *time.Time           `faker:"-"` // completed or canceled
+	CreatedBy  uint64               `faker:"-"`
+	Base
+}
+
+type CampaignStatus string
+
+const (
+	CampaignStatusScheduled CampaignStatus = "scheduled"
+	CampaignStatusSending   CampaignStatus = "sending"
+	CampaignStatusCompleted CampaignStatus = "completed"
+	CampaignStatusCanceled  CampaignStatus = "canceled"
+)
+
+var CampaignStatusProxy = map[CampaignStatus]string{
+	CampaignStatusScheduled: "زمان‌بندی شده",
+	CampaignStatusSending:   "در حال ارسال",
+	CampaignStatusCompleted: "ارسال شده",
+	CampaignStatusCanceled:  "لغو شده",
+}
+
+// CampaignAudience is the users a campaign goes to, with the filters of the users of the business.
+type CampaignAudience struct {
+	CityID      uint64     `json:",omitempty"`
+	WorkspaceID uint64     `json:",omitempty"`
+	DormitoryID uint64     `json:",omitempty"`
+	Role        string     `json:",omitempty"`
+	IsSuspended string     `json:",omitempty"` // 1 or 0, everyone when empty
+	CountUsing  uint64     `json:",omitempty"` // at least this many reservations in the range
+	StartTime   *time.Time `json:",omitempty"`
+	EndTime     *time.Time `json:",omitempty"`
+	UserIDs     []uint64   `json:",omitempty"`
+}
+
+func (ca *CampaignAudience) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal CampaignAudience with value %v", value)
+	}
+	return json.Unmarshal(byteValue, ca)
+}
+
+func (ca CampaignAudience) Value() (driver.Value, error) {
+	return json.Marshal(ca)
+}
+
+// CampaignRecipient is a user of the audience of a campaign, taken when the campaign starts.
+type CampaignRecipient struct {
+	ID             uint64                  `gorm:"primaryKey" faker:"-"`
+	CampaignID     uint64                  `gorm:"not null;uniqueIndex:idx_campaign_recipients_user" faker:"-"`
+	UserID         uint64                  `gorm:"not null;uniqueIndex:idx_campaign_recipients_user" faker:"-"`
+	User           User                    `gorm:"foreignKey:UserID" faker:"-"`
+	Status         CampaignRecipientStatus `gorm:"varchar(20);not null;default:pending;index"`
+	NotificationID *uint64                 `faker:"-"` // the deliveries over each channel are kept on it
+	Error          string                  `gorm:"varchar(500)"`
+	SentAt         *time.Time              `faker:"-"`
+	Base
+}
+
+type CampaignRecipientStatus string
+
+const (
+	CampaignRecipientPending  CampaignRecipientStatus = "pending"
+	CampaignRecipientSending  CampaignRecipientStatus = "sending" // claimed by a run until the result of the send is saved
+	CampaignRecipientSent     CampaignRecipientStatus = "sent"
+	CampaignRecipientFailed   CampaignRecipientStatus = "failed"
+	CampaignRecipientOptedOut CampaignRecipientStatus = "optedOut"
+	CampaignRecipientCanceled CampaignRecipientStatus = "canceled"
+)
+
+var CampaignRecipientStatusProxy = map[CampaignRecipientStatus]string{
+	CampaignRecipientPending:  "در انتظار ارسال",
+	CampaignRecipientSending:  "در حال ارسال",
+	CampaignRecipientSent:     "ارسال شده",
+	CampaignRecipientFailed:   "ناموفق",
+	CampaignRecipientOptedOut: "لغو اشتراک",
+	CampaignRecipientCanceled: "لغو شده",
+}
+
+// CampaignOptOut is a user that does not want the campaigns of the business.
+type CampaignOptOut struct {
+	ID         uint64 `gorm:"primaryKey" faker:"-"`
+	UserID     uint64 `gorm:"not null;uniqueIndex:idx_campaign_opt_outs_user" faker:"-"`
+	BusinessID uint64 `gorm:"not null;uniqueIndex:idx_campaign_opt_outs_user" faker:"-"`
+	CreatedAt  time.Time
+}
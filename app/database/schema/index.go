@@ -1,6 +1,9 @@
 package schema
 
-import "gorm.io/gorm"
+import (
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
 
 func MainDBModels() []any {
 	// order matters
@@ -20,6 +23,22 @@ func MainDBModels() []any {
 		Notification{},
 		Wallet{},
 		Transaction{},
+		Referral{},
+		Waitlist{},
+		MachineCommand{},
+		MachineDowntime{},
+		MachineFault{},
+		MachineCompensation{},
+		UserStrike{},
+		CalendarFeed{},
+		ReminderRule{},
+		ReservationReminder{},
+		OutboxMessage{},
+		Campaign{},
+		CampaignRecipient{},
+		CampaignOptOut{},
+		OperatorChat{},
+		OtpCode{},
 	}
 }
 
@@ -39,3 +58,77 @@ func MainDBDropExtraCommands(db *gorm.DB) {
 
 func ChatDBDropExtraCommands(db *gorm.DB) {
 }
+
+// MainDBMigrateData brings the existing rows and the constraints gorm does not know about up to date
+// after the models are migrated, every statement is a no-op the second time so running it again is harmless.
+func MainDBMigrateData(db *gorm.DB, logger zerolog.Logger) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, command := range reservationLifecycleCommands {
+			if err := tx.Exec(command).Error; err != nil {
+				return err
+			}
+		}
+
+		canceled, err := ResolveReservationOverlaps(tx)
+		if err != nil {
+			return err
+		}
+		if len(canceled) > 0 {
+			logger.Warn().Interface("reservations", canceled).
+				Msg("Canceled the reservations booked over an earlier one, they have to be refunded by hand")
+		}
+
+		for _, command := range ReservationNoOverlapCommands {
+			if err := tx.Exec(command).Error; err != nil {
+				return err
+			}
+		}
+
+		// the washing machine businesses get the reminders they had before the rules, the deleted rules count
+		// so the ones the owners removed do not come back
+		var businessIDs []uint64
+		if err := tx.Model(&Business{}).
+			Where("type = ?", BTypeWMReservation).
+			Where("NOT EXISTS (SELECT 1 FROM reminder_rules WHERE reminder_rules.business_id = businesses.id)").
+			Pluck("id", &businessIDs).Error; err != nil {
+			return err
+		}
+		for _, businessID := range businessIDs {
+			rules := DefaultReminderRules(businessID)
+			if err := tx.Create(&rules).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, command := range reminderRuleCommands {
+			if err := tx.Exec(command).Error; err != nil {
+				return err
+			}
+		}
+
+		return BackfillReferralCodes(tx)
+	})
+}
+
+// the payment holds used to be reserved rows deleted 10 minutes after their creation,
+// and the used reservations stayed reserved forever
+var reservationLifecycleCommands = []string{
+	`UPDATE reservations SET status = 'expired' WHERE status = 'paymentPending'`,
+	`UPDATE reservations
+		SET status = CASE WHEN deleted_at > now() THEN 'held' ELSE 'expired' END, deleted_at = NULL
+		WHERE status = 'reserved' AND deleted_at IS NOT NULL
+		AND ABS(EXTRACT(EPOCH FROM deleted_at - created_at) - 600) < 60`,
+	`UPDATE reservations
+		SET status = CASE WHEN end_time <= now() THEN 'completed' ELSE 'inUse' END
+		WHERE status = 'reserved' AND deleted_at IS NULL AND start_time <= now()
+		AND (COALESCE(meta->>'UniWashLastCommand', '') <> ''
+			OR EXISTS (SELECT 1 FROM machine_commands WHERE machine_commands.reservation_id = reservations.id AND machine_commands.command = 'ON'))`,
+	`UPDATE reservations SET status = 'completed'
+		WHERE status = 'reserved' AND deleted_at IS NULL AND end_time <= now()
+		AND product_id IN (SELECT id FROM products WHERE COALESCE(variant_type, '') <> 'washingMachine')`,
+	`UPDATE reservations SET status = 'noShow'
+		WHERE status = 'reserved' AND deleted_at IS NULL AND end_time <= now()
+		AND NOT EXISTS (SELECT 1 FROM machine_downtimes WHERE machine_downtimes.product_id = reservations.product_id
+			AND machine_downtimes.started_at < reservations.end_time
+			AND (machine_downtimes.ended_at IS NULL OR machine_downtimes.ended_at > reservations.start_time))`,
+}
@@ -0,0 +1,81 @@
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MachineCommand is one command sent to a washing machine, with its delivery status over time.
+type MachineCommand struct {
+	ID            uint64                `gorm:"primaryKey" faker:"-"`
+	BusinessID    uint64                `gorm:"not null;index" faker:"-"`
+	ProductID     uint64                `gorm:"not null;index" faker:"-"`
+	Product       Product               `gorm:"foreignKey:ProductID" faker:"-"`
+	ReservationID *uint64               `gorm:"index" faker:"-"`
+	Reservation   *Reservation          `gorm:"foreignKey:ReservationID" faker:"-"`
+	IssuerID      uint64                `gorm:"not null" faker:"-"`
+	Issuer        User                  `gorm:"foreignKey:IssuerID" faker:"-"`
+	IssuedBy      MachineCommandIssuer  `gorm:"varchar(20);not null"`
+	Command       UniWashCommand        `gorm:"varchar(20);not null"`
+	Driver        UniWashDriver         `gorm:"varchar(20)"`
+	ReferenceID   string                `gorm:"varchar(100);index"`
+	Status        MachineCommandStatus  `gorm:"varchar(20);not null;index"`
+	Error         string                `gorm:"varchar(500)"`
+	History       MachineCommandHistory `gorm:"type:jsonb"`
+	LastCheckedAt *time.Time            ``
+	DeliveredAt   *time.Time            ``
+	Base
+}
+
+type MachineCommandIssuer string
+
+const (
+	MachineCommandIssuerUser     MachineCommandIssuer = "user"
+	MachineCommandIssuerOperator MachineCommandIssuer = "operator"
+)
+
+type MachineCommandStatus string
+
+const (
+	MachineCommandStatusSent        MachineCommandStatus = "sent"        // accepted by the provider
+	MachineCommandStatusDelivered   MachineCommandStatus = "delivered"   // reached the machine
+	MachineCommandStatusFailed      MachineCommandStatus = "failed"      // not accepted or not delivered
+	MachineCommandStatusUnconfirmed MachineCommandStatus = "unconfirmed" // the provider never confirmed the delivery
+)
+
+var MachineCommandStatusProxy = map[MachineCommandStatus]string{
+	MachineCommandStatusSent:        "ارسال شده",
+	MachineCommandStatusDelivered:   "به دستگاه رسید",
+	MachineCommandStatusFailed:      "ناموفق",
+	MachineCommandStatusUnconfirmed: "تحویل تایید نشد",
+}
+
+type MachineCommandEvent struct {
+	Status MachineCommandStatus
+	Detail string `json:",omitempty"` // raw status of the provider
+	At     time.Time
+}
+
+type MachineCommandHistory []MachineCommandEvent
+
+func (mc *MachineCommand) SetStatus(status MachineCommandStatus, detail string, at time.Time) {
+	mc.Status = status
+	mc.History = append(mc.History, MachineCommandEvent{Status: status, Detail: detail, At: at})
+	if status == MachineCommandStatusDelivered {
+		mc.DeliveredAt = &at
+	}
+}
+
+func (h *MachineCommandHistory) Scan(value any) error {
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal MachineCommandHistory with value %v", value)
+	}
+	return json.Unmarshal(byteValue, h)
+}
+
+func (h MachineCommandHistory) Value() (driver.Value, error) {
+	return json.Marshal(h)
+}
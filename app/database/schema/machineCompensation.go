@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// MachineCompensation is the report of compensating, in one action, the reservations hit by a downtime.
+type MachineCompensation struct {
+	ID         uint64                   `gorm:"primaryKey" faker:"-"`
+	BusinessID uint64                   `gorm:"not null;index" faker:"-"`
+	DowntimeID uint64                   `gorm:"not null;index" faker:"-"`
+	Downtime   MachineDowntime          `gorm:"foreignKey:DowntimeID" faker:"-"`
+	OperatorID uint64                   `gorm:"not null" faker:"-"`
+	Operator   User                     `gorm:"foreignKey:OperatorID" faker:"-"`
+	Action     CompensationAction       `gorm:"varchar(20);not null"`
+	Fallback   CompensationAction       `gorm:"varchar(20)"` // when a reservation can not be moved
+	Items      MachineCompensationItems `gorm:"type:jsonb"`
+	Base
+}
+
+type CompensationAction string
+
+const (
+	CompensationActionRefund CompensationAction = "refund" // cancel and refund to the wallet
+	CompensationActionCoupon CompensationAction = "coupon" // cancel and issue a coupon
+	CompensationActionMove   CompensationAction = "move"   // move to a free machine of the same post
+)
+
+type CompensationResult string
+
+const (
+	CompensationResultRefunded CompensationResult = "refunded"
+	CompensationResultCoupon   CompensationResult = "coupon"
+	CompensationResultMoved    CompensationResult = "moved"
+	CompensationResultCanceled CompensationResult = "canceled" // nothing was paid to refund
+	CompensationResultFailed   CompensationResult = "failed"
+)
+
+var CompensationResultProxy = map[CompensationResult]string{
+	CompensationResultRefunded: "لغو و بازگشت وجه به کیف پول",
+	CompensationResultCoupon:   "لغو و صدور کد تخفیف",
+	CompensationResultMoved:    "انتقال به دستگاه دیگر",
+	CompensationResultCanceled: "لغو بدون بازگشت وجه، مبلغی پرداخت نشده بود",
+	CompensationResultFailed:   "ناموفق",
+}
+
+type MachineCompensationItem struct {
+	ReservationID    uint64
+	UserID           uint64
+	Result           CompensationResult
+	Amount           float64 `json:",omitempty"`
+	CouponCode       string  `json:",omitempty"`
+	MovedToProductID uint64  `json:",omitempty"`
+	MovedToSKU       string  `json:",omitempty"`
+	Notified         bool
+	Error            string `json:",omitempty"`
+}
+
+type MachineCompensationItems []MachineCompensationItem
+
+func (items *MachineCompensationItems) Scan(value any) error {
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal MachineCompensationItems with value %v", value)
+	}
+	return json.Unmarshal(byteValue, items)
+}
+
+func (items MachineCompensationItems) Value() (driver.Value, error) {
+	return json.Marshal(items)
+}
@@ -0,0 +1,55 @@
+package schema
+
+import "time"
+
+// MachineDowntime is a window in which a washing machine was out of service, open while EndedAt is nil.
+type MachineDowntime struct {
+	ID         uint64                `gorm:"primaryKey" faker:"-"`
+	BusinessID uint64                `gorm:"not null;index" faker:"-"`
+	ProductID  uint64                `gorm:"not null;index" faker:"-"`
+	Product    Product               `gorm:"foreignKey:ProductID" faker:"-"`
+	Reason     MachineDowntimeReason `gorm:"varchar(20);not null"`
+	Detail     string                `gorm:"varchar(500)"`
+	StartedAt  time.Time             `gorm:"not null"`
+	EndedAt    *time.Time            `gorm:"index"`
+	OpenedByID *uint64               `faker:"-"` // nil when opened automatically
+	OpenedBy   *User                 `gorm:"foreignKey:OpenedByID" faker:"-"`
+	ClosedByID *uint64               `faker:"-"`
+	ClosedBy   *User                 `gorm:"foreignKey:ClosedByID" faker:"-"`
+	Note       string                `gorm:"varchar(500)"`
+	Base
+}
+
+type MachineDowntimeReason string
+
+const (
+	MachineDowntimeReasonUndelivered  MachineDowntimeReason = "undelivered"  // consecutive commands did not reach the machine
+	MachineDowntimeReasonFaultReports MachineDowntimeReason = "faultReports" // users reported faults
+	MachineDowntimeReasonNoDelivery   MachineDowntimeReason = "noDelivery"   // commands kept failing for a long time
+	MachineDowntimeReasonManual       MachineDowntimeReason = "manual"       // turned off by an operator
+)
+
+var MachineDowntimeReasonProxy = map[MachineDowntimeReason]string{
+	MachineDowntimeReasonUndelivered:  "عدم دریافت دستورات",
+	MachineDowntimeReasonFaultReports: "گزارش خرابی کاربران",
+	MachineDowntimeReasonNoDelivery:   "عدم تحویل طولانی دستورات",
+	MachineDowntimeReasonManual:       "توسط اپراتور",
+}
+
+func (md *MachineDowntime) IsOpen() bool {
+	return md.EndedAt == nil
+}
+
+// MachineFault is a fault of a washing machine reported by a user.
+type MachineFault struct {
+	ID            uint64       `gorm:"primaryKey" faker:"-"`
+	BusinessID    uint64       `gorm:"not null;index" faker:"-"`
+	ProductID     uint64       `gorm:"not null;index" faker:"-"`
+	Product       Product      `gorm:"foreignKey:ProductID" faker:"-"`
+	UserID        uint64       `gorm:"not null" faker:"-"`
+	User          User         `gorm:"foreignKey:UserID" faker:"-"`
+	ReservationID *uint64      `faker:"-"`
+	Reservation   *Reservation `gorm:"foreignKey:ReservationID" faker:"-"`
+	Description   string       `gorm:"varchar(500)" faker:"paragraph"`
+	Base
+}
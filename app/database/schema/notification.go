@@ -1,19 +1,26 @@
 package schema
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"github.com/lib/pq"
 	"time"
 )
 
 type Notification struct {
-	ID         uint64               `gorm:"primaryKey" faker:"-"`
-	ReceiverID uint64               `gorm:"not null" faker:"-"`
-	Receiver   User                 `gorm:"foreignKey:ReceiverID" faker:"-"`
-	Type       pq.StringArray       `gorm:"type:text[];not null" faker:"-"`
-	BusinessID uint64               `gorm:"not null" faker:"-"`
-	SentAt     time.Time            `gorm:"not null"`
-	TemplateID uint64               `faker:"-"`
-	Template   NotificationTemplate `gorm:"foreignKey:TemplateID" faker:"-"`
+	ID         uint64                 `gorm:"primaryKey" faker:"-"`
+	ReceiverID uint64                 `gorm:"not null;index" faker:"-"`
+	Receiver   User                   `gorm:"foreignKey:ReceiverID" faker:"-"`
+	Type       pq.StringArray         `gorm:"type:text[];not null" faker:"-"`
+	BusinessID uint64                 `gorm:"not null" faker:"-"`
+	SentAt     time.Time              `gorm:"not null"`
+	TemplateID *uint64                `faker:"-"` // empty for the ones the app sends, e.g. reservation confirmations
+	Template   NotificationTemplate   `gorm:"foreignKey:TemplateID" faker:"-"`
+	Content    string                 `faker:"paragraph"` // the template rendered for the receiver
+	Category   NotificationCategory   `gorm:"varchar(20);not null;default:transactional"`
+	Deliveries NotificationDeliveries `gorm:"type:jsonb" faker:"-"`
+	ReadAt     *time.Time             `faker:"-"` // by the receiver, in the inbox
 	Base
 }
 
@@ -21,5 +28,44 @@ type NotificationType string
 
 const (
 	TSms          NotificationType = "Sms"
-	TNotification NotificationType = "Notification"
+	TNotification NotificationType = "Notification" // in-app
+	TBale         NotificationType = "Bale"
 )
+
+type NotificationDeliveryStatus string
+
+const (
+	NotificationDeliveryPending   NotificationDeliveryStatus = "pending"
+	NotificationDeliveryQueued    NotificationDeliveryStatus = "queued" // in the sms outbox, its state is kept there
+	NotificationDeliverySent      NotificationDeliveryStatus = "sent"   // handed to the provider
+	NotificationDeliveryDelivered NotificationDeliveryStatus = "delivered"
+	NotificationDeliveryFailed    NotificationDeliveryStatus = "failed"
+	NotificationDeliverySkipped   NotificationDeliveryStatus = "skipped" // the receiver turned the channel off
+)
+
+// NotificationDelivery is how the notification went over one of its channels.
+type NotificationDelivery struct {
+	Status      NotificationDeliveryStatus
+	MessageID   *uint64    `json:",omitempty"` // of the outbox
+	ReferenceID string     `json:",omitempty"`
+	Error       string     `json:",omitempty"`
+	At          *time.Time `json:",omitempty"`
+}
+
+type NotificationDeliveries map[NotificationType]NotificationDelivery
+
+func (nd *NotificationDeliveries) Scan(value any) error {
+	if value == nil {
+		// the notifications from before the deliveries were recorded
+		return nil
+	}
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal NotificationDeliveries with value %v", value)
+	}
+	return json.Unmarshal(byteValue, nd)
+}
+
+func (nd NotificationDeliveries) Value() (driver.Value, error) {
+	return json.Marshal(nd)
+}
@@ -0,0 +1,62 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+)
+
+// NotificationCategory is what the user turns the notifications on or off by
+type NotificationCategory string
+
+const (
+	NotificationCategoryTransactional NotificationCategory = "transactional" // e.g. reservation confirmations, the otps can not be turned off
+	NotificationCategoryReminders     NotificationCategory = "reminders"
+	NotificationCategoryMarketing     NotificationCategory = "marketing" // e.g. campaigns and coupons, they carry an opt-out
+)
+
+var NotificationCategories = []NotificationCategory{
+	NotificationCategoryTransactional,
+	NotificationCategoryReminders,
+	NotificationCategoryMarketing,
+}
+
+var NotificationChannels = []NotificationType{TSms, TNotification, TBale}
+
+// NotificationPreferences are the channels of each category the user turned on or off, the ones not in it are on.
+type NotificationPreferences map[NotificationCategory]map[NotificationType]bool
+
+// Allows tells if the user gets the notifications of the category over the channel
+func (p NotificationPreferences) Allows(category NotificationCategory, channel NotificationType) bool {
+	if category == "" {
+		category = NotificationCategoryTransactional
+	}
+
+	on, ok := p[category][channel]
+	return !ok || on
+}
+
+// Validate finds the categories and channels that are not known
+func (p NotificationPreferences) Validate() error {
+	for category, channels := range p {
+		if !slices.Contains(NotificationCategories, category) {
+			return fmt.Errorf("دسته اعلان %s معتبر نیست", category)
+		}
+		for channel := range channels {
+			if !slices.Contains(NotificationChannels, channel) {
+				return fmt.Errorf("کانال اعلان %s معتبر نیست", channel)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Turn sets the category over the channels on or off
+func (p NotificationPreferences) Turn(category NotificationCategory, on bool, channels ...NotificationType) {
+	if p[category] == nil {
+		p[category] = map[NotificationType]bool{}
+	}
+	for _, channel := range channels {
+		p[category][channel] = on
+	}
+}
@@ -0,0 +1,16 @@
+package schema
+
+import "time"
+
+// OperatorChat is a chat of the operator bot, linked to the account of an operator of the business.
+// The commands of the chat run as that user.
+type OperatorChat struct {
+	ID         uint64    `gorm:"primaryKey" faker:"-"`
+	ChatID     int64     `gorm:"not null;uniqueIndex" faker:"-"`
+	UserID     uint64    `gorm:"not null;index" faker:"-"`
+	User       *User     `gorm:"foreignKey:UserID" faker:"-"`
+	BusinessID uint64    `gorm:"not null;index" faker:"-"`
+	Business   *Business `gorm:"foreignKey:BusinessID" faker:"-"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
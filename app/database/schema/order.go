@@ -52,6 +52,7 @@ const (
 	OrderPaymentMethodCash           OrderPaymentMethod = "cash"
 	OrderPaymentMethodOnline         OrderPaymentMethod = "online"
 	OrderPaymentMethodCashOnDelivery OrderPaymentMethod = "cashOnDelivery"
+	OrderPaymentMethodWallet         OrderPaymentMethod = "wallet"
 )
 
 type OrderMeta struct {
@@ -0,0 +1,13 @@
+package schema
+
+import "time"
+
+// OtpCode is the last one time code the app made for a mobile, kept as a hash until it is used or expires.
+// The codes of MessageWay are kept by MessageWay.
+type OtpCode struct {
+	ID        uint64    `gorm:"primaryKey" faker:"-"`
+	Mobile    string    `gorm:"type:varchar(20);not null;uniqueIndex" faker:"-"`
+	Hash      string    `gorm:"type:varchar(64);not null" faker:"-"`
+	ExpiresAt time.Time `gorm:"not null" faker:"-"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,155 @@
+package schema
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// OutboxMessage is an sms, or a message on Bale, for the outbox worker to send. It is written in the transaction of what it is about,
+// so it is neither lost when the provider is down nor sent for something that was rolled back.
+// The machine commands are not queued, they are sent right away and tracked in MachineCommand.
+type OutboxMessage struct {
+	ID            uint64         `gorm:"primaryKey" faker:"-"`
+	BusinessID    *uint64        `gorm:"index" faker:"-"`
+	Purpose       OutboxPurpose  `gorm:"varchar(30);not null;index"`
+	Priority      OutboxPriority `gorm:"not null;default:1"`
+	Template      string         `gorm:"varchar(50);not null;default:''"` // the logical name of the sms, see SmsOTP
+	Provider      string         `gorm:"varchar(30)"`                     // the one that accepted it
+	Mobile        string         `gorm:"varchar(20);not null;index"`
+	Params        pq.StringArray `gorm:"type:text[]"`
+	Channels      pq.StringArray `gorm:"type:text[]"` // tried in order until one sends it, only the sms when empty
+	BaleChatID    *int64         `faker:"-"`          // of the user, when Bale is one of the Channels
+	Status        OutboxStatus   `gorm:"varchar(20);not null;default:queued"`
+	Attempts      int            `gorm:"not null;default:0"`
+	NextAttemptAt time.Time      `gorm:"not null" faker:"-"`
+	LockedUntil   *time.Time     `faker:"-"` // while a worker is sending it
+	ReferenceID   string         `gorm:"varchar(100)"`
+	LastError     string         `gorm:"varchar(500)"`
+	SentAt        *time.Time     `faker:"-"`
+	Base
+}
+
+type OutboxPurpose string
+
+const (
+	OutboxPurposeOTP          OutboxPurpose = "otp"
+	OutboxPurposeReminder     OutboxPurpose = "reminder"
+	OutboxPurposeCoupon       OutboxPurpose = "coupon"
+	OutboxPurposeNotification OutboxPurpose = "notification"
+	OutboxPurposeWaitlist     OutboxPurpose = "waitlist"
+	OutboxPurposeReservation  OutboxPurpose = "reservation"
+	OutboxPurposeMachine      OutboxPurpose = "machine"
+	OutboxPurposeCampaign     OutboxPurpose = "campaign"
+)
+
+// Category is what the user turns the messages of the purpose on or off by, see NotificationPreferences.
+func (p OutboxPurpose) Category() NotificationCategory {
+	switch p {
+	case OutboxPurposeReminder:
+		return NotificationCategoryReminders
+	case OutboxPurposeCoupon, OutboxPurposeCampaign:
+		return NotificationCategoryMarketing
+	}
+
+	return NotificationCategoryTransactional
+}
+
+// OverBale reports whether the messages of the purpose go to the linked Bale chat of the user first, the sms
+// is the fallback. The compensation coupons are of the reservations.
+func (p OutboxPurpose) OverBale() bool {
+	switch p {
+	case OutboxPurposeOTP, OutboxPurposeReservation, OutboxPurposeReminder:
+		return true
+	}
+
+	return false
+}
+
+// OutboxPriority orders the queue, the lower ones are sent first.
+type OutboxPriority int
+
+const (
+	OutboxPriorityHigh   OutboxPriority = 0 // the user is waiting for it, e.g. an otp
+	OutboxPriorityNormal OutboxPriority = 1
+	OutboxPriorityBulk   OutboxPriority = 2 // sent to many users at once, e.g. coupons
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusQueued  OutboxStatus = "queued" // also the failed ones waiting for their next attempt
+	OutboxStatusSending OutboxStatus = "sending"
+	OutboxStatusSent    OutboxStatus = "sent"    // accepted by the provider
+	OutboxStatusDead    OutboxStatus = "dead"    // failed for good, only sent again by hand
+	OutboxStatusSkipped OutboxStatus = "skipped" // the user turned the channels of its category off
+)
+
+var OutboxStatusProxy = map[OutboxStatus]string{
+	OutboxStatusQueued:  "در صف ارسال",
+	OutboxStatusSending: "در حال ارسال",
+	OutboxStatusSent:    "ارسال شده",
+	OutboxStatusDead:    "ناموفق",
+	OutboxStatusSkipped: "لغو شده توسط کاربر",
+}
+
+// the logical names of the sms, each provider maps them to a template of its own in the config
+const (
+	SmsOTP                  = "otp"
+	SmsMachineCommand       = "machine-command"
+	SmsCoupon               = "coupon"
+	SmsReminderTurnOn       = "reminder-turn-on"
+	SmsReminderTurnOff      = "reminder-turn-off"
+	SmsDeviceOff            = "device-off"
+	SmsWaitlistOffer        = "waitlist-offer"
+	SmsDowntimeAlert        = "downtime-alert"
+	SmsReservationMoved     = "reservation-moved"
+	SmsReservationRefunded  = "reservation-refunded"
+	SmsNoShowSuspension     = "no-show-suspension"
+	SmsRescheduled          = "rescheduled"
+	SmsNotification         = "notification"          // a single param the rendered content is sent in
+	SmsReservationConfirmed = "reservation-confirmed" // only on Bale, there is no sms of it
+)
+
+// SmsNames are all the logical names of the sms
+var SmsNames = []string{
+	SmsOTP, SmsMachineCommand, SmsCoupon, SmsReminderTurnOn, SmsReminderTurnOff, SmsDeviceOff, SmsWaitlistOffer,
+	SmsDowntimeAlert, SmsReservationMoved, SmsReservationRefunded, SmsNoShowSuspension, SmsRescheduled,
+	SmsNotification, SmsReservationConfirmed,
+}
+
+// NewSms is a message of the template to the mobile, ready to be queued.
+func NewSms(purpose OutboxPurpose, mobile uint64, template string, params ...string) *OutboxMessage {
+	return &OutboxMessage{
+		Purpose:  purpose,
+		Priority: OutboxPriorityNormal,
+		Template: template,
+		Mobile:   fmt.Sprintf("0%d", mobile),
+		Params:   params,
+		Status:   OutboxStatusQueued,
+	}
+}
+
+// NewBale is a message of the template to the Bale chat of the user of the mobile only, it is not sent to the
+// users that have not linked their account to the bot.
+func NewBale(purpose OutboxPurpose, mobile uint64, template string, params ...string) *OutboxMessage {
+	message := NewSms(purpose, mobile, template, params...)
+	message.Channels = pq.StringArray{string(TBale)}
+
+	return message
+}
+
+// SendChannels are the channels the message is tried over in order.
+func (m *OutboxMessage) SendChannels() []NotificationType {
+	if len(m.Channels) == 0 {
+		return []NotificationType{TSms}
+	}
+
+	channels := make([]NotificationType, len(m.Channels))
+	for i, channel := range m.Channels {
+		channels[i] = NotificationType(channel)
+	}
+
+	return channels
+}
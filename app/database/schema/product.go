@@ -86,12 +86,22 @@ const (
 	UniWashMachineStatusOFF UniWashMachineStatus = "OFF"
 )
 
+type UniWashDriver string
+
+const (
+	UniWashDriverSMS       UniWashDriver = "sms" // default
+	UniWashDriverHTTP      UniWashDriver = "http"
+	UniWashDriverSimulator UniWashDriver = "simulator"
+)
+
 type ProductMeta struct {
 	Detail               string                         `json:",omitempty" example:"Detail" validate:"omitempty,min=2,max=500" faker:"word"`  // The stock keeping unit (SKU) of the product. This is a unique identifier for the product that is used for inventory management.
 	SKU                  string                         `json:",omitempty" example:"sku-2f3s" validate:"omitempty,min=2,max=40" faker:"word"` // The stock keeping unit (SKU) of the product. This is a unique identifier for the product that is used for inventory management.
 	UniWashMobileNumber  string                         `json:",omitempty" example:"09909999999"  faker:"-"`                                  //validate:"omitempty,min=10,max=10"                                // The stock keeping unit (SKU) of the product. This is a unique identifier for the product that is used for inventory management.
 	UniWashMachineStatus UniWashMachineStatus           `json:",omitempty" faker:"-"`
-	PurchaseNote         string                         `json:",omitempty" validator:"omitempty,min=2,max=500"` // A note that is displayed to the customer after purchasing the product.
+	UniWashDriver        UniWashDriver                  `json:",omitempty" example:"sms" validate:"omitempty,oneof=sms http simulator" faker:"-"` // how the machine controller is reached
+	UniWashDeviceURL     string                         `json:",omitempty" example:"http://10.0.0.12:8080" validate:"omitempty,url" faker:"-"`    // base url of the http controllers
+	PurchaseNote         string                         `json:",omitempty" validator:"omitempty,min=2,max=500"`                                   // A note that is displayed to the customer after purchasing the product.
 	Weight               float64                        `json:",omitempty" validator:"omitempty,number"`
 	Width                float64                        `json:",omitempty" validator:"omitempty,number"`
 	Height               float64                        `json:",omitempty" validator:"omitempty,number"`
@@ -111,7 +121,30 @@ type ProductMeta struct {
 	StockSku      string `json:",omitempty" example:"sku-2f3s" validate:"omitempty,min=2,max=40" faker:"word"`
 	StockQuantity uint64 `json:",omitempty" validate:"omitempty,number"` // The number of units of the product that are currently in stock.
 
-	ReservationOptions ProductMetaReservationOptions `json:",omitempty" faker:"-"`
+	ReservationOptions       ProductMetaReservationOptions `json:",omitempty" faker:"-"`                                  // weekly opening windows, split into slots
+	ReservationSlotDuration  uint                          `json:",omitempty" example:"60" validate:"omitempty,max=1440"` // minutes, default 60
+	ReservationBuffer        uint                          `json:",omitempty" example:"0" validate:"omitempty,max=1440"`  // minutes between two slots
+	ReservationBlackoutDates []string                      `json:",omitempty" example:"2024-03-20"`                       // "2006-01-02"
+
+	ReservationCapacity        uint `json:",omitempty" example:"20" validate:"omitempty,max=10000"` // seats of a slot, like a class, 0 or 1 means a booking takes the whole slot
+	ReservationMaxSeatsPerUser uint `json:",omitempty" example:"2" validate:"omitempty,max=10000"`  // seats one user can book in a slot, 0 means up to the capacity
+}
+
+// SeatCapacity is how many seats the bookings of a slot can take together.
+func (pm ProductMeta) SeatCapacity() int {
+	if pm.ReservationCapacity < 1 {
+		return 1
+	}
+	return int(pm.ReservationCapacity)
+}
+
+// IsReservable reports whether the product is booked for a time range, a machine, a court or a class.
+func (p Product) IsReservable() bool {
+	return p.Type == ProductTypeReservable || p.IsVariantOf(ProductVariantTypeReservable) || p.IsVariantOf(ProductVariantTypeWashingMachine)
+}
+
+func (p Product) IsVariantOf(variantType ProductVariantType) bool {
+	return p.VariantType != nil && *p.VariantType == variantType
 }
 
 func (pm *ProductMeta) Scan(value any) error {
@@ -0,0 +1,116 @@
+package schema
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Referral struct {
+	ID            uint64         `gorm:"primaryKey"`
+	InviterID     uint64         `gorm:"not null;index"`
+	Inviter       User           `gorm:"foreignKey:InviterID"`
+	InviteeID     uint64         `gorm:"not null;uniqueIndex"`
+	Invitee       User           `gorm:"foreignKey:InviteeID"`
+	InviteeMobile uint64         `gorm:"not null;uniqueIndex"` // one reward per mobile number, even after the account is removed
+	Status        ReferralStatus `gorm:"varchar(50);default:pending;not null"`
+	BusinessID    *uint64        `gorm:"index"` // the business that paid the reward
+	Business      *Business      `gorm:"foreignKey:BusinessID"`
+	OrderID       *uint64        `` // the first completed order of the invitee
+	Meta          ReferralMeta   `gorm:"type:jsonb"`
+	Base
+}
+
+type ReferralStatus string
+
+const (
+	ReferralStatusPending  ReferralStatus = "pending"  // invitee registered, waiting for the first completed order
+	ReferralStatusRewarded ReferralStatus = "rewarded" // both inviter and invitee rewarded
+	ReferralStatusCapped   ReferralStatus = "capped"   // only invitee rewarded, inviter reached the cap
+	ReferralStatusRejected ReferralStatus = "rejected"
+)
+
+var ReferralStatusProxy = map[ReferralStatus]string{
+	ReferralStatusPending:  "در انتظار اولین سفارش",
+	ReferralStatusRewarded: "پاداش داده شد",
+	ReferralStatusCapped:   "سقف دعوت معرف پر شده",
+	ReferralStatusRejected: "رد شده",
+}
+
+type ReferralRewardType string
+
+const (
+	ReferralRewardTypeCoupon ReferralRewardType = "coupon"
+	ReferralRewardTypeWallet ReferralRewardType = "wallet"
+)
+
+type ReferralMeta struct {
+	RewardType        ReferralRewardType `json:",omitempty"`
+	InviterReward     float64            `json:",omitempty"`
+	InviteeReward     float64            `json:",omitempty"`
+	InviterCouponCode string             `json:",omitempty"`
+	InviteeCouponCode string             `json:",omitempty"`
+	RewardedAt        *time.Time         `json:",omitempty"`
+	RejectReason      string             `json:",omitempty"`
+}
+
+func (rm *ReferralMeta) Scan(value any) error {
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal ReferralMeta with value %v", value)
+	}
+	return json.Unmarshal(byteValue, rm)
+}
+
+func (rm ReferralMeta) Value() (driver.Value, error) {
+	return json.Marshal(rm)
+}
+
+const (
+	referralCodeLength  = 8
+	referralCodeCharset = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+// NewReferralCode draws the code of the invitation link of a user, the unique index of the users tells whether
+// it is free.
+func NewReferralCode() string {
+	code := make([]byte, referralCodeLength)
+	for i := range code {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(referralCodeCharset))))
+		code[i] = referralCodeCharset[n.Int64()]
+	}
+
+	return string(code)
+}
+
+// BackfillReferralCodes gives a code to the users from before the referral program, a code that is taken is
+// drawn again.
+func BackfillReferralCodes(tx *gorm.DB) error {
+	var userIDs []uint64
+	if err := tx.Model(&User{}).Where("referral_code IS NULL").Pluck("id", &userIDs).Error; err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		var err error
+		for i := 0; i < 10; i++ {
+			// a savepoint, so a taken code doesn't abort the migration
+			err = tx.Transaction(func(tx *gorm.DB) error {
+				return tx.Model(&User{}).Where("id = ?", userID).Update("referral_code", NewReferralCode()).Error
+			})
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ReminderRule sends a message to the users of the reservations of a business at an offset from their start
+// or end. Each sent reminder is recorded in ReservationReminder, so a rule reaches a reservation once.
+type ReminderRule struct {
+	ID            uint64             `gorm:"primaryKey" faker:"-"`
+	BusinessID    uint64             `gorm:"not null;index" faker:"-"`
+	Business      Business           `gorm:"foreignKey:BusinessID" faker:"-"`
+	Title         string             `gorm:"varchar(100)"`
+	Trigger       ReminderTrigger    `gorm:"varchar(20);not null"`
+	OffsetMinutes int                `gorm:"not null;default:0"`
+	Conditions    ReminderConditions `gorm:"type:jsonb"`
+	Channel       ReminderChannel    `gorm:"varchar(20);not null;default:sms"`
+	Template      string             `gorm:"varchar(50);not null;default:''"` // the logical name of the sms, see SmsOTP
+	Disabled      bool               `gorm:"not null;default:false"`
+	Base
+}
+
+type ReminderTrigger string
+
+const (
+	ReminderTriggerBeforeStart ReminderTrigger = "beforeStart"
+	ReminderTriggerAfterStart  ReminderTrigger = "afterStart"
+	ReminderTriggerBeforeEnd   ReminderTrigger = "beforeEnd"
+	ReminderTriggerAfterEnd    ReminderTrigger = "afterEnd"
+)
+
+var ReminderTriggerProxy = map[ReminderTrigger]string{
+	ReminderTriggerBeforeStart: "قبل از شروع",
+	ReminderTriggerAfterStart:  "بعد از شروع",
+	ReminderTriggerBeforeEnd:   "قبل از پایان",
+	ReminderTriggerAfterEnd:    "بعد از پایان",
+}
+
+type ReminderChannel string
+
+const (
+	ReminderChannelSMS ReminderChannel = "sms"
+)
+
+// ReminderConditions are checked on the reservation when the reminder is due, the unmet ones skip it.
+type ReminderConditions struct {
+	Statuses    []ReservationStatus `json:",omitempty"` // empty means the reserved and in use ones
+	MachineOn   bool                `json:",omitempty"` // the machine is not turned off by the operators
+	CommandSent bool                `json:",omitempty"` // the user has sent a command to the machine
+}
+
+func (rc *ReminderConditions) Scan(value any) error {
+	byteValue, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal ReminderConditions with value %v", value)
+	}
+	return json.Unmarshal(byteValue, rc)
+}
+
+func (rc ReminderConditions) Value() (driver.Value, error) {
+	return json.Marshal(rc)
+}
+
+// ReservationStatuses are the statuses the reservation may have for the reminder to be sent.
+func (rc ReminderConditions) ReservationStatuses() []ReservationStatus {
+	if len(rc.Statuses) == 0 {
+		return []ReservationStatus{ReservationStatusReserved, ReservationStatusInUse}
+	}
+	return rc.Statuses
+}
+
+// Met reports whether the reservation, with its product and post, meets the conditions.
+func (rc ReminderConditions) Met(reservation *Reservation) bool {
+	if reservation.Product.Post.Status != PostStatusPublished {
+		return false
+	}
+	if rc.MachineOn && reservation.Product.Meta.UniWashMachineStatus != UniWashMachineStatusON {
+		return false
+	}
+	if rc.CommandSent && reservation.Meta.UniWashLastCommand == "" {
+		return false
+	}
+	return true
+}
+
+// DueBetween gives the column and its range for the reservations whose reminder time is between from and to.
+func (r ReminderRule) DueBetween(from time.Time, to time.Time) (column string, start time.Time, end time.Time) {
+	offset := time.Duration(r.OffsetMinutes) * time.Minute
+
+	column = "start_time"
+	if r.Trigger == ReminderTriggerBeforeEnd || r.Trigger == ReminderTriggerAfterEnd {
+		column = "end_time"
+	}
+	if r.Trigger == ReminderTriggerAfterStart || r.Trigger == ReminderTriggerAfterEnd {
+		offset = -offset
+	}
+
+	return column, from.Add(offset), to.Add(offset)
+}
+
+// DefaultReminderRules are the rules a washing machine business starts with: turning the machine on an hour
+// before the reservation, and turning it off 20 minutes before its end.
+func DefaultReminderRules(businessID uint64) []ReminderRule {
+	return []ReminderRule{
+		{
+			BusinessID:    businessID,
+			Title:         "یادآوری روشن کردن دستگاه",
+			Trigger:       ReminderTriggerBeforeStart,
+			OffsetMinutes: 60,
+			Conditions:    ReminderConditions{Statuses: []ReservationStatus{ReservationStatusReserved}, MachineOn: true},
+			Channel:       ReminderChannelSMS,
+			Template:      SmsReminderTurnOn,
+		},
+		{
+			BusinessID:    businessID,
+			Title:         "یادآوری پایان استفاده از دستگاه",
+			Trigger:       ReminderTriggerBeforeEnd,
+			OffsetMinutes: 20,
+			Conditions:    ReminderConditions{Statuses: []ReservationStatus{ReservationStatusInUse}, MachineOn: true, CommandSent: true},
+			Channel:       ReminderChannelSMS,
+			Template:      SmsReminderTurnOff,
+		},
+	}
+}
+
+// ReservationReminder is a reminder sent for a reservation by a rule.
+type ReservationReminder struct {
+	ID            uint64          `gorm:"primaryKey" faker:"-"`
+	ReservationID uint64          `gorm:"not null;uniqueIndex:idx_reservation_reminders_rule" faker:"-"`
+	Reservation   Reservation     `gorm:"foreignKey:ReservationID" faker:"-"`
+	RuleID        uint64          `gorm:"not null;uniqueIndex:idx_reservation_reminders_rule;index" faker:"-"`
+	Rule          ReminderRule    `gorm:"foreignKey:RuleID" faker:"-"`
+	Channel       ReminderChannel `gorm:"varchar(20);not null"`
+	MessageID     *uint64         `gorm:"index" faker:"-"` // the queued sms, its state is in the outbox
+	ReferenceID   string          `gorm:"varchar(100)"`    // of the reminders sent before the outbox
+	SentAt        time.Time       `gorm:"not null" faker:"-"`
+}
+
+// the reminders sent before the rules were marked on the reservations
+var reminderRuleCommands = []string{
+	fmt.Sprintf(`INSERT INTO reservation_reminders (reservation_id, rule_id, channel, sent_at)
+		SELECT reservations.id, reminder_rules.id, reminder_rules.channel, reservations.updated_at FROM reservations
+		JOIN reminder_rules ON reminder_rules.business_id = reservations.business_id AND reminder_rules.template = '%s'
+		WHERE reservations.meta->>'TurnOnReminderSent' = 'true'
+		ON CONFLICT DO NOTHING`, SmsReminderTurnOn),
+	fmt.Sprintf(`INSERT INTO reservation_reminders (reservation_id, rule_id, channel, sent_at)
+		SELECT reservations.id, reminder_rules.id, reminder_rules.channel, reservations.updated_at FROM reservations
+		JOIN reminder_rules ON reminder_rules.business_id = reservations.business_id AND reminder_rules.template = '%s'
+		WHERE reservations.meta->>'TurnOffReminderSent' = 'true'
+		ON CONFLICT DO NOTHING`, SmsReminderTurnOff),
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Reservation TODO add proper index
@@ -21,17 +23,126 @@ type Reservation struct {
 	Business       Business          `gorm:"foreignKey:BusinessID" faker:"-"`
 	Meta           ReservationMeta   `gorm:"type:jsonb"`
 	UserUsageCount uint64            ``
+	Seats          int               `gorm:"not null;default:1"`     // seats of a class taken by the booking
+	Shared         bool              `gorm:"not null;default:false"` // the slot has seats, the other bookings can overlap it
 	Base
 }
 
 type ReservationStatus string
 
+// A reservation is held while waiting for payment, reserved once paid, inUse after the machine is
+// turned on and completed when its time is over. A hold that is not paid in time expires.
 const (
-	ReservationStatusCanceled       ReservationStatus = "canceled"
+	ReservationStatusHeld           ReservationStatus = "held"
 	ReservationStatusReserved       ReservationStatus = "reserved"
-	ReservationStatusPaymentPending ReservationStatus = "paymentPending"
+	ReservationStatusInUse          ReservationStatus = "inUse"
+	ReservationStatusCompleted      ReservationStatus = "completed"
+	ReservationStatusCanceled       ReservationStatus = "canceled"
+	ReservationStatusNoShow         ReservationStatus = "noShow" // the machine was not turned on in time
+	ReservationStatusExpired        ReservationStatus = "expired"
+	ReservationStatusPaymentPending ReservationStatus = "paymentPending" // Deprecated: migrated to held or expired
 )
 
+var ReservationStatusProxy = map[ReservationStatus]string{
+	ReservationStatusHeld:      "در انتظار پرداخت",
+	ReservationStatusReserved:  "رزرو شده",
+	ReservationStatusInUse:     "در حال استفاده",
+	ReservationStatusCompleted: "انجام شده",
+	ReservationStatusCanceled:  "لغو شده",
+	ReservationStatusNoShow:    "عدم حضور",
+	ReservationStatusExpired:   "منقضی شده",
+}
+
+// ReservationHoldDuration is how long a held reservation keeps its slot for the payment.
+const ReservationHoldDuration = 10 * time.Minute
+
+// ReservationNoOverlapConstraint stops two reservations that take their slot from overlapping on a machine,
+// whatever was checked before inserting them. The holds have to be expired to give their slot back.
+// The shared slots are left out, their seats are counted under a lock instead.
+const ReservationNoOverlapConstraint = "reservations_no_overlap"
+
+var ReservationNoOverlapCommands = []string{
+	`CREATE EXTENSION IF NOT EXISTS btree_gist`,
+	// the first version of the constraint did not know the shared slots
+	`DO $$ BEGIN
+		IF EXISTS (SELECT 1 FROM pg_constraint WHERE conname = '` + ReservationNoOverlapConstraint + `'
+			AND pg_get_constraintdef(oid) NOT LIKE '%shared%') THEN
+			ALTER TABLE reservations DROP CONSTRAINT ` + ReservationNoOverlapConstraint + `;
+		END IF;
+	END $$`,
+	`DO $$ BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = '` + ReservationNoOverlapConstraint + `') THEN
+			ALTER TABLE reservations ADD CONSTRAINT ` + ReservationNoOverlapConstraint + ` EXCLUDE USING gist (
+				product_id WITH =, tstzrange(start_time, end_time) WITH &&
+			) WHERE (status IN ('held', 'reserved', 'inUse') AND deleted_at IS NULL AND NOT shared);
+		END IF;
+	END $$`,
+}
+
+// ResolveReservationOverlaps cancels the reservations that overlap an earlier one on its machine, so the
+// constraint can be added to a database that was booked twice before it. The earliest booking of the slot is
+// kept, the held ones expire and the others are canceled. Their ids are returned for the operators to refund.
+// Nothing is done once the constraint is there.
+func ResolveReservationOverlaps(tx *gorm.DB) (canceled []uint64, err error) {
+	var constrained bool
+	if err = tx.Raw(`SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = ? AND pg_get_constraintdef(oid) LIKE '%shared%')`,
+		ReservationNoOverlapConstraint).Scan(&constrained).Error; err != nil || constrained {
+		return nil, err
+	}
+
+	var overlapping []*Reservation
+	if err = tx.Raw(`SELECT r.* FROM reservations r
+		WHERE r.status IN ('held', 'reserved', 'inUse') AND r.deleted_at IS NULL AND NOT r.shared
+		AND EXISTS (
+			SELECT 1 FROM reservations o
+			WHERE o.id <> r.id AND o.product_id = r.product_id
+			AND o.status IN ('held', 'reserved', 'inUse') AND o.deleted_at IS NULL AND NOT o.shared
+			AND tstzrange(o.start_time, o.end_time) && tstzrange(r.start_time, r.end_time)
+		)
+		ORDER BY r.product_id, r.created_at, r.id`).Scan(&overlapping).Error; err != nil {
+		return nil, err
+	}
+
+	// the rows that overlap nothing are not among them, so only the kept ones of the list can be in the way
+	var kept []*Reservation
+	for _, reservation := range overlapping {
+		overlaps := false
+		for _, other := range kept {
+			if other.ProductID == reservation.ProductID &&
+				other.StartTime.Before(reservation.EndTime) && reservation.StartTime.Before(other.EndTime) {
+				overlaps = true
+				break
+			}
+		}
+
+		if overlaps {
+			canceled = append(canceled, reservation.ID)
+		} else {
+			kept = append(kept, reservation)
+		}
+	}
+	if len(canceled) == 0 {
+		return nil, nil
+	}
+
+	err = tx.Model(&Reservation{}).
+		Where("id IN ?", canceled).
+		Update("status", gorm.Expr("CASE WHEN status = ? THEN ? ELSE ? END",
+			ReservationStatusHeld, ReservationStatusExpired, ReservationStatusCanceled)).Error
+
+	return canceled, err
+}
+
+// ReservationTakesSlot keeps the reservations that take their slot at the time: the paid ones
+// and the holds that have not expired yet.
+func ReservationTakesSlot(now time.Time) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("reservations.status IN ? OR (reservations.status = ? AND reservations.created_at > ?)",
+			[]ReservationStatus{ReservationStatusReserved, ReservationStatusInUse},
+			ReservationStatusHeld, now.Add(-ReservationHoldDuration))
+	}
+}
+
 type UniWashCommand string
 
 const (
@@ -44,11 +155,21 @@ const (
 )
 
 type ReservationMeta struct {
-	UniWashLastCommand            UniWashCommand `json:",omitempty"`
-	UniWashLastCommandTime        *time.Time     `json:",omitempty"`
-	UniWashLastCommandReferenceID string         `json:",omitempty"`
-	TurnOnReminderSent            bool           `json:",omitempty"`
-	TurnOffReminderSent           bool           `json:",omitempty"`
+	UniWashLastCommand            UniWashCommand          `json:",omitempty"`
+	UniWashLastCommandTime        *time.Time              `json:",omitempty"`
+	UniWashLastCommandReferenceID string                  `json:",omitempty"`
+	TurnOnReminderSent            bool                    `json:",omitempty"` // Deprecated: the sent reminders are ReservationReminder
+	TurnOffReminderSent           bool                    `json:",omitempty"` // Deprecated: the sent reminders are ReservationReminder
+	Reschedules                   []ReservationReschedule `json:",omitempty"`
+}
+
+// ReservationReschedule keeps where the reservation was before the user moved it.
+type ReservationReschedule struct {
+	FromProductID   uint64
+	FromStartTime   time.Time
+	FromEndTime     time.Time
+	PriceDifference float64 `json:",omitempty"` // charged from the wallet when positive, credited when negative
+	At              time.Time
 }
 
 func (pm *ReservationMeta) Scan(value any) error {
@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/exp/slices"
+	"time"
+)
+
+const DefaultReservationSlotDuration = 60 // minutes
+
+// DefaultReservationOptions opens the whole week, all day long.
+func DefaultReservationOptions() ProductMetaReservationOptions {
+	options := ProductMetaReservationOptions{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		options[day] = []ProductMetaDeviceHour{{From: "00:00:00", To: "00:00:00"}}
+	}
+	return options
+}
+
+// GetReservationOptions splits the weekly windows of the product into bookable slots.
+// Products without a schedule get 24 one-hour slots per day.
+func (pm ProductMeta) GetReservationOptions() ProductMetaReservationOptions {
+	windows := pm.ReservationOptions
+	if len(windows) == 0 {
+		windows = DefaultReservationOptions()
+	}
+
+	duration := pm.ReservationSlotDuration
+	if duration == 0 {
+		duration = DefaultReservationSlotDuration
+	}
+
+	options := ProductMetaReservationOptions{}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		options[day] = []ProductMetaDeviceHour{}
+		for _, window := range windows[day] {
+			from, to, err := window.minutes()
+			if err != nil {
+				continue
+			}
+
+			for start := from; start+int(duration) <= to; start += int(duration + pm.ReservationBuffer) {
+				options[day] = append(options[day], newDeviceHour(day, start, start+int(duration)))
+			}
+		}
+	}
+
+	return options
+}
+
+// ValidateReservationSchedule checks the schedule while saving the product.
+func (pm ProductMeta) ValidateReservationSchedule() error {
+	for day, windows := range pm.ReservationOptions {
+		if day < time.Sunday || day > time.Saturday {
+			return errors.New("روز هفته در برنامه رزرو معتبر نیست")
+		}
+		for _, window := range windows {
+			if _, _, err := window.minutes(); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, date := range pm.ReservationBlackoutDates {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return fmt.Errorf("تاریخ تعطیل %s معتبر نیست", date)
+		}
+	}
+
+	return nil
+}
+
+// IsBlackoutDate reports whether the given date ("2006-01-02") is closed for reservation.
+func (pm ProductMeta) IsBlackoutDate(date string) bool {
+	return slices.Contains(pm.ReservationBlackoutDates, date)
+}
+
+// minutes returns the window as minutes of the day, "00:00:00" as end of the window means midnight.
+func (dh ProductMetaDeviceHour) minutes() (from int, to int, err error) {
+	fromTime, err := time.Parse(time.TimeOnly, dh.From)
+	if err != nil {
+		return 0, 0, fmt.Errorf("ساعت شروع %s معتبر نیست", dh.From)
+	}
+	toTime, err := time.Parse(time.TimeOnly, dh.To)
+	if err != nil {
+		return 0, 0, fmt.Errorf("ساعت پایان %s معتبر نیست", dh.To)
+	}
+
+	from = fromTime.Hour()*60 + fromTime.Minute()
+	to = toTime.Hour()*60 + toTime.Minute()
+	if to == 0 {
+		to = 24 * 60
+	}
+
+	if from >= to {
+		return 0, 0, fmt.Errorf("بازه %s - %s معتبر نیست", dh.From, dh.To)
+	}
+
+	return from, to, nil
+}
+
+func newDeviceHour(day time.Weekday, start int, end int) ProductMetaDeviceHour {
+	id := fmt.Sprintf("%d-%02d", day, start/60)
+	if start%60 != 0 {
+		id = fmt.Sprintf("%d-%02d-%02d", day, start/60, start%60)
+	}
+
+	return ProductMetaDeviceHour{
+		ID:   id,
+		From: fmt.Sprintf("%02d:%02d:00", start/60, start%60),
+		To:   fmt.Sprintf("%02d:%02d:00", (end/60)%24, end%60),
+	}
+}
+
+// TimesOn returns the start and end of the slot on the given date, in the date location.
+func (dh ProductMetaDeviceHour) TimesOn(date time.Time) (start time.Time, end time.Time) {
+	from, to, err := dh.minutes()
+	if err != nil {
+		return
+	}
+
+	// time.Date instead of adding the minutes to the midnight, so the slots stay on the clock on the DST days
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, from, 0, 0, date.Location()), time.Date(y, m, d, 0, to, 0, 0, date.Location())
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"go-fiber-starter/utils/helpers"
+	"time"
 
 	"golang.org/x/exp/slices"
 )
@@ -28,6 +29,7 @@ type User struct {
 	Dormitory        *Taxonomy       `gorm:"foreignKey:DormitoryID" faker:"-"`
 	Businesses       []*Business     `gorm:"many2many:business_users;" faker:"-"`
 	ReservationCount uint64          `gorm:"" faker:"-"`
+	ReferralCode     *string         `gorm:"varchar(20);uniqueIndex" faker:"-"`
 	Meta             *UserMeta       `gorm:"type:jsonb" faker:"-"`
 	//FullName  string `gorm:"->;type:GENERATED ALWAYS AS (concat(first_name,' ',last_name));default:(-);"`
 	Base
@@ -83,8 +85,11 @@ type UserMetaTaxonomiesToObserve map[uint64]struct {
 }
 
 type UserMeta struct {
-	PostsToObserve      []uint64                    `json:",omitempty" example:"[1,2,3]"`
-	TaxonomiesToObserve UserMetaTaxonomiesToObserve `json:",omitempty" example:"{1: { checked: true; partialChecked: false }}"`
+	PostsToObserve          []uint64                    `json:",omitempty" example:"[1,2,3]"`
+	TaxonomiesToObserve     UserMetaTaxonomiesToObserve `json:",omitempty" example:"{1: { checked: true; partialChecked: false }}"`
+	SuspendedUntil          *time.Time                  `json:",omitempty"` // set when the suspension is lifted automatically
+	NotificationPreferences NotificationPreferences     `json:",omitempty"`
+	BaleChatID              int64                       `json:",omitempty"` // of the user bot, set when the user links the account to it
 }
 
 func (um *UserMeta) Scan(value any) error {
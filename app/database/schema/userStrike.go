@@ -0,0 +1,19 @@
+package schema
+
+// UserStrike is recorded for every reservation the user did not show up for.
+type UserStrike struct {
+	ID            uint64       `gorm:"primaryKey" faker:"-"`
+	UserID        uint64       `gorm:"not null;index" faker:"-"`
+	User          User         `gorm:"foreignKey:UserID" faker:"-"`
+	BusinessID    uint64       `gorm:"not null;index" faker:"-"`
+	ReservationID uint64       `gorm:"not null;uniqueIndex" faker:"-"`
+	Reservation   *Reservation `gorm:"foreignKey:ReservationID" faker:"-"`
+	Reason        StrikeReason `gorm:"varchar(20);not null"`
+	Base
+}
+
+type StrikeReason string
+
+const (
+	StrikeReasonNoShow StrikeReason = "noShow"
+)
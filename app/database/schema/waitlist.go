@@ -0,0 +1,42 @@
+package schema
+
+import "time"
+
+// Waitlist is a request of a user for a fully booked slot, either of a
+// specific machine (ProductID) or of any machine of a taxonomy (TaxonomyID).
+type Waitlist struct {
+	ID               uint64         `gorm:"primaryKey" faker:"-"`
+	UserID           uint64         `gorm:"not null;index" faker:"-"`
+	User             User           `gorm:"foreignKey:UserID" faker:"-"`
+	BusinessID       uint64         `gorm:"not null;index" faker:"-"`
+	Business         Business       `gorm:"foreignKey:BusinessID" faker:"-"`
+	ProductID        *uint64        `gorm:"index" faker:"-"`
+	Product          *Product       `gorm:"foreignKey:ProductID" faker:"-"`
+	TaxonomyID       *uint64        `gorm:"index" faker:"-"`
+	Taxonomy         *Taxonomy      `gorm:"foreignKey:TaxonomyID" faker:"-"`
+	StartTime        time.Time      `gorm:"not null;index" faker:"-"`
+	EndTime          time.Time      `gorm:"not null" faker:"-"`
+	Status           WaitlistStatus `gorm:"varchar(50);default:waiting;not null;index"`
+	OfferedProductID *uint64        `gorm:"index" faker:"-"` // the machine held for the user
+	OfferedProduct   *Product       `gorm:"foreignKey:OfferedProductID" faker:"-"`
+	OfferExpiresAt   *time.Time     `faker:"-"`
+	Base
+}
+
+type WaitlistStatus string
+
+const (
+	WaitlistStatusWaiting   WaitlistStatus = "waiting"
+	WaitlistStatusOffered   WaitlistStatus = "offered" // a machine is exclusively held for the user until OfferExpiresAt
+	WaitlistStatusFulfilled WaitlistStatus = "fulfilled"
+	WaitlistStatusExpired   WaitlistStatus = "expired"
+	WaitlistStatusCanceled  WaitlistStatus = "canceled"
+)
+
+var WaitlistStatusProxy = map[WaitlistStatus]string{
+	WaitlistStatusWaiting:   "در صف انتظار",
+	WaitlistStatusOffered:   "در انتظار رزرو کاربر",
+	WaitlistStatusFulfilled: "رزرو شد",
+	WaitlistStatusExpired:   "منقضی شده",
+	WaitlistStatusCanceled:  "لغو شده",
+}
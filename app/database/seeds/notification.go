@@ -44,7 +44,8 @@ func (Notification) Seed(db *gorm.DB) error {
 
 		fakeData.ReceiverID = utils.RandomFromArray(userIDs)
 		fakeData.BusinessID = utils.RandomFromArray(businessIDs)
-		fakeData.TemplateID = utils.RandomFromArray(notifTemplateIDs)
+		templateID := utils.RandomFromArray(notifTemplateIDs)
+		fakeData.TemplateID = &templateID
 
 		if err := db.Create(fakeData).Error; err != nil {
 			log.Error().Err(err)
@@ -23,6 +23,17 @@ func Protected(cfg *config.Config) fiber.Handler {
 	})
 }
 
+// ProtectedStream also takes the token from the query, the browsers can not set headers on an EventSource.
+func ProtectedStream(cfg *config.Config) fiber.Handler {
+	return jwtware.New(jwtware.Config{
+		ErrorHandler:   jwtError,
+		SuccessHandler: jwtSuccess,
+		Claims:         &JWTCustomClaim{},
+		SigningKey:     []byte(cfg.Middleware.Jwt.Secret),
+		TokenLookup:    "header:Authorization,query:token",
+	})
+}
+
 func jwtError(c *fiber.Ctx, err error) error {
 	if err.Error() == "Missing or malformed JWT" {
 		return c.Status(fiber.StatusUnauthorized).
@@ -43,7 +43,7 @@ func BusinessPermission(domain Domain, permission Permission) fiber.Handler {
 			return errors.New("مسیر وارد شده صحیح نیست")
 		}
 
-		if hasPermission(user.Permissions, businessID, domain, permission) {
+		if HasBusinessPermission(&user, businessID, domain, permission) {
 			return c.Next()
 		}
 		return c.Status(fiber.StatusForbidden).
@@ -51,6 +51,15 @@ func BusinessPermission(domain Domain, permission Permission) fiber.Handler {
 	}
 }
 
+// HasBusinessPermission is the check of BusinessPermission for the callers that are not a request, like the bots.
+func HasBusinessPermission(user *schema.User, businessID uint64, domain Domain, permission Permission) bool {
+	if user.IsAdmin() {
+		return true
+	}
+
+	return hasPermission(user.Permissions, businessID, domain, permission)
+}
+
 func hasPermission(userPermissions schema.UserPermissions, businessID uint64, domain Domain, permission Permission) bool {
 	for _, role := range userPermissions[businessID] {
 		r, ok1 := Permissions[role]
@@ -96,6 +105,7 @@ var Permissions = map[schema.UserRole]map[Domain]map[Permission]bool{
 		DReservation:          {PCreate: true, PReadAll: true, PReadSingle: true, PUpdate: true, PDelete: true},
 		DNotification:         {PCreate: true, PReadAll: true, PReadSingle: true, PUpdate: true, PDelete: true},
 		DNotificationTemplate: {PCreate: true, PReadAll: true, PReadSingle: true, PUpdate: true, PDelete: true},
+		DReferral:             {PCreate: true, PReadAll: true, PReadSingle: true, PUpdate: true, PDelete: true},
 	},
 	schema.URBusinessOwner: {
 		DUser:                 {PReadAll: true, PReadSingle: true, PDelete: true},
@@ -113,6 +123,7 @@ var Permissions = map[schema.UserRole]map[Domain]map[Permission]bool{
 		DReservation:          {PCreate: true, PReadAll: true, PReadSingle: true, PUpdate: true, PDelete: true},
 		DNotification:         {PCreate: true, PReadAll: true, PReadSingle: true, PUpdate: true, PDelete: true},
 		DNotificationTemplate: {PCreate: true, PReadAll: true, PReadSingle: true, PUpdate: true, PDelete: true},
+		DReferral:             {PReadAll: true, PReadSingle: true},
 	},
 	schema.URUser: {
 		DTaxonomy:     {PReadAll: true},
@@ -157,6 +168,7 @@ const (
 	DNotification
 	DMessageRoom
 	DNotificationTemplate
+	DReferral
 )
 
 // end define Domains
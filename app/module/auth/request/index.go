@@ -7,8 +7,9 @@ type Login struct {
 
 type Register struct {
 	Login
-	FirstName string `example:"mahdi" validate:"required,min=2,max=255"`
-	LastName  string `example:"lastname" validate:"required,min=2,max=255"`
+	FirstName    string `example:"mahdi" validate:"required,min=2,max=255"`
+	LastName     string `example:"lastname" validate:"required,min=2,max=255"`
+	ReferralCode string `example:"ab12cd34" validate:"omitempty,max=20"`
 }
 
 type SendOtp struct {
@@ -3,14 +3,15 @@ package service
 import (
 	"errors"
 	"fmt"
-	MessageWay "github.com/MessageWay/MessageWayGolang"
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/middleware"
 	"go-fiber-starter/app/module/auth/request"
 	"go-fiber-starter/app/module/auth/response"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	referralService "go-fiber-starter/app/module/referral/service"
 	usersRepo "go-fiber-starter/app/module/user/repository"
 	userResponse "go-fiber-starter/app/module/user/response"
-	"go-fiber-starter/internal"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/utils/config"
 	"go-fiber-starter/utils/helpers"
 	"gorm.io/gorm"
@@ -25,16 +26,20 @@ type IService interface {
 	ResetPass(req *request.ResetPass) error
 }
 
-func Service(Repo usersRepo.IRepository, MessageWay *internal.MessageWayService) IService {
+func Service(Repo usersRepo.IRepository, Sms *sms.Service, ReferralService referralService.IService, OutboxService outboxService.IService) IService {
 	return &service{
 		Repo,
-		MessageWay,
+		Sms,
+		ReferralService,
+		OutboxService,
 	}
 }
 
 type service struct {
-	Repo       usersRepo.IRepository
-	MessageWay *internal.MessageWayService
+	Repo            usersRepo.IRepository
+	Sms             *sms.Service
+	ReferralService referralService.IService
+	OutboxService   outboxService.IService
 }
 
 func (_i *service) Login(req request.Login, jwtConfig config.Jwt) (res response.Auth, err error) {
@@ -67,16 +72,33 @@ func (_i *service) Login(req request.Login, jwtConfig config.Jwt) (res response.
 }
 
 func (_i *service) Register(req *request.Register, jwtConfig config.Jwt) (res response.Auth, err error) {
+	var inviter *schema.User
+	if req.ReferralCode != "" {
+		inviter, err = _i.ReferralService.ValidateCode(req.ReferralCode, req.Mobile)
+		if err != nil {
+			return response.Auth{}, err
+		}
+	}
+
 	// check user by username
+	code := schema.NewReferralCode()
 	user := &schema.User{
-		Mobile:      req.Mobile,
-		LastName:    req.LastName,
-		FirstName:   req.FirstName,
-		Permissions: schema.UserPermissions{},
-		Password:    helpers.Hash([]byte(req.Password)),
+		Mobile:       req.Mobile,
+		LastName:     req.LastName,
+		FirstName:    req.FirstName,
+		Permissions:  schema.UserPermissions{},
+		Password:     helpers.Hash([]byte(req.Password)),
+		ReferralCode: &code,
+	}
+
+	// the user and the referral are saved together, so a failed referral can be registered again
+	tx, err := _i.Repo.BeginTransaction()
+	if err != nil {
+		return response.Auth{}, err
 	}
+	defer tx.Rollback()
 
-	err = _i.Repo.Create(user)
+	err = _i.Repo.Create(user, tx)
 	if err != nil {
 		if strings.Contains(err.Error(), "idx_users_mobile") {
 			err = errors.New("این شماره موبایل قبلا استفاده شده است")
@@ -86,6 +108,16 @@ func (_i *service) Register(req *request.Register, jwtConfig config.Jwt) (res re
 		return response.Auth{}, err
 	}
 
+	if inviter != nil {
+		if err = _i.ReferralService.Attach(inviter, user, tx); err != nil {
+			return response.Auth{}, err
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return response.Auth{}, err
+	}
+
 	// do create token
 	token, expiresAt, err := middleware.GenerateTokenAccess(*user, jwtConfig)
 	if err != nil {
@@ -105,16 +137,12 @@ func (_i *service) SendOtp(req *request.SendOtp) error {
 		return err
 	}
 
-	_, err = _i.MessageWay.Send(MessageWay.Message{
-		TemplateID: 3,
-		Method:     "sms",
-		Mobile:     fmt.Sprint("0", user.Mobile),
-	})
+	// the code is made by the provider that sends it, or the Bale bot of a linked account, before anything
+	// else in the queue
+	message := schema.NewSms(schema.OutboxPurposeOTP, user.Mobile, schema.SmsOTP)
+	message.Priority = schema.OutboxPriorityHigh
 
-	if err != nil {
-		return err
-	}
-	return nil
+	return _i.OutboxService.Enqueue(nil, message)
 }
 
 func (_i *service) ResetPass(req *request.ResetPass) error {
@@ -123,11 +151,14 @@ func (_i *service) ResetPass(req *request.ResetPass) error {
 		return err
 	}
 
-	_, err = _i.MessageWay.Verify(MessageWay.OTPVerifyRequest{
-		OTP:    req.Otp,
-		Mobile: fmt.Sprint("0", user.Mobile),
-	})
+	// the code is verified by the provider that sent it, the primary one if none did yet
+	mobile := fmt.Sprint("0", user.Mobile)
+	provider := ""
+	if sent, err := _i.OutboxService.LastSent(schema.OutboxPurposeOTP, mobile); err == nil {
+		provider = sent.Provider
+	}
 
+	err = _i.Sms.VerifyOTP(provider, mobile, req.Otp)
 	if err != nil {
 		return err
 	}
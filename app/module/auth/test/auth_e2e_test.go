@@ -5,7 +5,10 @@ import (
 	"strings"
 	"testing"
 
+	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/module/auth/request"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/internal/sms"
 )
 
 func TestLogin_Success(t *testing.T) {
@@ -299,6 +302,14 @@ func TestSendOtp_Success(t *testing.T) {
 	if result["result"] != "success" {
 		t.Errorf("expected success response, got: %v", result)
 	}
+
+	var message schema.OutboxMessage
+	if err := ta.DB.Where("mobile = ?", "09123456789").First(&message).Error; err != nil {
+		t.Fatalf("expected the otp to be queued: %v", err)
+	}
+	if message.Purpose != schema.OutboxPurposeOTP || message.Priority != schema.OutboxPriorityHigh {
+		t.Errorf("expected a high priority otp, got %+v", message)
+	}
 }
 
 func TestSendOtp_UserNotFound(t *testing.T) {
@@ -426,6 +437,38 @@ func TestResetPass_Success(t *testing.T) {
 	}
 }
 
+func TestResetPass_CodeIsUsedOnce(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	testMobile := uint64(9123456789)
+	ta.CreateTestUser(t, testMobile, "oldPassword123", "Test", "User")
+
+	// the code the fake provider of the test config would have sent
+	codes := sms.NewOTPCodes(ta.Config.Services.SMS.OtpSecret, sms.NewOTPStore(&database.Database{Main: ta.DB}))
+	code, err := codes.New("09123456789")
+	if err != nil {
+		t.Fatalf("failed to make the code: %v", err)
+	}
+
+	resetPassReq := request.ResetPass{
+		Login: request.Login{
+			Mobile:   testMobile,
+			Password: "newPassword456",
+		},
+		Otp: code,
+	}
+
+	if resp := ta.MakeRequest(t, http.MethodPost, "/v1/auth/reset-pass", resetPassReq); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the code to reset the password, got %d", resp.StatusCode)
+	}
+
+	resetPassReq.Password = "anotherPassword789"
+	if resp := ta.MakeRequest(t, http.MethodPost, "/v1/auth/reset-pass", resetPassReq); resp.StatusCode == http.StatusOK {
+		t.Errorf("expected the used code to be rejected, got %d", resp.StatusCode)
+	}
+}
+
 func TestResetPass_UserNotFound(t *testing.T) {
 	ta := SetupTestApp(t)
 	defer ta.Cleanup()
@@ -596,3 +639,113 @@ func TestResetPass_EmptyBody(t *testing.T) {
 		t.Errorf("expected status 422 or 500 for empty body, got %d", resp.StatusCode)
 	}
 }
+
+func TestRegister_WithReferralCode(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	inviter := ta.CreateTestUser(t, 9123456789, "inviterPassword", "Inviter", "User")
+	ta.SetReferralCode(t, inviter, "abcd1234")
+
+	registerReq := request.Register{
+		Login: request.Login{
+			Mobile:   9123456780,
+			Password: "newPassword123",
+		},
+		FirstName:    "New",
+		LastName:     "User",
+		ReferralCode: "ABCD1234",
+	}
+
+	resp := ta.MakeRequest(t, http.MethodPost, "/v1/auth/register", registerReq)
+
+	if resp.StatusCode != http.StatusOK {
+		result := ParseResponse(t, resp)
+		t.Errorf("expected status 200, got %d, response: %v", resp.StatusCode, result)
+		return
+	}
+
+	var referral schema.Referral
+	if err := ta.DB.Where("invitee_mobile = ?", registerReq.Mobile).First(&referral).Error; err != nil {
+		t.Fatalf("expected referral to be created: %v", err)
+	}
+
+	if referral.InviterID != inviter.ID || referral.Status != schema.ReferralStatusPending {
+		t.Errorf("expected pending referral of inviter %d, got: %+v", inviter.ID, referral)
+	}
+
+	var invitee schema.User
+	ta.DB.Where("mobile = ?", registerReq.Mobile).First(&invitee)
+	if invitee.ReferralCode == nil || len(*invitee.ReferralCode) != 8 {
+		t.Errorf("expected the new user to get a referral code, got %v", invitee.ReferralCode)
+	}
+}
+
+func TestRegister_FailedReferralCanBeRegisteredAgain(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	inviter := ta.CreateTestUser(t, 9123456789, "inviterPassword", "Inviter", "User")
+	ta.SetReferralCode(t, inviter, "abcd1234")
+
+	registerReq := request.Register{
+		Login: request.Login{
+			Mobile:   9123456780,
+			Password: "newPassword123",
+		},
+		FirstName:    "New",
+		LastName:     "User",
+		ReferralCode: "abcd1234",
+	}
+
+	// the referral can not be saved, the user must not be either
+	ta.DB.Exec("ALTER TABLE referrals ADD CONSTRAINT referrals_off CHECK (false) NOT VALID")
+	resp := ta.MakeRequest(t, http.MethodPost, "/v1/auth/register", registerReq)
+	ta.DB.Exec("ALTER TABLE referrals DROP CONSTRAINT referrals_off")
+	if resp.StatusCode == http.StatusOK {
+		t.Fatalf("expected the registration to fail with the referral")
+	}
+
+	var count int64
+	ta.DB.Model(&schema.User{}).Where("mobile = ?", registerReq.Mobile).Count(&count)
+	if count != 0 {
+		t.Fatalf("expected the user to be rolled back with the referral")
+	}
+
+	resp = ta.MakeRequest(t, http.MethodPost, "/v1/auth/register", registerReq)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the registration to be retried, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	var referral schema.Referral
+	if err := ta.DB.Where("invitee_mobile = ?", registerReq.Mobile).First(&referral).Error; err != nil || referral.InviterID != inviter.ID {
+		t.Errorf("expected the referral of the inviter, got %+v, %v", referral, err)
+	}
+}
+
+func TestRegister_InvalidReferralCode(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	registerReq := request.Register{
+		Login: request.Login{
+			Mobile:   9123456780,
+			Password: "newPassword123",
+		},
+		FirstName:    "New",
+		LastName:     "User",
+		ReferralCode: "notexist",
+	}
+
+	resp := ta.MakeRequest(t, http.MethodPost, "/v1/auth/register", registerReq)
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for invalid referral code, got %d", resp.StatusCode)
+	}
+
+	var count int64
+	ta.DB.Model(&schema.User{}).Where("mobile = ?", registerReq.Mobile).Count(&count)
+	if count != 0 {
+		t.Errorf("expected user not to be created with invalid referral code")
+	}
+}
@@ -14,8 +14,19 @@ import (
 	"go-fiber-starter/app/module/auth"
 	"go-fiber-starter/app/module/auth/controller"
 	"go-fiber-starter/app/module/auth/service"
+	businessRepo "go-fiber-starter/app/module/business/repository"
+	couponRepo "go-fiber-starter/app/module/coupon/repository"
+	couponService "go-fiber-starter/app/module/coupon/service"
+	outboxRepo "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	referralRepo "go-fiber-starter/app/module/referral/repository"
+	referralService "go-fiber-starter/app/module/referral/service"
+	transactionRepo "go-fiber-starter/app/module/transaction/repository"
 	userRepo "go-fiber-starter/app/module/user/repository"
-	"go-fiber-starter/internal"
+	userService "go-fiber-starter/app/module/user/service"
+	walletRepo "go-fiber-starter/app/module/wallet/repository"
+	walletService "go-fiber-starter/app/module/wallet/service"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/config"
 	"go-fiber-starter/utils/helpers"
@@ -92,6 +103,9 @@ func createTestErrorHandler() fiber.ErrorHandler {
 // and tries to create/check Business, Taxonomy tables due to FK definitions in schema
 func migrateTestModels(db *gorm.DB) error {
 	// Drop existing tables to ensure clean state
+	db.Exec("DROP TABLE IF EXISTS otp_codes CASCADE")
+	db.Exec("DROP TABLE IF EXISTS outbox_messages CASCADE")
+	db.Exec("DROP TABLE IF EXISTS referrals CASCADE")
 	db.Exec("DROP TABLE IF EXISTS business_users CASCADE")
 	db.Exec("DROP TABLE IF EXISTS users CASCADE")
 
@@ -113,6 +127,7 @@ func migrateTestModels(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -126,6 +141,66 @@ func migrateTestModels(db *gorm.DB) error {
 	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_mobile ON users(mobile)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at)")
 
+	// Create referrals table - structure matches schema.Referral fields
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS referrals (
+			id BIGSERIAL PRIMARY KEY,
+			inviter_id BIGINT NOT NULL,
+			invitee_id BIGINT NOT NULL UNIQUE,
+			invitee_mobile BIGINT NOT NULL UNIQUE,
+			status VARCHAR(50) NOT NULL DEFAULT 'pending',
+			business_id BIGINT,
+			order_id BIGINT,
+			meta JSONB,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create outbox_messages table - the otp is queued there
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS outbox_messages (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT,
+			purpose VARCHAR(30) NOT NULL,
+			priority BIGINT NOT NULL DEFAULT 1,
+			template VARCHAR(50) NOT NULL DEFAULT '',
+			provider VARCHAR(30),
+			mobile VARCHAR(20) NOT NULL,
+			params TEXT[],
+			channels TEXT[],
+			bale_chat_id BIGINT,
+			status VARCHAR(20) NOT NULL DEFAULT 'queued',
+			attempts BIGINT NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMPTZ NOT NULL,
+			locked_until TIMESTAMPTZ,
+			reference_id VARCHAR(100),
+			last_error VARCHAR(500),
+			sent_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create otp_codes table - the codes of the fake provider are kept there until they are used
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS otp_codes (
+			id BIGSERIAL PRIMARY KEY,
+			mobile VARCHAR(20) NOT NULL UNIQUE,
+			hash VARCHAR(64) NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -164,11 +239,24 @@ func SetupTestApp(t *testing.T) *TestApp {
 	// Create user repository
 	repo := userRepo.Repository(dbWrapper)
 
-	// Create mock MessageWay service with initialized App
-	mockMW := internal.NewMessageWay(cfg, logger)
+	// the fake sms provider of the test config
+	smsSvc := sms.NewService(cfg, logger, nil, dbWrapper)
+
+	// Create referral service, rewards are not paid in auth tests
+	userSvc := userService.Service(repo)
+	outboxSvc := outboxService.Service(outboxRepo.Repository(dbWrapper), smsSvc, cfg)
+	couponSvc := couponService.Service(couponRepo.Repository(dbWrapper), userSvc, outboxSvc)
+	walletSvc := walletService.Service(walletRepo.Repository(dbWrapper))
+	referralSvc := referralService.Service(
+		referralRepo.Repository(dbWrapper),
+		businessRepo.Repository(dbWrapper),
+		couponSvc,
+		walletSvc,
+		transactionRepo.Repository(dbWrapper),
+	)
 
 	// Create auth service
-	authService := service.Service(repo, mockMW)
+	authService := service.Service(repo, smsSvc, referralSvc, outboxSvc)
 
 	// Create auth controller
 	authController := controller.Controllers(authService, cfg)
@@ -180,6 +268,9 @@ func SetupTestApp(t *testing.T) *TestApp {
 	// Cleanup function
 	cleanup := func() {
 		// Clean up test data - delete in reverse order of dependencies
+		dbWrapper.Main.Exec("DELETE FROM otp_codes")
+		dbWrapper.Main.Exec("DELETE FROM outbox_messages")
+		dbWrapper.Main.Exec("DELETE FROM referrals")
 		dbWrapper.Main.Exec("DELETE FROM users")
 		dbWrapper.ShutdownDatabase()
 	}
@@ -215,6 +306,15 @@ func (ta *TestApp) CreateTestUser(t *testing.T, mobile uint64, password string,
 	return user
 }
 
+// SetReferralCode assigns a referral code to an existing test user
+func (ta *TestApp) SetReferralCode(t *testing.T, user *schema.User, code string) {
+	t.Helper()
+
+	if err := ta.DB.Model(user).Update("referral_code", code).Error; err != nil {
+		t.Fatalf("failed to set referral code: %v", err)
+	}
+}
+
 // MakeRequest makes an HTTP request to the test server
 func (ta *TestApp) MakeRequest(t *testing.T, method, path string, body interface{}) *http.Response {
 	t.Helper()
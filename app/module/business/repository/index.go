@@ -5,6 +5,8 @@ import (
 	"go-fiber-starter/app/module/business/request"
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/paginator"
+
+	"gorm.io/gorm"
 )
 
 type IRepository interface {
@@ -66,7 +68,17 @@ func (_i *repo) GetOne(id uint64) (business *schema.Business, err error) {
 }
 
 func (_i *repo) Create(business *schema.Business) (err error) {
-	return _i.DB.Main.Create(business).Error
+	return _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(business).Error; err != nil {
+			return err
+		}
+
+		if business.Type == schema.BTypeWMReservation {
+			rules := schema.DefaultReminderRules(business.ID)
+			return tx.Create(&rules).Error
+		}
+		return nil
+	})
 }
 
 func (_i *repo) Update(id uint64, business *schema.Business) (err error) {
@@ -7,6 +7,9 @@ import (
 	"go-fiber-starter/app/module/business/request"
 	"go-fiber-starter/app/module/business/response"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 type IService interface {
@@ -28,6 +31,8 @@ type service struct {
 	Repo repository.IRepository
 }
 
+var errInvalidTimezone = &fiber.Error{Code: fiber.StatusBadRequest, Message: "منطقه زمانی نامعتبر است"}
+
 func (_i *service) Index(req request.Businesses) (businesses []*response.Business, paging paginator.Pagination, err error) {
 	results, paging, err := _i.Repo.GetAll(req)
 	if err != nil {
@@ -56,11 +61,24 @@ func (_i *service) Show(id uint64, role schema.UserRole) (business *response.Bus
 }
 
 func (_i *service) Store(req request.Business) (err error) {
+	if req.Meta.Timezone != "" && !timezone.Valid(req.Meta.Timezone) {
+		return errInvalidTimezone
+	}
+
 	return _i.Repo.Create(req.ToDomain())
 }
 
 func (_i *service) Update(id uint64, req request.Business) (err error) {
-	return _i.Repo.Update(id, req.ToDomain())
+	if req.Meta.Timezone != "" && !timezone.Valid(req.Meta.Timezone) {
+		return errInvalidTimezone
+	}
+
+	if err = _i.Repo.Update(id, req.ToDomain()); err != nil {
+		return err
+	}
+	timezone.Forget(id)
+
+	return nil
 }
 
 func (_i *service) Destroy(id uint64) error {
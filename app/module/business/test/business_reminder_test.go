@@ -0,0 +1,52 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/business/request"
+)
+
+// ==================== REMINDER RULES TESTS ====================
+
+func TestStore_WashBusiness_GetsDefaultReminderRules(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	adminUser := ta.CreateTestUser(t, 9123456789, "adminPass123", "Admin", "User", schema.UserPermissions{
+		schema.ROOT_BUSINESS_ID: []schema.UserRole{schema.URAdmin},
+	})
+	token := ta.GenerateJWT(t, *adminUser)
+
+	for _, business := range []request.Business{
+		{Title: "Dormitory Wash", Type: schema.BTypeWMReservation, OwnerID: adminUser.ID},
+		{Title: "Bakery", Type: schema.BTypeBakery, OwnerID: adminUser.ID},
+	} {
+		resp := ta.MakeAuthenticatedRequest(t, http.MethodPost, "/v1/businesses", business, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+		}
+	}
+
+	var rules []schema.ReminderRule
+	ta.DB.Joins("JOIN businesses ON businesses.id = reminder_rules.business_id").
+		Where("businesses.title = ?", "Dormitory Wash").
+		Order("reminder_rules.id").
+		Find(&rules)
+	if len(rules) != 2 {
+		t.Fatalf("expected the 2 default reminder rules, got %d", len(rules))
+	}
+	if rules[0].Template != schema.SmsReminderTurnOn || rules[1].Template != schema.SmsReminderTurnOff {
+		t.Errorf("expected the turn on and turn off reminders, got templates %s and %s", rules[0].Template, rules[1].Template)
+	}
+
+	var others int64
+	ta.DB.Model(&schema.ReminderRule{}).
+		Joins("JOIN businesses ON businesses.id = reminder_rules.business_id").
+		Where("businesses.title = ?", "Bakery").
+		Count(&others)
+	if others != 0 {
+		t.Errorf("expected no reminder rules for the bakery, got %d", others)
+	}
+}
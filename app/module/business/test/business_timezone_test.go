@@ -0,0 +1,87 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/business/request"
+	"go-fiber-starter/utils/timezone"
+)
+
+// ==================== TIMEZONE TESTS ====================
+
+func setupTimezoneOwner(t *testing.T, ta *TestApp) (*schema.Business, string) {
+	t.Helper()
+
+	permissions := schema.UserPermissions{}
+	owner := ta.CreateTestUser(t, 9123456789, "ownerPass123", "Owner", "User", permissions)
+	business := ta.CreateTestBusiness(t, "Istanbul Pilot", schema.BTypeWMReservation, owner.ID, "")
+
+	permissions[business.ID] = []schema.UserRole{schema.URBusinessOwner}
+	owner.Permissions = permissions
+	ta.DB.Save(owner)
+
+	return business, ta.GenerateJWT(t, *owner)
+}
+
+func TestUpdate_Timezone_AppliesToBusiness(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	business, token := setupTimezoneOwner(t, ta)
+
+	// read once, so the change has to replace the kept settings
+	if settings := timezone.ForBusiness(business.ID); settings.Location.String() != timezone.DefaultName || settings.FirstDayOfWeek != time.Saturday {
+		t.Fatalf("expected the default settings, got %s starting on %s", settings.Location, settings.FirstDayOfWeek)
+	}
+
+	monday := time.Monday
+	resp := ta.MakeAuthenticatedRequest(t, http.MethodPut, fmt.Sprintf("/v1/businesses/%d", business.ID), request.Business{
+		Title:   business.Title,
+		Type:    business.Type,
+		OwnerID: business.OwnerID,
+		Meta:    schema.BusinessMeta{Timezone: "Europe/Istanbul", FirstDayOfWeek: &monday},
+	}, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	settings := timezone.ForBusiness(business.ID)
+	if settings.Location.String() != "Europe/Istanbul" {
+		t.Errorf("expected Europe/Istanbul, got %s", settings.Location)
+	}
+	if settings.FirstDayOfWeek != time.Monday {
+		t.Errorf("expected the week to start on Monday, got %s", settings.FirstDayOfWeek)
+	}
+
+	// 2025-01-01 was a Wednesday
+	wednesday := time.Date(2025, 1, 1, 22, 0, 0, 0, time.UTC) // already Thursday in Istanbul
+	want := time.Date(2024, 12, 30, 0, 0, 0, 0, settings.Location)
+	if got := settings.StartOfWeek(wednesday); !got.Equal(want) {
+		t.Errorf("expected the week to start at %s, got %s", want, got)
+	}
+}
+
+func TestUpdate_InvalidTimezone_BadRequest(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	business, token := setupTimezoneOwner(t, ta)
+
+	resp := ta.MakeAuthenticatedRequest(t, http.MethodPut, fmt.Sprintf("/v1/businesses/%d", business.ID), request.Business{
+		Title:   business.Title,
+		Type:    business.Type,
+		OwnerID: business.OwnerID,
+		Meta:    schema.BusinessMeta{Timezone: "Mars/Olympus"},
+	}, token)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+
+	if loc := timezone.In(business.ID); loc.String() != timezone.DefaultName {
+		t.Errorf("expected the default timezone to stay, got %s", loc)
+	}
+}
@@ -67,6 +67,7 @@ func createTestErrorHandler() fiber.ErrorHandler {
 // migrateTestModels creates the required tables for testing
 func migrateTestModels(db *gorm.DB) error {
 	// Drop existing tables to ensure clean state
+	db.Exec("DROP TABLE IF EXISTS reminder_rules CASCADE")
 	db.Exec("DROP TABLE IF EXISTS business_users CASCADE")
 	db.Exec("DROP TABLE IF EXISTS businesses CASCADE")
 	db.Exec("DROP TABLE IF EXISTS users CASCADE")
@@ -88,6 +89,7 @@ func migrateTestModels(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -126,6 +128,26 @@ func migrateTestModels(db *gorm.DB) error {
 		return err
 	}
 
+	// Create reminder_rules table, the washing machine businesses start with the default ones
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS reminder_rules (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL REFERENCES businesses(id) ON DELETE CASCADE,
+			title VARCHAR(100),
+			trigger VARCHAR(20) NOT NULL,
+			offset_minutes INT NOT NULL DEFAULT 0,
+			conditions JSONB,
+			channel VARCHAR(20) NOT NULL DEFAULT 'sms',
+			template VARCHAR(50) NOT NULL DEFAULT '',
+			disabled BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
 	// Create indexes
 	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_mobile ON users(mobile)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at)")
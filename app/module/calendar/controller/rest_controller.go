@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"fmt"
+	"go-fiber-starter/app/module/calendar/request"
+	"go-fiber-starter/app/module/calendar/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/ical"
+	"go-fiber-starter/utils/response"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Feed(c *fiber.Ctx) error
+	CreateFeed(c *fiber.Ctx) error
+	RevokeFeed(c *fiber.Ctx) error
+	Subscribe(c *fiber.Ctx) error
+	ReservationEvent(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// feedRequest is the feed of the business on the business routes and the one of the user on the others
+func feedRequest(c *fiber.Ctx) (req request.Feed, err error) {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return
+	}
+	req.UserID = user.ID
+
+	if c.Params("businessID") != "" {
+		req.BusinessID, err = utils.GetIntInParams(c, "businessID")
+	}
+
+	return
+}
+
+// Feed shows the calendar feed link
+// @Summary      Get the calendar feed link
+// @Tags         Calendar
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/calendar/feed [get]
+// @Router       /user/calendar/feed [get]
+func (_i *controller) Feed(c *fiber.Ctx) error {
+	req, err := feedRequest(c)
+	if err != nil {
+		return err
+	}
+
+	feed, err := _i.service.Feed(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: feed,
+	})
+}
+
+// CreateFeed gives a new calendar feed link, the previous one stops working
+// @Summary      Create or renew the calendar feed link
+// @Tags         Calendar
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/calendar/feed [post]
+// @Router       /user/calendar/feed [post]
+func (_i *controller) CreateFeed(c *fiber.Ctx) error {
+	req, err := feedRequest(c)
+	if err != nil {
+		return err
+	}
+
+	feed, err := _i.service.CreateFeed(req, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data:     feed,
+		Messages: response.Messages{"success"},
+	})
+}
+
+// RevokeFeed makes the calendar feed link stop working
+// @Summary      Revoke the calendar feed link
+// @Tags         Calendar
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/calendar/feed [delete]
+// @Router       /user/calendar/feed [delete]
+func (_i *controller) RevokeFeed(c *fiber.Ctx) error {
+	req, err := feedRequest(c)
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.RevokeFeed(req); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
+
+// Subscribe is the iCalendar feed the calendar apps fetch, the token in the link is the only key
+// @Summary      Get the iCalendar feed
+// @Tags         Calendar
+// @Param        token path string true "Feed token, with or without .ics"
+// @Param        PostID query int false "Post ID, only for the feeds of a business"
+// @Param        TaxonomyID query int false "Taxonomy ID, only for the feeds of a business"
+// @Produce      text/calendar
+// @Router       /calendar/:token [get]
+func (_i *controller) Subscribe(c *fiber.Ctx) error {
+	token := strings.TrimSuffix(c.Params("token"), ".ics")
+	postID, _ := utils.GetUintInQueries(c, "PostID")
+	taxonomyID, _ := utils.GetUintInQueries(c, "TaxonomyID")
+
+	calendar, err := _i.service.FeedCalendar(token, postID, taxonomyID)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, ical.ContentType)
+	c.Set(fiber.HeaderContentDisposition, `inline; filename="reservations.ics"`)
+	return c.Send(calendar)
+}
+
+// ReservationEvent downloads one reservation to add to a calendar
+// @Summary      Download the reservation as an iCalendar event
+// @Tags         Calendar
+// @Security     Bearer
+// @Param        reservationID path int true "Reservation ID"
+// @Produce      text/calendar
+// @Router       /user/calendar/reservations/:reservationID [get]
+func (_i *controller) ReservationEvent(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+	reservationID, err := utils.GetIntInParams(c, "reservationID")
+	if err != nil {
+		return err
+	}
+
+	calendar, err := _i.service.ReservationCalendar(user.ID, reservationID)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, ical.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="reservation-%d.ics"`, reservationID))
+	return c.Send(calendar)
+}
@@ -0,0 +1,56 @@
+package calendar
+
+import (
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/calendar/controller"
+	"go-fiber-starter/app/module/calendar/repository"
+	"go-fiber-starter/app/module/calendar/service"
+	"go-fiber-starter/utils/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes
+	_i.App.Route("/v1/business/:businessID/calendar", func(router fiber.Router) {
+		router.Get("/feed", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.Feed)
+		router.Post("/feed", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PUpdate), c.CreateFeed)
+		router.Delete("/feed", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PDelete), c.RevokeFeed)
+	})
+
+	_i.App.Route("/v1/user/calendar", func(router fiber.Router) {
+		router.Get("/feed", mdl.Protected(cfg), c.Feed)
+		router.Post("/feed", mdl.Protected(cfg), c.CreateFeed)
+		router.Delete("/feed", mdl.Protected(cfg), c.RevokeFeed)
+		router.Get("/reservations/:reservationID", mdl.Protected(cfg), c.ReservationEvent)
+	})
+
+	// public, the calendar apps can not send a bearer token
+	_i.App.Get("/v1/calendar/:token", c.Subscribe)
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+)
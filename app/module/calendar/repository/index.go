@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/calendar/request"
+	"go-fiber-starter/internal/bootstrap/database"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IRepository interface {
+	GetFeed(req request.Feed) (feed *schema.CalendarFeed, err error)
+	GetFeedByToken(token string) (feed *schema.CalendarFeed, err error)
+	CreateFeed(feed *schema.CalendarFeed) error
+	DeleteFeeds(req request.Feed) error
+	TouchFeed(id uint64, at time.Time) error
+	GetReservations(req request.FeedReservations) (reservations []*schema.Reservation, err error)
+	GetReservation(userID uint64, id uint64) (reservation *schema.Reservation, err error)
+}
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+// the reservations that are on the calendar, the unpaid and the missed ones are left out
+var calendarStatuses = []schema.ReservationStatus{
+	schema.ReservationStatusReserved,
+	schema.ReservationStatusInUse,
+	schema.ReservationStatusCompleted,
+}
+
+func (_i *repo) ownedBy(req request.Feed) *gorm.DB {
+	if req.BusinessID != 0 {
+		return _i.DB.Main.Where("business_id = ?", req.BusinessID)
+	}
+	return _i.DB.Main.Where("user_id = ? AND business_id IS NULL", req.UserID)
+}
+
+func (_i *repo) GetFeed(req request.Feed) (feed *schema.CalendarFeed, err error) {
+	if err = _i.ownedBy(req).First(&feed).Error; err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (_i *repo) GetFeedByToken(token string) (feed *schema.CalendarFeed, err error) {
+	if err = _i.DB.Main.Where("token = ?", token).First(&feed).Error; err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+func (_i *repo) CreateFeed(feed *schema.CalendarFeed) error {
+	return _i.DB.Main.Create(feed).Error
+}
+
+func (_i *repo) DeleteFeeds(req request.Feed) error {
+	return _i.ownedBy(req).Delete(&schema.CalendarFeed{}).Error
+}
+
+func (_i *repo) TouchFeed(id uint64, at time.Time) error {
+	return _i.DB.Main.Model(&schema.CalendarFeed{}).
+		Where("id = ?", id).
+		Update("last_fetched_at", at).Error
+}
+
+func (_i *repo) GetReservations(req request.FeedReservations) (reservations []*schema.Reservation, err error) {
+	query := _i.DB.Main.
+		Where("reservations.status IN ?", calendarStatuses).
+		Where("reservations.end_time >= ?", req.From)
+
+	if req.UserID != 0 {
+		query.Where("reservations.user_id = ?", req.UserID)
+	}
+
+	if req.BusinessID != 0 {
+		query.Where("reservations.business_id = ?", req.BusinessID)
+	}
+
+	if req.PostID != 0 {
+		query.Where("reservations.product_id IN (SELECT id FROM products WHERE post_id = ?)", req.PostID)
+	}
+
+	if req.TaxonomyID != 0 {
+		query.Where(`reservations.product_id IN (SELECT products.id FROM products
+			JOIN posts_taxonomies ON posts_taxonomies.post_id = products.post_id
+			WHERE posts_taxonomies.taxonomy_id = ?)`, req.TaxonomyID)
+	}
+
+	err = query.
+		Preload("User").
+		Preload("Business").
+		Preload("Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped() // This will include soft-deleted products
+		}).
+		Preload("Product.Post", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped() // This will include soft-deleted posts
+		}).
+		Preload("Product.Post.Taxonomies").
+		Order("reservations.start_time").
+		Limit(req.Limit).
+		Find(&reservations).Error
+
+	return
+}
+
+func (_i *repo) GetReservation(userID uint64, id uint64) (reservation *schema.Reservation, err error) {
+	if err = _i.DB.Main.
+		Where("user_id = ? AND status IN ?", userID, calendarStatuses).
+		Preload("Business").
+		Preload("Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Product.Post", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Product.Post.Taxonomies").
+		First(&reservation, id).Error; err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
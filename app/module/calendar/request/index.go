@@ -0,0 +1,18 @@
+package request
+
+import "time"
+
+// Feed is the feed of the business when BusinessID is set, otherwise the one of the user.
+type Feed struct {
+	UserID     uint64
+	BusinessID uint64
+}
+
+type FeedReservations struct {
+	UserID     uint64
+	BusinessID uint64
+	PostID     uint64
+	TaxonomyID uint64
+	From       time.Time
+	Limit      int
+}
@@ -0,0 +1,24 @@
+package response
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"time"
+)
+
+type Feed struct {
+	URL           string // the link to subscribe to in the calendar app, anyone with it can read the feed
+	CreatedAt     time.Time
+	LastFetchedAt *time.Time `json:",omitempty"`
+}
+
+func FromDomain(feed *schema.CalendarFeed, backendDomain string) *Feed {
+	if feed == nil {
+		return nil
+	}
+
+	return &Feed{
+		URL:           backendDomain + "/v1/calendar/" + feed.Token + ".ics",
+		CreatedAt:     feed.CreatedAt,
+		LastFetchedAt: feed.LastFetchedAt,
+	}
+}
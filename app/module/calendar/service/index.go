@@ -0,0 +1,216 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/calendar/repository"
+	"go-fiber-starter/app/module/calendar/request"
+	"go-fiber-starter/app/module/calendar/response"
+	"go-fiber-starter/utils/config"
+	"go-fiber-starter/utils/ical"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type IService interface {
+	Feed(req request.Feed) (feed *response.Feed, err error)
+	CreateFeed(req request.Feed, creatorID uint64) (feed *response.Feed, err error)
+	RevokeFeed(req request.Feed) error
+	FeedCalendar(token string, postID uint64, taxonomyID uint64) (calendar []byte, err error)
+	ReservationCalendar(userID uint64, reservationID uint64) (calendar []byte, err error)
+}
+
+func Service(Repo repository.IRepository, cfg *config.Config) IService {
+	return &service{
+		Repo:   Repo,
+		Config: cfg,
+	}
+}
+
+type service struct {
+	Repo   repository.IRepository
+	Config *config.Config
+}
+
+const (
+	feedTokenBytes       = 32
+	feedLimit            = 1000
+	feedRefreshInterval  = time.Hour
+	userFeedPastDays     = 30 // the users keep the history of their last month
+	businessFeedPastDays = 7
+)
+
+var errFeedNotFound = &fiber.Error{Code: fiber.StatusNotFound, Message: "لینک تقویم یافت نشد یا باطل شده است"}
+
+func (_i *service) Feed(req request.Feed) (feed *response.Feed, err error) {
+	item, err := _i.Repo.GetFeed(req)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response.FromDomain(item, _i.Config.App.BackendDomain), nil
+}
+
+// CreateFeed gives a new link and revokes the previous one, so a leaked link can be replaced.
+func (_i *service) CreateFeed(req request.Feed, creatorID uint64) (feed *response.Feed, err error) {
+	token, err := newFeedToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = _i.Repo.DeleteFeeds(req); err != nil {
+		return nil, err
+	}
+
+	item := &schema.CalendarFeed{Token: token, CreatorID: creatorID}
+	if req.BusinessID != 0 {
+		item.BusinessID = &req.BusinessID
+	} else {
+		item.UserID = &req.UserID
+	}
+	if err = _i.Repo.CreateFeed(item); err != nil {
+		return nil, err
+	}
+
+	return response.FromDomain(item, _i.Config.App.BackendDomain), nil
+}
+
+func (_i *service) RevokeFeed(req request.Feed) error {
+	return _i.Repo.DeleteFeeds(req)
+}
+
+func (_i *service) FeedCalendar(token string, postID uint64, taxonomyID uint64) (calendar []byte, err error) {
+	feed, err := _i.Repo.GetFeedByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errFeedNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	req := request.FeedReservations{Limit: feedLimit}
+	cal := ical.Calendar{RefreshInterval: feedRefreshInterval}
+
+	if feed.BusinessID != nil {
+		req.BusinessID = *feed.BusinessID
+		req.PostID = postID
+		req.TaxonomyID = taxonomyID
+		req.From = now.AddDate(0, 0, -businessFeedPastDays)
+		cal.Name = "برنامه رزروها"
+	} else {
+		req.UserID = *feed.UserID
+		req.From = now.AddDate(0, 0, -userFeedPastDays)
+		cal.Name = "رزروهای من"
+	}
+
+	reservations, err := _i.Repo.GetReservations(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reservation := range reservations {
+		cal.Events = append(cal.Events, reservationEvent(reservation, feed.BusinessID != nil, now))
+	}
+
+	// only a hint for the owner, the feed must not fail for it
+	_ = _i.Repo.TouchFeed(feed.ID, now)
+
+	return cal.Bytes(), nil
+}
+
+func (_i *service) ReservationCalendar(userID uint64, reservationID uint64) (calendar []byte, err error) {
+	reservation, err := _i.Repo.GetReservation(userID, reservationID)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "رزرو یافت نشد"}
+	}
+
+	cal := ical.Calendar{Events: []ical.Event{reservationEvent(reservation, false, time.Now())}}
+
+	return cal.Bytes(), nil
+}
+
+func newFeedToken() (string, error) {
+	b := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// reservationEvent names the machine for the user, and adds who booked it for the operators.
+func reservationEvent(reservation *schema.Reservation, forBusiness bool, now time.Time) ical.Event {
+	machine := reservation.Product.Post.Title
+	if reservation.Product.Meta.SKU != "" {
+		machine = fmt.Sprintf("%s (%s)", machine, reservation.Product.Meta.SKU)
+	}
+
+	event := ical.Event{
+		UID:      fmt.Sprintf("reservation-%d@zciti", reservation.ID),
+		Summary:  "رزرو " + machine,
+		Location: location(reservation.Product.Post.Taxonomies),
+		Status:   ical.StatusConfirmed,
+		Start:    reservation.StartTime,
+		End:      reservation.EndTime,
+		Stamp:    now,
+	}
+
+	if forBusiness {
+		event.Summary = fmt.Sprintf("%s - %s", machine, reservation.User.FullName())
+		event.Description = fmt.Sprintf("موبایل: 0%d", reservation.User.Mobile)
+	} else if reservation.Business.Title != "" {
+		event.Description = reservation.Business.Title
+	}
+
+	return event
+}
+
+// location writes the categories of the machine from the widest, like the city, to the narrowest, like the dormitory.
+func location(taxonomies []schema.Taxonomy) string {
+	byID := map[uint64]schema.Taxonomy{}
+	for _, taxonomy := range taxonomies {
+		if taxonomy.Type == schema.TaxonomyTypeCategory {
+			byID[taxonomy.ID] = taxonomy
+		}
+	}
+
+	depth := func(taxonomy schema.Taxonomy) (d int) {
+		for taxonomy.ParentID != nil {
+			parent, ok := byID[*taxonomy.ParentID]
+			if !ok || d > len(byID) {
+				break
+			}
+			taxonomy = parent
+			d++
+		}
+		return
+	}
+
+	categories := make([]schema.Taxonomy, 0, len(byID))
+	for _, taxonomy := range byID {
+		categories = append(categories, taxonomy)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		if di, dj := depth(categories[i]), depth(categories[j]); di != dj {
+			return di < dj
+		}
+		return categories[i].ID < categories[j].ID
+	})
+
+	titles := make([]string, 0, len(categories))
+	for _, taxonomy := range categories {
+		titles = append(titles, taxonomy.Title)
+	}
+
+	return strings.Join(titles, "، ")
+}
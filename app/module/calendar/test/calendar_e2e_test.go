@@ -0,0 +1,196 @@
+package test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/testapp"
+	"go-fiber-starter/utils/ical"
+)
+
+// =============================================================================
+// CALENDAR FEEDS - e2e
+// =============================================================================
+
+// createFeed creates the feed on the path and returns the public path of it
+func (ta *TestApp) createFeed(t *testing.T, path string, token string) string {
+	t.Helper()
+
+	resp := ta.MakeRequest(t, http.MethodPost, path, nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+
+	data, _ := testapp.ParseResponse(t, resp)["Data"].(map[string]interface{})
+	url, _ := data["URL"].(string)
+	i := strings.Index(url, "/v1/calendar/")
+	if i < 0 {
+		t.Fatalf("expected a feed url, got %q", url)
+	}
+
+	return url[i:]
+}
+
+// fetchCalendar gets the .ics and fails unless it is a calendar
+func (ta *TestApp) fetchCalendar(t *testing.T, path string, token string) string {
+	t.Helper()
+
+	resp := ta.MakeRequest(t, http.MethodGet, path, nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if contentType := resp.Header.Get("Content-Type"); contentType != ical.ContentType {
+		t.Fatalf("expected content type %q, got %q", ical.ContentType, contentType)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read the calendar: %v", err)
+	}
+
+	// the long lines are folded, the tests look for the unfolded content
+	return strings.ReplaceAll(string(body), "\r\n ", "")
+}
+
+func TestCalendar_UserFeedListsReservations(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	customer := ta.CreateTestUser(t, 9100000002, "Test", "User")
+	token := ta.GenerateTestToken(t, customer)
+
+	machine := ta.CreateTestMachine(t, business.ID, owner.ID, "WM-7")
+	city := ta.CreateTestTaxonomy(t, business.ID, "Tehran", nil, machine)
+	ta.CreateTestTaxonomy(t, business.ID, "Dormitory", city, machine)
+
+	reserved := ta.CreateTestReservation(t, customer.ID, machine, testapp.TomorrowSlot(10))
+	held := ta.CreateTestReservation(t, customer.ID, machine, testapp.TomorrowSlot(12))
+	ta.DB.Model(held).Update("status", schema.ReservationStatusHeld)
+
+	path := ta.createFeed(t, "/v1/user/calendar/feed", token)
+	calendar := ta.fetchCalendar(t, path, "")
+
+	if !strings.Contains(calendar, fmt.Sprintf("UID:reservation-%d@zciti", reserved.ID)) {
+		t.Errorf("expected the reserved slot in the feed, got:\n%s", calendar)
+	}
+	if strings.Contains(calendar, fmt.Sprintf("UID:reservation-%d@zciti", held.ID)) {
+		t.Error("expected the unpaid hold to be left out of the feed")
+	}
+	if !strings.Contains(calendar, "Machine WM-7 (WM-7)") {
+		t.Errorf("expected the machine title and SKU in the feed, got:\n%s", calendar)
+	}
+	if !strings.Contains(calendar, "LOCATION:Tehran، Dormitory") {
+		t.Errorf("expected the location from the city to the dormitory, got:\n%s", calendar)
+	}
+
+	// the feed link of someone else is not given
+	resp := ta.MakeRequest(t, http.MethodGet, "/v1/user/calendar/feed", nil, ta.GenerateTestToken(t, owner))
+	testapp.AssertStatus(t, resp, http.StatusNotFound)
+}
+
+func TestCalendar_BusinessFeedFilters(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	token := ta.GenerateTestToken(t, owner)
+	customer := ta.CreateTestUser(t, 9100000002, "Test", "User")
+
+	first := ta.CreateTestMachine(t, business.ID, owner.ID, "WM-1")
+	second := ta.CreateTestMachine(t, business.ID, owner.ID, "WM-2")
+	dormitory := ta.CreateTestTaxonomy(t, business.ID, "Dormitory", nil, second)
+
+	onFirst := ta.CreateTestReservation(t, customer.ID, first, testapp.TomorrowSlot(10))
+	onSecond := ta.CreateTestReservation(t, customer.ID, second, testapp.TomorrowSlot(10))
+
+	path := ta.createFeed(t, fmt.Sprintf("/v1/business/%d/calendar/feed", business.ID), token)
+
+	tests := []struct {
+		name     string
+		query    string
+		included []uint64
+		excluded []uint64
+	}{
+		{"all", "", []uint64{onFirst.ID, onSecond.ID}, nil},
+		{"post", fmt.Sprintf("?PostID=%d", first.PostID), []uint64{onFirst.ID}, []uint64{onSecond.ID}},
+		{"taxonomy", fmt.Sprintf("?TaxonomyID=%d", dormitory.ID), []uint64{onSecond.ID}, []uint64{onFirst.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calendar := ta.fetchCalendar(t, path+tt.query, "")
+			for _, id := range tt.included {
+				if !strings.Contains(calendar, fmt.Sprintf("UID:reservation-%d@zciti", id)) {
+					t.Errorf("expected reservation %d in the feed", id)
+				}
+			}
+			for _, id := range tt.excluded {
+				if strings.Contains(calendar, fmt.Sprintf("UID:reservation-%d@zciti", id)) {
+					t.Errorf("expected reservation %d to be filtered out", id)
+				}
+			}
+		})
+	}
+
+	// the operators see who booked
+	if calendar := ta.fetchCalendar(t, path, ""); !strings.Contains(calendar, "Test User") {
+		t.Errorf("expected the name of the customer in the business feed, got:\n%s", calendar)
+	}
+
+	// a customer can not create the feed of the business
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/calendar/feed", business.ID), nil, ta.GenerateTestToken(t, customer))
+	testapp.AssertStatus(t, resp, http.StatusForbidden)
+}
+
+func TestCalendar_RevokedTokenStopsWorking(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	customer := ta.CreateTestUser(t, 9100000002, "Test", "User")
+	token := ta.GenerateTestToken(t, customer)
+
+	old := ta.createFeed(t, "/v1/user/calendar/feed", token)
+	ta.fetchCalendar(t, old, "")
+
+	// renewing the link revokes the previous one
+	renewed := ta.createFeed(t, "/v1/user/calendar/feed", token)
+	if renewed == old {
+		t.Fatal("expected a new feed link")
+	}
+	testapp.AssertStatus(t, ta.MakeRequest(t, http.MethodGet, old, nil, ""), http.StatusNotFound)
+	ta.fetchCalendar(t, renewed, "")
+
+	resp := ta.MakeRequest(t, http.MethodDelete, "/v1/user/calendar/feed", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	testapp.AssertStatus(t, ta.MakeRequest(t, http.MethodGet, renewed, nil, ""), http.StatusNotFound)
+	testapp.AssertStatus(t, ta.MakeRequest(t, http.MethodGet, "/v1/user/calendar/feed", nil, token), http.StatusNotFound)
+}
+
+func TestCalendar_ReservationDownload(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	customer := ta.CreateTestUser(t, 9100000002, "Test", "User")
+	token := ta.GenerateTestToken(t, customer)
+
+	machine := ta.CreateTestMachine(t, business.ID, owner.ID, "WM-7")
+	reservation := ta.CreateTestReservation(t, customer.ID, machine, testapp.TomorrowSlot(10))
+
+	path := fmt.Sprintf("/v1/user/calendar/reservations/%d", reservation.ID)
+	calendar := ta.fetchCalendar(t, path, token)
+	if strings.Count(calendar, "BEGIN:VEVENT") != 1 {
+		t.Errorf("expected a single event, got:\n%s", calendar)
+	}
+	if !strings.Contains(calendar, "DTSTART:"+reservation.StartTime.UTC().Format("20060102T150405Z")) {
+		t.Errorf("expected the start of the reservation, got:\n%s", calendar)
+	}
+
+	// the reservations of the others are not downloadable
+	resp := ta.MakeRequest(t, http.MethodGet, path, nil, ta.GenerateTestToken(t, owner))
+	testapp.AssertStatus(t, resp, http.StatusNotFound)
+}
@@ -0,0 +1,38 @@
+package test
+
+import (
+	"testing"
+
+	"go-fiber-starter/app/module/calendar"
+	"go-fiber-starter/app/module/calendar/controller"
+	"go-fiber-starter/app/module/calendar/repository"
+	"go-fiber-starter/app/module/calendar/service"
+	"go-fiber-starter/internal/testapp"
+)
+
+// TestApp holds the calendar service on the test app
+type TestApp struct {
+	*testapp.Fixture
+	Service service.IService
+}
+
+// SetupTestApp initializes the test application with a test database
+func SetupTestApp(t *testing.T) *TestApp {
+	t.Helper()
+
+	f := testapp.New(t, "users", "businesses", "business_users", "posts", "products", "reservations", "taxonomies",
+		"posts_taxonomies", "calendar_feeds")
+
+	calendarSvc := service.Service(repository.Repository(f.Database), f.Config)
+
+	calendarRouter := &calendar.Router{
+		App:        f.App,
+		Controller: controller.Controllers(calendarSvc),
+	}
+	calendarRouter.RegisterRoutes(f.Config)
+
+	return &TestApp{
+		Fixture: f,
+		Service: calendarSvc,
+	}
+}
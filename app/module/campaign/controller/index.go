@@ -0,0 +1,13 @@
+package controller
+
+import "go-fiber-starter/app/module/campaign/service"
+
+type Controller struct {
+	RestController IRestController
+}
+
+func Controllers(s service.IService) *Controller {
+	return &Controller{
+		RestController(s),
+	}
+}
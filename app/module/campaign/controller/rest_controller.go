@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/campaign/request"
+	"go-fiber-starter/app/module/campaign/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Index(c *fiber.Ctx) error
+	Show(c *fiber.Ctx) error
+	Store(c *fiber.Ctx) error
+	Preview(c *fiber.Ctx) error
+	Cancel(c *fiber.Ctx) error
+	Recipients(c *fiber.Ctx) error
+
+	OptOuts(c *fiber.Ctx) error
+	SetOptOut(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// Index all Campaigns
+// @Summary      Get all campaigns
+// @Tags         Campaigns
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        Status query string false "scheduled, sending, completed or canceled"
+// @Router       /business/:businessID/campaigns [get]
+func (_i *controller) Index(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	req := request.Campaigns{
+		BusinessID: businessID,
+		Status:     schema.CampaignStatus(c.Query("Status")),
+		Pagination: paginate,
+	}
+
+	campaigns, paging, err := _i.service.Index(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: campaigns,
+		Meta: paging,
+	})
+}
+
+// Show one Campaign with its progress
+// @Summary      Get one campaign
+// @Tags         Campaigns
+// @Security     Bearer
+// @Param        id path int true "Campaign ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/campaigns/:id [get]
+func (_i *controller) Show(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	campaign, err := _i.service.Show(businessID, id)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: campaign,
+	})
+}
+
+// Store campaign
+// @Summary      Schedule a campaign
+// @Tags         Campaigns
+// @Security     Bearer
+// @Param 		 campaign body request.Campaign true "Campaign details"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/campaigns [post]
+func (_i *controller) Store(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.Campaign)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	req.CreatedBy = user.ID
+	campaign, err := _i.service.Store(*req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: campaign,
+	})
+}
+
+// Preview the audience of a campaign
+// @Summary      Get the audience size of a campaign
+// @Tags         Campaigns
+// @Security     Bearer
+// @Param 		 audience body schema.CampaignAudience true "Audience"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/campaigns/preview [post]
+func (_i *controller) Preview(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	req := request.Preview{BusinessID: businessID}
+	if err := response.ParseAndValidate(c, &req.Audience); err != nil {
+		return err
+	}
+
+	preview, err := _i.service.Preview(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: preview,
+	})
+}
+
+// Cancel a campaign, the recipients not sent to yet do not get it
+// @Summary      Cancel a campaign
+// @Tags         Campaigns
+// @Security     Bearer
+// @Param        id path int true "Campaign ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/campaigns/:id/cancel [post]
+func (_i *controller) Cancel(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.Cancel(businessID, id); err != nil {
+		return err
+	}
+
+	return c.JSON("success")
+}
+
+// Recipients of a campaign with their results
+// @Summary      Get the recipients of a campaign
+// @Tags         Campaigns
+// @Security     Bearer
+// @Param        id path int true "Campaign ID"
+// @Param        businessID path int true "Business ID"
+// @Param        Status query string false "pending, sent, failed, optedOut or canceled"
+// @Router       /business/:businessID/campaigns/:id/recipients [get]
+func (_i *controller) Recipients(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	req := request.Recipients{
+		BusinessID: businessID,
+		CampaignID: id,
+		Status:     schema.CampaignRecipientStatus(c.Query("Status")),
+		Pagination: paginate,
+	}
+
+	recipients, paging, err := _i.service.Recipients(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: recipients,
+		Meta: paging,
+	})
+}
+
+// OptOuts of the user
+// @Summary      Get the businesses the user does not get the campaigns of
+// @Tags         Campaigns
+// @Security     Bearer
+// @Router       /user/campaign-opt-outs [get]
+func (_i *controller) OptOuts(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	optOuts, err := _i.service.OptOuts(user.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: optOuts,
+	})
+}
+
+// SetOptOut stops or starts the campaigns of a business for the user
+// @Summary      Opt out of the campaigns of a business
+// @Tags         Campaigns
+// @Security     Bearer
+// @Param 		 optOut body request.OptOut true "Opt out"
+// @Router       /user/campaign-opt-outs [put]
+func (_i *controller) SetOptOut(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.OptOut)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.UserID = user.ID
+	if err = _i.service.SetOptOut(*req); err != nil {
+		return err
+	}
+
+	return c.JSON("success")
+}
@@ -0,0 +1,50 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/campaign/service"
+	"go-fiber-starter/internal"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type CampaignSender struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+
+	running sync.Mutex
+}
+
+func RunCampaignSender(
+	logger zerolog.Logger,
+	campaignService service.IService,
+	cronService *internal.CronService,
+) *CampaignSender {
+	sender := &CampaignSender{
+		Logger:   logger,
+		Service:  campaignService,
+		CronSpec: "@every 1m",
+	}
+
+	err := cronService.AddJob(sender.CronSpec, sender.SendCampaigns)
+	if err != nil {
+		sender.Logger.Fatal().Err(err).Msg("failed to add RunCampaignSender job")
+	}
+
+	return sender
+}
+
+// SendCampaigns starts the due campaigns and sends the next recipients of the sending ones, a run is
+// skipped while the last one is still sending.
+func (_s *CampaignSender) SendCampaigns() {
+	if !_s.running.TryLock() {
+		return
+	}
+	defer _s.running.Unlock()
+
+	if err := _s.Service.Process(time.Now()); err != nil {
+		_s.Logger.Err(err).Msg("Failed to send the campaigns")
+	}
+}
@@ -0,0 +1,57 @@
+package campaign
+
+import (
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/campaign/controller"
+	"go-fiber-starter/app/module/campaign/cron"
+	"go-fiber-starter/app/module/campaign/repository"
+	"go-fiber-starter/app/module/campaign/service"
+	"go-fiber-starter/utils/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes
+	_i.App.Route("/v1/business/:businessID/campaigns", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PReadAll), c.Index)
+		router.Post("/preview", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PReadAll), c.Preview)
+		router.Get("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PReadSingle), c.Show)
+		router.Get("/:id/recipients", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PReadSingle), c.Recipients)
+		router.Post("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PCreate), c.Store)
+		router.Post("/:id/cancel", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PUpdate), c.Cancel)
+	})
+
+	_i.App.Route("/v1/user/campaign-opt-outs", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), c.OptOuts)
+		router.Put("/", mdl.Protected(cfg), c.SetOptOut)
+	})
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+
+	fx.Invoke(cron.RunCampaignSender),
+)
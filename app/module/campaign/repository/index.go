@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/campaign/request"
+	"go-fiber-starter/app/module/campaign/response"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/paginator"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IRepository interface {
+	GetAll(req request.Campaigns) (campaigns []*schema.Campaign, paging paginator.Pagination, err error)
+	GetOne(businessID uint64, id uint64) (campaign *schema.Campaign, err error)
+	Create(campaign *schema.Campaign) (err error)
+	TemplateExists(businessID uint64, id uint64) (exists bool, err error)
+	Cancel(businessID uint64, id uint64, now time.Time) (canceled bool, err error)
+
+	GetDue(now time.Time) (campaigns []*schema.Campaign, err error)
+	Start(campaign *schema.Campaign, userIDs []uint64, optedOut []uint64, now time.Time) (started bool, err error)
+	GetSending() (campaigns []*schema.Campaign, err error)
+	GetPending(campaignID uint64, limit int) (recipients []*schema.CampaignRecipient, err error)
+	ClaimRecipient(id uint64, now time.Time) (claimed bool, err error)
+	ReclaimRecipients(campaign *schema.Campaign, claimedBefore time.Time) (err error)
+	UpdateRecipient(id uint64, status schema.CampaignRecipientStatus, notificationID *uint64, message string) (err error)
+	UpdateProgress(campaignID uint64, now time.Time) (err error)
+	GetRecipients(req request.Recipients) (recipients []*schema.CampaignRecipient, paging paginator.Pagination, err error)
+
+	GetOptedOut(businessID uint64, userIDs []uint64) (userIDsOut []uint64, err error)
+	GetOptOuts(userID uint64) (optOuts []*response.OptOut, err error)
+	OptOut(userID uint64, businessID uint64) (err error)
+	OptIn(userID uint64, businessID uint64) (err error)
+}
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+func (_i *repo) GetAll(req request.Campaigns) (campaigns []*schema.Campaign, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.Campaign{}).
+		Where(&schema.Campaign{BusinessID: req.BusinessID, Status: req.Status})
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.Preload("Template").Order("id desc").Find(&campaigns).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+func (_i *repo) GetOne(businessID uint64, id uint64) (campaign *schema.Campaign, err error) {
+	if err = _i.DB.Main.
+		Where(&schema.Campaign{BusinessID: businessID}).
+		Preload("Template").
+		First(&campaign, id).Error; err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (_i *repo) Create(campaign *schema.Campaign) (err error) {
+	return _i.DB.Main.Create(campaign).Error
+}
+
+func (_i *repo) TemplateExists(businessID uint64, id uint64) (exists bool, err error) {
+	var count int64
+	err = _i.DB.Main.Model(&schema.NotificationTemplate{}).
+		Where(&schema.NotificationTemplate{ID: id, BusinessID: businessID}).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// Cancel stops the campaign and the recipients it has not sent to yet, the in-flight one is sent anyway.
+// canceled is false when it was already completed or canceled.
+func (_i *repo) Cancel(businessID uint64, id uint64, now time.Time) (canceled bool, err error) {
+	err = _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&schema.Campaign{}).
+			Where("id = ? AND business_id = ? AND status IN ?", id, businessID,
+				[]schema.CampaignStatus{schema.CampaignStatusScheduled, schema.CampaignStatusSending}).
+			Updates(map[string]any{"status": schema.CampaignStatusCanceled, "finished_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		canceled = true
+
+		if err := tx.Model(&schema.CampaignRecipient{}).
+			Where("campaign_id = ? AND status = ?", id, schema.CampaignRecipientPending).
+			Update("status", schema.CampaignRecipientCanceled).Error; err != nil {
+			return err
+		}
+
+		return updateCounters(tx, id)
+	})
+
+	return
+}
+
+func (_i *repo) GetDue(now time.Time) (campaigns []*schema.Campaign, err error) {
+	err = _i.DB.Main.
+		Where("status = ? AND send_at <= ?", schema.CampaignStatusScheduled, now).
+		Order("send_at asc").
+		Find(&campaigns).Error
+
+	return
+}
+
+// Start takes the audience of the campaign as its recipients and starts sending, together so a campaign
+// is started once. started is false when another run or a cancel got it first.
+func (_i *repo) Start(campaign *schema.Campaign, userIDs []uint64, optedOut []uint64, now time.Time) (started bool, err error) {
+	err = _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&schema.Campaign{}).
+			Where("id = ? AND status = ?", campaign.ID, schema.CampaignStatusScheduled).
+			Updates(map[string]any{"status": schema.CampaignStatusSending, "started_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		started = true
+
+		out := make(map[uint64]bool, len(optedOut))
+		for _, id := range optedOut {
+			out[id] = true
+		}
+
+		recipients := make([]*schema.CampaignRecipient, 0, len(userIDs))
+		for _, userID := range userIDs {
+			status := schema.CampaignRecipientPending
+			if out[userID] {
+				status = schema.CampaignRecipientOptedOut
+			}
+			recipients = append(recipients, &schema.CampaignRecipient{CampaignID: campaign.ID, UserID: userID, Status: status})
+		}
+		if len(recipients) > 0 {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(recipients, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		return updateCounters(tx, campaign.ID)
+	})
+
+	return
+}
+
+func (_i *repo) GetSending() (campaigns []*schema.Campaign, err error) {
+	err = _i.DB.Main.
+		Where("status = ?", schema.CampaignStatusSending).
+		Order("send_at asc").
+		Find(&campaigns).Error
+
+	return
+}
+
+func (_i *repo) GetPending(campaignID uint64, limit int) (recipients []*schema.CampaignRecipient, err error) {
+	err = _i.DB.Main.
+		Where("campaign_id = ? AND status = ?", campaignID, schema.CampaignRecipientPending).
+		Order("id asc").
+		Limit(limit).
+		Find(&recipients).Error
+
+	return
+}
+
+// ClaimRecipient marks the recipient as sending before it is sent to, so a cancel in the meantime or another
+// run does not send it again. claimed is false when it is not pending anymore.
+func (_i *repo) ClaimRecipient(id uint64, now time.Time) (claimed bool, err error) {
+	result := _i.DB.Main.Model(&schema.CampaignRecipient{}).
+		Where("id = ? AND status = ?", id, schema.CampaignRecipientPending).
+		Updates(map[string]any{"status": schema.CampaignRecipientSending, "sent_at": now})
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReclaimRecipients settles the recipients claimed before the time whose result was never saved. The ones
+// with a notification of the campaign since their claim were sent, the others go back to pending to be sent.
+func (_i *repo) ReclaimRecipients(campaign *schema.Campaign, claimedBefore time.Time) (err error) {
+	return _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		notification := tx.Model(&schema.Notification{}).
+			Select("notifications.id").
+			Where("notifications.receiver_id = campaign_recipients.user_id AND notifications.business_id = ? AND notifications.template_id = ?",
+				campaign.BusinessID, campaign.TemplateID).
+			Where("notifications.sent_at >= campaign_recipients.sent_at").
+			Order("notifications.id asc").
+			Limit(1)
+
+		if err := tx.Model(&schema.CampaignRecipient{}).
+			Where("campaign_id = ? AND status = ? AND sent_at < ?", campaign.ID, schema.CampaignRecipientSending, claimedBefore).
+			Where("EXISTS (?)", notification).
+			Updates(map[string]any{"status": schema.CampaignRecipientSent, "notification_id": gorm.Expr("(?)", notification)}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&schema.CampaignRecipient{}).
+			Where("campaign_id = ? AND status = ? AND sent_at < ?", campaign.ID, schema.CampaignRecipientSending, claimedBefore).
+			Updates(map[string]any{"status": schema.CampaignRecipientPending, "sent_at": nil}).Error
+	})
+}
+
+func (_i *repo) UpdateRecipient(id uint64, status schema.CampaignRecipientStatus, notificationID *uint64, message string) (err error) {
+	return _i.DB.Main.Model(&schema.CampaignRecipient{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"status": status, "notification_id": notificationID, "error": message}).Error
+}
+
+// UpdateProgress counts the results of the recipients on the campaign, it is completed when none is pending or
+// being sent.
+func (_i *repo) UpdateProgress(campaignID uint64, now time.Time) (err error) {
+	return _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		if err := updateCounters(tx, campaignID); err != nil {
+			return err
+		}
+
+		return tx.Model(&schema.Campaign{}).
+			Where("id = ? AND status = ?", campaignID, schema.CampaignStatusSending).
+			Where("NOT EXISTS (SELECT 1 FROM campaign_recipients WHERE campaign_recipients.campaign_id = campaigns.id AND campaign_recipients.status IN ?)",
+				[]schema.CampaignRecipientStatus{schema.CampaignRecipientPending, schema.CampaignRecipientSending}).
+			Updates(map[string]any{"status": schema.CampaignStatusCompleted, "finished_at": now}).Error
+	})
+}
+
+func (_i *repo) GetRecipients(req request.Recipients) (recipients []*schema.CampaignRecipient, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.CampaignRecipient{}).
+		Where(&schema.CampaignRecipient{CampaignID: req.CampaignID, Status: req.Status})
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.Preload("User").Order("id asc").Find(&recipients).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+func (_i *repo) GetOptedOut(businessID uint64, userIDs []uint64) (userIDsOut []uint64, err error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	err = _i.DB.Main.Model(&schema.CampaignOptOut{}).
+		Where("business_id = ? AND user_id IN ?", businessID, userIDs).
+		Pluck("user_id", &userIDsOut).Error
+
+	return
+}
+
+func (_i *repo) GetOptOuts(userID uint64) (optOuts []*response.OptOut, err error) {
+	err = _i.DB.Main.Model(&schema.CampaignOptOut{}).
+		Select("campaign_opt_outs.business_id, campaign_opt_outs.created_at, businesses.title as business_title").
+		Joins("INNER JOIN businesses ON businesses.id = campaign_opt_outs.business_id").
+		Where("campaign_opt_outs.user_id = ?", userID).
+		Order("campaign_opt_outs.created_at desc").
+		Find(&optOuts).Error
+
+	return
+}
+
+func (_i *repo) OptOut(userID uint64, businessID uint64) (err error) {
+	return _i.DB.Main.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&schema.CampaignOptOut{UserID: userID, BusinessID: businessID}).Error
+}
+
+func (_i *repo) OptIn(userID uint64, businessID uint64) (err error) {
+	return _i.DB.Main.
+		Where("user_id = ? AND business_id = ?", userID, businessID).
+		Delete(&schema.CampaignOptOut{}).Error
+}
+
+// updateCounters counts the recipients of the campaign by their status
+func updateCounters(tx *gorm.DB, campaignID uint64) error {
+	var counts []struct {
+		Status schema.CampaignRecipientStatus
+		Count  int
+	}
+	if err := tx.Model(&schema.CampaignRecipient{}).
+		Select("status, COUNT(*) as count").
+		Where("campaign_id = ?", campaignID).
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		return err
+	}
+
+	total, sent, failed, skipped := 0, 0, 0, 0
+	for _, count := range counts {
+		total += count.Count
+		switch count.Status {
+		case schema.CampaignRecipientSent:
+			sent += count.Count
+		case schema.CampaignRecipientFailed:
+			failed += count.Count
+		case schema.CampaignRecipientOptedOut, schema.CampaignRecipientCanceled:
+			skipped += count.Count
+		}
+	}
+
+	return tx.Model(&schema.Campaign{}).
+		Where("id = ?", campaignID).
+		Updates(map[string]any{"total": total, "sent": sent, "failed": failed, "skipped": skipped}).Error
+}
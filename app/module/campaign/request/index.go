@@ -0,0 +1,81 @@
+package request
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultCampaignRate is the recipients sent to each minute when the campaign does not set it
+const DefaultCampaignRate = 100
+
+type Campaign struct {
+	BusinessID uint64
+	CreatedBy  uint64
+	Title      string                  `example:"ماشین‌های جدید خوابگاه ۳" validate:"required,max=100"`
+	TemplateID uint64                  `example:"1" validate:"required,min=1"`
+	Channels   []string                `example:"Sms" validate:"required,min=1,dive,oneof=Sms Notification"`
+	Audience   schema.CampaignAudience // the filters of the users of the business, everyone when empty
+	SendAt     string                  `example:"2024-03-20 10:00:00" validate:"omitempty,datetime=2006-01-02 15:04:05"` // now when empty
+	Rate       int                     `example:"100" validate:"min=0,max=10000"`                                        // recipients per minute
+}
+
+type Campaigns struct {
+	BusinessID uint64
+	Status     schema.CampaignStatus
+	Pagination *paginator.Pagination
+}
+
+type Recipients struct {
+	BusinessID uint64
+	CampaignID uint64
+	Status     schema.CampaignRecipientStatus
+	Pagination *paginator.Pagination
+}
+
+// Preview is the audience of a campaign before it is made
+type Preview struct {
+	BusinessID uint64
+	Audience   schema.CampaignAudience
+}
+
+type OptOut struct {
+	UserID     uint64
+	BusinessID uint64 `example:"1" validate:"required,min=1"`
+	OptOut     bool   `example:"true"` // false to get the campaigns of the business again
+}
+
+func (req *Campaign) ToDomain(now time.Time) (item *schema.Campaign, err error) {
+	item = &schema.Campaign{
+		BusinessID: req.BusinessID,
+		CreatedBy:  req.CreatedBy,
+		Title:      req.Title,
+		TemplateID: req.TemplateID,
+		Channels:   pq.StringArray(req.Channels),
+		Audience:   req.Audience,
+		SendAt:     now,
+		Rate:       req.Rate,
+		Status:     schema.CampaignStatusScheduled,
+	}
+
+	if req.SendAt != "" {
+		item.SendAt, err = time.ParseInLocation(time.DateTime, req.SendAt, timezone.In(req.BusinessID))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if item.Rate == 0 {
+		item.Rate = DefaultCampaignRate
+	}
+
+	audience := req.Audience
+	if audience.StartTime != nil && audience.EndTime != nil && audience.EndTime.Before(*audience.StartTime) {
+		return nil, errors.New("تاریخ شروع پس از پایان است")
+	}
+
+	return item, nil
+}
@@ -0,0 +1,98 @@
+package response
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"time"
+)
+
+type Campaign struct {
+	ID            uint64                  `json:",omitempty"`
+	BusinessID    uint64                  `json:",omitempty"`
+	Title         string                  `json:",omitempty"`
+	TemplateID    uint64                  `json:",omitempty"`
+	TemplateTitle string                  `json:",omitempty"`
+	Channels      []string                `json:",omitempty"`
+	Audience      schema.CampaignAudience ``
+	SendAt        time.Time               `json:",omitempty"`
+	Rate          int                     ``
+	Status        schema.CampaignStatus   `json:",omitempty"`
+	StatusDisplay string                  `json:",omitempty"`
+	Total         int                     ``
+	Sent          int                     ``
+	Failed        int                     ``
+	Skipped       int                     ``
+	Pending       int                     `` // the ones not sent to yet
+	StartedAt     *time.Time              `json:",omitempty"`
+	FinishedAt    *time.Time              `json:",omitempty"`
+	CreatedAt     time.Time               `json:",omitempty"`
+}
+
+type Recipient struct {
+	ID             uint64                         `json:",omitempty"`
+	UserID         uint64                         `json:",omitempty"`
+	FullName       string                         `json:",omitempty"`
+	Mobile         uint64                         `json:",omitempty"`
+	Status         schema.CampaignRecipientStatus `json:",omitempty"`
+	StatusDisplay  string                         `json:",omitempty"`
+	NotificationID *uint64                        `json:",omitempty"`
+	Error          string                         `json:",omitempty"`
+	SentAt         *time.Time                     `json:",omitempty"`
+}
+
+// Preview is the size of the audience, the opted out users are among them but are not sent to
+type Preview struct {
+	Count    int
+	OptedOut int
+}
+
+type OptOut struct {
+	BusinessID    uint64    `json:",omitempty"`
+	BusinessTitle string    `json:",omitempty"`
+	CreatedAt     time.Time `json:",omitempty"`
+}
+
+func FromDomain(item *schema.Campaign) (res *Campaign) {
+	if item == nil {
+		return nil
+	}
+
+	return &Campaign{
+		ID:            item.ID,
+		BusinessID:    item.BusinessID,
+		Title:         item.Title,
+		TemplateID:    item.TemplateID,
+		TemplateTitle: item.Template.Title,
+		Channels:      item.Channels,
+		Audience:      item.Audience,
+		SendAt:        item.SendAt,
+		Rate:          item.Rate,
+		Status:        item.Status,
+		StatusDisplay: schema.CampaignStatusProxy[item.Status],
+		Total:         item.Total,
+		Sent:          item.Sent,
+		Failed:        item.Failed,
+		Skipped:       item.Skipped,
+		Pending:       item.Total - item.Sent - item.Failed - item.Skipped,
+		StartedAt:     item.StartedAt,
+		FinishedAt:    item.FinishedAt,
+		CreatedAt:     item.CreatedAt,
+	}
+}
+
+func RecipientFromDomain(item *schema.CampaignRecipient) (res *Recipient) {
+	if item == nil {
+		return nil
+	}
+
+	return &Recipient{
+		ID:             item.ID,
+		UserID:         item.UserID,
+		FullName:       item.User.FullName(),
+		Mobile:         item.User.Mobile,
+		Status:         item.Status,
+		StatusDisplay:  schema.CampaignRecipientStatusProxy[item.Status],
+		NotificationID: item.NotificationID,
+		Error:          item.Error,
+		SentAt:         item.SentAt,
+	}
+}
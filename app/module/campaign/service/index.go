@@ -0,0 +1,321 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/campaign/repository"
+	"go-fiber-starter/app/module/campaign/request"
+	"go-fiber-starter/app/module/campaign/response"
+	notificationRequest "go-fiber-starter/app/module/notification/request"
+	notificationService "go-fiber-starter/app/module/notification/service"
+	userRequest "go-fiber-starter/app/module/user/request"
+	userService "go-fiber-starter/app/module/user/service"
+	"go-fiber-starter/utils/paginator"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// how long a claimed recipient waits for the result of its send, after that it is reclaimed by the next run
+const claimTimeout = 10 * time.Minute
+
+var errCampaignNotFound = &fiber.Error{Code: fiber.StatusNotFound, Message: "کمپین یافت نشد"}
+
+type IService interface {
+	Index(req request.Campaigns) (campaigns []*response.Campaign, paging paginator.Pagination, err error)
+	Show(businessID uint64, id uint64) (campaign *response.Campaign, err error)
+	Store(req request.Campaign) (campaign *response.Campaign, err error)
+	Preview(req request.Preview) (preview *response.Preview, err error)
+	Cancel(businessID uint64, id uint64) (err error)
+	Recipients(req request.Recipients) (recipients []*response.Recipient, paging paginator.Pagination, err error)
+	Process(now time.Time) error
+
+	OptOuts(userID uint64) (optOuts []*response.OptOut, err error)
+	SetOptOut(req request.OptOut) (err error)
+}
+
+func Service(
+	repo repository.IRepository,
+	userService userService.IService,
+	notificationService notificationService.IService,
+) IService {
+	return &service{
+		repo,
+		userService,
+		notificationService,
+	}
+}
+
+type service struct {
+	Repo                repository.IRepository
+	UserService         userService.IService
+	NotificationService notificationService.IService
+}
+
+func (_i *service) Index(req request.Campaigns) (campaigns []*response.Campaign, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetAll(req)
+	if err != nil {
+		return
+	}
+
+	for _, result := range results {
+		campaigns = append(campaigns, response.FromDomain(result))
+	}
+
+	return
+}
+
+func (_i *service) Show(businessID uint64, id uint64) (campaign *response.Campaign, err error) {
+	result, err := _i.Repo.GetOne(businessID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errCampaignNotFound
+		}
+		return nil, err
+	}
+
+	return response.FromDomain(result), nil
+}
+
+func (_i *service) Store(req request.Campaign) (campaign *response.Campaign, err error) {
+	exists, err := _i.Repo.TemplateExists(req.BusinessID, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "قالب اعلان یافت نشد"}
+	}
+
+	item, err := req.ToDomain(time.Now())
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: err.Error()}
+	}
+	if err = _i.Repo.Create(item); err != nil {
+		return nil, err
+	}
+
+	return _i.Show(item.BusinessID, item.ID)
+}
+
+func (_i *service) Preview(req request.Preview) (preview *response.Preview, err error) {
+	userIDs, err := _i.audience(req.BusinessID, req.Audience)
+	if err != nil {
+		return nil, err
+	}
+	optedOut, err := _i.Repo.GetOptedOut(req.BusinessID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Preview{Count: len(userIDs), OptedOut: len(optedOut)}, nil
+}
+
+func (_i *service) Cancel(businessID uint64, id uint64) (err error) {
+	if _, err = _i.Show(businessID, id); err != nil {
+		return err
+	}
+
+	canceled, err := _i.Repo.Cancel(businessID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !canceled {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "کمپین ارسال شده یا لغو شده است"}
+	}
+
+	return nil
+}
+
+func (_i *service) Recipients(req request.Recipients) (recipients []*response.Recipient, paging paginator.Pagination, err error) {
+	if _, err = _i.Show(req.BusinessID, req.CampaignID); err != nil {
+		return
+	}
+
+	results, paging, err := _i.Repo.GetRecipients(req)
+	if err != nil {
+		return
+	}
+
+	for _, result := range results {
+		recipients = append(recipients, response.RecipientFromDomain(result))
+	}
+
+	return
+}
+
+// Process starts the campaigns whose send time has come and sends the next recipients of the sending ones,
+// as many as their rate, so it is run every minute. A failed campaign does not stop the others.
+func (_i *service) Process(now time.Time) error {
+	var errs []error
+
+	due, err := _i.Repo.GetDue(now)
+	if err != nil {
+		return err
+	}
+	for _, campaign := range due {
+		if err := _i.start(campaign, now); err != nil {
+			errs = append(errs, fmt.Errorf("campaign %d: %w", campaign.ID, err))
+		}
+	}
+
+	sending, err := _i.Repo.GetSending()
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+	for _, campaign := range sending {
+		if err := _i.sendNext(campaign, now); err != nil {
+			errs = append(errs, fmt.Errorf("campaign %d: %w", campaign.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (_i *service) OptOuts(userID uint64) (optOuts []*response.OptOut, err error) {
+	return _i.Repo.GetOptOuts(userID)
+}
+
+func (_i *service) SetOptOut(req request.OptOut) (err error) {
+	if req.OptOut {
+		return _i.Repo.OptOut(req.UserID, req.BusinessID)
+	}
+
+	return _i.Repo.OptIn(req.UserID, req.BusinessID)
+}
+
+// start takes the users of the audience at the send time, the ones that join later do not get it
+func (_i *service) start(campaign *schema.Campaign, now time.Time) error {
+	userIDs, err := _i.audience(campaign.BusinessID, campaign.Audience)
+	if err != nil {
+		return err
+	}
+	optedOut, err := _i.Repo.GetOptedOut(campaign.BusinessID, userIDs)
+	if err != nil {
+		return err
+	}
+
+	started, err := _i.Repo.Start(campaign, userIDs, optedOut, now)
+	if err != nil || !started {
+		return err
+	}
+
+	// an empty audience is done right away
+	return _i.Repo.UpdateProgress(campaign.ID, now)
+}
+
+func (_i *service) sendNext(campaign *schema.Campaign, now time.Time) error {
+	rate := campaign.Rate
+	if rate <= 0 {
+		rate = request.DefaultCampaignRate
+	}
+
+	// the ones whose result was not saved, e.g. the run stopped in the middle of them
+	if err := _i.Repo.ReclaimRecipients(campaign, now.Add(-claimTimeout)); err != nil {
+		return err
+	}
+
+	recipients, err := _i.Repo.GetPending(campaign.ID, rate)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	if len(recipients) > 0 {
+		userIDs := make([]uint64, 0, len(recipients))
+		for _, recipient := range recipients {
+			userIDs = append(userIDs, recipient.UserID)
+		}
+		// the ones that opted out after the campaign started
+		optedOut, err := _i.Repo.GetOptedOut(campaign.BusinessID, userIDs)
+		if err != nil {
+			return err
+		}
+		out := make(map[uint64]bool, len(optedOut))
+		for _, id := range optedOut {
+			out[id] = true
+		}
+
+		for _, recipient := range recipients {
+			if err := _i.send(campaign, recipient, out[recipient.UserID], now); err != nil {
+				errs = append(errs, fmt.Errorf("recipient %d: %w", recipient.UserID, err))
+			}
+		}
+	}
+
+	if err := _i.Repo.UpdateProgress(campaign.ID, now); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// send delivers the campaign to a recipient, its failure is kept on the recipient and is not tried again. A
+// recipient whose result could not be saved stays sending until ReclaimRecipients settles it.
+func (_i *service) send(campaign *schema.Campaign, recipient *schema.CampaignRecipient, optedOut bool, now time.Time) error {
+	if optedOut {
+		return _i.Repo.UpdateRecipient(recipient.ID, schema.CampaignRecipientOptedOut, nil, "")
+	}
+
+	claimed, err := _i.Repo.ClaimRecipient(recipient.ID, now)
+	if err != nil || !claimed {
+		// canceled in the meantime
+		return err
+	}
+
+	notification, err := _i.NotificationService.Send(notificationRequest.Notification{
+		ReceiverID: recipient.UserID,
+		Type:       campaign.Channels,
+		TemplateID: campaign.TemplateID,
+		BusinessID: campaign.BusinessID,
+		Category:   schema.NotificationCategoryMarketing,
+	}, schema.OutboxPriorityBulk)
+	if err != nil {
+		return _i.Repo.UpdateRecipient(recipient.ID, schema.CampaignRecipientFailed, nil, err.Error())
+	}
+
+	// the user turned the marketing off over all the channels of the campaign
+	status := schema.CampaignRecipientOptedOut
+	message := ""
+	for channel, delivery := range notification.Deliveries {
+		switch delivery.Status {
+		case schema.NotificationDeliverySkipped:
+		case schema.NotificationDeliveryFailed:
+			status = schema.CampaignRecipientFailed
+			message = fmt.Sprintf("%s: %s", channel, delivery.Error)
+		default:
+			if status != schema.CampaignRecipientFailed {
+				status = schema.CampaignRecipientSent
+			}
+		}
+	}
+
+	return _i.Repo.UpdateRecipient(recipient.ID, status, &notification.ID, message)
+}
+
+// audience finds the users of the audience with the filters of the users of the business
+func (_i *service) audience(businessID uint64, audience schema.CampaignAudience) (userIDs []uint64, err error) {
+	users, _, err := _i.UserService.Users(userRequest.BusinessUsers{
+		BusinessID:  businessID,
+		CityID:      audience.CityID,
+		WorkspaceID: audience.WorkspaceID,
+		DormitoryID: audience.DormitoryID,
+		Role:        audience.Role,
+		IsSuspended: audience.IsSuspended,
+		CountUsing:  audience.CountUsing,
+		StartTime:   audience.StartTime,
+		EndTime:     audience.EndTime,
+		UserIDs:     audience.UserIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs = make([]uint64, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+
+	return userIDs, nil
+}
@@ -0,0 +1,279 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/testapp"
+)
+
+func TestCampaign_StoreSchedulesIt(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Laundry")
+	other := ta.CreateTestBusiness(t, owner, "Other Laundry")
+	template := ta.CreateTestTemplate(t, business.ID, "{{FirstName}} عزیز، ماشین‌های جدید خوابگاه ۳ آماده‌اند")
+	otherTemplate := ta.CreateTestTemplate(t, other.ID, "سلام")
+	token := ta.GenerateTestToken(t, owner)
+	path := fmt.Sprintf("/v1/business/%d/campaigns", business.ID)
+
+	resp := ta.MakeRequest(t, http.MethodPost, path, map[string]any{
+		"Title":      "New machines",
+		"TemplateID": otherTemplate.ID,
+		"Channels":   []string{string(schema.TSms)},
+	}, token)
+	testapp.AssertStatus(t, resp, http.StatusNotFound)
+
+	sendAt := time.Now().Add(24 * time.Hour).Format(time.DateTime)
+	resp = ta.MakeRequest(t, http.MethodPost, path, map[string]any{
+		"Title":      "New machines",
+		"TemplateID": template.ID,
+		"Channels":   []string{string(schema.TSms)},
+		"SendAt":     sendAt,
+	}, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+
+	var campaign schema.Campaign
+	if err := ta.DB.Where("business_id = ?", business.ID).First(&campaign).Error; err != nil {
+		t.Fatalf("expected the campaign to be stored: %v", err)
+	}
+	if campaign.Status != schema.CampaignStatusScheduled || campaign.Rate != 100 || campaign.CreatedBy != owner.ID {
+		t.Errorf("expected a scheduled campaign with the default rate, got %+v", campaign)
+	}
+
+	// it is not due yet
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+	ta.DB.First(&campaign, campaign.ID)
+	if campaign.Status != schema.CampaignStatusScheduled {
+		t.Errorf("expected the campaign to wait for its send time, got %s", campaign.Status)
+	}
+}
+
+func TestCampaign_PreviewCountsTheAudience(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Laundry")
+	token := ta.GenerateTestToken(t, owner)
+
+	dormitoryID := uint64(3)
+	for i, mobile := range []uint64{9100000002, 9100000003, 9100000004} {
+		user := ta.CreateTestUser(t, mobile, "User", fmt.Sprint(i))
+		if i < 2 {
+			ta.DB.Model(user).Update("dormitory_id", dormitoryID)
+		}
+		if i == 0 {
+			resp := ta.MakeRequest(t, http.MethodPut, "/v1/user/campaign-opt-outs", map[string]any{
+				"BusinessID": business.ID,
+				"OptOut":     true,
+			}, ta.GenerateTestToken(t, user))
+			testapp.AssertStatus(t, resp, http.StatusOK)
+		}
+	}
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/campaigns/preview", business.ID), map[string]any{
+		"DormitoryID": dormitoryID,
+	}, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+
+	data := testapp.ParseResponse(t, resp)["Data"].(map[string]any)
+	if data["Count"] != float64(2) || data["OptedOut"] != float64(1) {
+		t.Errorf("expected the two users of the dormitory, one opted out, got %v", data)
+	}
+}
+
+func TestCampaign_SendsAtItsRateAndSkipsTheOptedOut(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Laundry")
+	template := ta.CreateTestTemplate(t, business.ID, "{{FirstName}} عزیز، برنامه تعطیلات {{BusinessName}}")
+
+	first := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+	second := ta.CreateTestUser(t, 9100000003, "Sara", "Ahmadi")
+	optedOut := ta.CreateTestUser(t, 9100000004, "Reza", "Karimi")
+	ta.DB.Create(&schema.CampaignOptOut{UserID: optedOut.ID, BusinessID: business.ID})
+
+	campaign := ta.CreateTestCampaign(t, template, schema.CampaignAudience{
+		UserIDs: []uint64{first.ID, second.ID, optedOut.ID},
+	}, 1)
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+	ta.DB.First(campaign, campaign.ID)
+	if campaign.Status != schema.CampaignStatusSending || campaign.Total != 3 || campaign.Sent != 1 || campaign.Skipped != 1 {
+		t.Fatalf("expected one recipient sent in the first minute, got %+v", campaign)
+	}
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+	ta.DB.First(campaign, campaign.ID)
+	if campaign.Status != schema.CampaignStatusCompleted || campaign.Sent != 2 || campaign.FinishedAt == nil {
+		t.Fatalf("expected the campaign to be completed, got %+v", campaign)
+	}
+
+	var recipients []schema.CampaignRecipient
+	ta.DB.Where("campaign_id = ?", campaign.ID).Order("user_id asc").Find(&recipients)
+	if len(recipients) != 3 || recipients[2].Status != schema.CampaignRecipientOptedOut || recipients[2].NotificationID != nil {
+		t.Fatalf("expected the opted out user to be skipped, got %+v", recipients)
+	}
+
+	var notification schema.Notification
+	if err := ta.DB.First(&notification, *recipients[0].NotificationID).Error; err != nil {
+		t.Fatalf("expected the notification of the recipient: %v", err)
+	}
+	if notification.Content != "Ali عزیز، برنامه تعطیلات Zciti Laundry" {
+		t.Errorf("expected the template rendered for the recipient, got %q", notification.Content)
+	}
+
+	var messages []schema.OutboxMessage
+	ta.DB.Where("business_id = ?", business.ID).Find(&messages)
+	if len(messages) != 2 {
+		t.Fatalf("expected an sms for each sent recipient, got %d", len(messages))
+	}
+	for _, message := range messages {
+		if message.Priority != schema.OutboxPriorityBulk {
+			t.Errorf("expected the campaign sms to wait for the others, got priority %d", message.Priority)
+		}
+	}
+}
+
+func TestCampaign_ReclaimsTheRecipientWhoseResultWasNotSaved(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Laundry")
+	template := ta.CreateTestTemplate(t, business.ID, "سلام {{FirstName}}")
+	user := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+	campaign := ta.CreateTestCampaign(t, template, schema.CampaignAudience{UserIDs: []uint64{user.ID}}, 1)
+
+	// the notification is sent but its result is not saved on the recipient
+	now := time.Now()
+	ta.Repo.UpdateRecipientErr = errors.New("connection lost")
+	if err := ta.Service.Process(now); err == nil {
+		t.Fatal("expected the failed update to be reported")
+	}
+	ta.Repo.UpdateRecipientErr = nil
+
+	var recipient schema.CampaignRecipient
+	ta.DB.Where("campaign_id = ?", campaign.ID).First(&recipient)
+	if recipient.Status != schema.CampaignRecipientSending {
+		t.Fatalf("expected the recipient to stay sending, got %s", recipient.Status)
+	}
+
+	// it is left to the run that claimed it for a while
+	if err := ta.Service.Process(now.Add(time.Minute)); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+	ta.DB.First(campaign, campaign.ID)
+	if campaign.Status != schema.CampaignStatusSending {
+		t.Fatalf("expected the campaign to wait for the recipient, got %s", campaign.Status)
+	}
+
+	if err := ta.Service.Process(now.Add(11 * time.Minute)); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+	ta.DB.First(&recipient, recipient.ID)
+	if recipient.Status != schema.CampaignRecipientSent || recipient.NotificationID == nil {
+		t.Errorf("expected the recipient to be settled as sent with its notification, got %+v", recipient)
+	}
+
+	var notifications int64
+	ta.DB.Model(&schema.Notification{}).Where("receiver_id = ?", user.ID).Count(&notifications)
+	if notifications != 1 {
+		t.Errorf("expected the recipient not to be sent again, got %d notifications", notifications)
+	}
+
+	ta.DB.First(campaign, campaign.ID)
+	if campaign.Status != schema.CampaignStatusCompleted || campaign.Sent != 1 {
+		t.Errorf("expected the campaign to be completed, got %+v", campaign)
+	}
+}
+
+func TestCampaign_SendsAgainTheClaimedRecipientWithoutANotification(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Laundry")
+	template := ta.CreateTestTemplate(t, business.ID, "سلام {{FirstName}}")
+	user := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+	campaign := ta.CreateTestCampaign(t, template, schema.CampaignAudience{UserIDs: []uint64{user.ID}}, 1)
+
+	// a run claimed the recipient and stopped before sending it
+	claimedAt := time.Now().Add(-time.Hour)
+	ta.DB.Model(campaign).Updates(map[string]any{"status": schema.CampaignStatusSending, "started_at": claimedAt})
+	recipient := &schema.CampaignRecipient{CampaignID: campaign.ID, UserID: user.ID, Status: schema.CampaignRecipientSending, SentAt: &claimedAt}
+	ta.DB.Create(recipient)
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+
+	ta.DB.First(recipient, recipient.ID)
+	if recipient.Status != schema.CampaignRecipientSent || recipient.NotificationID == nil {
+		t.Errorf("expected the recipient to be sent, got %+v", recipient)
+	}
+	ta.DB.First(campaign, campaign.ID)
+	if campaign.Status != schema.CampaignStatusCompleted || campaign.Sent != 1 {
+		t.Errorf("expected the campaign to be completed, got %+v", campaign)
+	}
+}
+
+func TestCampaign_CancelStopsThePendingRecipients(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Laundry")
+	template := ta.CreateTestTemplate(t, business.ID, "سلام {{FirstName}}")
+	token := ta.GenerateTestToken(t, owner)
+
+	var userIDs []uint64
+	for _, mobile := range []uint64{9100000002, 9100000003, 9100000004} {
+		userIDs = append(userIDs, ta.CreateTestUser(t, mobile, "User", "Test").ID)
+	}
+	campaign := ta.CreateTestCampaign(t, template, schema.CampaignAudience{UserIDs: userIDs}, 1)
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+
+	path := fmt.Sprintf("/v1/business/%d/campaigns/%d", business.ID, campaign.ID)
+	resp := ta.MakeRequest(t, http.MethodPost, path+"/cancel", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("failed to process the campaigns: %v", err)
+	}
+
+	resp = ta.MakeRequest(t, http.MethodGet, path, nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	data := testapp.ParseResponse(t, resp)["Data"].(map[string]any)
+	if data["Status"] != string(schema.CampaignStatusCanceled) || data["Sent"] != float64(1) || data["Skipped"] != float64(2) || data["Pending"] != float64(0) {
+		t.Errorf("expected the campaign canceled after the first recipient, got %v", data)
+	}
+
+	resp = ta.MakeRequest(t, http.MethodGet, path+"/recipients?Status=canceled", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if recipients := testapp.ParseResponse(t, resp)["Data"].([]any); len(recipients) != 2 {
+		t.Errorf("expected the two recipients not sent to as canceled, got %v", recipients)
+	}
+
+	// it can not be canceled again
+	resp = ta.MakeRequest(t, http.MethodPost, path+"/cancel", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusBadRequest)
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/campaign"
+	"go-fiber-starter/app/module/campaign/controller"
+	"go-fiber-starter/app/module/campaign/repository"
+	"go-fiber-starter/app/module/campaign/service"
+	notificationRepository "go-fiber-starter/app/module/notification/repository"
+	notificationService "go-fiber-starter/app/module/notification/service"
+	outboxRepository "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	userRepository "go-fiber-starter/app/module/user/repository"
+	userService "go-fiber-starter/app/module/user/service"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/internal/testapp"
+)
+
+// TestApp holds the campaign service on the test app
+type TestApp struct {
+	*testapp.Fixture
+	Repo    *TestRepository
+	Service service.IService
+}
+
+// TestRepository is the campaign repository, the results of the recipients fail to be saved with
+// UpdateRecipientErr when it is set
+type TestRepository struct {
+	repository.IRepository
+	UpdateRecipientErr error
+}
+
+func (r *TestRepository) UpdateRecipient(id uint64, status schema.CampaignRecipientStatus, notificationID *uint64, message string) error {
+	if r.UpdateRecipientErr != nil {
+		return r.UpdateRecipientErr
+	}
+
+	return r.IRepository.UpdateRecipient(id, status, notificationID, message)
+}
+
+// SetupTestApp initializes the test application with a test database
+func SetupTestApp(t *testing.T) *TestApp {
+	t.Helper()
+
+	f := testapp.New(t, "users", "businesses", "notification_templates", "notifications", "outbox_messages",
+		"reservations", "taxonomies", "campaigns", "campaign_recipients", "campaign_opt_outs")
+	hub := f.NotificationHub(t)
+
+	// the sms are queued, the worker is not running in the tests
+	outboxSvc := outboxService.Service(outboxRepository.Repository(f.Database), sms.NewService(f.Config, f.Logger, nil, f.Database), f.Config)
+	notificationSvc := notificationService.Service(notificationRepository.Repository(f.Database), outboxSvc, hub)
+	repo := &TestRepository{IRepository: repository.Repository(f.Database)}
+	campaignSvc := service.Service(repo, userService.Service(userRepository.Repository(f.Database)), notificationSvc)
+	campaignRouter := &campaign.Router{
+		App:        f.App,
+		Controller: controller.Controllers(campaignSvc),
+	}
+	campaignRouter.RegisterRoutes(f.Config)
+
+	return &TestApp{
+		Fixture: f,
+		Repo:    repo,
+		Service: campaignSvc,
+	}
+}
+
+// CreateTestCampaign creates a campaign of the template that is due now
+func (ta *TestApp) CreateTestCampaign(t *testing.T, template *schema.NotificationTemplate, audience schema.CampaignAudience, rate int) *schema.Campaign {
+	t.Helper()
+
+	item := &schema.Campaign{
+		BusinessID: template.BusinessID,
+		Title:      "Test Campaign",
+		TemplateID: template.ID,
+		Channels:   []string{string(schema.TSms), string(schema.TNotification)},
+		Audience:   audience,
+		SendAt:     time.Now().Add(-time.Minute),
+		Rate:       rate,
+		Status:     schema.CampaignStatusScheduled,
+	}
+
+	if err := ta.DB.Create(item).Error; err != nil {
+		t.Fatalf("failed to create test campaign: %v", err)
+	}
+
+	return item
+}
@@ -104,7 +104,7 @@ func (_i *controller) Store(c *fiber.Ctx) error {
 	if err := response.ParseAndValidate(c, req); err != nil {
 		return err
 	}
-	err = _i.service.Store(*req)
+	err = _i.service.Store(*req, nil)
 	if err != nil {
 		return err
 	}
@@ -7,12 +7,14 @@ import (
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/paginator"
 	"strings"
+
+	"gorm.io/gorm"
 )
 
 type IRepository interface {
 	GetAll(req request.Coupons) (coupons []*schema.Coupon, paging paginator.Pagination, err error)
 	GetOne(businessID uint64, id *uint64, code *string) (coupon *schema.Coupon, err error)
-	Create(coupon *schema.Coupon) (err error)
+	Create(coupon *schema.Coupon, tx *gorm.DB) (err error)
 	Update(id uint64, coupon *schema.Coupon) (err error)
 	Delete(id uint64) (err error)
 }
@@ -78,8 +80,11 @@ func (_i *repo) GetOne(businessID uint64, id *uint64, code *string) (coupon *sch
 	return coupon, nil
 }
 
-func (_i *repo) Create(coupon *schema.Coupon) (err error) {
-	err = _i.DB.Main.Create(coupon).Error
+func (_i *repo) Create(coupon *schema.Coupon, tx *gorm.DB) (err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+	err = tx.Create(coupon).Error
 
 	if err != nil && strings.Contains(err.Error(), "value violates unique constraint") {
 		return errors.New("این کد کوپن قبلا ثبت شده است، لطفا مقداری خاص ثبت کنید")
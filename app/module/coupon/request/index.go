@@ -4,6 +4,7 @@ import (
 	"errors"
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
 	"strings"
 	"time"
 )
@@ -54,12 +55,11 @@ func (req *Coupon) ToDomain() (item *schema.Coupon, err error) {
 		Code:        strings.TrimSpace(req.Code),
 	}
 
+	loc := timezone.In(req.BusinessID)
 	if req.StartTime != "" {
-		loc, _ := time.LoadLocation("Asia/Tehran")
 		item.StartTime, _ = time.ParseInLocation(time.DateTime, req.StartTime, loc)
 	}
 	if req.EndTime != "" {
-		loc, _ := time.LoadLocation("Asia/Tehran")
 		item.EndTime, _ = time.ParseInLocation(time.DateTime, req.EndTime, loc)
 	}
 
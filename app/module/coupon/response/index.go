@@ -2,6 +2,7 @@ package response
 
 import (
 	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/timezone"
 	"time"
 )
 
@@ -33,8 +34,9 @@ func FromDomain(item *schema.Coupon) (res *Coupon) {
 		Description: item.Description,
 	}
 
-	res.EndTime = item.EndTime.Format(time.DateTime)
-	res.StartTime = item.StartTime.Format(time.DateTime)
+	loc := timezone.In(item.BusinessID)
+	res.EndTime = item.EndTime.In(loc).Format(time.DateTime)
+	res.StartTime = item.StartTime.In(loc).Format(time.DateTime)
 
 	return res
 }
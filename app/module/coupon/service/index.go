@@ -3,17 +3,17 @@ package service
 import (
 	"errors"
 	"fmt"
-	MessageWay "github.com/MessageWay/MessageWayGolang"
 	"github.com/gofiber/fiber/v2"
 	ptime "github.com/yaa110/go-persian-calendar"
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/module/coupon/repository"
 	"go-fiber-starter/app/module/coupon/request"
 	"go-fiber-starter/app/module/coupon/response"
+	outboxService "go-fiber-starter/app/module/outbox/service"
 	userRequest "go-fiber-starter/app/module/user/request"
 	userService "go-fiber-starter/app/module/user/service"
-	"go-fiber-starter/internal"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
 	"golang.org/x/exp/slices"
 	"gorm.io/gorm"
 	"time"
@@ -22,7 +22,7 @@ import (
 type IService interface {
 	Index(req request.Coupons) (coupons []*response.Coupon, paging paginator.Pagination, err error)
 	Show(businessID uint64, id *uint64, code *string) (coupon *response.Coupon, err error)
-	Store(req request.Coupon) (err error)
+	Store(req request.Coupon, tx *gorm.DB) (err error)
 	Update(id uint64, req request.Coupon) (err error)
 	Destroy(id uint64) error
 
@@ -32,18 +32,18 @@ type IService interface {
 	CalcTotalAmtWithDiscount(coupon *schema.Coupon, totalAmt *float64) (_totalAmt float64)
 }
 
-func Service(Repo repository.IRepository, userService userService.IService, messageWay *internal.MessageWayService) IService {
+func Service(Repo repository.IRepository, userService userService.IService, outboxService outboxService.IService) IService {
 	return &service{
 		Repo,
 		userService,
-		messageWay,
+		outboxService,
 	}
 }
 
 type service struct {
 	Repo        repository.IRepository
 	UserService userService.IService
-	MessageWay  *internal.MessageWayService
+	Outbox      outboxService.IService
 }
 
 func (_i *service) Index(req request.Coupons) (coupons []*response.Coupon, paging paginator.Pagination, err error) {
@@ -68,13 +68,13 @@ func (_i *service) Show(businessID uint64, id *uint64, code *string) (article *r
 	return response.FromDomain(result), nil
 }
 
-func (_i *service) Store(req request.Coupon) (err error) {
+func (_i *service) Store(req request.Coupon, tx *gorm.DB) (err error) {
 	item, err := req.ToDomain()
 	if err != nil {
 		return err
 	}
 
-	return _i.Repo.Create(item)
+	return _i.Repo.Create(item, tx)
 }
 
 func (_i *service) Update(id uint64, req request.Coupon) (err error) {
@@ -103,24 +103,23 @@ func (_i *service) CouponMessageSend(req request.CouponMessageSend) error {
 		return err
 	}
 
-	loc, _ := time.LoadLocation("Asia/Tehran")
-	gTime, err := time.ParseInLocation(time.DateTime, coupon.EndTime, loc)
+	gTime, err := time.ParseInLocation(time.DateTime, coupon.EndTime, timezone.In(req.BusinessID))
 	if err != nil {
 		return err
 	}
 	jTime := ptime.New(gTime)
+
+	// queued all together, so a failing one does not keep the rest from being sent
+	messages := make([]*schema.OutboxMessage, 0, len(users))
 	for _, user := range users {
+		message := schema.NewSms(schema.OutboxPurposeCoupon, user.Mobile, schema.SmsCoupon, user.FullName, coupon.Code, jTime.Format("yyyy/MM/dd HH:mm"))
+		message.BusinessID = &req.BusinessID
+		message.Priority = schema.OutboxPriorityBulk
+		messages = append(messages, message)
+	}
 
-		_, err := _i.MessageWay.Send(MessageWay.Message{
-			Provider:   5, // با سرشماره 5000
-			TemplateID: 12109,
-			Method:     "sms",
-			Params:     []string{user.FullName, coupon.Code, jTime.Format("yyyy/MM/dd HH:mm")},
-			Mobile:     fmt.Sprintf("0%d", user.Mobile),
-		})
-		if err != nil {
-			return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "ارسال دستور با خطا مواجه شد، دوباره امتحان کنید."}
-		}
+	if err := _i.Outbox.Enqueue(nil, messages...); err != nil {
+		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "ارسال پیامک‌ها با خطا مواجه شد، دوباره امتحان کنید."}
 	}
 
 	return nil
@@ -144,8 +143,9 @@ func (_i *service) ValidateCoupon(req request.ValidateCoupon) (coupon *schema.Co
 	}
 
 	if coupon.Meta.LimitInReservationTime {
-		jCouponStartTime := ptime.New(coupon.StartTime).Format("HH:mm - MM/dd")
-		jCouponEndTime := ptime.New(coupon.EndTime).Format("HH:mm - MM/dd")
+		loc := timezone.In(req.BusinessID)
+		jCouponStartTime := ptime.New(coupon.StartTime.In(loc)).Format("HH:mm - MM/dd")
+		jCouponEndTime := ptime.New(coupon.EndTime.In(loc)).Format("HH:mm - MM/dd")
 
 		if len(req.OrderReservationRanges) == 0 || len(req.OrderReservationRanges[0]) == 0 {
 			return nil, &fiber.Error{
@@ -154,7 +154,6 @@ func (_i *service) ValidateCoupon(req request.ValidateCoupon) (coupon *schema.Co
 			}
 		}
 
-		loc, _ := time.LoadLocation("Asia/Tehran")
 		for _, orderReservationRang := range req.OrderReservationRanges {
 			reqStartTime, err := time.ParseInLocation(time.DateTime, orderReservationRang[0], loc)
 			if err != nil {
@@ -17,9 +17,11 @@ import (
 	"go-fiber-starter/app/module/coupon/controller"
 	"go-fiber-starter/app/module/coupon/repository"
 	"go-fiber-starter/app/module/coupon/service"
+	outboxRepo "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
 	userRepo "go-fiber-starter/app/module/user/repository"
 	userService "go-fiber-starter/app/module/user/service"
-	"go-fiber-starter/internal"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/config"
 	"go-fiber-starter/utils/helpers"
@@ -92,6 +94,7 @@ func migrateTestModels(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -201,11 +204,12 @@ func SetupTestApp(t *testing.T) *TestApp {
 	// Create user service
 	userSvc := userService.Service(userRepository)
 
-	// Create mock MessageWay service
-	mockMW := internal.NewMessageWay(cfg, logger)
+	// the fake sms provider of the test config
+	smsSvc := sms.NewService(cfg, logger, nil, dbWrapper)
 
-	// Create coupon service
-	couponSvc := service.Service(couponRepo, userSvc, mockMW)
+	// Create coupon service, its messages go through the outbox
+	outboxSvc := outboxService.Service(outboxRepo.Repository(dbWrapper), smsSvc, cfg)
+	couponSvc := service.Service(couponRepo, userSvc, outboxSvc)
 
 	// Create coupon controller
 	couponController := controller.Controllers(couponSvc)
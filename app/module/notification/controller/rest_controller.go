@@ -1,8 +1,11 @@
 package controller
 
 import (
+	"bufio"
+	"fmt"
 	"go-fiber-starter/utils"
 	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"go-fiber-starter/app/module/notification/request"
@@ -17,8 +20,17 @@ type IRestController interface {
 	Store(c *fiber.Ctx) error
 	Update(c *fiber.Ctx) error
 	Delete(c *fiber.Ctx) error
+
+	Inbox(c *fiber.Ctx) error
+	UnreadCount(c *fiber.Ctx) error
+	Read(c *fiber.Ctx) error
+	ReadAll(c *fiber.Ctx) error
+	Stream(c *fiber.Ctx) error
 }
 
+// StreamHeartbeat keeps the idle streams open behind the proxies and finds the closed ones
+const StreamHeartbeat = 25 * time.Second
+
 func RestController(s service.IService) IRestController {
 	return &controller{s}
 }
@@ -160,3 +172,146 @@ func (_i *controller) Delete(c *fiber.Ctx) error {
 
 	return c.JSON("success")
 }
+
+// Inbox of the user
+// @Summary      Get the in-app notifications of the user
+// @Tags         Notifications
+// @Security     Bearer
+// @Param        Unread query bool false "Only the unread ones"
+// @Router       /user/notifications [get]
+func (_i *controller) Inbox(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	req := request.Inbox{
+		UserID:     user.ID,
+		Unread:     c.QueryBool("Unread"),
+		Pagination: paginate,
+	}
+
+	notifications, paging, err := _i.service.Inbox(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: notifications,
+		Meta: paging,
+	})
+}
+
+// UnreadCount of the inbox of the user
+// @Summary      Get the unread notifications count of the user
+// @Tags         Notifications
+// @Security     Bearer
+// @Router       /user/notifications/unread-count [get]
+func (_i *controller) UnreadCount(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	count, err := _i.service.UnreadCount(user.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: fiber.Map{"Count": count},
+	})
+}
+
+// Read marks a notification of the user as read
+// @Summary      Mark a notification as read
+// @Tags         Notifications
+// @Security     Bearer
+// @Param        id path int true "Notification ID"
+// @Router       /user/notifications/:id/read [patch]
+func (_i *controller) Read(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.MarkRead(user.ID, id); err != nil {
+		return err
+	}
+
+	return c.JSON("success")
+}
+
+// ReadAll marks all the notifications of the user as read
+// @Summary      Mark all the notifications as read
+// @Tags         Notifications
+// @Security     Bearer
+// @Router       /user/notifications/read-all [patch]
+func (_i *controller) ReadAll(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.MarkAllRead(user.ID); err != nil {
+		return err
+	}
+
+	return c.JSON("success")
+}
+
+// Stream pushes the new notifications of the user as server-sent events
+// @Summary      Stream the notifications of the user
+// @Description  An EventSource can not set headers, so the token may be given in the query.
+// @Tags         Notifications
+// @Security     Bearer
+// @Param        token query string false "Access token"
+// @Router       /user/notifications/stream [get]
+func (_i *controller) Stream(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // nginx would hold the events back otherwise
+
+	events, unsubscribe := _i.service.Subscribe(user.ID)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(StreamHeartbeat)
+		defer heartbeat.Stop()
+
+		// how long the browser waits before it reconnects, in milliseconds
+		_, _ = fmt.Fprint(w, "retry: 5000\n\n")
+		for {
+			if err := w.Flush(); err != nil {
+				// the client is gone
+				return
+			}
+
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Name, event.Data)
+			case <-heartbeat.C:
+				_, _ = fmt.Fprint(w, ": heartbeat\n\n")
+			}
+		}
+	})
+
+	return nil
+}
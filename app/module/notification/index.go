@@ -27,6 +27,14 @@ func (_i *Router) RegisterRoutes(cfg *config.Config) {
 		router.Put("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PUpdate), c.Update)
 		router.Delete("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PDelete), c.Delete)
 	})
+
+	_i.App.Route("/v1/user/notifications", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), c.Inbox)
+		router.Get("/unread-count", mdl.Protected(cfg), c.UnreadCount)
+		router.Get("/stream", mdl.ProtectedStream(cfg), c.Stream)
+		router.Patch("/read-all", mdl.Protected(cfg), c.ReadAll)
+		router.Patch("/:id/read", mdl.Protected(cfg), c.Read)
+	})
 }
 
 func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
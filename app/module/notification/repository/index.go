@@ -6,6 +6,9 @@ import (
 	"go-fiber-starter/app/module/notification/response"
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/paginator"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type IRepository interface {
@@ -14,6 +17,15 @@ type IRepository interface {
 	Create(notification *schema.Notification) (err error)
 	Update(id uint64, notification *schema.Notification) (err error)
 	Delete(id uint64) (err error)
+	GetTemplate(businessID uint64, id uint64) (template *schema.NotificationTemplate, err error)
+	GetReceiver(id uint64) (user *schema.User, err error)
+	GetBusiness(id uint64) (business *schema.Business, err error)
+	UpdateDeliveries(id uint64, deliveries schema.NotificationDeliveries) (err error)
+	GetInbox(req request.Inbox) (notifications []*response.InboxNotification, paging paginator.Pagination, err error)
+	GetInboxItem(userID uint64, id uint64) (notification *response.InboxNotification, err error)
+	CountUnread(userID uint64) (count int64, err error)
+	MarkRead(userID uint64, id uint64) (err error)
+	MarkAllRead(userID uint64) (err error)
 }
 
 func Repository(DB *database.Database) IRepository {
@@ -79,3 +91,100 @@ func (_i *repo) Update(id uint64, notification *schema.Notification) (err error)
 func (_i *repo) Delete(id uint64) error {
 	return _i.DB.Main.Delete(&schema.Notification{}, id).Error
 }
+
+func (_i *repo) GetTemplate(businessID uint64, id uint64) (template *schema.NotificationTemplate, err error) {
+	if err = _i.DB.Main.Where(&schema.NotificationTemplate{BusinessID: businessID}).First(&template, id).Error; err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (_i *repo) GetReceiver(id uint64) (user *schema.User, err error) {
+	if err = _i.DB.Main.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (_i *repo) GetBusiness(id uint64) (business *schema.Business, err error) {
+	if err = _i.DB.Main.Preload("Owner").First(&business, id).Error; err != nil {
+		return nil, err
+	}
+
+	return business, nil
+}
+
+func (_i *repo) UpdateDeliveries(id uint64, deliveries schema.NotificationDeliveries) (err error) {
+	return _i.DB.Main.Model(&schema.Notification{}).
+		Where("id = ?", id).
+		Update("deliveries", deliveries).Error
+}
+
+const inboxColumns = "notifications.id, notifications.business_id, notifications.content, notifications.sent_at, " +
+	"notifications.read_at, businesses.title as business_title"
+
+// inbox is the in-app notifications of the user
+func (_i *repo) inbox(userID uint64) *gorm.DB {
+	return _i.DB.Main.
+		Model(&schema.Notification{}).
+		Where("notifications.receiver_id = ? AND ? = ANY(notifications.type)", userID, schema.TNotification)
+}
+
+func (_i *repo) GetInbox(req request.Inbox) (notifications []*response.InboxNotification, paging paginator.Pagination, err error) {
+	query := _i.inbox(req.UserID).
+		Select(inboxColumns).
+		Joins("INNER JOIN businesses ON businesses.id = notifications.business_id")
+
+	if req.Unread {
+		query.Where("notifications.read_at IS NULL")
+	}
+
+	if req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.Order("notifications.sent_at desc").Find(&notifications).Error
+	if err != nil {
+		return
+	}
+
+	paging = *req.Pagination
+
+	return
+}
+
+func (_i *repo) GetInboxItem(userID uint64, id uint64) (notification *response.InboxNotification, err error) {
+	if err = _i.inbox(userID).
+		Select(inboxColumns).
+		Joins("INNER JOIN businesses ON businesses.id = notifications.business_id").
+		Where("notifications.id = ?", id).
+		Take(&notification).Error; err != nil {
+		return nil, err
+	}
+
+	return notification, nil
+}
+
+func (_i *repo) CountUnread(userID uint64) (count int64, err error) {
+	err = _i.inbox(userID).Where("notifications.read_at IS NULL").Count(&count).Error
+	return
+}
+
+func (_i *repo) MarkRead(userID uint64, id uint64) (err error) {
+	return _i.inbox(userID).
+		Where("notifications.id = ? AND notifications.read_at IS NULL", id).
+		Update("read_at", time.Now()).Error
+}
+
+func (_i *repo) MarkAllRead(userID uint64) (err error) {
+	return _i.inbox(userID).
+		Where("notifications.read_at IS NULL").
+		Update("read_at", time.Now()).Error
+}
@@ -3,16 +3,15 @@ package request
 import (
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/utils/paginator"
-	"time"
 )
 
 type Notification struct {
 	ID         uint64
-	ReceiverID uint64    `example:"1" validate:"required,number,min=1"`
-	Type       []string  `example:"Sms" validate:"required,dive"`
-	BusinessID uint64    `example:"1" validate:"min=1"`
-	SentAt     time.Time `example:"2023-10-20T15:47:33.084Z" validate:"datetime"`
-	TemplateID uint64    `example:"1" validate:"required,min=1"`
+	ReceiverID uint64   `example:"1" validate:"required,number,min=1"`
+	Type       []string `example:"Sms" validate:"required,min=1,dive,oneof=Sms Notification"` // the channels it is delivered over
+	TemplateID uint64   `example:"1" validate:"required,min=1"`
+	BusinessID uint64
+	Category   schema.NotificationCategory `json:"-"` // transactional when empty, the campaigns are marketing
 }
 
 type Notifications struct {
@@ -21,12 +20,24 @@ type Notifications struct {
 }
 
 func (req *Notification) ToDomain() *schema.Notification {
+	category := req.Category
+	if category == "" {
+		category = schema.NotificationCategoryTransactional
+	}
+
 	return &schema.Notification{
 		ID:         req.ID,
 		ReceiverID: req.ReceiverID,
 		Type:       req.Type,
 		BusinessID: req.BusinessID,
-		SentAt:     req.SentAt,
-		TemplateID: req.TemplateID,
+		TemplateID: &req.TemplateID,
+		Category:   category,
 	}
 }
+
+// Inbox is the in-app notifications of the user
+type Inbox struct {
+	UserID     uint64
+	Unread     bool
+	Pagination *paginator.Pagination
+}
@@ -2,6 +2,7 @@ package response
 
 import (
 	"github.com/lib/pq"
+	"go-fiber-starter/app/database/schema"
 	"time"
 )
 
@@ -13,7 +14,10 @@ type Notification struct {
 	BusinessID       uint64
 	BusinessTitle    string
 	SentAt           time.Time
-	TemplateID       uint64
+	TemplateID       *uint64
+	Content          string
+	Deliveries       schema.NotificationDeliveries
+	ReadAt           *time.Time
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -40,3 +44,19 @@ type Notification struct {
 //
 //	return res
 //}
+
+// InboxNotification is a notification in the inbox of its receiver
+type InboxNotification struct {
+	ID            uint64
+	BusinessID    uint64
+	BusinessTitle string
+	Content       string
+	SentAt        time.Time
+	ReadAt        *time.Time `json:",omitempty"`
+}
+
+// InboxEvent is pushed to the streams of the receiver
+type InboxEvent struct {
+	Notification *InboxNotification `json:",omitempty"`
+	Unread       int64
+}
@@ -1,28 +1,57 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/module/notification/repository"
 	"go-fiber-starter/app/module/notification/request"
 	"go-fiber-starter/app/module/notification/response"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/internal"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/render"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// the events of the inbox streams
+const (
+	InboxEventNotification = "notification"
+	InboxEventUnread       = "unread"
 )
 
 type IService interface {
 	Index(req request.Notifications) (notifications []*response.Notification, paging paginator.Pagination, err error)
 	Show(businessID uint64, id uint64) (notification *response.Notification, err error)
 	Store(req request.Notification) (err error)
+	Send(req request.Notification, priority schema.OutboxPriority) (notification *schema.Notification, err error)
 	Update(id uint64, req request.Notification) (err error)
 	Destroy(id uint64) error
+	Push(notification *schema.Notification) error
+
+	Inbox(req request.Inbox) (notifications []*response.InboxNotification, paging paginator.Pagination, err error)
+	UnreadCount(userID uint64) (count int64, err error)
+	MarkRead(userID uint64, id uint64) error
+	MarkAllRead(userID uint64) error
+	Subscribe(userID uint64) (events <-chan internal.HubEvent, unsubscribe func())
 }
 
-func Service(Repo repository.IRepository) IService {
+func Service(Repo repository.IRepository, outboxService outboxService.IService, hub *internal.NotificationHub) IService {
 	return &service{
 		Repo,
+		outboxService,
+		hub,
 	}
 }
 
 type service struct {
-	Repo repository.IRepository
+	Repo   repository.IRepository
+	Outbox outboxService.IService
+	Hub    *internal.NotificationHub
 }
 
 func (_i *service) Index(req request.Notifications) (notifications []*response.Notification, paging paginator.Pagination, err error) {
@@ -43,8 +72,137 @@ func (_i *service) Show(businessID uint64, id uint64) (article *response.Notific
 	return result, nil
 }
 
+// Store renders the template for the receiver and delivers it over the channels of the notification,
+// the status of each channel is kept on it. A failed channel does not fail the request.
 func (_i *service) Store(req request.Notification) (err error) {
-	return _i.Repo.Create(req.ToDomain())
+	_, err = _i.Send(req, schema.OutboxPriorityNormal)
+	return err
+}
+
+// Send is Store with the priority of its sms, the ones sent to many users at once wait for the others.
+func (_i *service) Send(req request.Notification, priority schema.OutboxPriority) (notification *schema.Notification, err error) {
+	template, err := _i.Repo.GetTemplate(req.BusinessID, req.TemplateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "قالب اعلان یافت نشد"}
+		}
+		return nil, err
+	}
+	receiver, err := _i.Repo.GetReceiver(req.ReceiverID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "کاربر گیرنده یافت نشد"}
+		}
+		return nil, err
+	}
+	business, err := _i.Repo.GetBusiness(req.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	notification = req.ToDomain()
+	notification.Content = render.Text(template.Content, keywordValues(receiver, business))
+	notification.SentAt = time.Now()
+	notification.Deliveries = schema.NotificationDeliveries{}
+
+	// the channels the receiver turned off are kept as skipped, the notification is only sent over the others
+	preferences := preferencesOf(receiver)
+	channels := notification.Type
+	notification.Type = make(pq.StringArray, 0, len(channels))
+	for _, channel := range channels {
+		if !preferences.Allows(notification.Category, schema.NotificationType(channel)) {
+			notification.Deliveries[schema.NotificationType(channel)] = schema.NotificationDelivery{Status: schema.NotificationDeliverySkipped}
+			continue
+		}
+		notification.Type = append(notification.Type, channel)
+		notification.Deliveries[schema.NotificationType(channel)] = schema.NotificationDelivery{Status: schema.NotificationDeliveryPending}
+	}
+	if err = _i.Repo.Create(notification); err != nil {
+		return nil, err
+	}
+
+	for _, channel := range notification.Type {
+		notification.Deliveries[schema.NotificationType(channel)] = _i.deliver(schema.NotificationType(channel), notification, receiver, priority)
+	}
+
+	if err = _i.Repo.UpdateDeliveries(notification.ID, notification.Deliveries); err != nil {
+		return nil, err
+	}
+
+	if notification.Deliveries[schema.TNotification].Status == schema.NotificationDeliveryDelivered {
+		// it is in the inbox anyway, the stream only shows it sooner
+		_ = _i.publish(notification.ReceiverID, notification.ID)
+	}
+
+	return notification, nil
+}
+
+// Push puts a notification of the app, e.g. a reservation confirmation, in the inbox of its receiver.
+// It is dropped when the receiver turned the in-app ones of its category off.
+func (_i *service) Push(notification *schema.Notification) error {
+	if notification.Category == "" {
+		notification.Category = schema.NotificationCategoryTransactional
+	}
+	receiver, err := _i.Repo.GetReceiver(notification.ReceiverID)
+	if err != nil {
+		return err
+	}
+	if !preferencesOf(receiver).Allows(notification.Category, schema.TNotification) {
+		return nil
+	}
+
+	now := time.Now()
+	notification.Type = []string{string(schema.TNotification)}
+	notification.SentAt = now
+	notification.Deliveries = schema.NotificationDeliveries{
+		schema.TNotification: {Status: schema.NotificationDeliveryDelivered, At: &now},
+	}
+	if err := _i.Repo.Create(notification); err != nil {
+		return err
+	}
+
+	_ = _i.publish(notification.ReceiverID, notification.ID)
+
+	return nil
+}
+
+func (_i *service) Inbox(req request.Inbox) (notifications []*response.InboxNotification, paging paginator.Pagination, err error) {
+	return _i.Repo.GetInbox(req)
+}
+
+func (_i *service) UnreadCount(userID uint64) (count int64, err error) {
+	return _i.Repo.CountUnread(userID)
+}
+
+func (_i *service) MarkRead(userID uint64, id uint64) error {
+	if _, err := _i.Repo.GetInboxItem(userID, id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &fiber.Error{Code: fiber.StatusNotFound, Message: "اعلان یافت نشد"}
+		}
+		return err
+	}
+
+	if err := _i.Repo.MarkRead(userID, id); err != nil {
+		return err
+	}
+
+	_ = _i.publishUnread(userID)
+
+	return nil
+}
+
+func (_i *service) MarkAllRead(userID uint64) error {
+	if err := _i.Repo.MarkAllRead(userID); err != nil {
+		return err
+	}
+
+	_ = _i.publishUnread(userID)
+
+	return nil
+}
+
+func (_i *service) Subscribe(userID uint64) (events <-chan internal.HubEvent, unsubscribe func()) {
+	return _i.Hub.Subscribe(userID)
 }
 
 func (_i *service) Update(id uint64, req request.Notification) (err error) {
@@ -55,3 +213,96 @@ func (_i *service) Update(id uint64, req request.Notification) (err error) {
 func (_i *service) Destroy(id uint64) error {
 	return _i.Repo.Delete(id)
 }
+
+// deliver sends the rendered content over the channel
+func (_i *service) deliver(channel schema.NotificationType, notification *schema.Notification, receiver *schema.User, priority schema.OutboxPriority) schema.NotificationDelivery {
+	now := time.Now()
+
+	switch channel {
+	case schema.TNotification:
+		// the receiver reads it in the app, storing it is the delivery
+		return schema.NotificationDelivery{Status: schema.NotificationDeliveryDelivered, At: &now}
+	case schema.TSms:
+		message := schema.NewSms(smsPurpose(notification.Category), receiver.Mobile, schema.SmsNotification, notification.Content)
+		message.BusinessID = &notification.BusinessID
+		message.Priority = priority
+		if err := _i.Outbox.Enqueue(nil, message); err != nil {
+			return schema.NotificationDelivery{Status: schema.NotificationDeliveryFailed, Error: err.Error(), At: &now}
+		}
+		if message.Status == schema.OutboxStatusSkipped {
+			return schema.NotificationDelivery{Status: schema.NotificationDeliverySkipped, MessageID: &message.ID, At: &now}
+		}
+
+		return schema.NotificationDelivery{Status: schema.NotificationDeliveryQueued, MessageID: &message.ID, At: &now}
+	}
+
+	return schema.NotificationDelivery{Status: schema.NotificationDeliveryFailed, Error: "unknown channel", At: &now}
+}
+
+// publish pushes the notification to the open streams of the receiver
+func (_i *service) publish(userID uint64, id uint64) error {
+	item, err := _i.Repo.GetInboxItem(userID, id)
+	if err != nil {
+		return err
+	}
+	unread, err := _i.Repo.CountUnread(userID)
+	if err != nil {
+		return err
+	}
+
+	err = _i.Hub.Publish(userID, InboxEventNotification, response.InboxEvent{Notification: item, Unread: unread})
+	if errors.Is(err, internal.ErrHubPayloadTooLarge) {
+		// the app reads the long ones from the inbox
+		item.Content = ""
+		err = _i.Hub.Publish(userID, InboxEventNotification, response.InboxEvent{Notification: item, Unread: unread})
+	}
+
+	return err
+}
+
+// publishUnread lets the other open streams of the user know what is read
+func (_i *service) publishUnread(userID uint64) error {
+	unread, err := _i.Repo.CountUnread(userID)
+	if err != nil {
+		return err
+	}
+
+	return _i.Hub.Publish(userID, InboxEventUnread, response.InboxEvent{Unread: unread})
+}
+
+// smsPurpose is the purpose of the sms of the category, the outbox finds the category by it
+func smsPurpose(category schema.NotificationCategory) schema.OutboxPurpose {
+	switch category {
+	case schema.NotificationCategoryMarketing:
+		return schema.OutboxPurposeCampaign
+	case schema.NotificationCategoryReminders:
+		return schema.OutboxPurposeReminder
+	}
+
+	return schema.OutboxPurposeNotification
+}
+
+// preferencesOf are the notification preferences of the user, everything is on when there are none
+func preferencesOf(user *schema.User) schema.NotificationPreferences {
+	if user == nil || user.Meta == nil {
+		return nil
+	}
+
+	return user.Meta.NotificationPreferences
+}
+
+// keywordValues are the values of the template keywords for the receiver
+func keywordValues(receiver *schema.User, business *schema.Business) render.Values {
+	values := render.Values{
+		"FirstName":    receiver.FirstName,
+		"LastName":     receiver.LastName,
+		"FullName":     receiver.FullName(),
+		"Mobile":       fmt.Sprintf("0%d", receiver.Mobile),
+		"BusinessName": business.Title,
+	}
+	if business.Owner.Mobile != 0 {
+		values["BusinessPhone"] = fmt.Sprintf("0%d", business.Owner.Mobile)
+	}
+
+	return values
+}
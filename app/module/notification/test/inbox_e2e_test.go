@@ -0,0 +1,127 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/notification/response"
+	"go-fiber-starter/app/module/notification/service"
+	"go-fiber-starter/internal/testapp"
+)
+
+func TestInbox_ListAndRead(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Gym")
+	receiver := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+	other := ta.CreateTestUser(t, 9100000003, "Other", "User")
+	token := ta.GenerateTestToken(t, receiver)
+
+	for _, content := range []string{"first", "second"} {
+		if err := ta.Service.Push(&schema.Notification{ReceiverID: receiver.ID, BusinessID: business.ID, Content: content}); err != nil {
+			t.Fatalf("failed to push notification: %v", err)
+		}
+	}
+	if err := ta.Service.Push(&schema.Notification{ReceiverID: other.ID, BusinessID: business.ID, Content: "not yours"}); err != nil {
+		t.Fatalf("failed to push notification: %v", err)
+	}
+
+	resp := ta.MakeRequest(t, http.MethodGet, "/v1/user/notifications?Page=1&Limit=10", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	result := testapp.ParseResponse(t, resp)
+	items, _ := result["Data"].([]any)
+	if len(items) != 2 {
+		t.Fatalf("expected the two notifications of the user, got %v", result["Data"])
+	}
+	latest := items[0].(map[string]any)
+	if latest["Content"] != "second" || latest["BusinessTitle"] != "Zciti Gym" {
+		t.Errorf("expected the latest notification first with its business, got %v", latest)
+	}
+	assertUnread(t, ta, token, 2)
+
+	resp = ta.MakeRequest(t, http.MethodPatch, fmt.Sprintf("/v1/user/notifications/%d/read", uint64(latest["ID"].(float64))), nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	assertUnread(t, ta, token, 1)
+
+	resp = ta.MakeRequest(t, http.MethodGet, "/v1/user/notifications?Unread=true", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if items, _ := testapp.ParseResponse(t, resp)["Data"].([]any); len(items) != 1 {
+		t.Errorf("expected one unread notification, got %d", len(items))
+	}
+
+	resp = ta.MakeRequest(t, http.MethodPatch, "/v1/user/notifications/read-all", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	assertUnread(t, ta, token, 0)
+
+	var otherUnread int64
+	ta.DB.Model(&schema.Notification{}).Where("receiver_id = ? AND read_at IS NULL", other.ID).Count(&otherUnread)
+	if otherUnread != 1 {
+		t.Errorf("expected the notifications of the other users to stay unread, got %d unread", otherUnread)
+	}
+}
+
+func TestInbox_ReadOthersNotification(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Gym")
+	receiver := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+
+	notification := &schema.Notification{ReceiverID: receiver.ID, BusinessID: business.ID, Content: "hello"}
+	if err := ta.Service.Push(notification); err != nil {
+		t.Fatalf("failed to push notification: %v", err)
+	}
+
+	resp := ta.MakeRequest(t, http.MethodPatch, fmt.Sprintf("/v1/user/notifications/%d/read", notification.ID), nil, ta.GenerateTestToken(t, owner))
+	testapp.AssertStatus(t, resp, http.StatusNotFound)
+}
+
+func TestInbox_StreamReceivesPushedNotification(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Gym")
+	receiver := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+
+	events, unsubscribe := ta.Service.Subscribe(receiver.ID)
+	defer unsubscribe()
+
+	if err := ta.Service.Push(&schema.Notification{ReceiverID: receiver.ID, BusinessID: business.ID, Content: "live"}); err != nil {
+		t.Fatalf("failed to push notification: %v", err)
+	}
+
+	select {
+	case event := <-events:
+		if event.Name != service.InboxEventNotification {
+			t.Fatalf("expected a notification event, got %s", event.Name)
+		}
+		var data response.InboxEvent
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			t.Fatalf("failed to parse the event: %v", err)
+		}
+		if data.Notification == nil || data.Notification.Content != "live" || data.Unread != 1 {
+			t.Errorf("expected the pushed notification with one unread, got %+v", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the pushed notification on the stream")
+	}
+}
+
+func assertUnread(t *testing.T, ta *TestApp, token string, expected int) {
+	t.Helper()
+
+	resp := ta.MakeRequest(t, http.MethodGet, "/v1/user/notifications/unread-count", nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	data, _ := testapp.ParseResponse(t, resp)["Data"].(map[string]any)
+	if count, _ := data["Count"].(float64); int(count) != expected {
+		t.Errorf("expected %d unread notifications, got %v", expected, data["Count"])
+	}
+}
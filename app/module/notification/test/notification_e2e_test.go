@@ -0,0 +1,164 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/notification/request"
+	"go-fiber-starter/internal/testapp"
+	"go-fiber-starter/utils/optout"
+)
+
+func TestNotificationTemplate_RejectsUnknownKeywords(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	token := ta.GenerateTestToken(t, owner)
+	path := fmt.Sprintf("/v1/business/%d/notificationTemplates", business.ID)
+
+	tests := []struct {
+		name     string
+		content  string
+		expected int
+	}{
+		{"known keywords", "سلام {{FirstName}}، {{ BusinessName }}", http.StatusOK},
+		{"unknown keyword", "سلام {{Name}}", http.StatusBadRequest},
+		{"unbalanced braces", "سلام {{FirstName}", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := ta.MakeRequest(t, http.MethodPost, path, map[string]any{
+				"Title":   "Welcome",
+				"Content": tt.content,
+			}, token)
+			testapp.AssertStatus(t, resp, tt.expected)
+		})
+	}
+
+	var count int64
+	ta.DB.Model(&schema.NotificationTemplate{}).Where("business_id = ?", business.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("expected only the valid template to be saved for the business, got %d", count)
+	}
+}
+
+func TestNotification_StoreRendersAndDelivers(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Gym")
+	receiver := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+	template := ta.CreateTestTemplate(t, business.ID, "{{FullName}} عزیز، به {{BusinessName}} خوش آمدید {{Unknown}}")
+	token := ta.GenerateTestToken(t, owner)
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/notifications", business.ID), map[string]any{
+		"ReceiverID": receiver.ID,
+		"TemplateID": template.ID,
+		"Type":       []string{string(schema.TSms), string(schema.TNotification)},
+	}, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+
+	var notification schema.Notification
+	if err := ta.DB.Where("receiver_id = ?", receiver.ID).First(&notification).Error; err != nil {
+		t.Fatalf("expected the notification to be stored: %v", err)
+	}
+
+	expected := "Ali Rezaei عزیز، به Zciti Gym خوش آمدید {{Unknown}}"
+	if notification.Content != expected {
+		t.Errorf("expected content %q, got %q", expected, notification.Content)
+	}
+
+	sms := notification.Deliveries[schema.TSms]
+	if sms.Status != schema.NotificationDeliveryQueued || sms.MessageID == nil {
+		t.Fatalf("expected the sms to be queued, got %+v", sms)
+	}
+	var message schema.OutboxMessage
+	if err := ta.DB.First(&message, *sms.MessageID).Error; err != nil {
+		t.Fatalf("expected the sms in the outbox: %v", err)
+	}
+	if len(message.Params) != 1 || message.Params[0] != expected || message.Mobile != "09100000002" {
+		t.Errorf("expected the rendered content to the receiver, got %+v", message)
+	}
+	if inApp := notification.Deliveries[schema.TNotification]; inApp.Status != schema.NotificationDeliveryDelivered {
+		t.Errorf("expected the in-app notification to be delivered, got %+v", inApp)
+	}
+}
+
+func TestNotification_StoreRejectsTemplateOfAnotherBusiness(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Gym")
+	other := ta.CreateTestBusiness(t, ta.CreateTestUser(t, 9100000003, "Other", "Owner"), "Other")
+	receiver := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+	template := ta.CreateTestTemplate(t, other.ID, "سلام {{FirstName}}")
+	token := ta.GenerateTestToken(t, owner)
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/notifications", business.ID), map[string]any{
+		"ReceiverID": receiver.ID,
+		"TemplateID": template.ID,
+		"Type":       []string{string(schema.TNotification)},
+	}, token)
+	testapp.AssertStatus(t, resp, http.StatusNotFound)
+}
+
+func TestNotification_SendFollowsThePreferencesOfTheReceiver(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Owner", "User")
+	business := ta.CreateTestBusiness(t, owner, "Zciti Gym")
+	optedOut := ta.CreateTestUser(t, 9100000002, "Ali", "Rezaei")
+	receiver := ta.CreateTestUser(t, 9100000003, "Sara", "Ahmadi")
+	template := ta.CreateTestTemplate(t, business.ID, "تخفیف ویژه {{BusinessName}}")
+
+	prefs := schema.NotificationPreferences{}
+	prefs.Turn(schema.NotificationCategoryMarketing, false, schema.TSms)
+	ta.DB.Model(optedOut).Update("meta", &schema.UserMeta{NotificationPreferences: prefs})
+
+	send := func(receiverID uint64, category schema.NotificationCategory) *schema.Notification {
+		notification, err := ta.Service.Send(request.Notification{
+			ReceiverID: receiverID,
+			Type:       []string{string(schema.TSms), string(schema.TNotification)},
+			TemplateID: template.ID,
+			BusinessID: business.ID,
+			Category:   category,
+		}, schema.OutboxPriorityBulk)
+		if err != nil {
+			t.Fatalf("failed to send the notification: %v", err)
+		}
+		return notification
+	}
+
+	notification := send(optedOut.ID, schema.NotificationCategoryMarketing)
+	if sms := notification.Deliveries[schema.TSms]; sms.Status != schema.NotificationDeliverySkipped {
+		t.Errorf("expected the marketing sms to be skipped, got %+v", sms)
+	}
+	if inApp := notification.Deliveries[schema.TNotification]; inApp.Status != schema.NotificationDeliveryDelivered {
+		t.Errorf("expected the in-app notification to be delivered, got %+v", inApp)
+	}
+
+	// only the marketing is off
+	notification = send(optedOut.ID, schema.NotificationCategoryTransactional)
+	if sms := notification.Deliveries[schema.TSms]; sms.Status != schema.NotificationDeliveryQueued {
+		t.Errorf("expected the transactional sms to be queued, got %+v", sms)
+	}
+
+	notification = send(receiver.ID, schema.NotificationCategoryMarketing)
+	var message schema.OutboxMessage
+	if err := ta.DB.First(&message, *notification.Deliveries[schema.TSms].MessageID).Error; err != nil {
+		t.Fatalf("expected the sms in the outbox: %v", err)
+	}
+	link := optout.Link(ta.Config.App.BackendDomain, ta.Config.Middleware.Jwt.Secret, receiver.ID)
+	if message.Purpose != schema.OutboxPurposeCampaign || len(message.Params) != 2 || !strings.Contains(message.Params[1], link) {
+		t.Errorf("expected the marketing sms to carry the opt-out link, got %+v", message)
+	}
+}
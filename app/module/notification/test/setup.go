@@ -0,0 +1,52 @@
+package test
+
+import (
+	"testing"
+
+	"go-fiber-starter/app/module/notification"
+	"go-fiber-starter/app/module/notification/controller"
+	"go-fiber-starter/app/module/notification/repository"
+	"go-fiber-starter/app/module/notification/service"
+	notificationtemplate "go-fiber-starter/app/module/notificationTemplate"
+	templateController "go-fiber-starter/app/module/notificationTemplate/controller"
+	templateRepository "go-fiber-starter/app/module/notificationTemplate/repository"
+	templateService "go-fiber-starter/app/module/notificationTemplate/service"
+	outboxRepository "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/internal/testapp"
+)
+
+// TestApp holds the notification service on the test app
+type TestApp struct {
+	*testapp.Fixture
+	Service service.IService
+}
+
+// SetupTestApp initializes the test application with a test database
+func SetupTestApp(t *testing.T) *TestApp {
+	t.Helper()
+
+	f := testapp.New(t, "users", "businesses", "notification_templates", "notifications", "outbox_messages")
+	hub := f.NotificationHub(t)
+
+	// the sms are queued, the worker is not running in the tests
+	outboxSvc := outboxService.Service(outboxRepository.Repository(f.Database), sms.NewService(f.Config, f.Logger, nil, f.Database), f.Config)
+	notificationSvc := service.Service(repository.Repository(f.Database), outboxSvc, hub)
+	notificationRouter := &notification.Router{
+		App:        f.App,
+		Controller: controller.Controllers(notificationSvc),
+	}
+	notificationRouter.RegisterRoutes(f.Config)
+
+	templateRouter := &notificationtemplate.Router{
+		App:        f.App,
+		Controller: templateController.Controllers(templateService.Service(templateRepository.Repository(f.Database))),
+	}
+	templateRouter.RegisterRoutes(f.Config)
+
+	return &TestApp{
+		Fixture: f,
+		Service: notificationSvc,
+	}
+}
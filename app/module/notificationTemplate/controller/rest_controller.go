@@ -6,6 +6,7 @@ import (
 	"go-fiber-starter/app/module/notificationTemplate/service"
 	"go-fiber-starter/utils"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/render"
 	"go-fiber-starter/utils/response"
 	"strconv"
 )
@@ -65,15 +66,7 @@ func (_i *controller) Index(c *fiber.Ctx) error {
 // @Param        businessID path int true "Business ID"
 // @Router       /business/:businessID/notificationTemplates/keywords [get]
 func (_i *controller) Keywords(c *fiber.Ctx) error {
-	var keywords = map[string]string{
-		"FirstName":     "نام کاربر",
-		"LastName":      "نام خانوادگی کاربر",
-		"FullName":      "نام و نام خانوادگی کاربر",
-		"BusinessName":  "نام کسب و کار",
-		"BusinessPhone": "تلفن کسب و کار",
-	}
-
-	return c.JSON(keywords)
+	return c.JSON(render.Keywords)
 }
 
 // Store
@@ -20,9 +20,10 @@ type Index struct {
 
 func (req *NotificationTemplate) ToDomain() *schema.NotificationTemplate {
 	return &schema.NotificationTemplate{
-		ID:      req.ID,
-		Title:   req.Title,
-		Content: req.Content,
-		Tag:     req.Tag,
+		ID:         req.ID,
+		Title:      req.Title,
+		Content:    req.Content,
+		Tag:        req.Tag,
+		BusinessID: req.BusinessID,
 	}
 }
@@ -5,6 +5,10 @@ import (
 	"go-fiber-starter/app/module/notificationTemplate/request"
 	"go-fiber-starter/app/module/notificationTemplate/response"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/render"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 type IService interface {
@@ -47,13 +51,33 @@ func (_i *service) Show(id uint64) (notificationTemplate *response.NotificationT
 }
 
 func (_i *service) Store(req request.NotificationTemplate) (err error) {
+	if err = validateKeywords(req.Content); err != nil {
+		return err
+	}
+
 	return _i.Repo.Create(req.ToDomain())
 }
 
 func (_i *service) Update(id uint64, req request.NotificationTemplate) (err error) {
+	if err = validateKeywords(req.Content); err != nil {
+		return err
+	}
+
 	return _i.Repo.Update(id, req.ToDomain())
 }
 
 func (_i *service) Destroy(businessID uint64, id uint64) error {
 	return _i.Repo.Delete(businessID, id)
 }
+
+// validateKeywords rejects the content with placeholders that can not be rendered
+func validateKeywords(content string) error {
+	if invalid := render.Invalid(content); len(invalid) > 0 {
+		return &fiber.Error{
+			Code:    fiber.StatusBadRequest,
+			Message: "کلمات کلیدی نامعتبر در متن قالب: " + strings.Join(invalid, "، "),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"go-fiber-starter/app/module/operatorBot/request"
+	"go-fiber-starter/app/module/operatorBot/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Link(c *fiber.Ctx) error
+	Chats(c *fiber.Ctx) error
+	DeleteChat(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// Link to the operator bot
+// @Summary      Get a link that connects a Bale chat to the operator bot as the user
+// @Description  The link works for 10 minutes, the chat runs the commands with the permissions of the user.
+// @Tags         OperatorBot
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/operator-bot/link [get]
+func (_i *controller) Link(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	link, err := _i.service.Link(businessID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: link,
+	})
+}
+
+// Chats linked to the operator bot
+// @Summary      Get the chats linked to the operator bot
+// @Tags         OperatorBot
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/operator-bot/chats [get]
+func (_i *controller) Chats(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	chats, paging, err := _i.service.Chats(request.Chats{
+		BusinessID: businessID,
+		Pagination: paginate,
+	})
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: chats,
+		Meta: paging,
+	})
+}
+
+// DeleteChat unlinks a chat from the operator bot
+// @Summary      Unlink a chat from the operator bot
+// @Tags         OperatorBot
+// @Security     Bearer
+// @Param        id path int true "Chat ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/operator-bot/chats/:id [delete]
+func (_i *controller) DeleteChat(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.DeleteChat(businessID, id); err != nil {
+		return err
+	}
+
+	return c.JSON("success")
+}
@@ -0,0 +1,49 @@
+package operatorbot
+
+import (
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/operatorBot/controller"
+	"go-fiber-starter/app/module/operatorBot/poller"
+	"go-fiber-starter/app/module/operatorBot/repository"
+	"go-fiber-starter/app/module/operatorBot/service"
+	"go-fiber-starter/utils/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes, the chats run as the business owners
+	_i.App.Route("/v1/business/:businessID/operator-bot", func(router fiber.Router) {
+		router.Get("/link", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DBusiness, mdl.PUpdate), c.Link)
+		router.Get("/chats", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DBusiness, mdl.PUpdate), c.Chats)
+		router.Delete("/chats/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DBusiness, mdl.PUpdate), c.DeleteChat)
+	})
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+
+	fx.Invoke(poller.RunUpdatePoller),
+)
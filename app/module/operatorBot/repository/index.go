@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/operatorBot/request"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/paginator"
+
+	"gorm.io/gorm/clause"
+)
+
+type IRepository interface {
+	GetAll(req request.Chats) (chats []*schema.OperatorChat, paging paginator.Pagination, err error)
+	GetChat(chatID int64) (chat *schema.OperatorChat, err error)
+	GetUser(id uint64) (user *schema.User, err error)
+	Link(chat *schema.OperatorChat) (err error)
+	Unlink(chatID int64) (err error)
+	Delete(businessID uint64, id uint64) (deleted bool, err error)
+}
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+func (_i *repo) GetAll(req request.Chats) (chats []*schema.OperatorChat, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.OperatorChat{}).
+		Where(&schema.OperatorChat{BusinessID: req.BusinessID})
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.Preload("User").Order("id desc").Find(&chats).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+// GetChat gives the chat with its user as it is now, the permissions of the user may have changed since the link
+func (_i *repo) GetChat(chatID int64) (chat *schema.OperatorChat, err error) {
+	err = _i.DB.Main.
+		Where(&schema.OperatorChat{ChatID: chatID}).
+		Preload("User").
+		Preload("Business").
+		First(&chat).Error
+
+	return
+}
+
+func (_i *repo) GetUser(id uint64) (user *schema.User, err error) {
+	err = _i.DB.Main.First(&user, id).Error
+	return
+}
+
+// Link links the chat to the user, a chat linked before moves to the new user
+func (_i *repo) Link(chat *schema.OperatorChat) (err error) {
+	return _i.DB.Main.
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "chat_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"user_id", "business_id", "updated_at"}),
+		}).
+		Create(chat).Error
+}
+
+func (_i *repo) Unlink(chatID int64) (err error) {
+	return _i.DB.Main.Where(&schema.OperatorChat{ChatID: chatID}).Delete(&schema.OperatorChat{}).Error
+}
+
+func (_i *repo) Delete(businessID uint64, id uint64) (deleted bool, err error) {
+	result := _i.DB.Main.Where(&schema.OperatorChat{BusinessID: businessID}).Delete(&schema.OperatorChat{}, id)
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,8 @@
+package request
+
+import "go-fiber-starter/utils/paginator"
+
+type Chats struct {
+	BusinessID uint64
+	Pagination *paginator.Pagination
+}
@@ -0,0 +1,34 @@
+package response
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"time"
+)
+
+type Chat struct {
+	ID        uint64    `json:",omitempty"`
+	ChatID    int64     `json:",omitempty"`
+	UserID    uint64    `json:",omitempty"`
+	FullName  string    `json:",omitempty"`
+	CreatedAt time.Time `json:",omitempty"`
+}
+
+// Link opens the bot and links the chat to the user who asked for it
+type Link struct {
+	Link      string
+	ExpiresAt time.Time
+}
+
+func FromDomain(item *schema.OperatorChat) *Chat {
+	chat := &Chat{
+		ID:        item.ID,
+		ChatID:    item.ChatID,
+		UserID:    item.UserID,
+		CreatedAt: item.CreatedAt,
+	}
+	if item.User != nil {
+		chat.FullName = item.User.FullName()
+	}
+
+	return chat
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/operatorBot/repository"
+	"go-fiber-starter/app/module/operatorBot/request"
+	"go-fiber-starter/app/module/operatorBot/response"
+	orequest "go-fiber-starter/app/module/order/request"
+	oservice "go-fiber-starter/app/module/order/service"
+	rrequest "go-fiber-starter/app/module/reservation/request"
+	rresponse "go-fiber-starter/app/module/reservation/response"
+	rservice "go-fiber-starter/app/module/reservation/service"
+	urequest "go-fiber-starter/app/module/uniwash/request"
+	uservice "go-fiber-starter/app/module/uniwash/service"
+	"go-fiber-starter/internal"
+	"go-fiber-starter/utils/config"
+	"go-fiber-starter/utils/deeplink"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// the purpose of the link tokens, so the other tokens signed with the secret are not taken for them
+const linkPurpose = "operatorBot"
+
+// how long a link works after it is given
+const linkLifetime = 10 * time.Minute
+
+const help = `دستورها:
+/reservations شناسه دستگاه — رزروهای امروز دستگاه
+/on شناسه رزرو — روشن کردن دستگاه رزرو
+/off شناسه رزرو — خاموش کردن دستگاه رزرو
+/status شناسه رزرو — وضعیت آخرین دستور رزرو
+/revenue — درآمد امروز
+/coupon شناسه رزرو — ارسال کد جبرانی به کاربر رزرو
+/stop — قطع اتصال این گفتگو`
+
+var (
+	errForbidden           = &fiber.Error{Code: fiber.StatusForbidden, Message: "شما دسترسی لازم را ندارید"}
+	errChatNotLinked       = &fiber.Error{Code: fiber.StatusUnauthorized, Message: "این گفتگو به حسابی متصل نیست، لینک اتصال را از پنل مدیریت بگیرید."}
+	errReservationNotFound = &fiber.Error{Code: fiber.StatusNotFound, Message: "رزرو یافت نشد"}
+	errChatNotFound        = &fiber.Error{Code: fiber.StatusNotFound, Message: "گفتگو یافت نشد"}
+)
+
+type IService interface {
+	Link(businessID uint64, userID uint64) (link *response.Link, err error)
+	Chats(req request.Chats) (chats []*response.Chat, paging paginator.Pagination, err error)
+	DeleteChat(businessID uint64, id uint64) (err error)
+
+	Handle(chatID int64, text string) (reply string, err error)
+}
+
+func Service(
+	repo repository.IRepository,
+	reservationService rservice.IService,
+	uniwashService uservice.IService,
+	orderService oservice.IService,
+	bot *internal.OperatorBot,
+	cfg *config.Config,
+) IService {
+	return &service{
+		repo,
+		reservationService,
+		uniwashService,
+		orderService,
+		bot,
+		cfg,
+	}
+}
+
+type service struct {
+	Repo               repository.IRepository
+	ReservationService rservice.IService
+	UniwashService     uservice.IService
+	OrderService       oservice.IService
+	Bot                *internal.OperatorBot
+	Config             *config.Config
+}
+
+func (_i *service) Link(businessID uint64, userID uint64) (link *response.Link, err error) {
+	if _i.Bot.Name == "" {
+		return nil, &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "ربات اپراتور راه‌اندازی نشده است"}
+	}
+
+	expiresAt := time.Now().Add(linkLifetime)
+	token := deeplink.Token(_i.Config.Middleware.Jwt.Secret, linkPurpose, expiresAt, userID, businessID)
+
+	return &response.Link{Link: deeplink.Link(_i.Bot.Name, token), ExpiresAt: expiresAt}, nil
+}
+
+func (_i *service) Chats(req request.Chats) (chats []*response.Chat, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetAll(req)
+	if err != nil {
+		return
+	}
+
+	for _, result := range results {
+		chats = append(chats, response.FromDomain(result))
+	}
+
+	return
+}
+
+func (_i *service) DeleteChat(businessID uint64, id uint64) (err error) {
+	deleted, err := _i.Repo.Delete(businessID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errChatNotFound
+	}
+
+	return nil
+}
+
+// Handle runs a command of a chat as the user the chat is linked to, with the permissions that user has on
+// the REST API, and gives the reply of the bot.
+func (_i *service) Handle(chatID int64, text string) (reply string, err error) {
+	command, args := parseCommand(text)
+
+	if command == "/start" {
+		return _i.link(chatID, args)
+	}
+
+	chat, err := _i.Repo.GetChat(chatID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errChatNotLinked
+		}
+		return "", err
+	}
+	if chat.User == nil {
+		return "", errChatNotLinked
+	}
+
+	switch command {
+	case "/help":
+		return help, nil
+	case "/stop":
+		if err = _i.Repo.Unlink(chatID); err != nil {
+			return "", err
+		}
+		return "اتصال این گفتگو قطع شد.", nil
+	case "/reservations":
+		return _i.reservations(chat, args)
+	case "/on":
+		return _i.sendCommand(chat, args, schema.UniWashCommandON)
+	case "/off":
+		return _i.sendCommand(chat, args, schema.UniWashCommandOFF)
+	case "/status":
+		return _i.lastCommandStatus(chat, args)
+	case "/revenue":
+		return _i.revenue(chat)
+	case "/coupon":
+		return _i.coupon(chat, args)
+	}
+
+	return "دستور شناخته نشد.\n\n" + help, nil
+}
+
+// link links the chat to the user and the business of the token
+func (_i *service) link(chatID int64, token string) (reply string, err error) {
+	if token == "" {
+		return "", errChatNotLinked
+	}
+
+	ids, err := deeplink.Parse(_i.Config.Middleware.Jwt.Secret, linkPurpose, token, time.Now())
+	if err != nil || len(ids) != 2 {
+		return "", &fiber.Error{Code: fiber.StatusBadRequest, Message: deeplink.ErrInvalidToken.Error()}
+	}
+	userID, businessID := ids[0], ids[1]
+
+	user, err := _i.Repo.GetUser(userID)
+	if err != nil {
+		return "", err
+	}
+	// the same permission as getting the link
+	if !mdl.HasBusinessPermission(user, businessID, mdl.DBusiness, mdl.PUpdate) {
+		return "", errForbidden
+	}
+
+	if err = _i.Repo.Link(&schema.OperatorChat{ChatID: chatID, UserID: userID, BusinessID: businessID}); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("این گفتگو به حساب %s متصل شد.\n\n%s", user.FullName(), help), nil
+}
+
+func (_i *service) reservations(chat *schema.OperatorChat, args string) (reply string, err error) {
+	if !mdl.HasBusinessPermission(chat.User, chat.BusinessID, mdl.DReservation, mdl.PReadAll) {
+		return "", errForbidden
+	}
+	productID, err := parseID(args, "شناسه دستگاه")
+	if err != nil {
+		return "", err
+	}
+
+	settings := timezone.ForBusiness(chat.BusinessID)
+	startTime := settings.Today()
+	endTime := startTime.AddDate(0, 0, 1)
+	reservations, _, err := _i.ReservationService.Index(rrequest.Reservations{
+		BusinessID: chat.BusinessID,
+		ProductID:  productID,
+		StartTime:  &startTime,
+		EndTime:    &endTime,
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(reservations) == 0 {
+		return "دستگاه امروز رزروی ندارد.", nil
+	}
+
+	lines := []string{fmt.Sprintf("رزروهای امروز دستگاه %d:", productID)}
+	// the newest are first
+	for i := len(reservations) - 1; i >= 0; i-- {
+		reservation := reservations[i]
+		lines = append(lines, fmt.Sprintf("#%d  %s تا %s  %s  %s",
+			reservation.ID,
+			reservation.StartTime.In(settings.Location).Format("15:04"),
+			reservation.EndTime.In(settings.Location).Format("15:04"),
+			reservation.User.FullName,
+			schema.ReservationStatusProxy[reservation.Status],
+		))
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+func (_i *service) sendCommand(chat *schema.OperatorChat, args string, command schema.UniWashCommand) (reply string, err error) {
+	if !mdl.HasBusinessPermission(chat.User, chat.BusinessID, mdl.DProduct, mdl.PReadAll) {
+		return "", errForbidden
+	}
+	reservation, err := _i.reservation(chat, args)
+	if err != nil {
+		return "", err
+	}
+
+	if err = _i.UniwashService.SendCommand(urequest.SendCommand{
+		BusinessID:    chat.BusinessID,
+		ReservationID: reservation.ID,
+		ProductID:     reservation.ProductID,
+		Command:       command,
+		IssuerID:      chat.UserID,
+	}, false); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("دستور %s برای رزرو %d ارسال شد.", command, reservation.ID), nil
+}
+
+func (_i *service) lastCommandStatus(chat *schema.OperatorChat, args string) (reply string, err error) {
+	if !mdl.HasBusinessPermission(chat.User, chat.BusinessID, mdl.DProduct, mdl.PReadAll) {
+		return "", errForbidden
+	}
+	reservationID, err := parseID(args, "شناسه رزرو")
+	if err != nil {
+		return "", err
+	}
+
+	status, err := _i.UniwashService.CheckLastCommandStatus(chat.BusinessID, reservationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errReservationNotFound
+		}
+		return "", err
+	}
+
+	return fmt.Sprintf("وضعیت آخرین دستور رزرو %d: %s", reservationID, status.Status), nil
+}
+
+func (_i *service) revenue(chat *schema.OperatorChat) (reply string, err error) {
+	if !mdl.HasBusinessPermission(chat.User, chat.BusinessID, mdl.DOrder, mdl.PReadAll) {
+		return "", errForbidden
+	}
+
+	today := timezone.ForBusiness(chat.BusinessID).Today()
+	_, totalAmount, paging, err := _i.OrderService.Index(orequest.Orders{
+		BusinessID:     chat.BusinessID,
+		Status:         string(schema.OrderStatusCompleted),
+		OrderStartTime: &today,
+		OrderEndTime:   &today,
+		// only the count and the sum are needed
+		Pagination: &paginator.Pagination{Page: 1, Limit: 1},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("درآمد امروز: %d تومان از %d سفارش", totalAmount, paging.Total), nil
+}
+
+func (_i *service) coupon(chat *schema.OperatorChat, args string) (reply string, err error) {
+	if !mdl.HasBusinessPermission(chat.User, chat.BusinessID, mdl.DProduct, mdl.PReadAll) {
+		return "", errForbidden
+	}
+	reservation, err := _i.reservation(chat, args)
+	if err != nil {
+		return "", err
+	}
+
+	if err = _i.UniwashService.SendFullCouponToUser(chat.BusinessID, reservation.ID); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("کد جبرانی برای %s ارسال شد.", reservation.User.FullName), nil
+}
+
+func (_i *service) reservation(chat *schema.OperatorChat, args string) (reservation *rresponse.Reservation, err error) {
+	reservationID, err := parseID(args, "شناسه رزرو")
+	if err != nil {
+		return nil, err
+	}
+
+	reservation, err = _i.ReservationService.Show(chat.BusinessID, reservationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errReservationNotFound
+		}
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// parseCommand splits the command from its arguments, the command may have the name of the bot in the groups
+func parseCommand(text string) (command string, args string) {
+	command, args, _ = strings.Cut(strings.TrimSpace(text), " ")
+	command, _, _ = strings.Cut(command, "@")
+
+	return strings.ToLower(command), strings.TrimSpace(args)
+}
+
+func parseID(args string, name string) (id uint64, err error) {
+	id, err = strconv.ParseUint(englishDigits(strings.TrimPrefix(args, "#")), 10, 64)
+	if err != nil || id == 0 {
+		return 0, &fiber.Error{Code: fiber.StatusBadRequest, Message: fmt.Sprintf("%s را بعد از دستور بنویسید", name)}
+	}
+
+	return id, nil
+}
+
+// englishDigits reads the ids typed with the persian keyboard
+func englishDigits(text string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '۰' && r <= '۹':
+			return '0' + r - '۰'
+		case r >= '٠' && r <= '٩':
+			return '0' + r - '٠'
+		}
+		return r
+	}, text)
+}
@@ -0,0 +1,168 @@
+package test
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/operatorBot/request"
+	orequest "go-fiber-starter/app/module/order/request"
+	oresponse "go-fiber-starter/app/module/order/response"
+	oservice "go-fiber-starter/app/module/order/service"
+	rrequest "go-fiber-starter/app/module/reservation/request"
+	rresponse "go-fiber-starter/app/module/reservation/response"
+	rservice "go-fiber-starter/app/module/reservation/service"
+	urequest "go-fiber-starter/app/module/uniwash/request"
+	uservice "go-fiber-starter/app/module/uniwash/service"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/utils/paginator"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// MockOperatorBotRepository keeps the users and the chats in memory
+type MockOperatorBotRepository struct {
+	mu    sync.Mutex
+	users map[uint64]*schema.User
+	chats map[int64]*schema.OperatorChat
+}
+
+func NewMockOperatorBotRepository(users ...*schema.User) *MockOperatorBotRepository {
+	repo := &MockOperatorBotRepository{
+		users: map[uint64]*schema.User{},
+		chats: map[int64]*schema.OperatorChat{},
+	}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+
+	return repo
+}
+
+// GetAll implements repository.IRepository
+func (_m *MockOperatorBotRepository) GetAll(req request.Chats) ([]*schema.OperatorChat, paginator.Pagination, error) {
+	return nil, paginator.Pagination{}, nil
+}
+
+// GetChat implements repository.IRepository
+func (_m *MockOperatorBotRepository) GetChat(chatID int64) (*schema.OperatorChat, error) {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	chat, ok := _m.chats[chatID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	linked := *chat
+	linked.User = _m.users[chat.UserID]
+
+	return &linked, nil
+}
+
+// GetUser implements repository.IRepository
+func (_m *MockOperatorBotRepository) GetUser(id uint64) (*schema.User, error) {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	user, ok := _m.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return user, nil
+}
+
+// Link implements repository.IRepository
+func (_m *MockOperatorBotRepository) Link(chat *schema.OperatorChat) error {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	_m.chats[chat.ChatID] = chat
+	return nil
+}
+
+// Unlink implements repository.IRepository
+func (_m *MockOperatorBotRepository) Unlink(chatID int64) error {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	delete(_m.chats, chatID)
+	return nil
+}
+
+// Delete implements repository.IRepository
+func (_m *MockOperatorBotRepository) Delete(businessID uint64, id uint64) (bool, error) {
+	return false, nil
+}
+
+// Linked tells the user the chat is linked to, 0 when it is not
+func (_m *MockOperatorBotRepository) Linked(chatID int64) uint64 {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	if chat, ok := _m.chats[chatID]; ok {
+		return chat.UserID
+	}
+	return 0
+}
+
+// MockReservationService gives the reservations it is built with, the other methods are not used by the bot
+type MockReservationService struct {
+	rservice.IService
+
+	Reservations []*rresponse.Reservation
+	indexed      []rrequest.Reservations
+}
+
+func (m *MockReservationService) Index(req rrequest.Reservations) ([]*rresponse.Reservation, paginator.Pagination, error) {
+	m.indexed = append(m.indexed, req)
+	return m.Reservations, paginator.Pagination{}, nil
+}
+
+func (m *MockReservationService) Show(businessID uint64, id uint64) (*rresponse.Reservation, error) {
+	for _, reservation := range m.Reservations {
+		if reservation.ID == id {
+			return reservation, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// MockUniwashService records the commands and the coupons, the other methods are not used by the bot
+type MockUniwashService struct {
+	uservice.IService
+
+	Commands []urequest.SendCommand
+	Coupons  []uint64
+}
+
+func (m *MockUniwashService) SendCommand(req urequest.SendCommand, isForUser bool) error {
+	if isForUser {
+		panic("the bot sends the commands as an operator")
+	}
+	m.Commands = append(m.Commands, req)
+	return nil
+}
+
+func (m *MockUniwashService) CheckLastCommandStatus(businessID uint64, reservationID uint64) (*sms.Status, error) {
+	return &sms.Status{Status: "تحویل شده"}, nil
+}
+
+func (m *MockUniwashService) SendFullCouponToUser(businessID uint64, reservationID uint64) error {
+	m.Coupons = append(m.Coupons, reservationID)
+	return nil
+}
+
+// MockOrderService gives the total it is built with, the other methods are not used by the bot
+type MockOrderService struct {
+	oservice.IService
+
+	TotalAmount uint64
+	Count       int64
+	indexed     []orequest.Orders
+}
+
+func (m *MockOrderService) Index(req orequest.Orders) ([]*oresponse.Order, uint64, paginator.Pagination, error) {
+	m.indexed = append(m.indexed, req)
+	paging := *req.Pagination
+	paging.Total = m.Count
+	return nil, m.TotalAmount, paging, nil
+}
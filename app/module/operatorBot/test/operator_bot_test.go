@@ -0,0 +1,168 @@
+package test
+
+import (
+	"go-fiber-starter/app/database/schema"
+	rresponse "go-fiber-starter/app/module/reservation/response"
+	uresponse "go-fiber-starter/app/module/user/response"
+	"go-fiber-starter/utils/deeplink"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// Linking Tests
+// =============================================================================
+
+func TestHandle_StartLinksTheChat(t *testing.T) {
+	owner := newUser(10, schema.URBusinessOwner)
+	tb := newTestBot(owner)
+
+	link, err := tb.Service.Link(testBusinessID, owner.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(link.Link, "https://ble.ir/zciti_operator_bot?start=") {
+		t.Errorf("expected a link to the bot, got %s", link.Link)
+	}
+
+	reply, err := tb.Service.Handle(100, "/start "+startParam(t, link.Link))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(reply, owner.FullName()) {
+		t.Errorf("expected the reply to name the linked account, got %q", reply)
+	}
+	if tb.Repo.Linked(100) != owner.ID {
+		t.Errorf("expected the chat to be linked to the owner")
+	}
+
+	if _, err = tb.Service.Handle(100, "/stop"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tb.Repo.Linked(100) != 0 {
+		t.Errorf("expected the chat to be unlinked")
+	}
+}
+
+func TestHandle_StartRejectsBadTokens(t *testing.T) {
+	owner := newUser(10, schema.URBusinessOwner)
+	observer := newUser(11, schema.URBusinessObserver)
+	tb := newTestBot(owner, observer)
+
+	expired := deeplink.Token("test-secret", "operatorBot", time.Now().Add(-time.Minute), owner.ID, testBusinessID)
+	forged := deeplink.Token("another-secret", "operatorBot", time.Now().Add(time.Minute), owner.ID, testBusinessID)
+	for _, token := range []string{expired, forged, "abc"} {
+		_, err := tb.Service.Handle(100, "/start "+token)
+		assertFiberError(t, err, http.StatusBadRequest)
+	}
+
+	// only the ones who can get the link from the panel
+	observerToken := deeplink.Token("test-secret", "operatorBot", time.Now().Add(time.Minute), observer.ID, testBusinessID)
+	_, err := tb.Service.Handle(100, "/start "+observerToken)
+	assertFiberError(t, err, http.StatusForbidden)
+
+	if tb.Repo.Linked(100) != 0 {
+		t.Errorf("expected the chat to stay unlinked")
+	}
+}
+
+func TestHandle_UnlinkedChatIsRefused(t *testing.T) {
+	tb := newTestBot()
+
+	_, err := tb.Service.Handle(100, "/revenue")
+	assertFiberError(t, err, http.StatusUnauthorized)
+}
+
+// =============================================================================
+// Command Tests
+// =============================================================================
+
+func TestHandle_CommandsRunAsTheLinkedUser(t *testing.T) {
+	owner := newUser(10, schema.URBusinessOwner)
+	tb := newTestBot(owner)
+	tb.linkChat(t, 100, owner)
+	tb.Reservations.Reservations = []*rresponse.Reservation{{
+		ID:        5,
+		ProductID: 42,
+		Status:    schema.ReservationStatusReserved,
+		StartTime: time.Now(),
+		EndTime:   time.Now().Add(time.Hour),
+		User:      uresponse.User{FullName: "Ali Rezaei"},
+	}}
+
+	// with the persian digits of the keyboard
+	if _, err := tb.Service.Handle(100, "/on ۵"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tb.Uniwash.Commands) != 1 {
+		t.Fatalf("expected one command, got %d", len(tb.Uniwash.Commands))
+	}
+	command := tb.Uniwash.Commands[0]
+	if command.ReservationID != 5 || command.ProductID != 42 || command.Command != schema.UniWashCommandON || command.IssuerID != owner.ID {
+		t.Errorf("expected the ON command of the machine of the reservation by the owner, got %+v", command)
+	}
+
+	reply, err := tb.Service.Handle(100, "/reservations 42")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(reply, "#5") || !strings.Contains(reply, "Ali Rezaei") {
+		t.Errorf("expected the reservation in the reply, got %q", reply)
+	}
+	if req := tb.Reservations.indexed[0]; req.ProductID != 42 || req.BusinessID != testBusinessID || req.StartTime == nil || req.EndTime == nil {
+		t.Errorf("expected today's reservations of the machine, got %+v", req)
+	}
+
+	if _, err = tb.Service.Handle(100, "/coupon 5"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tb.Uniwash.Coupons) != 1 || tb.Uniwash.Coupons[0] != 5 {
+		t.Errorf("expected a coupon for the reservation, got %v", tb.Uniwash.Coupons)
+	}
+
+	_, err = tb.Service.Handle(100, "/off 6")
+	assertFiberError(t, err, http.StatusNotFound)
+	_, err = tb.Service.Handle(100, "/off")
+	assertFiberError(t, err, http.StatusBadRequest)
+}
+
+func TestHandle_RevenueOfToday(t *testing.T) {
+	owner := newUser(10, schema.URBusinessOwner)
+	tb := newTestBot(owner)
+	tb.linkChat(t, 100, owner)
+	tb.Orders.TotalAmount = 150000
+	tb.Orders.Count = 3
+
+	reply, err := tb.Service.Handle(100, "/revenue@zciti_operator_bot")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(reply, "150000") || !strings.Contains(reply, "3") {
+		t.Errorf("expected the total and the count of the orders, got %q", reply)
+	}
+
+	req := tb.Orders.indexed[0]
+	if req.Status != string(schema.OrderStatusCompleted) || req.OrderStartTime == nil || req.OrderEndTime == nil {
+		t.Errorf("expected the completed orders of today, got %+v", req)
+	}
+}
+
+func TestHandle_ChecksThePermissionsOfTheUser(t *testing.T) {
+	owner := newUser(10, schema.URBusinessOwner)
+	tb := newTestBot(owner)
+	tb.linkChat(t, 100, owner)
+	tb.Reservations.Reservations = []*rresponse.Reservation{{ID: 5, ProductID: 42}}
+
+	// the owner was removed from the business after linking the chat
+	owner.Permissions = schema.UserPermissions{}
+
+	for _, command := range []string{"/on 5", "/coupon 5", "/status 5", "/revenue", "/reservations 42"} {
+		_, err := tb.Service.Handle(100, command)
+		assertFiberError(t, err, http.StatusForbidden)
+	}
+	if len(tb.Uniwash.Commands) != 0 || len(tb.Uniwash.Coupons) != 0 {
+		t.Errorf("expected nothing to be sent without the permission")
+	}
+}
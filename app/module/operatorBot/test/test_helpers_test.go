@@ -0,0 +1,90 @@
+package test
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/operatorBot/service"
+	"go-fiber-starter/internal"
+	"go-fiber-starter/utils/config"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const testBusinessID = 1
+
+// TestBot is the service of the bot on the mocks
+type TestBot struct {
+	Service      service.IService
+	Repo         *MockOperatorBotRepository
+	Reservations *MockReservationService
+	Uniwash      *MockUniwashService
+	Orders       *MockOrderService
+}
+
+func newTestBot(users ...*schema.User) *TestBot {
+	cfg := &config.Config{}
+	cfg.Middleware.Jwt.Secret = "test-secret"
+
+	tb := &TestBot{
+		Repo:         NewMockOperatorBotRepository(users...),
+		Reservations: &MockReservationService{},
+		Uniwash:      &MockUniwashService{},
+		Orders:       &MockOrderService{},
+	}
+	tb.Service = service.Service(tb.Repo, tb.Reservations, tb.Uniwash, tb.Orders, &internal.OperatorBot{ChatBot: internal.ChatBot{Name: "zciti_operator_bot"}}, cfg)
+
+	return tb
+}
+
+// ===== User Factory =====
+
+func newUser(id uint64, role schema.UserRole) *schema.User {
+	return &schema.User{
+		ID:          id,
+		FirstName:   "Operator",
+		LastName:    "User",
+		Permissions: schema.UserPermissions{testBusinessID: {role}},
+	}
+}
+
+// ===== Helpers =====
+
+// linkChat links the chat the way an operator does, with the link of the panel
+func (tb *TestBot) linkChat(t *testing.T, chatID int64, user *schema.User) {
+	t.Helper()
+
+	link, err := tb.Service.Link(testBusinessID, user.ID)
+	if err != nil {
+		t.Fatalf("failed to get the link: %v", err)
+	}
+	if _, err = tb.Service.Handle(chatID, "/start "+startParam(t, link.Link)); err != nil {
+		t.Fatalf("failed to link the chat: %v", err)
+	}
+}
+
+func startParam(t *testing.T, link string) string {
+	t.Helper()
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid link %q: %v", link, err)
+	}
+	return parsed.Query().Get("start")
+}
+
+// ===== Test Assertions =====
+
+// assertFiberError asserts that the bot refused the command with the status
+func assertFiberError(t *testing.T, err error, code int) {
+	t.Helper()
+
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		t.Fatalf("expected an error with status %d, got %v", code, err)
+	}
+	if fiberErr.Code != code {
+		t.Errorf("expected status %d, got %d (%s)", code, fiberErr.Code, fiberErr.Message)
+	}
+}
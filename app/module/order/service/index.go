@@ -12,10 +12,12 @@ import (
 	oirepository "go-fiber-starter/app/module/orderItem/repository"
 	oirequest "go-fiber-starter/app/module/orderItem/request"
 	prepository "go-fiber-starter/app/module/product/repository"
+	referralService "go-fiber-starter/app/module/referral/service"
 	reserveService "go-fiber-starter/app/module/reservation/service"
 	transactionRepo "go-fiber-starter/app/module/transaction/repository"
 	uniService "go-fiber-starter/app/module/uniwash/service"
 	userService "go-fiber-starter/app/module/user/service"
+	waitlistService "go-fiber-starter/app/module/waitlist/service"
 	walletService "go-fiber-starter/app/module/wallet/service"
 	"go-fiber-starter/internal"
 	"go-fiber-starter/utils/config"
@@ -23,6 +25,7 @@ import (
 	"strconv"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 )
 
 type IService interface {
@@ -47,6 +50,8 @@ func Service(
 	orderItemRepo oirepository.IRepository,
 	reserveService reserveService.IService,
 	transactionRepo transactionRepo.IRepository,
+	referralService referralService.IService,
+	waitlistService waitlistService.IService,
 ) IService {
 	return &service{
 		repo,
@@ -61,6 +66,8 @@ func Service(
 		reserveService,
 		orderItemRepo,
 		transactionRepo,
+		referralService,
+		waitlistService,
 	}
 }
 
@@ -77,6 +84,8 @@ type service struct {
 	ReserveService  reserveService.IService
 	OrderItemRepo   oirepository.IRepository
 	TransactionRepo transactionRepo.IRepository
+	ReferralService referralService.IService
+	WaitlistService waitlistService.IService
 }
 
 func (_i *service) Index(req request.Orders) (orders []*response.Order, totalAmount uint64, paging paginator.Pagination, err error) {
@@ -136,14 +145,41 @@ func (_i *service) Store(req request.Order) (orderID uint64, paymentURL string,
 
 		var reservationID *uint64
 		if product.VariantType != nil && *product.VariantType == schema.ProductVariantTypeWashingMachine {
-			if err := _i.UniService.ValidateReservation(item); err != nil {
+			if err := _i.ReserveService.CheckBookingRights(req.User.ID, req.BusinessID); err != nil {
+				return 0, "", err
+			}
+			if err := _i.UniService.ValidateReservation(item, product); err != nil {
 				return 0, "", err
 			}
 			if err := _i.ReserveService.IsReservable(item, req.BusinessID); err != nil {
 				return 0, "", err
 			}
+			if err := _i.ReserveService.CheckQuota(item, product, req.User.ID, req.BusinessID, 0); err != nil {
+				return 0, "", err
+			}
+			if err := _i.WaitlistService.CheckHold(item, req.User.ID, req.BusinessID); err != nil {
+				return 0, "", err
+			}
+
+			reservationID, err = _i.UniService.ReserveReservation(item, req.User.ID, req.BusinessID, tx)
+			if err != nil {
+				return 0, "", err
+			}
 
-			reservationID, err = _i.UniService.ReserveReservation(item, req.User.ID, req.BusinessID)
+			OrderReservationRanges = append(OrderReservationRanges, []string{item.Date + " " + item.StartTime, item.Date + " " + item.EndTime})
+		} else if product.IsReservable() {
+			// courts and classes, the seats of the slot are counted instead of looking for any booking
+			if err := _i.ReserveService.CheckBookingRights(req.User.ID, req.BusinessID); err != nil {
+				return 0, "", err
+			}
+			if err := _i.UniService.ValidateReservation(item, product); err != nil {
+				return 0, "", err
+			}
+			if err := _i.ReserveService.CheckQuota(item, product, req.User.ID, req.BusinessID, 0); err != nil {
+				return 0, "", err
+			}
+
+			reservationID, err = _i.ReserveService.ReserveSeats(item, product, req.User.ID, req.BusinessID, tx)
 			if err != nil {
 				return 0, "", err
 			}
@@ -209,12 +245,7 @@ func (_i *service) Store(req request.Order) (orderID uint64, paymentURL string,
 		}
 	}
 
-	// در صورت تکمیل شدن سفارش، عملیات پس از ثبت انجام شود
-	if req.Status == schema.OrderStatusCompleted {
-		if err = _i.UpdateOrderItemsAfterOrderComplete(orderItems); err != nil {
-			return orderID, "", nil
-		}
-	} else {
+	if req.Status != schema.OrderStatusCompleted {
 		redirectURL := fmt.Sprintf(
 			"%s/v1/user/orders/status?OrderID=%d&UserID=%d",
 			_i.Config.App.BackendDomain,
@@ -275,6 +306,19 @@ func (_i *service) Store(req request.Order) (orderID uint64, paymentURL string,
 		return 0, "", err
 	}
 
+	// در صورت تکمیل شدن سفارش، عملیات پس از ثبت انجام شود
+	// رزروها داخل تراکنش ساخته شده‌اند و تنها پس از تأیید آن قابل تغییرند
+	if req.Status == schema.OrderStatusCompleted {
+		if err = _i.UpdateOrderItemsAfterOrderComplete(orderItems); err != nil {
+			return orderID, "", nil
+		}
+
+		// پاداش معرفی در اولین سفارش تکمیل شده، خطای آن سفارش ثبت شده را ناموفق نمی‌کند
+		if err = _i.ReferralService.RewardFirstOrder(req.User.ID, req.BusinessID, orderID, totalAmtWithTax); err != nil {
+			log.Error().Err(err).Uint64("orderID", orderID).Msg("failed to reward the referral of the order")
+		}
+	}
+
 	return orderID, paymentURL, nil
 }
 
@@ -297,6 +341,7 @@ func (_i *service) Status(userID uint64, orderID uint64, refNum string) (state s
 			transaction.Status = schema.TransactionStatusFailed
 			transaction.GatewayTransactionID = &refNum
 			_ = _i.TransactionRepo.Update(transaction.ID, transaction)
+			_i.ReleaseOrderItemsAfterPaymentFailure(order.OrderItems)
 
 			return "FAILED", err
 		}
@@ -304,6 +349,7 @@ func (_i *service) Status(userID uint64, orderID uint64, refNum string) (state s
 		if !verified.Success {
 			transaction.Status = schema.TransactionStatusFailed
 			_ = _i.TransactionRepo.Update(transaction.ID, transaction)
+			_i.ReleaseOrderItemsAfterPaymentFailure(order.OrderItems)
 
 			return "FAILED", &fiber.Error{Code: fiber.StatusBadRequest, Message: "پرداخت ناموفق بوده است"}
 		}
@@ -334,21 +380,38 @@ func (_i *service) Status(userID uint64, orderID uint64, refNum string) (state s
 		return "OK", err
 	}
 
+	if err = _i.ReferralService.RewardFirstOrder(order.UserID, order.BusinessID, order.ID, order.TotalAmt); err != nil {
+		log.Error().Err(err).Uint64("orderID", order.ID).Msg("failed to reward the referral of the order")
+	}
+
 	return "OK", nil
 }
 
 func (_i *service) UpdateOrderItemsAfterOrderComplete(orderItems []schema.OrderItem) error {
 	for _, item := range orderItems {
-		if item.Meta.ProductVariantType == schema.ProductVariantTypeWashingMachine {
+		if item.ReservationID != nil {
 			if err := _i.UniService.Reserve(*item.ReservationID); err != nil {
 				return err
 			}
+			// the offer of the waitlist is kept until the reservation is paid, the reservation stands without it
+			if err := _i.WaitlistService.Fulfill(*item.ReservationID); err != nil {
+				log.Error().Err(err).Uint64("reservationID", *item.ReservationID).Msg("failed to fulfill the waitlist of the reservation")
+			}
 		}
 	}
 
 	return nil
 }
 
+// ReleaseOrderItemsAfterPaymentFailure gives the held slots back right away instead of waiting for the hold to expire.
+func (_i *service) ReleaseOrderItemsAfterPaymentFailure(orderItems []schema.OrderItem) {
+	for _, item := range orderItems {
+		if item.ReservationID != nil {
+			_ = _i.UniService.Release(*item.ReservationID)
+		}
+	}
+}
+
 func (_i *service) Update(id uint64, req request.Order) (err error) {
 	return _i.Repo.Update(id, req.ToDomain(nil, nil))
 }
@@ -95,6 +95,7 @@ func migrateTestModels(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -21,14 +21,13 @@ type OrderItem struct {
 	StartTime string // for reservable
 }
 
-func (req *OrderItem) GetStartDateTime() time.Time {
-	loc, _ := time.LoadLocation("Asia/Tehran")
+// GetStartDateTime reads the slot in the timezone of the business, see timezone.In.
+func (req *OrderItem) GetStartDateTime(loc *time.Location) time.Time {
 	startTime, _ := time.ParseInLocation(time.DateTime, req.Date+" "+req.StartTime, loc)
 	return startTime
 }
 
-func (req *OrderItem) GetEndDateTime() time.Time {
-	loc, _ := time.LoadLocation("Asia/Tehran")
+func (req *OrderItem) GetEndDateTime(loc *time.Location) time.Time {
 	endTime, _ := time.ParseInLocation(time.DateTime, req.Date+" "+req.EndTime, loc)
 
 	// if hour is 00 should store in next day
@@ -61,10 +60,12 @@ func ToDomain(p ToDomainParams) *schema.OrderItem {
 			ProductType:        p.Product.Type,
 			ProductSKU:         p.Product.Meta.SKU,
 			ProductDetail:      p.Product.Meta.Detail,
-			ProductVariantType: *p.Product.VariantType,
 			//ProductImage:  post.Image,
 		},
 	}
+	if p.Product.VariantType != nil {
+		orderItem.Meta.ProductVariantType = *p.Product.VariantType
+	}
 
 	return &orderItem
 }
@@ -1,6 +1,7 @@
 package response
 
 import (
+	"fmt"
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/module/uniwash/response"
 )
@@ -13,8 +14,10 @@ type OrderItem struct {
 	Subtotal    float64               `json:",omitempty"`
 	TaxAmt      float64               `json:",omitempty"`
 	Reservation *response.Reservation `json:",omitempty"`
-	Type        schema.OrderItemType  `json:",omitempty"`
-	Meta        schema.OrderItemMeta  `json:",omitempty"`
+	// CalendarPath downloads the reservation as an .ics event, to add it to the calendar after the payment
+	CalendarPath string               `json:",omitempty"`
+	Type         schema.OrderItemType `json:",omitempty"`
+	Meta         schema.OrderItemMeta `json:",omitempty"`
 }
 
 func FromDomain(item *schema.OrderItem) (res *OrderItem) {
@@ -44,6 +47,7 @@ func FromDomain(item *schema.OrderItem) (res *OrderItem) {
 			//ProductTitle:  item.Reservation.ProductTitle,
 			//ProductDetail: item.Reservation.ProductDetail,
 		}
+		oi.CalendarPath = fmt.Sprintf("/v1/user/calendar/reservations/%d", item.Reservation.ID)
 	}
 	return oi
 }
@@ -93,6 +93,7 @@ func migrateTestModels(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -209,6 +210,8 @@ func migrateTestModels(db *gorm.DB) error {
 			business_id BIGINT NOT NULL,
 			meta JSONB,
 			user_usage_count BIGINT DEFAULT 0,
+			seats INT NOT NULL DEFAULT 1,
+			shared BOOLEAN NOT NULL DEFAULT FALSE,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
 			deleted_at TIMESTAMPTZ
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/outbox/request"
+	"go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Index(c *fiber.Ctx) error
+	Show(c *fiber.Ctx) error
+	Retry(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// Index all OutboxMessages
+// @Summary      Get the sms of the business with their delivery state
+// @Tags         OutboxMessages
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        Status query string false "queued, sending, sent or dead"
+// @Param        Purpose query string false "e.g. reminder"
+// @Param        Mobile query string false "e.g. 09123456789"
+// @Router       /business/:businessID/sms-outbox [get]
+func (_i *controller) Index(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.OutboxMessages
+	req.BusinessID = businessID
+	req.Status = schema.OutboxStatus(c.Query("Status"))
+	req.Purpose = schema.OutboxPurpose(c.Query("Purpose"))
+	req.Mobile = c.Query("Mobile")
+	req.Pagination = paginate
+
+	messages, paging, err := _i.service.Index(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: messages,
+		Meta: paging,
+	})
+}
+
+// Show one OutboxMessage
+// @Summary      Get one sms of the business
+// @Tags         OutboxMessages
+// @Security     Bearer
+// @Param        id path int true "OutboxMessage ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/sms-outbox/:id [get]
+func (_i *controller) Show(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	message, err := _i.service.Show(businessID, id)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: message,
+	})
+}
+
+// Retry a dead OutboxMessage
+// @Summary      Send a failed sms again
+// @Tags         OutboxMessages
+// @Security     Bearer
+// @Param        id path int true "OutboxMessage ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/sms-outbox/:id/retry [post]
+func (_i *controller) Retry(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.Retry(businessID, id); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
@@ -0,0 +1,49 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/internal"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type OutboxWorker struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+
+	running sync.Mutex
+}
+
+func RunOutboxWorker(
+	logger zerolog.Logger,
+	outboxService service.IService,
+	cronService *internal.CronService,
+) *OutboxWorker {
+	worker := &OutboxWorker{
+		Logger:   logger,
+		Service:  outboxService,
+		CronSpec: "@every 1s",
+	}
+
+	err := cronService.AddJob(worker.CronSpec, worker.SendQueued)
+	if err != nil {
+		worker.Logger.Fatal().Err(err).Msg("failed to add RunOutboxWorker job")
+	}
+
+	return worker
+}
+
+// SendQueued sends the queued sms that are due, a run is skipped while the last one is still sending.
+func (_w *OutboxWorker) SendQueued() {
+	if !_w.running.TryLock() {
+		return
+	}
+	defer _w.running.Unlock()
+
+	if err := _w.Service.Process(time.Now()); err != nil {
+		_w.Logger.Err(err).Msg("Failed to send the queued sms")
+	}
+}
@@ -0,0 +1,51 @@
+package outbox
+
+import (
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/outbox/controller"
+	"go-fiber-starter/app/module/outbox/cron"
+	"go-fiber-starter/app/module/outbox/repository"
+	"go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/utils/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes
+	_i.App.Route("/v1/business/:businessID/sms-outbox", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PReadAll), c.Index)
+		router.Get("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PReadSingle), c.Show)
+		router.Post("/:id/retry", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DNotification, mdl.PCreate), c.Retry)
+	})
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.SmsSender),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+
+	fx.Invoke(cron.RunOutboxWorker),
+)
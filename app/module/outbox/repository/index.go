@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/outbox/request"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/paginator"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IRepository interface {
+	GetAll(req request.OutboxMessages) (messages []*schema.OutboxMessage, paging paginator.Pagination, err error)
+	GetOne(businessID uint64, id uint64) (message *schema.OutboxMessage, err error)
+	Create(messages []*schema.OutboxMessage, tx *gorm.DB) (err error)
+	Claim(now time.Time, lockUntil time.Time, limit int) (messages []*schema.OutboxMessage, err error)
+	Update(message *schema.OutboxMessage) (err error)
+	GetLastSent(purpose schema.OutboxPurpose, mobile string) (message *schema.OutboxMessage, err error)
+	GetReceivers(tx *gorm.DB, mobiles []uint64) (users []*schema.User, err error)
+}
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+func (_i *repo) GetAll(req request.OutboxMessages) (messages []*schema.OutboxMessage, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.OutboxMessage{}).
+		Where("business_id = ?", req.BusinessID)
+
+	if req.Status != "" {
+		query.Where("status = ?", req.Status)
+	}
+	if req.Purpose != "" {
+		query.Where("purpose = ?", req.Purpose)
+	}
+	if req.Mobile != "" {
+		query.Where("mobile = ?", req.Mobile)
+	}
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.Order("id desc").Find(&messages).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+func (_i *repo) GetOne(businessID uint64, id uint64) (message *schema.OutboxMessage, err error) {
+	if err = _i.DB.Main.Where("business_id = ?", businessID).First(&message, id).Error; err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// Create queues the messages in the transaction, if any, so they are only sent when it is committed.
+func (_i *repo) Create(messages []*schema.OutboxMessage, tx *gorm.DB) (err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+
+	return tx.Create(messages).Error
+}
+
+// Claim takes the messages that are due to be sent, the most urgent first. They stay locked for the worker
+// until lockUntil, so a worker that dies while sending them only holds them until then.
+// SKIP LOCKED keeps the workers of the other processes from taking the same ones.
+func (_i *repo) Claim(now time.Time, lockUntil time.Time, limit int) (messages []*schema.OutboxMessage, err error) {
+	err = _i.DB.Main.Raw(`
+		UPDATE outbox_messages SET status = ?, attempts = attempts + 1, locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE deleted_at IS NULL
+				AND ((status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?))
+			ORDER BY priority, next_attempt_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		schema.OutboxStatusSending, lockUntil, now,
+		schema.OutboxStatusQueued, now, schema.OutboxStatusSending, now,
+		limit,
+	).Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the sub query
+	sort.SliceStable(messages, func(i, j int) bool {
+		if messages[i].Priority != messages[j].Priority {
+			return messages[i].Priority < messages[j].Priority
+		}
+		if !messages[i].NextAttemptAt.Equal(messages[j].NextAttemptAt) {
+			return messages[i].NextAttemptAt.Before(messages[j].NextAttemptAt)
+		}
+		return messages[i].ID < messages[j].ID
+	})
+
+	return messages, nil
+}
+
+// GetLastSent is the latest message of the purpose the providers accepted for the mobile.
+func (_i *repo) GetLastSent(purpose schema.OutboxPurpose, mobile string) (message *schema.OutboxMessage, err error) {
+	err = _i.DB.Main.
+		Where("purpose = ? AND mobile = ? AND status = ?", purpose, mobile, schema.OutboxStatusSent).
+		Order("sent_at desc").
+		First(&message).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// Update saves the state of the message after an attempt to send it or a retry.
+func (_i *repo) Update(message *schema.OutboxMessage) (err error) {
+	return _i.DB.Main.Model(&schema.OutboxMessage{}).
+		Where("id = ?", message.ID).
+		Select("status", "attempts", "next_attempt_at", "locked_until", "reference_id", "provider", "last_error", "sent_at").
+		Updates(message).Error
+}
+
+// GetReceivers finds the users of the mobiles with their notification preferences, in the transaction if any.
+func (_i *repo) GetReceivers(tx *gorm.DB, mobiles []uint64) (users []*schema.User, err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+
+	err = tx.Model(&schema.User{}).
+		Select("id", "mobile", "meta").
+		Where("mobile IN ?", mobiles).
+		Find(&users).Error
+
+	return
+}
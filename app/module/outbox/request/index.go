@@ -0,0 +1,14 @@
+package request
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/paginator"
+)
+
+type OutboxMessages struct {
+	BusinessID uint64
+	Status     schema.OutboxStatus
+	Purpose    schema.OutboxPurpose
+	Mobile     string
+	Pagination *paginator.Pagination
+}
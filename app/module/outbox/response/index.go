@@ -0,0 +1,54 @@
+package response
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"time"
+)
+
+type OutboxMessage struct {
+	ID            uint64               `json:",omitempty"`
+	Purpose       schema.OutboxPurpose `json:",omitempty"`
+	Priority      schema.OutboxPriority
+	Template      string              `json:",omitempty"`
+	Provider      string              `json:",omitempty"`
+	Mobile        string              `json:",omitempty"`
+	Params        []string            `json:",omitempty"`
+	Channels      []string            `json:",omitempty"` // in the order they are tried, only the sms when empty
+	Status        schema.OutboxStatus `json:",omitempty"`
+	StatusDisplay string              `json:",omitempty"`
+	Attempts      int
+	NextAttemptAt *time.Time `json:",omitempty"` // only while it is queued
+	ReferenceID   string     `json:",omitempty"`
+	LastError     string     `json:",omitempty"`
+	SentAt        *time.Time `json:",omitempty"`
+	CreatedAt     time.Time  `json:",omitempty"`
+}
+
+func FromDomain(item *schema.OutboxMessage) (res *OutboxMessage) {
+	if item == nil {
+		return nil
+	}
+
+	res = &OutboxMessage{
+		ID:            item.ID,
+		Purpose:       item.Purpose,
+		Priority:      item.Priority,
+		Template:      item.Template,
+		Provider:      item.Provider,
+		Mobile:        item.Mobile,
+		Params:        item.Params,
+		Channels:      item.Channels,
+		Status:        item.Status,
+		StatusDisplay: schema.OutboxStatusProxy[item.Status],
+		Attempts:      item.Attempts,
+		ReferenceID:   item.ReferenceID,
+		LastError:     item.LastError,
+		SentAt:        item.SentAt,
+		CreatedAt:     item.CreatedAt,
+	}
+	if item.Status == schema.OutboxStatusQueued {
+		res.NextAttemptAt = &item.NextAttemptAt
+	}
+
+	return res
+}
@@ -0,0 +1,386 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/outbox/repository"
+	"go-fiber-starter/app/module/outbox/request"
+	"go-fiber-starter/app/module/outbox/response"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/utils/config"
+	"go-fiber-starter/utils/optout"
+	"go-fiber-starter/utils/paginator"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// OutboxLock is how long a claimed message is kept from the other workers, it is taken again after that
+// if the worker died while sending it.
+const OutboxLock = 2 * time.Minute
+
+// the longest wait between two attempts of a message
+const maxBackoff = time.Hour
+
+// Sender sends an sms or a message on Bale, it is the sms service of the providers outside the tests.
+type Sender interface {
+	Send(message sms.Message) (*sms.Receipt, error)
+	SendOTP(mobile string) (*sms.Receipt, error)
+	SendBale(chatID int64, message sms.Message) (*sms.Receipt, error)
+	SendBaleOTP(chatID int64, mobile string) (*sms.Receipt, error)
+}
+
+func SmsSender(service *sms.Service) Sender {
+	return service
+}
+
+type IService interface {
+	Index(req request.OutboxMessages) (messages []*response.OutboxMessage, paging paginator.Pagination, err error)
+	Show(businessID uint64, id uint64) (message *response.OutboxMessage, err error)
+	Retry(businessID uint64, id uint64) (err error)
+
+	Enqueue(tx *gorm.DB, messages ...*schema.OutboxMessage) (err error)
+	Process(now time.Time) (err error)
+	LastSent(purpose schema.OutboxPurpose, mobile string) (message *schema.OutboxMessage, err error)
+}
+
+func Service(repo repository.IRepository, sender Sender, cfg *config.Config) IService {
+	return &service{
+		repo,
+		sender,
+		newOutboxPolicy(cfg),
+		baleChannel{
+			Enabled: cfg.Services.BaleBot.UserBotToken != "",
+			OTP:     cfg.Services.BaleBot.UserBotOTP,
+		},
+		optOut{
+			Domain:  cfg.App.BackendDomain,
+			Secret:  cfg.Middleware.Jwt.Secret,
+			Keyword: cfg.Services.SMS.OptOutKeyword,
+		},
+	}
+}
+
+type service struct {
+	Repo   repository.IRepository
+	Sender Sender
+	Policy outboxPolicy
+	Bale   baleChannel
+	OptOut optOut
+}
+
+// baleChannel is whether the messages go to the linked Bale chats of the users first, see OutboxPurpose.OverBale
+type baleChannel struct {
+	Enabled bool
+	OTP     bool // the otps only when they are allowed there too
+}
+
+// optOut is what the marketing sms carry to stop them, see optout.Text
+type optOut struct {
+	Domain  string
+	Secret  string
+	Keyword string
+}
+
+// outboxPolicy is how fast the messages are sent and how long the failed ones are tried again
+type outboxPolicy struct {
+	Rate        int
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func newOutboxPolicy(cfg *config.Config) outboxPolicy {
+	policy := outboxPolicy{
+		Rate:        cfg.Services.Outbox.Rate,
+		MaxAttempts: cfg.Services.Outbox.MaxAttempts,
+		Backoff:     cfg.Services.Outbox.Backoff * time.Second,
+	}
+
+	if policy.Rate <= 0 {
+		policy.Rate = 5
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 6
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 30 * time.Second
+	}
+
+	return policy
+}
+
+func (_i *service) Index(req request.OutboxMessages) (messages []*response.OutboxMessage, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetAll(req)
+	if err != nil {
+		return
+	}
+
+	for _, result := range results {
+		messages = append(messages, response.FromDomain(result))
+	}
+
+	return
+}
+
+func (_i *service) Show(businessID uint64, id uint64) (message *response.OutboxMessage, err error) {
+	result, err := _i.Repo.GetOne(businessID, id)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "پیامک یافت نشد"}
+	}
+
+	return response.FromDomain(result), nil
+}
+
+// Retry queues a dead message again, e.g. after the mobile of the user is fixed or the provider is charged.
+func (_i *service) Retry(businessID uint64, id uint64) (err error) {
+	message, err := _i.Repo.GetOne(businessID, id)
+	if err != nil {
+		return &fiber.Error{Code: fiber.StatusNotFound, Message: "پیامک یافت نشد"}
+	}
+	if message.Status != schema.OutboxStatusDead {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "فقط پیامک‌های ناموفق دوباره ارسال می‌شوند"}
+	}
+
+	message.Status = schema.OutboxStatusQueued
+	message.NextAttemptAt = time.Now()
+	message.LockedUntil = nil
+	message.Attempts = 0
+
+	return _i.Repo.Update(message)
+}
+
+// Enqueue queues the messages in the transaction, or right away when tx is nil. They are sent by the worker.
+// Each one is sent over the channels the user allows, the linked Bale chat first for the purposes that go there,
+// and is kept as skipped when none is left. The marketing ones get the opt-out as their last param.
+func (_i *service) Enqueue(tx *gorm.DB, messages ...*schema.OutboxMessage) (err error) {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	receivers, err := _i.receivers(tx, messages)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	queued := make([]*schema.OutboxMessage, 0, len(messages))
+	for _, message := range messages {
+		message.Status = schema.OutboxStatusQueued
+		if message.NextAttemptAt.IsZero() {
+			message.NextAttemptAt = now
+		}
+
+		receiver := receivers[message.Mobile]
+		var meta *schema.UserMeta
+		if receiver != nil {
+			meta = receiver.Meta
+		}
+
+		onlyBale := slices.Equal(message.SendChannels(), []schema.NotificationType{schema.TBale})
+		channels := _i.channels(message, meta)
+		if len(channels) == 0 && onlyBale {
+			// the ones of the users that have not linked their account, there is nothing to keep of them
+			continue
+		}
+		queued = append(queued, message)
+
+		if len(channels) == 0 {
+			message.Status = schema.OutboxStatusSkipped
+			message.LastError = fmt.Sprintf("the user turned the %s sms off", message.Purpose.Category())
+			continue
+		}
+		message.Channels = nil
+		for _, channel := range channels {
+			message.Channels = append(message.Channels, string(channel))
+		}
+		if slices.Contains(channels, schema.TBale) {
+			message.BaleChatID = &meta.BaleChatID
+		}
+
+		if message.Purpose.Category() == schema.NotificationCategoryMarketing {
+			var userID uint64
+			if receiver != nil {
+				userID = receiver.ID
+			}
+			message.Params = append(message.Params, optout.Text(_i.OptOut.Domain, _i.OptOut.Secret, _i.OptOut.Keyword, userID))
+		}
+	}
+	if len(queued) == 0 {
+		return nil
+	}
+
+	return _i.Repo.Create(queued, tx)
+}
+
+// channels are the ones of the message the user of the meta allows, in the order they are tried. The otps can
+// not be turned off, and only go to Bale when it is allowed for them.
+func (_i *service) channels(message *schema.OutboxMessage, meta *schema.UserMeta) (channels []schema.NotificationType) {
+	requested := message.SendChannels()
+	if len(message.Channels) == 0 && message.Purpose.OverBale() {
+		requested = []schema.NotificationType{schema.TBale, schema.TSms}
+	}
+
+	otp := message.Purpose == schema.OutboxPurposeOTP
+	category := message.Purpose.Category()
+	for _, channel := range requested {
+		if channel == schema.TBale {
+			if !_i.Bale.Enabled || (otp && !_i.Bale.OTP) || meta == nil || meta.BaleChatID == 0 {
+				continue
+			}
+		}
+		if !otp && meta != nil && !meta.NotificationPreferences.Allows(category, channel) {
+			continue
+		}
+
+		channels = append(channels, channel)
+	}
+
+	return channels
+}
+
+// receivers are the users the messages go to by their mobile, a mobile may be of no user, e.g. of a business
+func (_i *service) receivers(tx *gorm.DB, messages []*schema.OutboxMessage) (map[string]*schema.User, error) {
+	var mobiles []uint64
+	for _, message := range messages {
+		// the otps are only looked up for their Bale chat
+		if message.Purpose == schema.OutboxPurposeOTP && !(_i.Bale.Enabled && _i.Bale.OTP) {
+			continue
+		}
+		if mobile, err := strconv.ParseUint(message.Mobile, 10, 64); err == nil {
+			mobiles = append(mobiles, mobile)
+		}
+	}
+	if len(mobiles) == 0 {
+		return nil, nil
+	}
+
+	users, err := _i.Repo.GetReceivers(tx, mobiles)
+	if err != nil {
+		return nil, err
+	}
+
+	receivers := make(map[string]*schema.User, len(users))
+	for _, user := range users {
+		receivers[fmt.Sprintf("0%d", user.Mobile)] = user
+	}
+
+	return receivers, nil
+}
+
+// LastSent is the latest message of the purpose sent to the mobile, e.g. to know the provider of an otp.
+func (_i *service) LastSent(purpose schema.OutboxPurpose, mobile string) (message *schema.OutboxMessage, err error) {
+	return _i.Repo.GetLastSent(purpose, mobile)
+}
+
+// Process sends the messages that are due, no faster than the rate of the provider. A message that fails
+// is tried again later, each time waiting twice as long, and is dead after the last attempt.
+// Only the messages that die and the ones that could not be saved are returned as errors.
+func (_i *service) Process(now time.Time) (err error) {
+	messages, err := _i.Repo.Claim(now, now.Add(OutboxLock), _i.Policy.Rate)
+	if err != nil {
+		return err
+	}
+
+	interval := time.Second / time.Duration(_i.Policy.Rate)
+	start := time.Now()
+
+	var errs []error
+	for i, message := range messages {
+		time.Sleep(time.Until(start.Add(time.Duration(i) * interval)))
+
+		if sendErr := _i.send(message); sendErr != nil && message.Status == schema.OutboxStatusDead {
+			errs = append(errs, fmt.Errorf("sms %d is dead: %w", message.ID, sendErr))
+		}
+
+		if err := _i.Repo.Update(message); err != nil {
+			errs = append(errs, fmt.Errorf("sms %d: %w", message.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// send sends the message over the first of its channels that takes it and sets its status from the result
+func (_i *service) send(message *schema.OutboxMessage) error {
+	var receipt *sms.Receipt
+	var errs []error
+	for _, channel := range message.SendChannels() {
+		var err error
+		if receipt, err = _i.sendOver(channel, message); err == nil {
+			break
+		}
+		errs = append(errs, err)
+	}
+
+	message.LockedUntil = nil
+	if receipt != nil {
+		sentAt := time.Now()
+		message.Status = schema.OutboxStatusSent
+		message.SentAt = &sentAt
+		message.LastError = ""
+		message.Provider = receipt.Provider
+		message.ReferenceID = receipt.ReferenceID
+		return nil
+	}
+
+	err := errors.Join(errs...)
+	message.LastError = truncate(err.Error(), 500)
+	// none of the providers of the last channel can ever send it, e.g. the mobile is invalid
+	if errors.Is(errs[len(errs)-1], sms.ErrInvalidMessage) || message.Attempts >= _i.Policy.MaxAttempts {
+		message.Status = schema.OutboxStatusDead
+		return err
+	}
+
+	message.Status = schema.OutboxStatusQueued
+	message.NextAttemptAt = time.Now().Add(_i.backoff(message.Attempts))
+
+	return err
+}
+
+func (_i *service) sendOver(channel schema.NotificationType, message *schema.OutboxMessage) (*sms.Receipt, error) {
+	if channel == schema.TBale {
+		if message.BaleChatID == nil {
+			return nil, fmt.Errorf("%w: no bale chat", sms.ErrInvalidMessage)
+		}
+		if message.Purpose == schema.OutboxPurposeOTP {
+			return _i.Sender.SendBaleOTP(*message.BaleChatID, message.Mobile)
+		}
+		return _i.Sender.SendBale(*message.BaleChatID, sms.Message{
+			Template: message.Template,
+			Mobile:   message.Mobile,
+			Params:   message.Params,
+		})
+	}
+
+	if message.Purpose == schema.OutboxPurposeOTP {
+		return _i.Sender.SendOTP(message.Mobile)
+	}
+	return _i.Sender.Send(sms.Message{
+		Template: message.Template,
+		Mobile:   message.Mobile,
+		Params:   message.Params,
+	})
+}
+
+// backoff is the wait after the attempt, doubled after each one
+func (_i *service) backoff(attempts int) time.Duration {
+	wait := _i.Policy.Backoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+
+	return min(wait, maxBackoff)
+}
+
+func truncate(text string, length int) string {
+	runes := []rune(text)
+	if len(runes) <= length {
+		return text
+	}
+
+	return string(runes[:length])
+}
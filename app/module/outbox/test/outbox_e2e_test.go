@@ -0,0 +1,272 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/internal/testapp"
+)
+
+func TestProcess_SendsByPriority(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	bulk := ta.Enqueue(t, business.ID, 9100000002, schema.OutboxPriorityBulk)
+	otp := ta.Enqueue(t, business.ID, 9100000003, schema.OutboxPriorityHigh)
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sent := ta.Sender.Sent()
+	if len(sent) != 2 || sent[0].Mobile != otp.Mobile || sent[1].Mobile != bulk.Mobile {
+		t.Fatalf("expected the high priority sms first, got %+v", sent)
+	}
+
+	message := ta.GetMessage(t, otp.ID)
+	if message.Status != schema.OutboxStatusSent || message.ReferenceID != "ref-09100000003" || message.Provider != sms.ProviderFake || message.SentAt == nil || message.Attempts != 1 {
+		t.Errorf("expected the sms to be sent once with its reference, got %+v", message)
+	}
+
+	// nothing is sent twice
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ta.Sender.Sent()) != 2 {
+		t.Errorf("expected the sent messages not to be sent again, got %d sends", len(ta.Sender.Sent()))
+	}
+}
+
+func TestProcess_RetriesTransientFailures(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	queued := ta.Enqueue(t, business.ID, 9100000002, schema.OutboxPriorityNormal)
+
+	ta.Sender.SendFunc = func(message sms.Message) (*sms.Receipt, error) {
+		return nil, errors.New("connection reset")
+	}
+
+	now := time.Now()
+	if err := ta.Service.Process(now); err != nil {
+		t.Fatalf("expected a retried failure not to be reported, got %v", err)
+	}
+
+	message := ta.GetMessage(t, queued.ID)
+	if message.Status != schema.OutboxStatusQueued || message.Attempts != 1 || message.LastError != "connection reset" {
+		t.Fatalf("expected the sms to wait for another attempt, got %+v", message)
+	}
+	if wait := message.NextAttemptAt.Sub(now); wait < 9*time.Second {
+		t.Errorf("expected the next attempt after the backoff, got %s", wait)
+	}
+
+	// not due before the backoff
+	if err := ta.Service.Process(now.Add(time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ta.Sender.Sent()) != 1 {
+		t.Fatalf("expected no attempt before the backoff, got %d", len(ta.Sender.Sent()))
+	}
+
+	ta.Sender.SendFunc = nil
+	if err := ta.Service.Process(message.NextAttemptAt.Add(time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if message = ta.GetMessage(t, queued.ID); message.Status != schema.OutboxStatusSent || message.Attempts != 2 {
+		t.Errorf("expected the sms to be sent on the second attempt, got %+v", message)
+	}
+}
+
+func TestProcess_DeadLetters(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+
+	t.Run("after the last attempt", func(t *testing.T) {
+		queued := ta.Enqueue(t, business.ID, 9100000002, schema.OutboxPriorityNormal)
+		ta.Sender.SendFunc = func(message sms.Message) (*sms.Receipt, error) {
+			return nil, fmt.Errorf("%w: credit", sms.ErrRejected)
+		}
+
+		at := time.Now()
+		for attempt := 1; attempt <= ta.Config.Services.Outbox.MaxAttempts; attempt++ {
+			err := ta.Service.Process(at)
+			if attempt < ta.Config.Services.Outbox.MaxAttempts && err != nil {
+				t.Fatalf("attempt %d: expected no error before the last attempt, got %v", attempt, err)
+			}
+			if attempt == ta.Config.Services.Outbox.MaxAttempts && err == nil {
+				t.Fatal("expected the dead sms to be reported")
+			}
+			at = ta.GetMessage(t, queued.ID).NextAttemptAt.Add(time.Second)
+		}
+
+		if message := ta.GetMessage(t, queued.ID); message.Status != schema.OutboxStatusDead {
+			t.Errorf("expected the sms to be dead, got %+v", message)
+		}
+	})
+
+	t.Run("when it can never be sent", func(t *testing.T) {
+		queued := ta.Enqueue(t, business.ID, 9100000003, schema.OutboxPriorityNormal)
+		ta.Sender.SendFunc = func(message sms.Message) (*sms.Receipt, error) {
+			return nil, fmt.Errorf("%w: mobile not found", sms.ErrInvalidMessage)
+		}
+
+		if err := ta.Service.Process(time.Now()); err == nil {
+			t.Fatal("expected the dead sms to be reported")
+		}
+		if message := ta.GetMessage(t, queued.ID); message.Status != schema.OutboxStatusDead || message.Attempts != 1 {
+			t.Errorf("expected the sms to be dead after one attempt, got %+v", message)
+		}
+	})
+}
+
+func TestOutbox_RetryDeadMessage(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	other := ta.CreateTestBusiness(t, ta.CreateTestUser(t, 9100000004, "Test", "User"), "Test Business")
+	token := ta.GenerateTestToken(t, owner)
+
+	queued := ta.Enqueue(t, business.ID, 9100000002, schema.OutboxPriorityNormal)
+	ta.Enqueue(t, other.ID, 9100000003, schema.OutboxPriorityNormal)
+	path := fmt.Sprintf("/v1/business/%d/sms-outbox/%d/retry", business.ID, queued.ID)
+
+	// only the dead ones are sent again
+	resp := ta.MakeRequest(t, http.MethodPost, path, nil, token)
+	testapp.AssertStatus(t, resp, http.StatusBadRequest)
+
+	ta.Sender.SendFunc = func(message sms.Message) (*sms.Receipt, error) {
+		return nil, fmt.Errorf("%w: mobile not found", sms.ErrInvalidMessage)
+	}
+	_ = ta.Service.Process(time.Now())
+
+	resp = ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/sms-outbox?Status=dead", business.ID), nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if items, _ := testapp.ParseResponse(t, resp)["Data"].([]any); len(items) != 1 {
+		t.Fatalf("expected only the dead sms of the business, got %d", len(items))
+	}
+
+	resp = ta.MakeRequest(t, http.MethodPost, path, nil, token)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+
+	message := ta.GetMessage(t, queued.ID)
+	if message.Status != schema.OutboxStatusQueued || message.Attempts != 0 {
+		t.Errorf("expected the sms to be queued again, got %+v", message)
+	}
+}
+
+func TestProcess_SendsToTheLinkedBaleChatFirst(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	linked := ta.CreateTestUser(t, 9100000002, "Test", "User")
+	ta.LinkBale(t, linked, 100)
+	ta.CreateTestUser(t, 9100000003, "Test", "User")
+
+	reminder := ta.Enqueue(t, business.ID, 9100000002, schema.OutboxPriorityNormal)
+	unlinked := ta.Enqueue(t, business.ID, 9100000003, schema.OutboxPriorityNormal)
+	otp := schema.NewSms(schema.OutboxPurposeOTP, 9100000002, schema.SmsOTP)
+	// the marketing ones are never sent on Bale
+	coupon := schema.NewSms(schema.OutboxPurposeCoupon, 9100000002, schema.SmsCoupon, "Ali", "CODE", "1405/01/01")
+	if err := ta.Service.Enqueue(nil, otp, coupon); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if message := ta.GetMessage(t, reminder.ID); len(message.Channels) != 2 || message.Channels[0] != string(schema.TBale) || message.BaleChatID == nil || *message.BaleChatID != 100 {
+		t.Errorf("expected Bale and then the sms for the linked user, got %v", message.Channels)
+	}
+	if message := ta.GetMessage(t, unlinked.ID); len(message.Channels) != 1 || message.Channels[0] != string(schema.TSms) {
+		t.Errorf("expected only the sms for the user without a chat, got %v", message.Channels)
+	}
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	bale := ta.Sender.SentBale()
+	if len(bale) != 2 || bale[0].ChatID != 100 {
+		t.Fatalf("expected the reminder and the otp on Bale, got %+v", bale)
+	}
+	if sent := ta.Sender.Sent(); len(sent) != 2 {
+		t.Errorf("expected only the unlinked reminder and the coupon by sms, got %+v", sent)
+	}
+	if message := ta.GetMessage(t, otp.ID); message.Status != schema.OutboxStatusSent || message.Provider != sms.ProviderBale {
+		t.Errorf("expected the otp to be sent on Bale, for it to be verified there, got %+v", message)
+	}
+}
+
+func TestProcess_FallsBackToTheSms(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	linked := ta.CreateTestUser(t, 9100000002, "Test", "User")
+	ta.LinkBale(t, linked, 100)
+	queued := ta.Enqueue(t, business.ID, 9100000002, schema.OutboxPriorityNormal)
+
+	ta.Sender.SendBaleFunc = func(chatID int64, message sms.Message) (*sms.Receipt, error) {
+		return nil, errors.New("bale: Forbidden: bot was blocked by the user")
+	}
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	message := ta.GetMessage(t, queued.ID)
+	if message.Status != schema.OutboxStatusSent || message.Provider != sms.ProviderFake || message.Attempts != 1 {
+		t.Errorf("expected the sms to be sent in the same attempt, got %+v", message)
+	}
+	if len(ta.Sender.SentBale()) != 1 || len(ta.Sender.Sent()) != 1 {
+		t.Errorf("expected one try on Bale and one sms")
+	}
+}
+
+func TestEnqueue_BaleOnlyMessages(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	owner := ta.CreateTestUser(t, 9100000001, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	linked := ta.CreateTestUser(t, 9100000002, "Test", "User")
+	ta.LinkBale(t, linked, 100)
+	ta.CreateTestUser(t, 9100000003, "Test", "User")
+
+	var confirmations []*schema.OutboxMessage
+	for _, mobile := range []uint64{9100000002, 9100000003} {
+		message := schema.NewBale(schema.OutboxPurposeReservation, mobile, schema.SmsReservationConfirmed, "W-12", "1405/01/01 ساعت 10:00")
+		message.BusinessID = &business.ID
+		confirmations = append(confirmations, message)
+	}
+	if err := ta.Service.Enqueue(nil, confirmations...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var count int64
+	ta.DB.Model(&schema.OutboxMessage{}).Count(&count)
+	if count != 1 || confirmations[0].ID == 0 {
+		t.Fatalf("expected only the confirmation of the linked user to be queued, got %d", count)
+	}
+
+	if err := ta.Service.Process(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ta.Sender.SentBale()) != 1 || len(ta.Sender.Sent()) != 0 {
+		t.Errorf("expected the confirmation on Bale and no sms")
+	}
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/outbox/repository"
+	"go-fiber-starter/internal/bootstrap/database"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// the provider is read back by the verification of the otp, it must be saved with the result of the attempt
+func TestRepository_UpdateSavesTheProvider(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("failed to open the dry run database: %v", err)
+	}
+
+	var statement string
+	db.Callback().Update().After("gorm:update").Register("test:statement", func(tx *gorm.DB) {
+		statement = tx.Statement.SQL.String()
+	})
+
+	sentAt := time.Now()
+	message := &schema.OutboxMessage{ID: 1, Status: schema.OutboxStatusSent, Provider: "kavenegar", ReferenceID: "42", SentAt: &sentAt}
+	if err := repository.Repository(&database.Database{Main: db}).Update(message); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(statement, `"provider"=`) {
+		t.Errorf("expected the provider to be saved, got %s", statement)
+	}
+}
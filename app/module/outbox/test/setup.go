@@ -0,0 +1,241 @@
+package test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/outbox"
+	"go-fiber-starter/app/module/outbox/controller"
+	"go-fiber-starter/app/module/outbox/repository"
+	"go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/internal/testapp"
+
+	baleBotApi "github.com/ghiac/bale-bot-api"
+)
+
+// TestApp holds the outbox service on the test app, its sms go to the Sender
+type TestApp struct {
+	*testapp.Fixture
+	Service service.IService
+	Sender  *MockSender
+}
+
+// MockSender records the sent messages, SendFunc and SendBaleFunc decide how each one goes
+type MockSender struct {
+	mu       sync.Mutex
+	sent     []sms.Message
+	sentBale []BaleMessage
+
+	SendFunc     func(message sms.Message) (*sms.Receipt, error)
+	SendBaleFunc func(chatID int64, message sms.Message) (*sms.Receipt, error)
+}
+
+// BaleMessage is a message the MockSender sent to a Bale chat
+type BaleMessage struct {
+	ChatID int64
+	sms.Message
+}
+
+func (m *MockSender) Send(message sms.Message) (*sms.Receipt, error) {
+	m.mu.Lock()
+	m.sent = append(m.sent, message)
+	m.mu.Unlock()
+
+	if m.SendFunc != nil {
+		return m.SendFunc(message)
+	}
+	return &sms.Receipt{Provider: sms.ProviderFake, ReferenceID: "ref-" + message.Mobile}, nil
+}
+
+func (m *MockSender) SendOTP(mobile string) (*sms.Receipt, error) {
+	return m.Send(sms.Message{Template: schema.SmsOTP, Mobile: mobile})
+}
+
+func (m *MockSender) SendBale(chatID int64, message sms.Message) (*sms.Receipt, error) {
+	m.mu.Lock()
+	m.sentBale = append(m.sentBale, BaleMessage{ChatID: chatID, Message: message})
+	m.mu.Unlock()
+
+	if m.SendBaleFunc != nil {
+		return m.SendBaleFunc(chatID, message)
+	}
+	return &sms.Receipt{Provider: sms.ProviderBale, ReferenceID: "bale-" + message.Mobile}, nil
+}
+
+func (m *MockSender) SendBaleOTP(chatID int64, mobile string) (*sms.Receipt, error) {
+	return m.SendBale(chatID, sms.Message{Template: schema.SmsOTP, Mobile: mobile})
+}
+
+// SentBale returns the messages handed to the Bale bot, in order
+func (m *MockSender) SentBale() []BaleMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]BaleMessage{}, m.sentBale...)
+}
+
+// Sent returns the messages handed to the providers, in order
+func (m *MockSender) Sent() []sms.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]sms.Message{}, m.sent...)
+}
+
+// MockProvider is an sms provider that fails with Err, or sends and remembers the messages
+type MockProvider struct {
+	ProviderName string
+	Err          error
+	Sent         []sms.Message
+}
+
+func (m *MockProvider) Name() string {
+	return m.ProviderName
+}
+
+func (m *MockProvider) Send(message sms.Message) (string, error) {
+	if m.Err != nil {
+		return "", m.Err
+	}
+	m.Sent = append(m.Sent, message)
+	return m.ProviderName + "-ref", nil
+}
+
+func (m *MockProvider) SendOTP(mobile string) (string, error) {
+	return m.Send(sms.Message{Template: schema.SmsOTP, Mobile: mobile})
+}
+
+func (m *MockProvider) VerifyOTP(mobile string, code string) error {
+	if code != "12345" {
+		return sms.ErrInvalidOTP
+	}
+	return nil
+}
+
+func (m *MockProvider) Status(referenceID string) (string, error) {
+	return "delivered " + referenceID, nil
+}
+
+// MockBaleBot is the user bot, it fails with Err or remembers the texts it sent
+type MockBaleBot struct {
+	Err  error
+	Sent []baleBotApi.MessageConfig
+}
+
+func (m *MockBaleBot) Send(c baleBotApi.Chattable) (baleBotApi.Message, error) {
+	if m.Err != nil {
+		return baleBotApi.Message{}, m.Err
+	}
+	m.Sent = append(m.Sent, c.(baleBotApi.MessageConfig))
+	return baleBotApi.Message{MessageID: len(m.Sent)}, nil
+}
+
+// MockOTPStore keeps the otp codes in memory, by the mobile
+type MockOTPStore struct {
+	mu    sync.Mutex
+	codes map[string]otpHash
+}
+
+type otpHash struct {
+	hash      string
+	expiresAt time.Time
+}
+
+func (m *MockOTPStore) Save(mobile string, hash string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.codes == nil {
+		m.codes = map[string]otpHash{}
+	}
+	m.codes[mobile] = otpHash{hash, expiresAt}
+	return nil
+}
+
+func (m *MockOTPStore) Use(mobile string, hash string, now time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	saved, ok := m.codes[mobile]
+	if !ok || saved.hash != hash || !now.Before(saved.expiresAt) {
+		return false, nil
+	}
+	delete(m.codes, mobile)
+	return true, nil
+}
+
+// NewTestOTPCodes are the otp codes of the providers in memory
+func NewTestOTPCodes() *sms.OTPCodes {
+	return sms.NewOTPCodes("test-secret", &MockOTPStore{})
+}
+
+// SetupTestApp initializes the test application with a test database
+func SetupTestApp(t *testing.T) *TestApp {
+	t.Helper()
+
+	f := testapp.New(t, "users", "businesses", "outbox_messages")
+
+	// a fast provider and a short wait between the attempts
+	f.Config.Services.Outbox.Rate = 50
+	f.Config.Services.Outbox.MaxAttempts = 3
+	f.Config.Services.Outbox.Backoff = 10
+	// the messages of the linked users go to Bale, the MockSender stands in for the bot
+	f.Config.Services.BaleBot.UserBotToken = "test-token"
+	f.Config.Services.BaleBot.UserBotOTP = true
+
+	sender := &MockSender{}
+	outboxSvc := service.Service(repository.Repository(f.Database), sender, f.Config)
+	outboxRouter := &outbox.Router{
+		App:        f.App,
+		Controller: controller.Controllers(outboxSvc),
+	}
+	outboxRouter.RegisterRoutes(f.Config)
+
+	return &TestApp{
+		Fixture: f,
+		Service: outboxSvc,
+		Sender:  sender,
+	}
+}
+
+// =============================================================================
+// Factories
+// =============================================================================
+
+// Enqueue queues an sms of the business to the mobile
+func (ta *TestApp) Enqueue(t *testing.T, businessID uint64, mobile uint64, priority schema.OutboxPriority) *schema.OutboxMessage {
+	t.Helper()
+
+	message := schema.NewSms(schema.OutboxPurposeReminder, mobile, schema.SmsReminderTurnOff)
+	message.BusinessID = &businessID
+	message.Priority = priority
+
+	if err := ta.Service.Enqueue(nil, message); err != nil {
+		t.Fatalf("failed to queue the sms: %v", err)
+	}
+
+	return message
+}
+
+// LinkBale links the account of the user to the Bale chat
+func (ta *TestApp) LinkBale(t *testing.T, user *schema.User, chatID int64) {
+	t.Helper()
+
+	user.Meta = &schema.UserMeta{BaleChatID: chatID}
+	if err := ta.DB.Model(user).Update("meta", user.Meta).Error; err != nil {
+		t.Fatalf("failed to link the user: %v", err)
+	}
+}
+
+// GetMessage reads the message from the database
+func (ta *TestApp) GetMessage(t *testing.T, id uint64) *schema.OutboxMessage {
+	t.Helper()
+
+	var message schema.OutboxMessage
+	if err := ta.DB.First(&message, id).Error; err != nil {
+		t.Fatalf("failed to get the sms: %v", err)
+	}
+
+	return &message
+}
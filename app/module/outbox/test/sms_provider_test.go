@@ -0,0 +1,263 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/utils/config"
+
+	"github.com/rs/zerolog"
+)
+
+func TestSms_FailsOverToTheNextProvider(t *testing.T) {
+	primary := &MockProvider{ProviderName: "primary", Err: fmt.Errorf("%w: out of credit", sms.ErrRejected)}
+	secondary := &MockProvider{ProviderName: "secondary"}
+	service := sms.New(zerolog.Nop(), primary, secondary)
+
+	receipt, err := service.Send(sms.Message{Template: schema.SmsCoupon, Mobile: "09100000001", Params: []string{"Ali"}})
+	if err != nil {
+		t.Fatalf("expected the second provider to send it, got %v", err)
+	}
+	if receipt.Provider != "secondary" || receipt.Reference() != "secondary:secondary-ref" || len(secondary.Sent) != 1 {
+		t.Errorf("expected the sms to be sent by the second provider, got %+v", receipt)
+	}
+
+	// the status is asked from the one that sent it
+	status, err := service.Status(receipt.Reference())
+	if err != nil || status.Provider != "secondary" || status.Status != "delivered secondary-ref" {
+		t.Errorf("expected the status of the second provider, got %+v, %v", status, err)
+	}
+
+	if _, err = service.Status("other:12345"); !errors.Is(err, sms.ErrUnknownProvider) {
+		t.Errorf("expected an unknown provider, got %v", err)
+	}
+
+	// the references from before the providers are of MessageWay, the primary one answers when it is not configured
+	if status, err = service.Status("12345"); err != nil || status.Provider != "primary" {
+		t.Errorf("expected the status of the primary provider, got %+v, %v", status, err)
+	}
+}
+
+func TestSms_InvalidOnlyWhenNoProviderCanSend(t *testing.T) {
+	invalid := &MockProvider{ProviderName: "invalid", Err: fmt.Errorf("%w: mobile not found", sms.ErrInvalidMessage)}
+	noTemplate := &MockProvider{ProviderName: "noTemplate", Err: sms.ErrNoTemplate}
+	down := &MockProvider{ProviderName: "down", Err: errors.New("connection reset")}
+
+	_, err := sms.New(zerolog.Nop(), invalid, noTemplate).Send(sms.Message{Template: schema.SmsCoupon})
+	if !errors.Is(err, sms.ErrInvalidMessage) {
+		t.Errorf("expected an invalid message, got %v", err)
+	}
+
+	_, err = sms.New(zerolog.Nop(), invalid, down).Send(sms.Message{Template: schema.SmsCoupon})
+	if err == nil || errors.Is(err, sms.ErrInvalidMessage) {
+		t.Errorf("expected a failure that is tried again, got %v", err)
+	}
+}
+
+func TestSms_MessageWayNeedsTheTemplatesWithoutDefaults(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "zciti.toml")
+	write := func(templates string) *config.Config {
+		t.Helper()
+		content := "[services.messageway]\napiKey = \"key\"\n\n[services.sms]\nproviders = [\"messageway\"]\n\n[services.sms.templates.messageway]\n" + templates
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write the config: %v", err)
+		}
+		cfg, err := config.ParseConfig(file, true)
+		if err != nil {
+			t.Fatalf("failed to parse the config: %v", err)
+		}
+		return cfg
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected the service to refuse messageway without the templates")
+			}
+		}()
+		sms.NewService(write(`waitlist-offer = { id = "16623", line = "5" }`), zerolog.Nop(), nil, nil)
+	}()
+
+	templates := ""
+	for i, name := range []string{schema.SmsWaitlistOffer, schema.SmsDowntimeAlert, schema.SmsReservationMoved, schema.SmsReservationRefunded,
+		schema.SmsNoShowSuspension, schema.SmsRescheduled, schema.SmsNotification} {
+		templates += fmt.Sprintf("%s = { id = \"%d\", line = \"5\" }\n", name, 16623+i)
+	}
+	if service := sms.NewService(write(templates), zerolog.Nop(), nil, nil); service == nil {
+		t.Error("expected the service with all the templates")
+	}
+}
+
+func TestSms_KavenegarLookupAndOtp(t *testing.T) {
+	var sent []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		sent = append(sent, map[string]string{
+			"path":     r.URL.Path,
+			"receptor": r.PostForm.Get("receptor"),
+			"template": r.PostForm.Get("template"),
+			"token":    r.PostForm.Get("token"),
+			"token2":   r.PostForm.Get("token2"),
+		})
+
+		if r.PostForm.Get("receptor") == "0" {
+			_, _ = fmt.Fprint(w, `{"return":{"status":411,"message":"receptor is invalid"},"entries":null}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"return":{"status":200,"message":"ok"},"entries":[{"messageid":8792343,"status":10,"statustext":"delivered"}]}`)
+	}))
+	defer server.Close()
+
+	provider := sms.NewKavenegar("key", map[string]sms.Template{
+		schema.SmsOTP:    {ID: "zciti-otp"},
+		schema.SmsCoupon: {ID: "zciti-coupon"},
+	}, NewTestOTPCodes())
+	provider.BaseURL = server.URL
+
+	referenceID, err := provider.Send(sms.Message{Template: schema.SmsCoupon, Mobile: "09100000001", Params: []string{"Ali", "OFF10"}})
+	if err != nil || referenceID != "8792343" {
+		t.Fatalf("expected the message id of kavenegar, got %q, %v", referenceID, err)
+	}
+	if sent[0]["path"] != "/key/verify/lookup.json" || sent[0]["template"] != "zciti-coupon" || sent[0]["token"] != "Ali" || sent[0]["token2"] != "OFF10" {
+		t.Errorf("expected the lookup of the coupon template, got %v", sent[0])
+	}
+
+	if _, err = provider.Send(sms.Message{Template: schema.SmsDeviceOff, Mobile: "09100000001"}); !errors.Is(err, sms.ErrNoTemplate) {
+		t.Errorf("expected no template, got %v", err)
+	}
+	if _, err = provider.Send(sms.Message{Template: schema.SmsCoupon, Mobile: "0"}); !errors.Is(err, sms.ErrInvalidMessage) {
+		t.Errorf("expected an invalid message, got %v", err)
+	}
+
+	// the code is made by the app, the one sent is the one verified
+	if _, err = provider.SendOTP("09100000001"); err != nil {
+		t.Fatalf("failed to send the otp: %v", err)
+	}
+	code := sent[len(sent)-1]["token"]
+	if err = provider.VerifyOTP("09100000002", code); !errors.Is(err, sms.ErrInvalidOTP) {
+		t.Errorf("expected the code of another mobile to be invalid, got %v", err)
+	}
+	if err = provider.VerifyOTP("09100000001", code); err != nil {
+		t.Errorf("expected the sent code to be valid, got %v", err)
+	}
+	if err = provider.VerifyOTP("09100000001", code); !errors.Is(err, sms.ErrInvalidOTP) {
+		t.Errorf("expected the used code to be invalid, got %v", err)
+	}
+
+	if status, err := provider.Status(referenceID); err != nil || status != "delivered" {
+		t.Errorf("expected the message to be delivered, got %q, %v", status, err)
+	}
+}
+
+func TestSms_FakeKeepsTheMessages(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "sms.jsonl")
+	provider := sms.NewFake(file, "", zerolog.Nop(), NewTestOTPCodes())
+
+	if _, err := provider.Send(sms.Message{Template: schema.SmsCoupon, Mobile: "09100000001", Params: []string{"OFF10"}}); err != nil {
+		t.Fatalf("failed to send: %v", err)
+	}
+	if _, err := provider.SendOTP("09100000001"); err != nil {
+		t.Fatalf("failed to send the otp: %v", err)
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatalf("expected the messages in the file: %v", err)
+	}
+	defer f.Close()
+
+	var messages []sms.FakeMessage
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var message sms.FakeMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			t.Fatalf("failed to parse the line: %v", err)
+		}
+		messages = append(messages, message)
+	}
+
+	if len(messages) != 2 || messages[0].Template != schema.SmsCoupon || messages[1].OTP == "" {
+		t.Fatalf("expected the coupon and the otp in the file, got %+v", messages)
+	}
+	if err = provider.VerifyOTP("09100000001", messages[1].OTP); err != nil {
+		t.Errorf("expected the code in the file to be valid, got %v", err)
+	}
+	if err = provider.VerifyOTP("09100000001", messages[1].OTP); !errors.Is(err, sms.ErrInvalidOTP) {
+		t.Errorf("expected the code to be used once, got %v", err)
+	}
+
+	// a fake that can't post its messages stands in for a provider that is down
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	primary := sms.NewFake("", down.URL, zerolog.Nop(), NewTestOTPCodes())
+	secondary := &MockProvider{ProviderName: "secondary"}
+	receipt, err := sms.New(zerolog.Nop(), primary, secondary).Send(sms.Message{Template: schema.SmsCoupon, Mobile: "09100000001"})
+	if err != nil || receipt.Provider != "secondary" {
+		t.Errorf("expected the second provider to send it, got %+v, %v", receipt, err)
+	}
+}
+
+func TestSms_BaleSendsTheTextsAndOtp(t *testing.T) {
+	service := sms.New(zerolog.Nop(), &MockProvider{ProviderName: "primary"})
+	if _, err := service.SendBale(100, sms.Message{Template: schema.SmsCoupon}); !errors.Is(err, sms.ErrNoBale) {
+		t.Fatalf("expected nothing on Bale without the bot, got %v", err)
+	}
+
+	bot := &MockBaleBot{}
+	service.Bale = sms.NewBale(bot, NewTestOTPCodes())
+
+	receipt, err := service.SendBale(100, sms.Message{
+		Template: schema.SmsReservationRefunded,
+		Mobile:   "09100000001",
+		Params:   []string{"Ali", "W-12", "25000"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if receipt.Reference() != "bale:1" || bot.Sent[0].ChatID != 100 {
+		t.Errorf("expected the message in the chat, got %+v to %d", receipt, bot.Sent[0].ChatID)
+	}
+	if text := bot.Sent[0].Text; !strings.Contains(text, "25000 تومان") || !strings.Contains(text, "دستگاه W-12") {
+		t.Errorf("expected the params in the text, got %q", text)
+	}
+
+	if _, err = service.SendBale(100, sms.Message{Template: "16630"}); !errors.Is(err, sms.ErrNoTemplate) {
+		t.Errorf("expected no text for an unknown template, got %v", err)
+	}
+	if _, err = service.SendBale(100, sms.Message{Template: schema.SmsCoupon, Params: []string{"Ali"}}); !errors.Is(err, sms.ErrInvalidMessage) {
+		t.Errorf("expected a message without its params to be invalid, got %v", err)
+	}
+
+	if _, err = service.SendBaleOTP(100, "09100000001"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	code := regexp.MustCompile(`\d{5}`).FindString(bot.Sent[len(bot.Sent)-1].Text)
+	if err = service.VerifyOTP(sms.ProviderBale, "09100000002", code); !errors.Is(err, sms.ErrInvalidOTP) {
+		t.Errorf("expected the code of another mobile to be rejected, got %v", err)
+	}
+	if err = service.VerifyOTP(sms.ProviderBale, "09100000001", code); err != nil {
+		t.Errorf("expected the code sent on Bale to be verified, got %v", err)
+	}
+	if err = service.VerifyOTP(sms.ProviderBale, "09100000001", code); !errors.Is(err, sms.ErrInvalidOTP) {
+		t.Errorf("expected the used code to be rejected, got %v", err)
+	}
+
+	status, err := service.Status(receipt.Reference())
+	if err != nil || status.Provider != sms.ProviderBale {
+		t.Errorf("expected the status of the Bale message, got %+v, %v", status, err)
+	}
+}
@@ -16,6 +16,7 @@ type IRepository interface {
 	GetAll(req request.ProductsRequest, isForUser bool) (products []*schema.Post, paging paginator.Pagination, err error)
 	GetOne(businessID uint64, id uint64) (post *schema.Post, err error)
 	GetOneVariant(businessID uint64, id uint64) (product *schema.Product, err error)
+	GetReservableVariants(req request.ReservableVariants) (products []*schema.Product, err error)
 	Create(product *schema.Product) (err error)
 	Creates(product []*schema.Product) (err error)
 	Update(product *schema.Product) (err error)
@@ -98,6 +99,33 @@ func (_i *repo) GetOneVariant(businessID uint64, id uint64) (product *schema.Pro
 	return product, nil
 }
 
+func (_i *repo) GetReservableVariants(req request.ReservableVariants) (products []*schema.Product, err error) {
+	query := _i.DB.Main.Model(&schema.Product{}).
+		Joins("JOIN posts ON posts.id = products.post_id AND posts.deleted_at IS NULL").
+		Where("products.business_id = ?", req.BusinessID).
+		Where("(products.variant_type IN (?) OR products.type = ?)", []schema.ProductVariantType{
+			schema.ProductVariantTypeWashingMachine,
+			schema.ProductVariantTypeReservable,
+		}, schema.ProductTypeReservable).
+		Where("posts.status = ?", schema.PostStatusPublished)
+
+	if req.ProductID > 0 {
+		query.Where("products.id = ?", req.ProductID)
+	}
+
+	if req.PostID > 0 {
+		query.Where("products.post_id = ?", req.PostID)
+	}
+
+	if req.TaxonomyID > 0 {
+		query.Where("products.post_id IN (SELECT post_id FROM posts_taxonomies WHERE taxonomy_id = ?)", req.TaxonomyID)
+	}
+
+	err = query.Preload("Post").Order("products.post_id, products.id").Find(&products).Error
+
+	return
+}
+
 func (_i *repo) Creates(product []*schema.Product) (err error) {
 	err = _i.DB.Main.Create(&product).Error
 	if err != nil {
@@ -188,6 +216,7 @@ func (_i *repo) DeleteVariant(businessID uint64, productID uint64, variantID uin
 	err := _i.DB.Main.
 		Model(&schema.Reservation{}).
 		Where("product_id = ? AND start_time > ?", variantID, time.Now()).
+		Scopes(schema.ReservationTakesSlot(time.Now())).
 		Count(&reservationCount).
 		Error
 	if err != nil {
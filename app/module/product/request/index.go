@@ -1,9 +1,12 @@
 package request
 
 import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/module/post/request"
 	"go-fiber-starter/utils/paginator"
+	"strings"
 )
 
 type StoreProductAttribute struct {
@@ -31,6 +34,13 @@ type ProductInPost struct {
 	Meta        schema.ProductMeta
 }
 
+type ReservableVariants struct {
+	BusinessID uint64
+	TaxonomyID uint64
+	PostID     uint64
+	ProductID  uint64
+}
+
 type ProductsRequest struct {
 	BusinessID uint64
 	Keyword    string
@@ -38,6 +48,27 @@ type ProductsRequest struct {
 	Pagination *paginator.Pagination
 }
 
+func (req *Product) ValidateReservationSchedules() error {
+	if err := req.Product.ValidateReservationSchedule(); err != nil {
+		return err
+	}
+	for _, variant := range req.Variants {
+		if err := variant.ValidateReservationSchedule(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (req *ProductInPost) ValidateReservationSchedule() error {
+	if err := req.Meta.ValidateReservationSchedule(); err != nil {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: strings.TrimSpace(fmt.Sprintf("%s %s", req.Meta.SKU, err.Error()))}
+	}
+
+	return nil
+}
+
 func (req *Product) ToDomain(postID uint64, businessID uint64) (products []*schema.Product) {
 	var MinPrice = req.Product.Price
 	var MaxPrice = req.Product.Price
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"go-fiber-starter/app/database/schema"
 	postService "go-fiber-starter/app/module/post/service"
 	"go-fiber-starter/app/module/product/repository"
@@ -8,7 +9,10 @@ import (
 	"go-fiber-starter/app/module/product/response"
 	uresponse "go-fiber-starter/app/module/user/response"
 	userService "go-fiber-starter/app/module/user/service"
+	"go-fiber-starter/internal/device"
 	"go-fiber-starter/utils/paginator"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 type IService interface {
@@ -22,9 +26,9 @@ type IService interface {
 	DeleteVariant(businessID uint64, productID uint64, variantID uint64) error
 }
 
-func Service(repo repository.IRepository, pService postService.IService, uService userService.IService) IService {
+func Service(repo repository.IRepository, pService postService.IService, uService userService.IService, devices *device.Registry) IService {
 	return &service{
-		repo, pService, uService,
+		repo, pService, uService, devices,
 	}
 }
 
@@ -32,6 +36,7 @@ type service struct {
 	Repo     repository.IRepository
 	PService postService.IService
 	uService userService.IService
+	Devices  *device.Registry
 }
 
 func (_i *service) Index(req request.ProductsRequest, isForUser bool) (products []*response.Product, paging paginator.Pagination, err error) {
@@ -64,6 +69,13 @@ func (_i *service) Show(businessID uint64, id uint64) (product *response.Product
 }
 
 func (_i *service) Store(req request.Product) (postID *uint64, err error) {
+	if err = req.ValidateReservationSchedules(); err != nil {
+		return nil, err
+	}
+	if err = _i.checkDevices(append([]request.ProductInPost{req.Product}, req.Variants...)...); err != nil {
+		return nil, err
+	}
+
 	post, err := _i.PService.Store(req.Post)
 	if err != nil {
 		return nil, err
@@ -79,6 +91,13 @@ func (_i *service) Store(req request.Product) (postID *uint64, err error) {
 }
 
 func (_i *service) StoreVariant(req request.ProductInPost) (productID *uint64, err error) {
+	if err = req.ValidateReservationSchedule(); err != nil {
+		return nil, err
+	}
+	if err = _i.checkDevices(req); err != nil {
+		return nil, err
+	}
+
 	product := req.ToDomain(req.PostID, req.BusinessID)
 
 	if product.ID == 0 {
@@ -108,6 +127,13 @@ func (_i *service) StoreAttribute(req request.StoreProductAttribute) (err error)
 }
 
 func (_i *service) Update(id uint64, req request.Product) (err error) {
+	if err = req.ValidateReservationSchedules(); err != nil {
+		return err
+	}
+	if err = _i.checkDevices(append([]request.ProductInPost{req.Product}, req.Variants...)...); err != nil {
+		return err
+	}
+
 	if err = _i.PService.Update(id, req.Post); err != nil {
 		return err
 	}
@@ -138,3 +164,20 @@ func (_i *service) Delete(businessID uint64, id uint64) error {
 func (_i *service) DeleteVariant(businessID uint64, productID uint64, variantID uint64) error {
 	return _i.Repo.DeleteVariant(businessID, productID, variantID)
 }
+
+// checkDevices refuses the machines on a driver that is off and the controllers out of the configured hosts.
+func (_i *service) checkDevices(products ...request.ProductInPost) error {
+	for _, product := range products {
+		err := _i.Devices.Check(product.Meta)
+		switch {
+		case errors.Is(err, device.ErrUnknownDriver):
+			return &fiber.Error{Code: fiber.StatusBadRequest, Message: "نوع اتصال دستگاه پشتیبانی نمی‌شود"}
+		case errors.Is(err, device.ErrHostNotAllowed):
+			return &fiber.Error{Code: fiber.StatusBadRequest, Message: "آدرس دستگاه مجاز نیست"}
+		case err != nil:
+			return err
+		}
+	}
+
+	return nil
+}
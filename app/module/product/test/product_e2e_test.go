@@ -307,6 +307,51 @@ func TestStore_Success(t *testing.T) {
 	}
 }
 
+func TestStore_RefusesADeviceHostNotConfigured(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	user := ta.CreateTestUser(t, 9123456789, "testPassword123", "Test", "User", 0, nil)
+	business := ta.CreateTestBusiness(t, "Test Business", schema.BTypeGymManager, user.ID)
+	user.Permissions[business.ID] = []schema.UserRole{schema.URBusinessOwner}
+	ta.DB.Save(user)
+
+	token := ta.GenerateTestToken(t, user)
+
+	// the token of the controllers must not be sent to a url of the business
+	storeReq := request.Product{
+		Post: postRequest.Post{
+			Title:   "Washing Machine",
+			Content: "Washing machine content",
+			Status:  schema.PostStatusPublished,
+			Type:    schema.PostTypeProduct,
+			Meta: schema.PostMeta{
+				CommentsStatus: schema.PostCommentStatusOpen,
+			},
+		},
+		Product: request.ProductInPost{
+			Price:       250,
+			Type:        schema.ProductTypeSimple,
+			StockStatus: schema.ProductStockStatusInStock,
+			Meta: schema.ProductMeta{
+				UniWashDriver:    schema.UniWashDriverHTTP,
+				UniWashDeviceURL: "http://169.254.169.254/latest",
+			},
+		},
+	}
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/products", business.ID), storeReq, token)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	var count int64
+	ta.DB.Model(&schema.Post{}).Where("title = ? AND business_id = ?", "Washing Machine", business.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("expected the product not to be created, count: %d", count)
+	}
+}
+
 func TestStore_WithVariants(t *testing.T) {
 	ta := SetupTestApp(t)
 	defer ta.Cleanup()
@@ -23,6 +23,8 @@ import (
 	userRepo "go-fiber-starter/app/module/user/repository"
 	userService "go-fiber-starter/app/module/user/service"
 	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/internal/device"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/utils/config"
 	"go-fiber-starter/utils/helpers"
 
@@ -99,6 +101,7 @@ func migrateTestModels(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -237,6 +240,8 @@ func migrateTestModels(db *gorm.DB) error {
 			business_id BIGINT NOT NULL,
 			meta JSONB,
 			user_usage_count BIGINT DEFAULT 0,
+			seats INT NOT NULL DEFAULT 1,
+			shared BOOLEAN NOT NULL DEFAULT FALSE,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
 			deleted_at TIMESTAMPTZ
@@ -298,7 +303,8 @@ func SetupTestApp(t *testing.T) *TestApp {
 	// Create services
 	userSvc := userService.Service(userRepository)
 	postSvc := postService.Service(postRepository)
-	productSvc := service.Service(productRepo, postSvc, userSvc)
+	devices := device.NewRegistry(cfg, logger, sms.NewService(cfg, logger, nil, dbWrapper))
+	productSvc := service.Service(productRepo, postSvc, userSvc, devices)
 
 	// Create controllers
 	productCtrl := controller.Controllers(productSvc)
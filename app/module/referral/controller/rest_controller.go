@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/referral/request"
+	"go-fiber-starter/app/module/referral/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Index(c *fiber.Ctx) error
+	Leaderboard(c *fiber.Ctx) error
+	MyReferral(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// Index all Referrals
+// @Summary      Get all referrals of the business
+// @Tags         Referrals
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        InviterID query int false "Inviter ID"
+// @Param        Status query string false "Status"
+// @Router       /business/:businessID/referrals [get]
+func (_i *controller) Index(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.Referrals
+	req.BusinessID = businessID
+	req.Pagination = paginate
+	req.InviterID, _ = utils.GetUintInQueries(c, "InviterID")
+	req.Status = schema.ReferralStatus(c.Query("Status"))
+
+	referrals, paging, err := _i.service.Index(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: referrals,
+		Meta: paging,
+	})
+}
+
+// Leaderboard of inviters
+// @Summary      Get referral leaderboard of the business
+// @Tags         Referrals
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        StartTime query string false "StartTime"
+// @Param        EndTime query string false "EndTime"
+// @Router       /business/:businessID/referrals/leaderboard [get]
+func (_i *controller) Leaderboard(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.Leaderboard
+	req.BusinessID = businessID
+	req.Pagination = paginate
+	req.StartTime = utils.GetDateInQueries(c, "StartTime")
+	req.EndTime = utils.GetDateInQueries(c, "EndTime")
+
+	items, paging, err := _i.service.Leaderboard(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: items,
+		Meta: paging,
+	})
+}
+
+// MyReferral
+// @Summary      Get referral code and stats of the authenticated user
+// @Tags         Referrals
+// @Security     Bearer
+// @Router       /user/referral [get]
+func (_i *controller) MyReferral(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	referral, err := _i.service.MyReferral(user.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: referral,
+	})
+}
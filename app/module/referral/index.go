@@ -0,0 +1,48 @@
+package referral
+
+import (
+	"github.com/gofiber/fiber/v2"
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/referral/controller"
+	"go-fiber-starter/app/module/referral/repository"
+	"go-fiber-starter/app/module/referral/service"
+	"go-fiber-starter/utils/config"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes
+	_i.App.Route("/v1/business/:businessID/referrals", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReferral, mdl.PReadAll), c.Index)
+		router.Get("/leaderboard", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReferral, mdl.PReadAll), c.Leaderboard)
+	})
+
+	_i.App.Route("/v1/user/referral", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), c.MyReferral)
+	})
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+)
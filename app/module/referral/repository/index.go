@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/referral/request"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IRepository interface {
+	GetAll(req request.Referrals) (referrals []*schema.Referral, paging paginator.Pagination, err error)
+	GetPendingByInvitee(inviteeID uint64) (referral *schema.Referral, err error)
+	ExistsForMobile(mobile uint64) (exists bool, err error)
+	CountByInviter(inviterID uint64) (invited int64, rewarded int64, err error)
+	CountRewardedInBusiness(inviterID uint64, businessID uint64, tx *gorm.DB) (count int64, err error)
+	Leaderboard(req request.Leaderboard) (rows []*LeaderboardRow, paging paginator.Pagination, err error)
+	Create(referral *schema.Referral, tx *gorm.DB) (err error)
+	Update(id uint64, referral *schema.Referral, tx *gorm.DB) (err error)
+	Convert(id uint64, status schema.ReferralStatus, businessID uint64, orderID uint64, tx *gorm.DB) (converted bool, err error)
+	LockInviter(inviterID uint64, tx *gorm.DB) (err error)
+	BeginTransaction() (*gorm.DB, error)
+
+	GetUserByID(id uint64) (user *schema.User, err error)
+	GetUserByReferralCode(code string) (user *schema.User, err error)
+	SetUserReferralCode(userID uint64, code string) (saved string, err error)
+}
+
+type LeaderboardRow struct {
+	InviterID      uint64
+	FirstName      string
+	LastName       string
+	Mobile         uint64
+	ConvertedCount int64
+	RewardedCount  int64
+	TotalReward    float64
+}
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+func (_i *repo) GetAll(req request.Referrals) (referrals []*schema.Referral, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.
+		Model(&schema.Referral{}).
+		Where(
+			"referrals.business_id = ? OR (referrals.business_id IS NULL AND referrals.inviter_id IN (SELECT user_id FROM business_users WHERE business_id = ?))",
+			req.BusinessID, req.BusinessID,
+		)
+
+	if req.InviterID > 0 {
+		query.Where("referrals.inviter_id = ?", req.InviterID)
+	}
+
+	if req.Status != "" {
+		query.Where("referrals.status = ?", req.Status)
+	}
+
+	if req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.
+		Preload("Inviter").
+		Preload("Invitee").
+		Order("created_at desc").
+		Find(&referrals).Error
+	if err != nil {
+		return
+	}
+
+	paging = *req.Pagination
+
+	return
+}
+
+func (_i *repo) GetPendingByInvitee(inviteeID uint64) (referral *schema.Referral, err error) {
+	err = _i.DB.Main.
+		Where(&schema.Referral{InviteeID: inviteeID, Status: schema.ReferralStatusPending}).
+		First(&referral).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return referral, nil
+}
+
+func (_i *repo) ExistsForMobile(mobile uint64) (exists bool, err error) {
+	var count int64
+	err = _i.DB.Main.
+		Unscoped().
+		Model(&schema.Referral{}).
+		Where("invitee_mobile = ?", mobile).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+func (_i *repo) CountByInviter(inviterID uint64) (invited int64, rewarded int64, err error) {
+	err = _i.DB.Main.
+		Model(&schema.Referral{}).
+		Where("inviter_id = ?", inviterID).
+		Count(&invited).Error
+	if err != nil {
+		return
+	}
+
+	err = _i.DB.Main.
+		Model(&schema.Referral{}).
+		Where("inviter_id = ? AND status = ?", inviterID, schema.ReferralStatusRewarded).
+		Count(&rewarded).Error
+
+	return
+}
+
+func (_i *repo) CountRewardedInBusiness(inviterID uint64, businessID uint64, tx *gorm.DB) (count int64, err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+	err = tx.
+		Model(&schema.Referral{}).
+		Where("inviter_id = ? AND business_id = ? AND status = ?", inviterID, businessID, schema.ReferralStatusRewarded).
+		Count(&count).Error
+
+	return
+}
+
+func (_i *repo) Leaderboard(req request.Leaderboard) (rows []*LeaderboardRow, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.
+		Model(&schema.Referral{}).
+		Joins("JOIN users ON users.id = referrals.inviter_id").
+		Where("referrals.business_id = ?", req.BusinessID)
+
+	if req.StartTime != nil && !req.StartTime.IsZero() {
+		query.Where("referrals.updated_at >= ?", utils.StartOfDayString(*req.StartTime))
+	}
+
+	if req.EndTime != nil && !req.EndTime.IsZero() {
+		query.Where("referrals.updated_at <= ?", utils.EndOfDayString(*req.EndTime))
+	}
+
+	query.
+		Select(`referrals.inviter_id, users.first_name, users.last_name, users.mobile,
+			COUNT(*) AS converted_count,
+			COUNT(*) FILTER (WHERE referrals.status = ?) AS rewarded_count,
+			COALESCE(SUM(CAST(referrals.meta->>'InviterReward' AS NUMERIC)) FILTER (WHERE referrals.status = ?), 0) AS total_reward`,
+			schema.ReferralStatusRewarded, schema.ReferralStatusRewarded,
+		).
+		Group("referrals.inviter_id, users.first_name, users.last_name, users.mobile")
+
+	if req.Pagination.Page > 0 {
+		var total int64
+		_i.DB.Main.Table("(?) AS leaderboard", query).Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.Order("rewarded_count desc, converted_count desc").Scan(&rows).Error
+	if err != nil {
+		return
+	}
+
+	paging = *req.Pagination
+
+	return
+}
+
+func (_i *repo) Create(referral *schema.Referral, tx *gorm.DB) (err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+	return tx.Create(referral).Error
+}
+
+func (_i *repo) Update(id uint64, referral *schema.Referral, tx *gorm.DB) (err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+	return tx.Model(&schema.Referral{}).
+		Where(&schema.Referral{ID: id}).
+		Updates(referral).Error
+}
+
+// Convert moves a pending referral to its final status, it reports false when
+// another order has already converted it.
+func (_i *repo) Convert(id uint64, status schema.ReferralStatus, businessID uint64, orderID uint64, tx *gorm.DB) (converted bool, err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+	result := tx.Model(&schema.Referral{}).
+		Where("id = ? AND status = ?", id, schema.ReferralStatusPending).
+		Updates(&schema.Referral{Status: status, BusinessID: &businessID, OrderID: &orderID})
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// LockInviter locks the inviter's row until the transaction ends, so the rewards
+// of two invitees converting at once are counted against the cap one after the other.
+func (_i *repo) LockInviter(inviterID uint64, tx *gorm.DB) (err error) {
+	var user schema.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&user, inviterID).Error
+}
+
+func (_i *repo) BeginTransaction() (*gorm.DB, error) {
+	tx := _i.DB.Main.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx, nil
+}
+
+func (_i *repo) GetUserByID(id uint64) (user *schema.User, err error) {
+	if err := _i.DB.Main.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (_i *repo) GetUserByReferralCode(code string) (user *schema.User, err error) {
+	if err := _i.DB.Main.Where("referral_code = ?", code).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// SetUserReferralCode sets the code when the user has none yet and returns the code the user ends up with,
+// which is the one of another request when it set its code first.
+func (_i *repo) SetUserReferralCode(userID uint64, code string) (saved string, err error) {
+	result := _i.DB.Main.Model(&schema.User{}).
+		Where("id = ? AND referral_code IS NULL", userID).
+		Update("referral_code", code)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected > 0 {
+		return code, nil
+	}
+
+	user, err := _i.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.ReferralCode == nil {
+		return "", gorm.ErrRecordNotFound
+	}
+
+	return *user.ReferralCode, nil
+}
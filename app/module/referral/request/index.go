@@ -0,0 +1,21 @@
+package request
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/paginator"
+	"time"
+)
+
+type Referrals struct {
+	BusinessID uint64
+	InviterID  uint64
+	Status     schema.ReferralStatus
+	Pagination *paginator.Pagination
+}
+
+type Leaderboard struct {
+	BusinessID uint64
+	StartTime  *time.Time
+	EndTime    *time.Time
+	Pagination *paginator.Pagination
+}
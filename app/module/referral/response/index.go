@@ -0,0 +1,62 @@
+package response
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"time"
+)
+
+type Referral struct {
+	ID            uint64                `json:",omitempty"`
+	InviterID     uint64                `json:",omitempty"`
+	InviterName   string                `json:",omitempty"`
+	InviteeID     uint64                `json:",omitempty"`
+	InviteeName   string                `json:",omitempty"`
+	InviteeMobile uint64                `json:",omitempty"`
+	Status        schema.ReferralStatus `json:",omitempty"`
+	StatusDisplay string                `json:",omitempty"`
+	OrderID       *uint64               `json:",omitempty"`
+	Meta          schema.ReferralMeta   `json:",omitempty"`
+	CreatedAt     time.Time             `json:",omitempty"`
+}
+
+type MyReferral struct {
+	Code          string
+	InvitedCount  int64
+	RewardedCount int64
+}
+
+type LeaderboardItem struct {
+	InviterID      uint64
+	FullName       string
+	Mobile         uint64
+	ConvertedCount int64
+	RewardedCount  int64
+	TotalReward    float64
+}
+
+func FromDomain(item *schema.Referral) (res *Referral) {
+	if item == nil {
+		return nil
+	}
+
+	res = &Referral{
+		ID:            item.ID,
+		Meta:          item.Meta,
+		Status:        item.Status,
+		OrderID:       item.OrderID,
+		InviterID:     item.InviterID,
+		InviteeID:     item.InviteeID,
+		CreatedAt:     item.CreatedAt,
+		InviteeMobile: item.InviteeMobile,
+		StatusDisplay: schema.ReferralStatusProxy[item.Status],
+	}
+
+	if item.Inviter.ID != 0 {
+		res.InviterName = item.Inviter.FullName()
+	}
+	if item.Invitee.ID != 0 {
+		res.InviteeName = item.Invitee.FullName()
+	}
+
+	return res
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	businessRepo "go-fiber-starter/app/module/business/repository"
+	couponRequest "go-fiber-starter/app/module/coupon/request"
+	couponService "go-fiber-starter/app/module/coupon/service"
+	"go-fiber-starter/app/module/referral/repository"
+	"go-fiber-starter/app/module/referral/request"
+	"go-fiber-starter/app/module/referral/response"
+	transactionRepo "go-fiber-starter/app/module/transaction/repository"
+	walletService "go-fiber-starter/app/module/wallet/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type IService interface {
+	Index(req request.Referrals) (referrals []*response.Referral, paging paginator.Pagination, err error)
+	Leaderboard(req request.Leaderboard) (items []*response.LeaderboardItem, paging paginator.Pagination, err error)
+	MyReferral(userID uint64) (res *response.MyReferral, err error)
+
+	ValidateCode(code string, inviteeMobile uint64) (inviter *schema.User, err error)
+	Attach(inviter *schema.User, invitee *schema.User, tx *gorm.DB) (err error)
+	RewardFirstOrder(userID uint64, businessID uint64, orderID uint64, orderTotalAmt float64) (err error)
+}
+
+func Service(
+	repo repository.IRepository,
+	businessRepo businessRepo.IRepository,
+	couponService couponService.IService,
+	walletService walletService.IService,
+	transactionRepo transactionRepo.IRepository,
+) IService {
+	return &service{
+		repo,
+		businessRepo,
+		couponService,
+		walletService,
+		transactionRepo,
+	}
+}
+
+type service struct {
+	Repo            repository.IRepository
+	BusinessRepo    businessRepo.IRepository
+	CouponService   couponService.IService
+	WalletService   walletService.IService
+	TransactionRepo transactionRepo.IRepository
+}
+
+func (_i *service) Index(req request.Referrals) (referrals []*response.Referral, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetAll(req)
+	if err != nil {
+		return
+	}
+
+	for _, result := range results {
+		referrals = append(referrals, response.FromDomain(result))
+	}
+
+	return
+}
+
+func (_i *service) Leaderboard(req request.Leaderboard) (items []*response.LeaderboardItem, paging paginator.Pagination, err error) {
+	rows, paging, err := _i.Repo.Leaderboard(req)
+	if err != nil {
+		return
+	}
+
+	for _, row := range rows {
+		items = append(items, &response.LeaderboardItem{
+			InviterID:      row.InviterID,
+			FullName:       fmt.Sprintf("%s %s", row.FirstName, row.LastName),
+			Mobile:         row.Mobile,
+			ConvertedCount: row.ConvertedCount,
+			RewardedCount:  row.RewardedCount,
+			TotalReward:    row.TotalReward,
+		})
+	}
+
+	return
+}
+
+func (_i *service) MyReferral(userID uint64) (res *response.MyReferral, err error) {
+	code, err := _i.getOrCreateCode(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invited, rewarded, err := _i.Repo.CountByInviter(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.MyReferral{Code: code, InvitedCount: invited, RewardedCount: rewarded}, nil
+}
+
+func (_i *service) ValidateCode(code string, inviteeMobile uint64) (inviter *schema.User, err error) {
+	inviter, err = _i.Repo.GetUserByReferralCode(strings.ToLower(strings.TrimSpace(code)))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "کد معرف معتبر نمی باشد"}
+		}
+		return nil, err
+	}
+
+	if inviter.Mobile == inviteeMobile {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "امکان استفاده از کد معرف خودتان وجود ندارد"}
+	}
+
+	exists, err := _i.Repo.ExistsForMobile(inviteeMobile)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "برای این شماره موبایل قبلا کد معرف ثبت شده است"}
+	}
+
+	return inviter, nil
+}
+
+// Attach saves the pending referral of the invitee, in the transaction that creates the invitee.
+func (_i *service) Attach(inviter *schema.User, invitee *schema.User, tx *gorm.DB) (err error) {
+	if inviter.ID == invitee.ID {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "امکان استفاده از کد معرف خودتان وجود ندارد"}
+	}
+
+	return _i.Repo.Create(&schema.Referral{
+		InviterID:     inviter.ID,
+		InviteeID:     invitee.ID,
+		InviteeMobile: invitee.Mobile,
+		Status:        schema.ReferralStatusPending,
+	}, tx)
+}
+
+// RewardFirstOrder pays the referral rewards of the invitee's first completed order
+// in a business that has the referral program enabled.
+func (_i *service) RewardFirstOrder(userID uint64, businessID uint64, orderID uint64, orderTotalAmt float64) (err error) {
+	referral, err := _i.Repo.GetPendingByInvitee(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	business, err := _i.BusinessRepo.GetOne(businessID)
+	if err != nil {
+		return err
+	}
+
+	config := business.Meta.Referral
+	if !config.Enabled || orderTotalAmt < config.MinOrderAmount {
+		return nil
+	}
+
+	// the claim and the rewards are saved together, a failed reward leaves the referral pending for the next order
+	tx, err := _i.Repo.BeginTransaction()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err = _i.Repo.LockInviter(referral.InviterID, tx); err != nil {
+		return err
+	}
+
+	rewardedCount, err := _i.Repo.CountRewardedInBusiness(referral.InviterID, businessID, tx)
+	if err != nil {
+		return err
+	}
+
+	status := schema.ReferralStatusRewarded
+	if config.MaxRewardsPerInviter > 0 && rewardedCount >= int64(config.MaxRewardsPerInviter) {
+		status = schema.ReferralStatusCapped
+	}
+
+	claimed, err := _i.Repo.Convert(referral.ID, status, businessID, orderID, tx)
+	if err != nil || !claimed {
+		return err
+	}
+
+	now := time.Now()
+	meta := schema.ReferralMeta{RewardType: config.RewardType, RewardedAt: &now, InviteeReward: config.InviteeReward}
+
+	if meta.InviteeCouponCode, err = _i.reward(business, userID, config.InviteeReward, tx); err != nil {
+		return err
+	}
+
+	if status == schema.ReferralStatusRewarded {
+		meta.InviterReward = config.InviterReward
+		if meta.InviterCouponCode, err = _i.reward(business, referral.InviterID, config.InviterReward, tx); err != nil {
+			return err
+		}
+	}
+
+	if err = _i.Repo.Update(referral.ID, &schema.Referral{Meta: meta}, tx); err != nil {
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// reward credits the user's wallet or issues a single use coupon, based on the business config,
+// and returns the coupon code when one is issued.
+func (_i *service) reward(business *schema.Business, userID uint64, amount float64, tx *gorm.DB) (couponCode string, err error) {
+	if amount <= 0 {
+		return "", nil
+	}
+
+	config := business.Meta.Referral
+	if config.RewardType == schema.ReferralRewardTypeWallet {
+		wallet, err := _i.WalletService.GetOrCreateWallet(&userID, nil, tx)
+		if err != nil {
+			return "", err
+		}
+
+		if err = _i.WalletService.AddAmount(wallet.ID, amount, tx); err != nil {
+			return "", err
+		}
+
+		return "", _i.TransactionRepo.Create(&schema.Transaction{
+			Amount:             amount,
+			UserID:             userID,
+			WalletID:           wallet.ID,
+			Description:        "پاداش دعوت از دوستان",
+			OrderPaymentMethod: schema.OrderPaymentMethodWallet,
+			Status:             schema.TransactionStatusSuccess,
+		}, tx)
+	}
+
+	validDays := config.CouponValidDays
+	if validDays <= 0 {
+		validDays = 30
+	}
+
+	code := "ref" + utils.GenerateRandomString(6)
+	for i := 0; i < 30; i++ {
+		_coupon, err := _i.CouponService.Show(business.ID, nil, &code)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", err
+		}
+
+		if _coupon == nil {
+			break
+		}
+		code = "ref" + utils.GenerateRandomString(6)
+	}
+
+	startTime := timezone.ForBusiness(business.ID).Today()
+	endTime := startTime.AddDate(0, 0, validDays).Add(-time.Second)
+
+	err = _i.CouponService.Store(couponRequest.Coupon{
+		Code:       code,
+		BusinessID: business.ID,
+		Value:      amount,
+		Type:       schema.CouponTypeFixedAmount,
+		Title:      "هدیه دعوت از دوستان",
+		StartTime:  startTime.Format(time.DateTime),
+		EndTime:    endTime.Format(time.DateTime),
+		Meta: schema.CouponMeta{
+			MaxUsage:       1,
+			IncludeUserIDs: []uint64{userID},
+		},
+	}, tx)
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+func (_i *service) getOrCreateCode(userID uint64) (code string, err error) {
+	user, err := _i.Repo.GetUserByID(userID)
+	if err != nil {
+		return "", err
+	}
+
+	if user.ReferralCode != nil {
+		return *user.ReferralCode, nil
+	}
+
+	// the users get their code when they are created or migrated, this is for any that slipped through.
+	// retry on the rare unique index collision
+	for i := 0; i < 10; i++ {
+		if code, err = _i.Repo.SetUserReferralCode(userID, schema.NewReferralCode()); err == nil {
+			return code, nil
+		}
+	}
+
+	return "", err
+}
@@ -0,0 +1,288 @@
+package test
+
+import (
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/referral/request"
+	"go-fiber-starter/utils/paginator"
+)
+
+var walletReferral = schema.BusinessMetaReferral{
+	Enabled:        true,
+	RewardType:     schema.ReferralRewardTypeWallet,
+	InviterReward:  20000,
+	InviteeReward:  10000,
+	MinOrderAmount: 5000,
+}
+
+func TestRewardFirstOrder_WalletRewardsBoth(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	inviter := ta.CreateTestUser(t, 9120000001, "Inviter")
+	invitee := ta.CreateTestUser(t, 9120000002, "Invitee")
+	business := ta.CreateTestBusiness(t, inviter.ID, walletReferral)
+	ta.Invite(t, inviter, invitee)
+
+	if err := ta.Service.RewardFirstOrder(invitee.ID, business.ID, 1, 6000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	referral := ta.Referral(t, invitee.ID)
+	if referral.Status != schema.ReferralStatusRewarded {
+		t.Errorf("expected the referral to be rewarded, got %s", referral.Status)
+	}
+	if referral.BusinessID == nil || *referral.BusinessID != business.ID || referral.OrderID == nil || *referral.OrderID != 1 {
+		t.Errorf("expected the business and the order to be saved on the referral, got %+v", referral)
+	}
+	if referral.Meta.InviterReward != 20000 || referral.Meta.InviteeReward != 10000 {
+		t.Errorf("expected the rewards to be saved in the meta, got %+v", referral.Meta)
+	}
+
+	if amount := ta.WalletAmount(t, inviter.ID); amount != 20000 {
+		t.Errorf("expected the inviter wallet to be 20000, got %v", amount)
+	}
+	if amount := ta.WalletAmount(t, invitee.ID); amount != 10000 {
+		t.Errorf("expected the invitee wallet to be 10000, got %v", amount)
+	}
+
+	var transactions int64
+	ta.DB.Model(&schema.Transaction{}).Count(&transactions)
+	if transactions != 2 {
+		t.Errorf("expected a transaction for each reward, got %d", transactions)
+	}
+}
+
+func TestRewardFirstOrder_CouponRewards(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	config := walletReferral
+	config.RewardType = schema.ReferralRewardTypeCoupon
+
+	inviter := ta.CreateTestUser(t, 9120000001, "Inviter")
+	invitee := ta.CreateTestUser(t, 9120000002, "Invitee")
+	business := ta.CreateTestBusiness(t, inviter.ID, config)
+	ta.Invite(t, inviter, invitee)
+
+	if err := ta.Service.RewardFirstOrder(invitee.ID, business.ID, 1, 6000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	referral := ta.Referral(t, invitee.ID)
+	for userID, code := range map[uint64]string{inviter.ID: referral.Meta.InviterCouponCode, invitee.ID: referral.Meta.InviteeCouponCode} {
+		var coupon schema.Coupon
+		if err := ta.DB.Where("business_id = ? AND code = ?", business.ID, code).First(&coupon).Error; err != nil {
+			t.Fatalf("expected the coupon %q to be issued: %v", code, err)
+		}
+		if coupon.Meta.MaxUsage != 1 || len(coupon.Meta.IncludeUserIDs) != 1 || coupon.Meta.IncludeUserIDs[0] != userID {
+			t.Errorf("expected a single use coupon of the user %d, got %+v", userID, coupon.Meta)
+		}
+	}
+}
+
+func TestRewardFirstOrder_BelowTheMinimumStaysPending(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	inviter := ta.CreateTestUser(t, 9120000001, "Inviter")
+	invitee := ta.CreateTestUser(t, 9120000002, "Invitee")
+	business := ta.CreateTestBusiness(t, inviter.ID, walletReferral)
+	ta.Invite(t, inviter, invitee)
+
+	if err := ta.Service.RewardFirstOrder(invitee.ID, business.ID, 1, 4000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if referral := ta.Referral(t, invitee.ID); referral.Status != schema.ReferralStatusPending {
+		t.Errorf("expected the referral to stay pending, got %s", referral.Status)
+	}
+	if amount := ta.WalletAmount(t, invitee.ID); amount != 0 {
+		t.Errorf("expected no reward, got %v", amount)
+	}
+}
+
+func TestRewardFirstOrder_RewardsOnlyOnce(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	inviter := ta.CreateTestUser(t, 9120000001, "Inviter")
+	invitee := ta.CreateTestUser(t, 9120000002, "Invitee")
+	business := ta.CreateTestBusiness(t, inviter.ID, walletReferral)
+	ta.Invite(t, inviter, invitee)
+
+	for orderID := uint64(1); orderID <= 2; orderID++ {
+		if err := ta.Service.RewardFirstOrder(invitee.ID, business.ID, orderID, 6000); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if amount := ta.WalletAmount(t, invitee.ID); amount != 10000 {
+		t.Errorf("expected the invitee to be rewarded once, got %v", amount)
+	}
+	if referral := ta.Referral(t, invitee.ID); *referral.OrderID != 1 {
+		t.Errorf("expected the first order to be kept, got %d", *referral.OrderID)
+	}
+}
+
+func TestRewardFirstOrder_CapsTheInviter(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	config := walletReferral
+	config.MaxRewardsPerInviter = 1
+
+	inviter := ta.CreateTestUser(t, 9120000001, "Inviter")
+	first := ta.CreateTestUser(t, 9120000002, "First")
+	second := ta.CreateTestUser(t, 9120000003, "Second")
+	business := ta.CreateTestBusiness(t, inviter.ID, config)
+	ta.Invite(t, inviter, first)
+	ta.Invite(t, inviter, second)
+
+	if err := ta.Service.RewardFirstOrder(first.ID, business.ID, 1, 6000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ta.Service.RewardFirstOrder(second.ID, business.ID, 2, 6000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	referral := ta.Referral(t, second.ID)
+	if referral.Status != schema.ReferralStatusCapped {
+		t.Errorf("expected the second referral to be capped, got %s", referral.Status)
+	}
+	if referral.Meta.InviterReward != 0 {
+		t.Errorf("expected no inviter reward on the capped referral, got %v", referral.Meta.InviterReward)
+	}
+	if amount := ta.WalletAmount(t, inviter.ID); amount != 20000 {
+		t.Errorf("expected the inviter to be rewarded once, got %v", amount)
+	}
+	if amount := ta.WalletAmount(t, second.ID); amount != 10000 {
+		t.Errorf("expected the capped invitee to be rewarded, got %v", amount)
+	}
+}
+
+func TestRewardFirstOrder_FailedRewardKeepsTheReferralPending(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	inviter := ta.CreateTestUser(t, 9120000001, "Inviter")
+	invitee := ta.CreateTestUser(t, 9120000002, "Invitee")
+	business := ta.CreateTestBusiness(t, inviter.ID, walletReferral)
+	ta.Invite(t, inviter, invitee)
+
+	// the transaction records of the rewards can not be written
+	ta.DB.Exec("ALTER TABLE transactions RENAME TO transactions_off")
+	defer ta.DB.Exec("ALTER TABLE transactions_off RENAME TO transactions")
+
+	if err := ta.Service.RewardFirstOrder(invitee.ID, business.ID, 1, 6000); err == nil {
+		t.Fatal("expected the reward to fail")
+	}
+
+	if referral := ta.Referral(t, invitee.ID); referral.Status != schema.ReferralStatusPending {
+		t.Errorf("expected the referral to stay pending, got %s", referral.Status)
+	}
+	if amount := ta.WalletAmount(t, invitee.ID); amount != 0 {
+		t.Errorf("expected the wallet credit to be rolled back, got %v", amount)
+	}
+}
+
+func TestLeaderboard_OrdersByTheRewards(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	config := walletReferral
+	config.MaxRewardsPerInviter = 2
+
+	top := ta.CreateTestUser(t, 9120000001, "Top")
+	other := ta.CreateTestUser(t, 9120000002, "Other")
+	business := ta.CreateTestBusiness(t, top.ID, config)
+
+	invitees := map[*schema.User][]uint64{top: {9120000011, 9120000012, 9120000013}, other: {9120000021}}
+	orderID := uint64(0)
+	for inviter, mobiles := range invitees {
+		for _, mobile := range mobiles {
+			invitee := ta.CreateTestUser(t, mobile, "Invitee")
+			ta.Invite(t, inviter, invitee)
+
+			orderID++
+			if err := ta.Service.RewardFirstOrder(invitee.ID, business.ID, orderID, 6000); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	items, _, err := ta.Service.Leaderboard(request.Leaderboard{BusinessID: business.ID, Pagination: &paginator.Pagination{}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected two inviters, got %d", len(items))
+	}
+
+	first := items[0]
+	if first.InviterID != top.ID || first.ConvertedCount != 3 || first.RewardedCount != 2 || first.TotalReward != 40000 {
+		t.Errorf("expected the capped rewards to be left out of the top inviter, got %+v", first)
+	}
+	if second := items[1]; second.InviterID != other.ID || second.RewardedCount != 1 || second.TotalReward != 20000 {
+		t.Errorf("unexpected second inviter: %+v", second)
+	}
+}
+
+func TestSetUserReferralCode_KeepsTheFirstCode(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	user := ta.CreateTestUser(t, 9120000001, "User")
+
+	first, err := ta.Repo.SetUserReferralCode(user.ID, "firstcod")
+	if err != nil || first != "firstcod" {
+		t.Fatalf("expected the code to be set, got %q, %v", first, err)
+	}
+
+	// a request that lost the race gets the saved code instead of its own
+	second, err := ta.Repo.SetUserReferralCode(user.ID, "secondco")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second != "firstcod" {
+		t.Errorf("expected the first code, got %q", second)
+	}
+
+	res, err := ta.Service.MyReferral(user.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Code != "firstcod" {
+		t.Errorf("expected the saved code, got %q", res.Code)
+	}
+}
+
+func TestBackfillReferralCodes_GivesEveryUserACode(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	coded := ta.CreateTestUser(t, 9120000001, "Coded")
+	if _, err := ta.Repo.SetUserReferralCode(coded.ID, "keptcode"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ta.CreateTestUser(t, 9120000002, "First")
+	ta.CreateTestUser(t, 9120000003, "Second")
+
+	if err := ta.DB.Transaction(schema.BackfillReferralCodes); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var users []schema.User
+	ta.DB.Order("id").Find(&users)
+	codes := map[string]bool{}
+	for _, user := range users {
+		if user.ReferralCode == nil {
+			t.Fatalf("expected the user %d to get a code", user.ID)
+		}
+		codes[*user.ReferralCode] = true
+	}
+	if *users[0].ReferralCode != "keptcode" || len(codes) != 3 {
+		t.Errorf("expected the code of the first user to be kept and the others to be distinct, got %v", codes)
+	}
+}
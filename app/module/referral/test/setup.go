@@ -0,0 +1,271 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	businessRepo "go-fiber-starter/app/module/business/repository"
+	couponRepo "go-fiber-starter/app/module/coupon/repository"
+	couponService "go-fiber-starter/app/module/coupon/service"
+	outboxRepo "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/app/module/referral/repository"
+	"go-fiber-starter/app/module/referral/service"
+	transactionRepo "go-fiber-starter/app/module/transaction/repository"
+	userRepo "go-fiber-starter/app/module/user/repository"
+	userService "go-fiber-starter/app/module/user/service"
+	walletRepo "go-fiber-starter/app/module/wallet/repository"
+	walletService "go-fiber-starter/app/module/wallet/service"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/utils/config"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// TestApp holds the referral service on the test database
+type TestApp struct {
+	DB      *gorm.DB
+	Config  *config.Config
+	Cleanup func()
+	Repo    repository.IRepository
+	Service service.IService
+}
+
+// getProjectRoot returns the project root directory
+func getProjectRoot() string {
+	_, b, _, _ := runtime.Caller(0)
+	// Navigate from app/module/referral/test/ to project root
+	return filepath.Join(filepath.Dir(b), "..", "..", "..", "..")
+}
+
+// migrateTestModels creates the tables the rewards are written to
+func migrateTestModels(db *gorm.DB) error {
+	for _, table := range []string{"coupons", "transactions", "wallets", "referrals", "businesses", "users"} {
+		db.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", table))
+	}
+
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS users (
+			id BIGSERIAL PRIMARY KEY,
+			first_name VARCHAR(255),
+			last_name VARCHAR(255),
+			mobile BIGINT NOT NULL UNIQUE,
+			mobile_confirmed BOOLEAN DEFAULT FALSE,
+			show_mobile BOOLEAN,
+			is_suspended BOOLEAN DEFAULT FALSE,
+			suspense_reason VARCHAR(500),
+			permissions JSONB NOT NULL DEFAULT '{}',
+			password VARCHAR(255) NOT NULL,
+			city_id BIGINT,
+			workspace_id BIGINT,
+			dormitory_id BIGINT,
+			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
+			meta JSONB,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)`,
+		`CREATE TABLE IF NOT EXISTS businesses (
+			id BIGSERIAL PRIMARY KEY,
+			title VARCHAR(255) NOT NULL,
+			type VARCHAR(255) NOT NULL,
+			owner_id BIGINT NOT NULL,
+			account VARCHAR(100) DEFAULT 'default',
+			meta JSONB,
+			description VARCHAR(500),
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)`,
+		`CREATE TABLE IF NOT EXISTS referrals (
+			id BIGSERIAL PRIMARY KEY,
+			inviter_id BIGINT NOT NULL,
+			invitee_id BIGINT NOT NULL UNIQUE,
+			invitee_mobile BIGINT NOT NULL UNIQUE,
+			status VARCHAR(50) NOT NULL DEFAULT 'pending',
+			business_id BIGINT,
+			order_id BIGINT,
+			meta JSONB,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)`,
+		`CREATE TABLE IF NOT EXISTS wallets (
+			id BIGSERIAL PRIMARY KEY,
+			amount FLOAT DEFAULT 0,
+			user_id BIGINT,
+			business_id BIGINT,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)`,
+		`CREATE TABLE IF NOT EXISTS transactions (
+			id BIGSERIAL PRIMARY KEY,
+			amount FLOAT NOT NULL,
+			status VARCHAR(20) DEFAULT 'pending' NOT NULL,
+			description VARCHAR(255) NOT NULL DEFAULT '',
+			order_payment_method VARCHAR(20) DEFAULT 'online' NOT NULL,
+			gateway_transaction_id VARCHAR(255),
+			wallet_id BIGINT,
+			order_id BIGINT,
+			user_id BIGINT NOT NULL,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)`,
+		`CREATE TABLE IF NOT EXISTS coupons (
+			id BIGSERIAL PRIMARY KEY,
+			code VARCHAR(255) NOT NULL,
+			title VARCHAR(255) NOT NULL,
+			description VARCHAR(500),
+			value FLOAT NOT NULL,
+			type VARCHAR(50) NOT NULL,
+			start_time TIMESTAMPTZ NOT NULL,
+			end_time TIMESTAMPTZ NOT NULL,
+			times_used INT DEFAULT 0,
+			business_id BIGINT NOT NULL,
+			meta JSONB,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ,
+			UNIQUE(code, business_id)
+		)`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetupTestApp initializes the referral service with a test database
+func SetupTestApp(t *testing.T) *TestApp {
+	t.Helper()
+
+	cfg, err := config.ParseConfig(filepath.Join(getProjectRoot(), "config", "zciti-test.toml"), true)
+	if err != nil {
+		t.Fatalf("failed to load test config: %v", err)
+	}
+
+	logger := zerolog.Nop()
+
+	dbWrapper := database.NewDatabase(cfg, logger)
+	dbWrapper.ConnectDatabase()
+	if dbWrapper.Main == nil {
+		t.Fatalf("failed to connect to test database")
+	}
+
+	if err := migrateTestModels(dbWrapper.Main); err != nil {
+		t.Fatalf("failed to migrate test models: %v", err)
+	}
+
+	smsSvc := sms.NewService(cfg, logger, nil, dbWrapper)
+	outboxSvc := outboxService.Service(outboxRepo.Repository(dbWrapper), smsSvc, cfg)
+	couponSvc := couponService.Service(couponRepo.Repository(dbWrapper), userService.Service(userRepo.Repository(dbWrapper)), outboxSvc)
+
+	repo := repository.Repository(dbWrapper)
+	svc := service.Service(
+		repo,
+		businessRepo.Repository(dbWrapper),
+		couponSvc,
+		walletService.Service(walletRepo.Repository(dbWrapper)),
+		transactionRepo.Repository(dbWrapper),
+	)
+
+	cleanup := func() {
+		for _, table := range []string{"coupons", "transactions", "wallets", "referrals", "businesses", "users"} {
+			dbWrapper.Main.Exec(fmt.Sprintf("DELETE FROM %s", table))
+		}
+		dbWrapper.ShutdownDatabase()
+	}
+
+	return &TestApp{
+		DB:      dbWrapper.Main,
+		Config:  cfg,
+		Cleanup: cleanup,
+		Repo:    repo,
+		Service: svc,
+	}
+}
+
+// CreateTestUser creates a user with the mobile
+func (ta *TestApp) CreateTestUser(t *testing.T, mobile uint64, firstName string) *schema.User {
+	t.Helper()
+
+	user := &schema.User{
+		FirstName:   firstName,
+		LastName:    "Test",
+		Mobile:      mobile,
+		Password:    "hashed",
+		Permissions: schema.UserPermissions{},
+	}
+	if err := ta.DB.Create(user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	return user
+}
+
+// CreateTestBusiness creates a business with the referral program config
+func (ta *TestApp) CreateTestBusiness(t *testing.T, ownerID uint64, referral schema.BusinessMetaReferral) *schema.Business {
+	t.Helper()
+
+	business := &schema.Business{
+		Title:   "Referral Business",
+		Type:    schema.BTypeWMReservation,
+		OwnerID: ownerID,
+		Account: schema.BusinessAccountDefault,
+		Meta:    schema.BusinessMeta{Referral: referral},
+	}
+	if err := ta.DB.Omit("Owner", "Users").Create(business).Error; err != nil {
+		t.Fatalf("failed to create test business: %v", err)
+	}
+
+	return business
+}
+
+// Invite attaches a pending referral of the invitee to the inviter
+func (ta *TestApp) Invite(t *testing.T, inviter *schema.User, invitee *schema.User) {
+	t.Helper()
+
+	if err := ta.Service.Attach(inviter, invitee, nil); err != nil {
+		t.Fatalf("failed to attach the referral: %v", err)
+	}
+}
+
+// Referral reads the referral of the invitee
+func (ta *TestApp) Referral(t *testing.T, inviteeID uint64) *schema.Referral {
+	t.Helper()
+
+	var referral schema.Referral
+	if err := ta.DB.Where("invitee_id = ?", inviteeID).First(&referral).Error; err != nil {
+		t.Fatalf("failed to read the referral: %v", err)
+	}
+
+	return &referral
+}
+
+// WalletAmount reads the wallet balance of the user, zero when the user has no wallet
+func (ta *TestApp) WalletAmount(t *testing.T, userID uint64) float64 {
+	t.Helper()
+
+	var wallet schema.Wallet
+	err := ta.DB.Where("user_id = ?", userID).First(&wallet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0
+	}
+	if err != nil {
+		t.Fatalf("failed to read the wallet: %v", err)
+	}
+
+	return wallet.Amount
+}
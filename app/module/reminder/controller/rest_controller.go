@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"go-fiber-starter/app/module/reminder/request"
+	"go-fiber-starter/app/module/reminder/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Index(c *fiber.Ctx) error
+	Show(c *fiber.Ctx) error
+	Store(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// Index all ReminderRules
+// @Summary      Get all reminder rules of the business
+// @Tags         ReminderRules
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/reminder-rules [get]
+func (_i *controller) Index(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.ReminderRules
+	req.BusinessID = businessID
+	req.Pagination = paginate
+
+	rules, paging, err := _i.service.Index(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: rules,
+		Meta: paging,
+	})
+}
+
+// Show one ReminderRule
+// @Summary      Get one reminder rule
+// @Tags         ReminderRules
+// @Security     Bearer
+// @Param        id path int true "ReminderRule ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/reminder-rules/:id [get]
+func (_i *controller) Show(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	rule, err := _i.service.Show(businessID, id)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: rule,
+	})
+}
+
+// Store ReminderRule
+// @Summary      Create a reminder rule, e.g. an SMS 5 minutes before the end of the reservations
+// @Tags         ReminderRules
+// @Security     Bearer
+// @Param 		 rule body request.ReminderRule true "ReminderRule details"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/reminder-rules [post]
+func (_i *controller) Store(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	req := new(request.ReminderRule)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	rule, err := _i.service.Store(*req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: rule,
+	})
+}
+
+// Update ReminderRule
+// @Summary      Update a reminder rule
+// @Tags         ReminderRules
+// @Security     Bearer
+// @Param 		 rule body request.ReminderRule true "ReminderRule details"
+// @Param        id path int true "ReminderRule ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/reminder-rules/:id [put]
+func (_i *controller) Update(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	req := new(request.ReminderRule)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	if err = _i.service.Update(id, *req); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
+
+// Delete ReminderRule
+// @Summary      Delete a reminder rule
+// @Tags         ReminderRules
+// @Security     Bearer
+// @Param        id path int true "ReminderRule ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/reminder-rules/:id [delete]
+func (_i *controller) Delete(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.Destroy(businessID, id); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
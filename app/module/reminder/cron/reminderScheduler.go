@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/reminder/service"
+	"go-fiber-starter/internal"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+type ReminderScheduler struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+}
+
+func RunReminderScheduler(
+	logger zerolog.Logger,
+	reminderService service.IService,
+	cronService *internal.CronService,
+) *ReminderScheduler {
+	scheduler := &ReminderScheduler{
+		Logger:   logger,
+		Service:  reminderService,
+		CronSpec: "@every 1m",
+	}
+
+	err := cronService.AddJob(scheduler.CronSpec, scheduler.SendReminders)
+	if err != nil {
+		scheduler.Logger.Fatal().Err(err).Msg("failed to add RunReminderScheduler job")
+	}
+
+	return scheduler
+}
+
+// SendReminders sends the reminders of the rules of the businesses whose time has come
+func (_s *ReminderScheduler) SendReminders() {
+	// only instants are compared, so the timezones of the businesses do not matter here
+	if err := _s.Service.SendDue(time.Now()); err != nil {
+		_s.Logger.Err(err).Msg("Failed to send the reservation reminders")
+	}
+}
@@ -0,0 +1,51 @@
+package reminder
+
+import (
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/reminder/controller"
+	"go-fiber-starter/app/module/reminder/cron"
+	"go-fiber-starter/app/module/reminder/repository"
+	"go-fiber-starter/app/module/reminder/service"
+	"go-fiber-starter/utils/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes
+	_i.App.Route("/v1/business/:businessID/reminder-rules", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.Index)
+		router.Get("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadSingle), c.Show)
+		router.Post("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PCreate), c.Store)
+		router.Put("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PUpdate), c.Update)
+		router.Delete("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PDelete), c.Delete)
+	})
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+
+	fx.Invoke(cron.RunReminderScheduler),
+)
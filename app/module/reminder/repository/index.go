@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/reminder/request"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/paginator"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errAlreadySent rolls back the message of a reminder that another run has already queued
+var errAlreadySent = errors.New("the reminder is already sent")
+
+type IRepository interface {
+	GetAll(req request.ReminderRules) (rules []*schema.ReminderRule, paging paginator.Pagination, err error)
+	GetOne(businessID uint64, id uint64) (rule *schema.ReminderRule, err error)
+	Create(rule *schema.ReminderRule) (err error)
+	Update(id uint64, rule *schema.ReminderRule) (err error)
+	Delete(businessID uint64, id uint64) (err error)
+	GetEnabled() (rules []*schema.ReminderRule, err error)
+	GetDue(rule *schema.ReminderRule, from time.Time, to time.Time) (reservations []*schema.Reservation, err error)
+	MarkSent(reminder *schema.ReservationReminder, message *schema.OutboxMessage, enqueue Enqueue) (queued bool, err error)
+}
+
+// Enqueue queues the messages in the transaction, it is the Enqueue of the outbox service.
+type Enqueue func(tx *gorm.DB, messages ...*schema.OutboxMessage) error
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+func (_i *repo) GetAll(req request.ReminderRules) (rules []*schema.ReminderRule, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.ReminderRule{}).
+		Where(&schema.ReminderRule{BusinessID: req.BusinessID})
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.Order("id asc").Find(&rules).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+func (_i *repo) GetOne(businessID uint64, id uint64) (rule *schema.ReminderRule, err error) {
+	if err = _i.DB.Main.Where(&schema.ReminderRule{BusinessID: businessID}).First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+
+	return rule, nil
+}
+
+func (_i *repo) Create(rule *schema.ReminderRule) (err error) {
+	return _i.DB.Main.Create(rule).Error
+}
+
+// Update saves every field of the rule, so it can be enabled again or its offset set to zero.
+func (_i *repo) Update(id uint64, rule *schema.ReminderRule) (err error) {
+	return _i.DB.Main.Model(&schema.ReminderRule{}).
+		Where(&schema.ReminderRule{ID: id, BusinessID: rule.BusinessID}).
+		Select("title", "trigger", "offset_minutes", "conditions", "channel", "template", "disabled").
+		Updates(rule).Error
+}
+
+func (_i *repo) Delete(businessID uint64, id uint64) (err error) {
+	return _i.DB.Main.Where(&schema.ReminderRule{BusinessID: businessID}).Delete(&schema.ReminderRule{}, id).Error
+}
+
+func (_i *repo) GetEnabled() (rules []*schema.ReminderRule, err error) {
+	err = _i.DB.Main.
+		Where("disabled = ?", false).
+		Order("id asc").
+		Find(&rules).Error
+
+	return
+}
+
+// GetDue finds the reservations of the business of the rule whose reminder time is between from and to
+// and that have not got it yet.
+func (_i *repo) GetDue(rule *schema.ReminderRule, from time.Time, to time.Time) (reservations []*schema.Reservation, err error) {
+	column, start, end := rule.DueBetween(from, to)
+
+	err = _i.DB.Main.Model(&schema.Reservation{}).
+		Where("business_id = ? AND status IN ?", rule.BusinessID, rule.Conditions.ReservationStatuses()).
+		Where("reservations."+column+" > ? AND reservations."+column+" <= ?", start, end).
+		Where("NOT EXISTS (SELECT 1 FROM reservation_reminders WHERE reservation_reminders.reservation_id = reservations.id AND reservation_reminders.rule_id = ?)", rule.ID).
+		Preload("User").
+		Preload("Product.Post").
+		Order("reservations.id asc").
+		Find(&reservations).Error
+
+	return
+}
+
+// MarkSent records the reminder and queues its message together, so a reminder is neither lost nor sent twice.
+// queued is false when the reminder was already recorded, e.g. by another run.
+func (_i *repo) MarkSent(reminder *schema.ReservationReminder, message *schema.OutboxMessage, enqueue Enqueue) (queued bool, err error) {
+	err = _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		if err := enqueue(tx, message); err != nil {
+			return err
+		}
+
+		reminder.MessageID = &message.ID
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reminder)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadySent
+		}
+
+		return nil
+	})
+	if errors.Is(err, errAlreadySent) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
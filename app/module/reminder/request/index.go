@@ -0,0 +1,40 @@
+package request
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/paginator"
+)
+
+type ReminderRules struct {
+	BusinessID uint64
+	Pagination *paginator.Pagination
+}
+
+type ReminderRule struct {
+	BusinessID    uint64
+	Title         string                    `example:"پنج دقیقه تا پایان" validate:"required,max=100"`
+	Trigger       schema.ReminderTrigger    `example:"beforeEnd" validate:"required,oneof=beforeStart afterStart beforeEnd afterEnd"`
+	OffsetMinutes int                       `example:"5" validate:"min=0,max=10080"` // at most a week
+	Conditions    schema.ReminderConditions // the reminder is skipped unless the reservation meets all of them
+	Channel       schema.ReminderChannel    `example:"sms" validate:"omitempty,oneof=sms"`
+	Template      string                    `example:"reminder-turn-off" validate:"required,max=50"` // the logical name of the sms in the config
+	Disabled      bool
+}
+
+func (req *ReminderRule) ToDomain() *schema.ReminderRule {
+	channel := req.Channel
+	if channel == "" {
+		channel = schema.ReminderChannelSMS
+	}
+
+	return &schema.ReminderRule{
+		BusinessID:    req.BusinessID,
+		Title:         req.Title,
+		Trigger:       req.Trigger,
+		OffsetMinutes: req.OffsetMinutes,
+		Conditions:    req.Conditions,
+		Channel:       channel,
+		Template:      req.Template,
+		Disabled:      req.Disabled,
+	}
+}
@@ -0,0 +1,40 @@
+package response
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"time"
+)
+
+type ReminderRule struct {
+	ID             uint64                 `json:",omitempty"`
+	BusinessID     uint64                 `json:",omitempty"`
+	Title          string                 `json:",omitempty"`
+	Trigger        schema.ReminderTrigger `json:",omitempty"`
+	TriggerDisplay string                 `json:",omitempty"`
+	OffsetMinutes  int
+	Conditions     schema.ReminderConditions
+	Channel        schema.ReminderChannel `json:",omitempty"`
+	Template       string                 `json:",omitempty"`
+	Disabled       bool
+	CreatedAt      time.Time `json:",omitempty"`
+}
+
+func FromDomain(item *schema.ReminderRule) (res *ReminderRule) {
+	if item == nil {
+		return nil
+	}
+
+	return &ReminderRule{
+		ID:             item.ID,
+		BusinessID:     item.BusinessID,
+		Title:          item.Title,
+		Trigger:        item.Trigger,
+		TriggerDisplay: schema.ReminderTriggerProxy[item.Trigger],
+		OffsetMinutes:  item.OffsetMinutes,
+		Conditions:     item.Conditions,
+		Channel:        item.Channel,
+		Template:       item.Template,
+		Disabled:       item.Disabled,
+		CreatedAt:      item.CreatedAt,
+	}
+}
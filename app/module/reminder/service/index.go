@@ -0,0 +1,189 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	nservice "go-fiber-starter/app/module/notification/service"
+	oservice "go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/app/module/reminder/repository"
+	"go-fiber-starter/app/module/reminder/request"
+	"go-fiber-starter/app/module/reminder/response"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	ptime "github.com/yaa110/go-persian-calendar"
+)
+
+// ReminderGracePeriod is how late a reminder is still sent, e.g. after a restart or for a reservation made
+// after its reminder time. The older ones are skipped.
+const ReminderGracePeriod = 10 * time.Minute
+
+type IService interface {
+	Index(req request.ReminderRules) (rules []*response.ReminderRule, paging paginator.Pagination, err error)
+	Show(businessID uint64, id uint64) (rule *response.ReminderRule, err error)
+	Store(req request.ReminderRule) (rule *response.ReminderRule, err error)
+	Update(id uint64, req request.ReminderRule) (err error)
+	Destroy(businessID uint64, id uint64) error
+	SendDue(now time.Time) error
+}
+
+func Service(
+	repo repository.IRepository,
+	outboxService oservice.IService,
+	notificationService nservice.IService,
+	smsService *sms.Service,
+) IService {
+	return &service{
+		repo,
+		outboxService,
+		notificationService,
+		smsService,
+	}
+}
+
+type service struct {
+	Repo                repository.IRepository
+	Outbox              oservice.IService
+	NotificationService nservice.IService
+	Sms                 *sms.Service
+}
+
+func (_i *service) Index(req request.ReminderRules) (rules []*response.ReminderRule, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetAll(req)
+	if err != nil {
+		return
+	}
+
+	for _, result := range results {
+		rules = append(rules, response.FromDomain(result))
+	}
+
+	return
+}
+
+func (_i *service) Show(businessID uint64, id uint64) (rule *response.ReminderRule, err error) {
+	result, err := _i.Repo.GetOne(businessID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.FromDomain(result), nil
+}
+
+func (_i *service) Store(req request.ReminderRule) (rule *response.ReminderRule, err error) {
+	if err = _i.checkTemplate(req.Template); err != nil {
+		return nil, err
+	}
+
+	item := req.ToDomain()
+	if err = _i.Repo.Create(item); err != nil {
+		return nil, err
+	}
+
+	return response.FromDomain(item), nil
+}
+
+func (_i *service) Update(id uint64, req request.ReminderRule) (err error) {
+	if _, err = _i.Repo.GetOne(req.BusinessID, id); err != nil {
+		return err
+	}
+	if err = _i.checkTemplate(req.Template); err != nil {
+		return err
+	}
+
+	return _i.Repo.Update(id, req.ToDomain())
+}
+
+// checkTemplate refuses the names no provider has a template of, their reminders would never be sent
+func (_i *service) checkTemplate(template string) error {
+	if !_i.Sms.HasTemplate(template) {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "قالب پیامک در تنظیمات وجود ندارد"}
+	}
+
+	return nil
+}
+
+func (_i *service) Destroy(businessID uint64, id uint64) error {
+	if _, err := _i.Repo.GetOne(businessID, id); err != nil {
+		return err
+	}
+
+	return _i.Repo.Delete(businessID, id)
+}
+
+// SendDue queues the reminders whose time has come, a failed one does not stop the others
+// and is tried again in the next run.
+func (_i *service) SendDue(now time.Time) error {
+	rules, err := _i.Repo.GetEnabled()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, rule := range rules {
+		reservations, err := _i.Repo.GetDue(rule, now.Add(-ReminderGracePeriod), now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("reminder rule %d: %w", rule.ID, err))
+			continue
+		}
+
+		for _, reservation := range reservations {
+			if !rule.Conditions.Met(reservation) {
+				continue
+			}
+
+			if err := _i.send(rule, reservation); err != nil {
+				errs = append(errs, fmt.Errorf("reminder rule %d, reservation %d: %w", rule.ID, reservation.ID, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (_i *service) send(rule *schema.ReminderRule, reservation *schema.Reservation) error {
+	message := schema.NewSms(schema.OutboxPurposeReminder, reservation.User.Mobile, rule.Template)
+	message.BusinessID = &reservation.BusinessID
+
+	reminder := &schema.ReservationReminder{
+		ReservationID: reservation.ID,
+		RuleID:        rule.ID,
+		Channel:       rule.Channel,
+		SentAt:        time.Now(),
+	}
+	queued, err := _i.Repo.MarkSent(reminder, message, _i.Outbox.Enqueue)
+	if err != nil {
+		return fmt.Errorf("failed to queue the SMS: %w", err)
+	}
+	if !queued {
+		return nil
+	}
+
+	// a copy in the inbox of the user, the reminder is already queued if this fails
+	_ = _i.NotificationService.Push(&schema.Notification{
+		ReceiverID: reservation.UserID,
+		BusinessID: reservation.BusinessID,
+		Content:    inAppContent(rule, reservation),
+		Category:   schema.NotificationCategoryReminders,
+	})
+
+	return nil
+}
+
+// inAppContent is the reminder as it shows in the inbox
+func inAppContent(rule *schema.ReminderRule, reservation *schema.Reservation) string {
+	at := reservation.StartTime
+	if rule.Trigger == schema.ReminderTriggerBeforeEnd || rule.Trigger == schema.ReminderTriggerAfterEnd {
+		at = reservation.EndTime
+	}
+
+	return fmt.Sprintf("%s: %s، ساعت %s",
+		rule.Title,
+		reservation.Product.Post.Title,
+		ptime.New(at.In(timezone.In(reservation.BusinessID))).Format("HH:mm"),
+	)
+}
@@ -0,0 +1,179 @@
+package test
+
+import (
+	"go-fiber-starter/app/database/schema"
+	nservice "go-fiber-starter/app/module/notification/service"
+	oservice "go-fiber-starter/app/module/outbox/service"
+	"go-fiber-starter/app/module/reminder/repository"
+	"go-fiber-starter/app/module/reminder/request"
+	"go-fiber-starter/utils/paginator"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MockReminderRepository is a mock implementation of repository.IRepository for the scheduler tests
+type MockReminderRepository struct {
+	mu sync.Mutex
+
+	// Function hooks for customizing behavior
+	GetEnabledFunc func() ([]*schema.ReminderRule, error)
+	GetDueFunc     func(rule *schema.ReminderRule, from time.Time, to time.Time) ([]*schema.Reservation, error)
+	MarkSentFunc   func(reminder *schema.ReservationReminder) error
+
+	// Call tracking
+	getDueCalls []getDueCall
+	sent        []schema.ReservationReminder
+}
+
+type getDueCall struct {
+	Rule *schema.ReminderRule
+	From time.Time
+	To   time.Time
+}
+
+// NewMockReminderRepository creates a new mock repository with default behavior
+func NewMockReminderRepository() *MockReminderRepository {
+	return &MockReminderRepository{}
+}
+
+// GetAll implements repository.IRepository
+func (_m *MockReminderRepository) GetAll(req request.ReminderRules) ([]*schema.ReminderRule, paginator.Pagination, error) {
+	return nil, paginator.Pagination{}, nil
+}
+
+// GetOne implements repository.IRepository
+func (_m *MockReminderRepository) GetOne(businessID uint64, id uint64) (*schema.ReminderRule, error) {
+	return nil, nil
+}
+
+// Create implements repository.IRepository
+func (_m *MockReminderRepository) Create(rule *schema.ReminderRule) error {
+	return nil
+}
+
+// Update implements repository.IRepository
+func (_m *MockReminderRepository) Update(id uint64, rule *schema.ReminderRule) error {
+	return nil
+}
+
+// Delete implements repository.IRepository
+func (_m *MockReminderRepository) Delete(businessID uint64, id uint64) error {
+	return nil
+}
+
+// GetEnabled implements repository.IRepository
+func (_m *MockReminderRepository) GetEnabled() ([]*schema.ReminderRule, error) {
+	if _m.GetEnabledFunc != nil {
+		return _m.GetEnabledFunc()
+	}
+	return nil, nil
+}
+
+// GetDue implements repository.IRepository
+func (_m *MockReminderRepository) GetDue(rule *schema.ReminderRule, from time.Time, to time.Time) ([]*schema.Reservation, error) {
+	_m.mu.Lock()
+	_m.getDueCalls = append(_m.getDueCalls, getDueCall{rule, from, to})
+	_m.mu.Unlock()
+
+	if _m.GetDueFunc != nil {
+		return _m.GetDueFunc(rule, from, to)
+	}
+	return nil, nil
+}
+
+// MarkSent implements repository.IRepository, the message is queued without a transaction
+func (_m *MockReminderRepository) MarkSent(reminder *schema.ReservationReminder, message *schema.OutboxMessage, enqueue repository.Enqueue) (bool, error) {
+	_m.mu.Lock()
+	_m.sent = append(_m.sent, *reminder)
+	_m.mu.Unlock()
+
+	if _m.MarkSentFunc != nil {
+		if err := _m.MarkSentFunc(reminder); err != nil {
+			return false, err
+		}
+	}
+	if err := enqueue(nil, message); err != nil {
+		return false, err
+	}
+	reminder.MessageID = &message.ID
+	return true, nil
+}
+
+// ===== Assertion Helpers =====
+
+// WasSent returns true if MarkSent was called for the reservation by the rule
+func (_m *MockReminderRepository) WasSent(reservationID uint64, ruleID uint64) bool {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+	for _, reminder := range _m.sent {
+		if reminder.ReservationID == reservationID && reminder.RuleID == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+// SentCount returns the number of times MarkSent was called
+func (_m *MockReminderRepository) SentCount() int {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+	return len(_m.sent)
+}
+
+// GetDueCalls returns the arguments GetDue was called with
+func (_m *MockReminderRepository) GetDueCalls() []getDueCall {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+	result := make([]getDueCall, len(_m.getDueCalls))
+	copy(result, _m.getDueCalls)
+	return result
+}
+
+// MockNotificationService records the in-app copies of the reminders, the other methods are not used by the scheduler
+type MockNotificationService struct {
+	nservice.IService
+
+	mu     sync.Mutex
+	pushed []*schema.Notification
+}
+
+func (m *MockNotificationService) Push(notification *schema.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pushed = append(m.pushed, notification)
+	return nil
+}
+
+// Pushed returns the notifications put in the inboxes
+func (m *MockNotificationService) Pushed() []*schema.Notification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*schema.Notification{}, m.pushed...)
+}
+
+// MockOutboxService records the queued messages, the other methods are not used by the scheduler
+type MockOutboxService struct {
+	oservice.IService
+
+	mu     sync.Mutex
+	queued []*schema.OutboxMessage
+}
+
+func (m *MockOutboxService) Enqueue(tx *gorm.DB, messages ...*schema.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, message := range messages {
+		message.ID = uint64(len(m.queued) + 1)
+		m.queued = append(m.queued, message)
+	}
+	return nil
+}
+
+// Queued returns the messages handed to the outbox
+func (m *MockOutboxService) Queued() []*schema.OutboxMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*schema.OutboxMessage{}, m.queued...)
+}
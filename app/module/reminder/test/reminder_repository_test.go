@@ -0,0 +1,82 @@
+package test
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/reminder/repository"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/config"
+
+	"github.com/rs/zerolog"
+)
+
+func TestRepository_UpdateSavesTheTemplate(t *testing.T) {
+	repo, cleanup := setupTestRepository(t)
+	defer cleanup()
+
+	rule := turnOnRule(0)
+	if err := repo.Create(rule); err != nil {
+		t.Fatalf("failed to create the rule: %v", err)
+	}
+
+	updated := *rule
+	updated.Template = schema.SmsReminderTurnOff
+	updated.OffsetMinutes = 0
+	updated.Disabled = true
+	if err := repo.Update(rule.ID, &updated); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	saved, err := repo.GetOne(rule.BusinessID, rule.ID)
+	if err != nil {
+		t.Fatalf("failed to read the rule: %v", err)
+	}
+	if saved.Template != schema.SmsReminderTurnOff || saved.OffsetMinutes != 0 || !saved.Disabled {
+		t.Errorf("expected the template, the zero offset and the disabling to be saved, got %+v", saved)
+	}
+}
+
+// setupTestRepository connects the repository to the test database with an empty reminder_rules table
+func setupTestRepository(t *testing.T) (repository.IRepository, func()) {
+	t.Helper()
+
+	_, b, _, _ := runtime.Caller(0)
+	cfg, err := config.ParseConfig(filepath.Join(filepath.Dir(b), "..", "..", "..", "..", "config", "zciti-test.toml"), true)
+	if err != nil {
+		t.Fatalf("failed to load test config: %v", err)
+	}
+
+	dbWrapper := database.NewDatabase(cfg, zerolog.Nop())
+	dbWrapper.ConnectDatabase()
+	if dbWrapper.Main == nil {
+		t.Fatalf("failed to connect to test database")
+	}
+
+	dbWrapper.Main.Exec("DROP TABLE IF EXISTS reminder_rules CASCADE")
+	if err := dbWrapper.Main.Exec(`
+		CREATE TABLE reminder_rules (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL,
+			title VARCHAR(100),
+			trigger VARCHAR(20) NOT NULL,
+			offset_minutes INT NOT NULL DEFAULT 0,
+			conditions JSONB,
+			channel VARCHAR(20) NOT NULL DEFAULT 'sms',
+			template VARCHAR(50) NOT NULL DEFAULT '',
+			disabled BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		t.Fatalf("failed to migrate test models: %v", err)
+	}
+
+	return repository.Repository(dbWrapper), func() {
+		dbWrapper.Main.Exec("DROP TABLE IF EXISTS reminder_rules CASCADE")
+		dbWrapper.ShutdownDatabase()
+	}
+}
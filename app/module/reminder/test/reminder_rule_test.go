@@ -0,0 +1,51 @@
+package test
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/reminder/request"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func reminderRuleRequest(template string) request.ReminderRule {
+	return request.ReminderRule{
+		BusinessID:    1,
+		Title:         "پنج دقیقه تا پایان",
+		Trigger:       schema.ReminderTriggerBeforeEnd,
+		OffsetMinutes: 5,
+		Template:      template,
+	}
+}
+
+func TestStore_SavesAConfiguredTemplate(t *testing.T) {
+	rule, err := newTestService(NewMockReminderRepository()).Store(reminderRuleRequest(schema.SmsReminderTurnOff))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rule.Template != schema.SmsReminderTurnOff {
+		t.Errorf("expected template %s, got %s", schema.SmsReminderTurnOff, rule.Template)
+	}
+}
+
+// a rule of a template no provider has would never be sent
+func TestStore_RefusesATemplateNotConfigured(t *testing.T) {
+	for _, template := range []string{"16620", "reminder", schema.SmsWaitlistOffer} {
+		_, err := newTestService(NewMockReminderRepository()).Store(reminderRuleRequest(template))
+
+		var fiberErr *fiber.Error
+		if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+			t.Errorf("expected a bad request for template %q, got %v", template, err)
+		}
+	}
+}
+
+func TestUpdate_RefusesATemplateNotConfigured(t *testing.T) {
+	err := newTestService(NewMockReminderRepository()).Update(1, reminderRuleRequest("16621"))
+
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest {
+		t.Errorf("expected a bad request, got %v", err)
+	}
+}
@@ -0,0 +1,243 @@
+package test
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/reminder/service"
+	"strings"
+	"testing"
+	"time"
+)
+
+// =============================================================================
+// SendDue Tests
+// =============================================================================
+
+func TestSendDue_Success(t *testing.T) {
+	mockRepo := NewMockReminderRepository()
+	rule := turnOnRule(7)
+	reservation := NewReservationBuilder().WithID(1).Build()
+
+	mockRepo.GetEnabledFunc = func() ([]*schema.ReminderRule, error) {
+		return []*schema.ReminderRule{rule}, nil
+	}
+	mockRepo.GetDueFunc = func(rule *schema.ReminderRule, from time.Time, to time.Time) ([]*schema.Reservation, error) {
+		return []*schema.Reservation{reservation}, nil
+	}
+
+	if err := newTestService(mockRepo).SendDue(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assertSent(t, mockRepo, 1, 7)
+}
+
+func TestSendDue_QueuesSms(t *testing.T) {
+	mockRepo := NewMockReminderRepository()
+	outbox := &MockOutboxService{}
+	rule := turnOnRule(7)
+	reservation := NewReservationBuilder().WithID(1).Build()
+
+	mockRepo.GetEnabledFunc = func() ([]*schema.ReminderRule, error) {
+		return []*schema.ReminderRule{rule}, nil
+	}
+	mockRepo.GetDueFunc = func(rule *schema.ReminderRule, from time.Time, to time.Time) ([]*schema.Reservation, error) {
+		return []*schema.Reservation{reservation}, nil
+	}
+
+	if err := service.Service(mockRepo, outbox, &MockNotificationService{}, newTestSms()).SendDue(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	queued := outbox.Queued()
+	if len(queued) != 1 {
+		t.Fatalf("expected one queued sms, got %d", len(queued))
+	}
+	if queued[0].Purpose != schema.OutboxPurposeReminder || queued[0].Template != rule.Template ||
+		queued[0].BusinessID == nil || *queued[0].BusinessID != reservation.BusinessID {
+		t.Errorf("expected the reminder sms of the rule for the business, got %+v", queued[0])
+	}
+}
+
+func TestSendDue_PushesInAppCopy(t *testing.T) {
+	mockRepo := NewMockReminderRepository()
+	notifications := &MockNotificationService{}
+	rule := turnOnRule(7)
+	reservation := NewReservationBuilder().WithID(1).Build()
+
+	mockRepo.GetEnabledFunc = func() ([]*schema.ReminderRule, error) {
+		return []*schema.ReminderRule{rule}, nil
+	}
+	mockRepo.GetDueFunc = func(rule *schema.ReminderRule, from time.Time, to time.Time) ([]*schema.Reservation, error) {
+		return []*schema.Reservation{reservation}, nil
+	}
+
+	if err := newTestService(mockRepo, notifications).SendDue(time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pushed := notifications.Pushed()
+	if len(pushed) != 1 {
+		t.Fatalf("expected one in-app copy, got %d", len(pushed))
+	}
+	if pushed[0].ReceiverID != reservation.UserID || pushed[0].BusinessID != reservation.BusinessID {
+		t.Errorf("expected the copy to go to the user of the reservation, got %+v", pushed[0])
+	}
+	if !strings.HasPrefix(pushed[0].Content, rule.Title) {
+		t.Errorf("expected the copy to start with the rule title, got %q", pushed[0].Content)
+	}
+}
+
+func TestSendDue_RulesError(t *testing.T) {
+	mockRepo := NewMockReminderRepository()
+	mockRepo.GetEnabledFunc = func() ([]*schema.ReminderRule, error) {
+		return nil, errors.New("database error")
+	}
+
+	if err := newTestService(mockRepo).SendDue(time.Now()); err == nil {
+		t.Error("expected error, got nil")
+	}
+	if len(mockRepo.GetDueCalls()) != 0 || mockRepo.SentCount() != 0 {
+		t.Error("expected nothing to be sent without the rules")
+	}
+}
+
+func TestSendDue_Window(t *testing.T) {
+	mockRepo := NewMockReminderRepository()
+	mockRepo.GetEnabledFunc = func() ([]*schema.ReminderRule, error) {
+		return []*schema.ReminderRule{turnOnRule(1), turnOffRule(2)}, nil
+	}
+
+	now := time.Date(2025, 9, 8, 9, 26, 5, 0, time.UTC)
+	if err := newTestService(mockRepo).SendDue(now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	calls := mockRepo.GetDueCalls()
+	if len(calls) != 2 {
+		t.Fatalf("expected GetDue to be called for each rule, got %d calls", len(calls))
+	}
+	for _, call := range calls {
+		if !call.To.Equal(now) || !call.From.Equal(now.Add(-service.ReminderGracePeriod)) {
+			t.Errorf("expected the window to end now and start a grace period before, got %s - %s", call.From, call.To)
+		}
+	}
+}
+
+// =============================================================================
+// Conditions - Table Driven Tests
+// =============================================================================
+
+func TestSendDue_Conditions(t *testing.T) {
+	tests := []struct {
+		name          string
+		rule          *schema.ReminderRule
+		postStatus    schema.PostStatus
+		machineStatus schema.UniWashMachineStatus
+		lastCommand   schema.UniWashCommand
+		shouldSend    bool
+	}{
+		{"turn on skips unpublished post", turnOnRule(1), schema.PostStatusDraft, schema.UniWashMachineStatusON, "", false},
+		{"turn on skips machine OFF", turnOnRule(1), schema.PostStatusPublished, schema.UniWashMachineStatusOFF, "", false},
+		{"turn on sends without a command", turnOnRule(1), schema.PostStatusPublished, schema.UniWashMachineStatusON, "", true},
+		{"turn off skips without a command", turnOffRule(2), schema.PostStatusPublished, schema.UniWashMachineStatusON, "", false},
+		{"turn off skips machine OFF", turnOffRule(2), schema.PostStatusPublished, schema.UniWashMachineStatusOFF, schema.UniWashCommandON, false},
+		{"turn off sends after a command", turnOffRule(2), schema.PostStatusPublished, schema.UniWashMachineStatusON, schema.UniWashCommandON, true},
+		{"no conditions sends with machine OFF", &schema.ReminderRule{ID: 3, Channel: schema.ReminderChannelSMS, Template: schema.SmsReminderTurnOff}, schema.PostStatusPublished, schema.UniWashMachineStatusOFF, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := NewMockReminderRepository()
+			reservation := NewReservationBuilder().
+				WithID(1).
+				WithPostStatus(tt.postStatus).
+				WithMachineStatus(tt.machineStatus).
+				WithLastCommand(tt.lastCommand).
+				Build()
+
+			mockRepo.GetEnabledFunc = func() ([]*schema.ReminderRule, error) {
+				return []*schema.ReminderRule{tt.rule}, nil
+			}
+			mockRepo.GetDueFunc = func(rule *schema.ReminderRule, from time.Time, to time.Time) ([]*schema.Reservation, error) {
+				return []*schema.Reservation{reservation}, nil
+			}
+
+			if err := newTestService(mockRepo).SendDue(time.Now()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.shouldSend {
+				assertSent(t, mockRepo, 1, tt.rule.ID)
+			} else {
+				assertNotSent(t, mockRepo, 1, tt.rule.ID)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Error Handling
+// =============================================================================
+
+func TestSendDue_ContinuesOnIndividualError(t *testing.T) {
+	mockRepo := NewMockReminderRepository()
+	mockRepo.GetEnabledFunc = func() ([]*schema.ReminderRule, error) {
+		return []*schema.ReminderRule{turnOnRule(1), turnOffRule(2)}, nil
+	}
+	mockRepo.GetDueFunc = func(rule *schema.ReminderRule, from time.Time, to time.Time) ([]*schema.Reservation, error) {
+		if rule.ID == 1 {
+			return nil, errors.New("database error")
+		}
+		return []*schema.Reservation{
+			NewReservationBuilder().WithID(1).WithLastCommand(schema.UniWashCommandON).Build(),
+			NewReservationBuilder().WithID(2).WithLastCommand(schema.UniWashCommandON).Build(),
+		}, nil
+	}
+	mockRepo.MarkSentFunc = func(reminder *schema.ReservationReminder) error {
+		if reminder.ReservationID == 1 {
+			return errors.New("mark failed")
+		}
+		return nil
+	}
+
+	if err := newTestService(mockRepo).SendDue(time.Now()); err == nil {
+		t.Error("expected the failures to be returned")
+	}
+
+	assertSent(t, mockRepo, 2, 2)
+}
+
+// =============================================================================
+// DueBetween Tests
+// =============================================================================
+
+func TestReminderRule_DueBetween(t *testing.T) {
+	from := time.Date(2025, 9, 8, 9, 16, 0, 0, time.UTC)
+	to := from.Add(10 * time.Minute)
+
+	tests := []struct {
+		trigger schema.ReminderTrigger
+		column  string
+		shift   time.Duration
+	}{
+		{schema.ReminderTriggerBeforeStart, "start_time", 60 * time.Minute},
+		{schema.ReminderTriggerAfterStart, "start_time", -60 * time.Minute},
+		{schema.ReminderTriggerBeforeEnd, "end_time", 60 * time.Minute},
+		{schema.ReminderTriggerAfterEnd, "end_time", -60 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.trigger), func(t *testing.T) {
+			rule := schema.ReminderRule{Trigger: tt.trigger, OffsetMinutes: 60}
+
+			column, start, end := rule.DueBetween(from, to)
+			if column != tt.column {
+				t.Errorf("expected column %s, got %s", tt.column, column)
+			}
+			if !start.Equal(from.Add(tt.shift)) || !end.Equal(to.Add(tt.shift)) {
+				t.Errorf("expected %s - %s, got %s - %s", from.Add(tt.shift), to.Add(tt.shift), start, end)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/reminder/service"
+	"go-fiber-starter/internal/sms"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ===== Service Factory =====
+
+// newTestService creates the reminder service on the mocks
+func newTestService(repo *MockReminderRepository, notifications ...*MockNotificationService) service.IService {
+	notificationService := &MockNotificationService{}
+	if len(notifications) > 0 {
+		notificationService = notifications[0]
+	}
+
+	return service.Service(repo, &MockOutboxService{}, notificationService, newTestSms())
+}
+
+// newTestSms is an sms service that only has the templates of the default reminder rules
+func newTestSms() *sms.Service {
+	return sms.New(zerolog.Nop(), &sms.KavenegarProvider{Templates: map[string]sms.Template{
+		schema.SmsReminderTurnOn:  {ID: "turn-on"},
+		schema.SmsReminderTurnOff: {ID: "turn-off"},
+	}})
+}
+
+// ===== Rule Factory =====
+
+func turnOnRule(id uint64) *schema.ReminderRule {
+	rule := schema.DefaultReminderRules(1)[0]
+	rule.ID = id
+	return &rule
+}
+
+func turnOffRule(id uint64) *schema.ReminderRule {
+	rule := schema.DefaultReminderRules(1)[1]
+	rule.ID = id
+	return &rule
+}
+
+// ===== Reservation Factory =====
+
+// ReservationBuilder provides a fluent API for building test reservations
+type ReservationBuilder struct {
+	reservation *schema.Reservation
+}
+
+// NewReservationBuilder creates a new builder with sensible defaults
+func NewReservationBuilder() *ReservationBuilder {
+	return &ReservationBuilder{
+		reservation: &schema.Reservation{
+			ID:         1,
+			UserID:     1,
+			ProductID:  1,
+			BusinessID: 1,
+			Status:     schema.ReservationStatusReserved,
+			StartTime:  time.Now(),
+			EndTime:    time.Now().Add(time.Hour),
+			User: schema.User{
+				ID:     1,
+				Mobile: 9123456789,
+			},
+			Product: schema.Product{
+				ID: 1,
+				Post: schema.Post{
+					ID:     1,
+					Status: schema.PostStatusPublished,
+				},
+				Meta: schema.ProductMeta{
+					UniWashMachineStatus: schema.UniWashMachineStatusON,
+				},
+			},
+		},
+	}
+}
+
+func (_b *ReservationBuilder) WithID(id uint64) *ReservationBuilder {
+	_b.reservation.ID = id
+	return _b
+}
+
+func (_b *ReservationBuilder) WithPostStatus(status schema.PostStatus) *ReservationBuilder {
+	_b.reservation.Product.Post.Status = status
+	return _b
+}
+
+func (_b *ReservationBuilder) WithMachineStatus(status schema.UniWashMachineStatus) *ReservationBuilder {
+	_b.reservation.Product.Meta.UniWashMachineStatus = status
+	return _b
+}
+
+func (_b *ReservationBuilder) WithLastCommand(cmd schema.UniWashCommand) *ReservationBuilder {
+	_b.reservation.Meta.UniWashLastCommand = cmd
+	return _b
+}
+
+func (_b *ReservationBuilder) Build() *schema.Reservation {
+	return _b.reservation
+}
+
+// ===== Test Assertions =====
+
+// assertSent asserts that the reminder of the rule was recorded for the reservation
+func assertSent(t *testing.T, mock *MockReminderRepository, reservationID uint64, ruleID uint64) {
+	t.Helper()
+	if !mock.WasSent(reservationID, ruleID) {
+		t.Errorf("expected the reminder of rule %d to be sent for reservation %d", ruleID, reservationID)
+	}
+}
+
+// assertNotSent asserts that the reminder of the rule was NOT recorded for the reservation
+func assertNotSent(t *testing.T, mock *MockReminderRepository, reservationID uint64, ruleID uint64) {
+	t.Helper()
+	if mock.WasSent(reservationID, ruleID) {
+		t.Errorf("expected the reminder of rule %d NOT to be sent for reservation %d", ruleID, reservationID)
+	}
+}
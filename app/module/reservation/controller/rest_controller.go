@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/utils"
 	"slices"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 	"go-fiber-starter/app/module/reservation/service"
 	"go-fiber-starter/utils/paginator"
 	"go-fiber-starter/utils/response"
+	"go-fiber-starter/utils/timezone"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -20,6 +22,12 @@ type IRestController interface {
 	Store(c *fiber.Ctx) error
 	Update(c *fiber.Ctx) error
 	Delete(c *fiber.Ctx) error
+	Availability(c *fiber.Ctx) error
+	NoShows(c *fiber.Ctx) error
+	Usage(c *fiber.Ctx) error
+	Quota(c *fiber.Ctx) error
+	UpdateQuota(c *fiber.Ctx) error
+	MyQuota(c *fiber.Ctx) error
 }
 
 func RestController(s service.IService) IRestController {
@@ -35,6 +43,7 @@ type controller struct {
 // @Tags         Reservations
 // @Security     Bearer
 // @Param        businessID path int true "Business ID"
+// @Param        Status query string false "comma separated, held,reserved,inUse,completed,canceled,noShow,expired"
 // @Router       /business/:businessID/reservations [get]
 func (_i *controller) Index(c *fiber.Ctx) error {
 	businessID, err := utils.GetIntInParams(c, "businessID")
@@ -60,6 +69,16 @@ func (_i *controller) Index(c *fiber.Ctx) error {
 	req.WorkspaceID, _ = utils.GetUintInQueries(c, "WorkspaceID")
 	req.DormitoryID, _ = utils.GetUintInQueries(c, "DormitoryID")
 
+	if statuses := strings.TrimSpace(c.Query("Status")); statuses != "" {
+		for _, status := range strings.Split(statuses, ",") {
+			status := schema.ReservationStatus(strings.TrimSpace(status))
+			if _, ok := schema.ReservationStatusProxy[status]; !ok {
+				return &fiber.Error{Code: fiber.StatusBadRequest, Message: "وضعیت رزرو نامعتبر است"}
+			}
+			req.Statuses = append(req.Statuses, status)
+		}
+	}
+
 	var taxonomies []uint64
 	if req.DormitoryID != 0 {
 		taxonomies = append(taxonomies, req.DormitoryID)
@@ -223,3 +242,201 @@ func (_i *controller) Delete(c *fiber.Ctx) error {
 
 	return c.JSON("success")
 }
+
+// Availability of reservable machines
+// @Summary      Get the slot grid of the machines
+// @Tags         Reservations
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        StartDate query string false "StartDate, default today"
+// @Param        EndDate query string false "EndDate, default a week after StartDate"
+// @Param        TaxonomyID query int false "Taxonomy ID"
+// @Param        PostID query int false "Post ID"
+// @Param        ProductID query int false "Product ID"
+// @Router       /user/business/:businessID/reservations/availability [get]
+func (_i *controller) Availability(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	var req request.Availability
+	req.BusinessID = businessID
+	req.TaxonomyID, _ = utils.GetUintInQueries(c, "TaxonomyID")
+	req.PostID, _ = utils.GetUintInQueries(c, "PostID")
+	req.ProductID, _ = utils.GetUintInQueries(c, "ProductID")
+
+	req.StartDate = *utils.GetDateInQueries(c, "StartDate")
+	if req.StartDate.IsZero() {
+		req.StartDate = timezone.ForBusiness(businessID).Today()
+	}
+
+	req.EndDate = *utils.GetDateInQueries(c, "EndDate")
+	if req.EndDate.IsZero() {
+		req.EndDate = req.StartDate.AddDate(0, 0, 6)
+	}
+
+	machines, err := _i.service.Availability(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: machines,
+	})
+}
+
+// NoShows report of the business
+// @Summary      Get the no-show rate and the users who missed their reservations
+// @Tags         Reservations
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        StartDate query string false "StartDate, default 30 days before EndDate"
+// @Param        EndDate query string false "EndDate, default today"
+// @Router       /business/:businessID/reservations/no-shows [get]
+func (_i *controller) NoShows(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	var req request.NoShowReport
+	req.BusinessID = businessID
+
+	req.EndDate = *utils.GetDateInQueries(c, "EndDate")
+	if req.EndDate.IsZero() {
+		req.EndDate = timezone.ForBusiness(businessID).Today()
+	}
+	// the end date is inclusive
+	req.EndDate = req.EndDate.AddDate(0, 0, 1)
+
+	req.StartDate = *utils.GetDateInQueries(c, "StartDate")
+	if req.StartDate.IsZero() {
+		req.StartDate = req.EndDate.AddDate(0, 0, -30)
+	}
+
+	report, err := _i.service.NoShowReport(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: report,
+	})
+}
+
+// Usage report of the business
+// @Summary      Get how many reservations were booked and actually used, by status
+// @Tags         Reservations
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        StartDate query string false "StartDate, default 30 days before EndDate"
+// @Param        EndDate query string false "EndDate, default today"
+// @Router       /business/:businessID/reservations/usage [get]
+func (_i *controller) Usage(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	var req request.Usage
+	req.BusinessID = businessID
+
+	req.EndDate = *utils.GetDateInQueries(c, "EndDate")
+	if req.EndDate.IsZero() {
+		req.EndDate = timezone.ForBusiness(businessID).Today()
+	}
+	// the end date is inclusive
+	req.EndDate = req.EndDate.AddDate(0, 0, 1)
+
+	req.StartDate = *utils.GetDateInQueries(c, "StartDate")
+	if req.StartDate.IsZero() {
+		req.StartDate = req.EndDate.AddDate(0, 0, -30)
+	}
+
+	usage, err := _i.service.Usage(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: usage,
+	})
+}
+
+// Quota rules of the business
+// @Summary      Get the fair-use booking quotas
+// @Tags         Reservations
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/reservations/quota [get]
+func (_i *controller) Quota(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	quota, err := _i.service.Quota(businessID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: quota,
+	})
+}
+
+// UpdateQuota rules of the business
+// @Summary      Update the fair-use booking quotas, zero means unlimited
+// @Tags         Reservations
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        quota body request.Quota true "Quota"
+// @Router       /business/:businessID/reservations/quota [put]
+func (_i *controller) UpdateQuota(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	req := new(request.Quota)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	if err = _i.service.UpdateQuota(*req); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
+
+// MyQuota usage of the user
+// @Summary      Get the booking quotas and how much of them the user has used
+// @Tags         Reservations
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Router       /user/business/:businessID/reservations/quota [get]
+func (_i *controller) MyQuota(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	usage, err := _i.service.QuotaUsage(businessID, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: usage,
+	})
+}
@@ -0,0 +1,44 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/reservation/service"
+	"go-fiber-starter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+type NoShowDetection struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+}
+
+func RunNoShowDetection(
+	logger zerolog.Logger,
+	reservationService service.IService,
+	cronService *internal.CronService,
+) *NoShowDetection {
+	detection := &NoShowDetection{
+		Logger:   logger,
+		Service:  reservationService,
+		CronSpec: "@every 1m",
+	}
+
+	err := cronService.AddJob(detection.CronSpec, detection.Detect)
+	if err != nil {
+		detection.Logger.Fatal().Err(err).Msg("failed to add RunNoShowDetection job")
+	}
+
+	return detection
+}
+
+// Detect marks the missed reservations and gives back the booking rights of the users whose suspension is over
+func (_s *NoShowDetection) Detect() {
+	if err := _s.Service.MarkNoShows(); err != nil {
+		_s.Logger.Err(err).Msg("Failed to mark the no-show reservations")
+	}
+
+	if err := _s.Service.LiftSuspensions(); err != nil {
+		_s.Logger.Err(err).Msg("Failed to lift the expired suspensions")
+	}
+}
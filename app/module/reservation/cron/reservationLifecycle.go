@@ -0,0 +1,40 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/reservation/service"
+	"go-fiber-starter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+type ReservationLifecycle struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+}
+
+func RunReservationLifecycle(
+	logger zerolog.Logger,
+	reservationService service.IService,
+	cronService *internal.CronService,
+) *ReservationLifecycle {
+	lifecycle := &ReservationLifecycle{
+		Logger:   logger,
+		Service:  reservationService,
+		CronSpec: "@every 1m",
+	}
+
+	err := cronService.AddJob(lifecycle.CronSpec, lifecycle.Advance)
+	if err != nil {
+		lifecycle.Logger.Fatal().Err(err).Msg("failed to add RunReservationLifecycle job")
+	}
+
+	return lifecycle
+}
+
+// Advance expires the unpaid holds and completes the reservations whose time is over
+func (_s *ReservationLifecycle) Advance() {
+	if err := _s.Service.AdvanceLifecycle(); err != nil {
+		_s.Logger.Err(err).Msg("Failed to advance the reservations lifecycle")
+	}
+}
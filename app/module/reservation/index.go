@@ -24,11 +24,21 @@ func (_i *Router) RegisterRoutes(cfg *config.Config) {
 	// define routes
 	_i.App.Route("/v1/business/:businessID/reservations", func(router fiber.Router) {
 		router.Get("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.Index)
+		router.Get("/availability", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.Availability)
+		router.Get("/no-shows", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.NoShows)
+		router.Get("/usage", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.Usage)
+		router.Get("/quota", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.Quota)
+		router.Put("/quota", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PUpdate), c.UpdateQuota)
 		router.Get("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadSingle), c.Show)
 		router.Post("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PCreate), c.Store)
 		router.Put("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PUpdate), c.Update)
 		router.Delete("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PDelete), c.Delete)
 	})
+
+	_i.App.Route("/v1/user/business/:businessID/reservations", func(router fiber.Router) {
+		router.Get("/availability", mdl.Protected(cfg), mdl.ForUser, c.Availability)
+		router.Get("/quota", mdl.Protected(cfg), mdl.ForUser, c.MyQuota)
+	})
 }
 
 func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
@@ -47,6 +57,6 @@ var Module = fx.Options(
 
 	fx.Provide(newRouter),
 
-	fx.Invoke(cron.RunTurnOnMsgReminders),
-	fx.Invoke(cron.RunTurnOffMsgReminders),
+	fx.Invoke(cron.RunNoShowDetection),
+	fx.Invoke(cron.RunReservationLifecycle),
 )
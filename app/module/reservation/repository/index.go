@@ -1,12 +1,15 @@
 package repository
 
 import (
+	"encoding/json"
+	"fmt"
 	"go-fiber-starter/app/database/schema"
 	oirequest "go-fiber-starter/app/module/orderItem/request"
 	"go-fiber-starter/app/module/reservation/request"
 	"go-fiber-starter/app/module/reservation/response"
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
 	"strings"
 	"time"
 
@@ -21,8 +24,28 @@ type IRepository interface {
 	Update(id uint64, reservation *schema.Reservation) (err error)
 	Delete(id uint64) (err error)
 	IsReservable(req oirequest.OrderItem, businessID uint64) error
-	MarkTurnOnReminderSent(id uint64) error
-	MarkTurnOffReminderSent(id uint64) error
+	GetActiveInRange(productIDs []uint64, start time.Time, end time.Time) (reservations []*schema.Reservation, err error)
+	GetNoShowCandidates(deadline time.Time, since time.Time) (reservations []*schema.Reservation, err error)
+	MarkNoShow(id uint64) (marked bool, err error)
+	CreateStrike(strike *schema.UserStrike) error
+	CountStrikes(userID uint64, since time.Time) (count int64, err error)
+	GetUser(id uint64) (user *schema.User, err error)
+	SuspendUser(userID uint64, reason string, until time.Time) error
+	LiftExpiredSuspensions(now time.Time) (lifted int64, err error)
+	CountByStatus(businessID uint64, from time.Time, to time.Time) (counts map[schema.ReservationStatus]int64, err error)
+	GetNoShowUsers(businessID uint64, from time.Time, to time.Time, limit int) (users []*NoShowUser, err error)
+	GetBusiness(id uint64) (business *schema.Business, err error)
+	UpdateQuota(businessID uint64, quota schema.BusinessMetaQuota) error
+	GetUserActiveReservations(userID uint64, businessID uint64, from time.Time, to time.Time, taxonomyIDs []uint64) (reservations []*schema.Reservation, err error)
+	GetPostTaxonomyIDs(postID uint64) (taxonomyIDs []uint64, err error)
+	ExpireHolds(heldBefore time.Time) (expired int64, err error)
+	CompleteEnded(now time.Time) (completed int64, err error)
+	ReserveSeats(reservation *schema.Reservation, capacity int, maxPerUser int, tx *gorm.DB) error
+}
+
+type NoShowUser struct {
+	UserID  uint64
+	NoShows int64
 }
 
 func Repository(DB *database.Database) IRepository {
@@ -64,6 +87,11 @@ func (_i *repo) GetAll(req request.Reservations) (reservations []*schema.Reserva
 
 	if req.Status != "" {
 		query.Where(&schema.Reservation{Status: req.Status})
+	} else if len(req.Statuses) > 0 {
+		query.Where("reservations.status IN ?", req.Statuses)
+	} else {
+		// the unpaid ones are left out unless asked for
+		query.Where("reservations.status NOT IN ?", []schema.ReservationStatus{schema.ReservationStatusHeld, schema.ReservationStatusExpired})
 	}
 
 	if req.FullName != "" || req.Mobile != "" {
@@ -105,22 +133,6 @@ func (_i *repo) GetAll(req request.Reservations) (reservations []*schema.Reserva
 	// 	query.Where("products.post_id IN (?)", req.Posts)
 	// }
 
-	if req.TurnOnReminderSent != nil {
-		if *req.TurnOnReminderSent {
-			query.Where("meta->>'TurnOnReminderSent' = 'true'")
-		} else {
-			query.Where("meta->>'TurnOnReminderSent' IS NULL OR meta->>'TurnOnReminderSent' != 'true'")
-		}
-	}
-
-	if req.TurnOffReminderSent != nil {
-		if *req.TurnOffReminderSent {
-			query.Where("meta->>'TurnOffReminderSent' = 'true'")
-		} else {
-			query.Where("meta->>'TurnOffReminderSent' IS NULL OR meta->>'TurnOffReminderSent' != 'true'")
-		}
-	}
-
 	if req.Pagination != nil && req.Pagination.Page > 0 {
 		var total int64
 		query.Count(&total)
@@ -181,15 +193,15 @@ func (_i *repo) Delete(id uint64) error {
 
 func (_i *repo) IsReservable(req oirequest.OrderItem, businessID uint64) error {
 	var reservation schema.Reservation
+	loc := timezone.In(businessID)
 	if err := _i.DB.Main.
 		Where(&schema.Reservation{
 			BusinessID: businessID,
 			ProductID:  req.ProductID,
-			EndTime:    req.GetEndDateTime(),
-			StartTime:  req.GetStartDateTime(),
-			Status:     schema.ReservationStatusReserved,
+			EndTime:    req.GetEndDateTime(loc),
+			StartTime:  req.GetStartDateTime(loc),
 		}).
-		Unscoped().Where("deleted_at > ? OR deleted_at IS NULL", time.Now()).
+		Scopes(schema.ReservationTakesSlot(time.Now())).
 		First(&reservation).Error; err == nil {
 		return &fiber.Error{
 			Code:    fiber.StatusBadRequest,
@@ -200,14 +212,234 @@ func (_i *repo) IsReservable(req oirequest.OrderItem, businessID uint64) error {
 	return nil
 }
 
-func (_i *repo) MarkTurnOnReminderSent(id uint64) error {
-	return _i.DB.Main.Model(&schema.Reservation{}).
-		Where("id = ?", id).
-		Update("meta", gorm.Expr("meta || ?", `{"TurnOnReminderSent": true}`)).Error
+// GetActiveInRange returns paid and held reservations of the products that overlap the range.
+func (_i *repo) GetActiveInRange(productIDs []uint64, start time.Time, end time.Time) (reservations []*schema.Reservation, err error) {
+	err = _i.DB.Main.
+		Select("id, product_id, start_time, end_time, status, seats, shared, created_at").
+		Where("product_id IN (?)", productIDs).
+		Where("start_time < ? AND end_time > ?", end, start).
+		Scopes(schema.ReservationTakesSlot(time.Now())).
+		Find(&reservations).Error
+
+	return
 }
 
-func (_i *repo) MarkTurnOffReminderSent(id uint64) error {
-	return _i.DB.Main.Model(&schema.Reservation{}).
-		Where("id = ?", id).
-		Update("meta", gorm.Expr("meta || ?", `{"TurnOffReminderSent": true}`)).Error
+// GetNoShowCandidates returns the confirmed reservations that started between since and the deadline
+// and never got an ON command, leaving out the machines that were out of service.
+func (_i *repo) GetNoShowCandidates(deadline time.Time, since time.Time) (reservations []*schema.Reservation, err error) {
+	err = _i.DB.Main.
+		Joins("JOIN products ON products.id = reservations.product_id").
+		Where("reservations.status = ?", schema.ReservationStatusReserved).
+		Where("reservations.start_time > ? AND reservations.start_time <= ?", since, deadline).
+		Where("products.variant_type = ?", schema.ProductVariantTypeWashingMachine).
+		Where("COALESCE(products.meta->>'UniWashMachineStatus', '') <> ?", schema.UniWashMachineStatusOFF).
+		Where("COALESCE(reservations.meta->>'UniWashLastCommand', '') = ''").
+		Where("NOT EXISTS (SELECT 1 FROM machine_commands WHERE machine_commands.reservation_id = reservations.id AND machine_commands.command = ?)", schema.UniWashCommandON).
+		Where(`NOT EXISTS (SELECT 1 FROM machine_downtimes WHERE machine_downtimes.product_id = reservations.product_id
+			AND machine_downtimes.started_at < reservations.end_time
+			AND (machine_downtimes.ended_at IS NULL OR machine_downtimes.ended_at > reservations.start_time))`).
+		Preload("User").
+		Preload("Product").
+		Order("reservations.start_time").
+		Find(&reservations).Error
+
+	return
+}
+
+// MarkNoShow reports false when the reservation is no longer reserved.
+func (_i *repo) MarkNoShow(id uint64) (marked bool, err error) {
+	result := _i.DB.Main.Model(&schema.Reservation{}).
+		Where("id = ? AND status = ?", id, schema.ReservationStatusReserved).
+		Update("status", schema.ReservationStatusNoShow)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+func (_i *repo) CreateStrike(strike *schema.UserStrike) error {
+	return _i.DB.Main.Create(strike).Error
+}
+
+func (_i *repo) CountStrikes(userID uint64, since time.Time) (count int64, err error) {
+	err = _i.DB.Main.Model(&schema.UserStrike{}).
+		Where(&schema.UserStrike{UserID: userID}).
+		Where("created_at > ?", since).
+		Count(&count).Error
+
+	return
+}
+
+func (_i *repo) GetUser(id uint64) (user *schema.User, err error) {
+	if err = _i.DB.Main.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (_i *repo) SuspendUser(userID uint64, reason string, until time.Time) error {
+	untilJSON, err := json.Marshal(until)
+	if err != nil {
+		return err
+	}
+
+	return _i.DB.Main.Model(&schema.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]any{
+			"is_suspended":    true,
+			"suspense_reason": reason,
+			"meta":            gorm.Expr("jsonb_set(COALESCE(meta, '{}'::jsonb), '{SuspendedUntil}', ?::jsonb)", string(untilJSON)),
+		}).Error
+}
+
+// LiftExpiredSuspensions only lifts the suspensions that were given with an end.
+func (_i *repo) LiftExpiredSuspensions(now time.Time) (lifted int64, err error) {
+	result := _i.DB.Main.Model(&schema.User{}).
+		Where("is_suspended = ?", true).
+		Where("meta->>'SuspendedUntil' IS NOT NULL AND (meta->>'SuspendedUntil')::timestamptz <= ?", now).
+		Updates(map[string]any{
+			"is_suspended":    false,
+			"suspense_reason": "",
+			"meta":            gorm.Expr("meta - 'SuspendedUntil'"),
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+func (_i *repo) CountByStatus(businessID uint64, from time.Time, to time.Time) (counts map[schema.ReservationStatus]int64, err error) {
+	var rows []struct {
+		Status schema.ReservationStatus
+		Count  int64
+	}
+	err = _i.DB.Main.Model(&schema.Reservation{}).
+		Select("status, COUNT(*) AS count").
+		Where(&schema.Reservation{BusinessID: businessID}).
+		Where("start_time >= ? AND start_time < ?", from, to).
+		Group("status").
+		Scan(&rows).Error
+
+	counts = map[schema.ReservationStatus]int64{}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return
+}
+
+func (_i *repo) GetNoShowUsers(businessID uint64, from time.Time, to time.Time, limit int) (users []*NoShowUser, err error) {
+	err = _i.DB.Main.Model(&schema.Reservation{}).
+		Select("user_id, COUNT(*) AS no_shows").
+		Where(&schema.Reservation{BusinessID: businessID, Status: schema.ReservationStatusNoShow}).
+		Where("start_time >= ? AND start_time < ?", from, to).
+		Group("user_id").
+		Order("no_shows desc").
+		Limit(limit).
+		Scan(&users).Error
+
+	return
+}
+
+func (_i *repo) GetBusiness(id uint64) (business *schema.Business, err error) {
+	if err = _i.DB.Main.First(&business, id).Error; err != nil {
+		return nil, err
+	}
+
+	return business, nil
+}
+
+func (_i *repo) UpdateQuota(businessID uint64, quota schema.BusinessMetaQuota) error {
+	quotaJSON, err := json.Marshal(quota)
+	if err != nil {
+		return err
+	}
+
+	return _i.DB.Main.Model(&schema.Business{}).
+		Where("id = ?", businessID).
+		Update("meta", gorm.Expr("jsonb_set(COALESCE(meta, '{}'::jsonb), '{Quota}', ?::jsonb)", string(quotaJSON))).Error
+}
+
+// GetUserActiveReservations returns the paid and held reservations of the user that start in the range,
+// only on the machines of the given taxonomies when there are any.
+func (_i *repo) GetUserActiveReservations(userID uint64, businessID uint64, from time.Time, to time.Time, taxonomyIDs []uint64) (reservations []*schema.Reservation, err error) {
+	query := _i.DB.Main.
+		Where(&schema.Reservation{UserID: userID, BusinessID: businessID}).
+		Scopes(schema.ReservationTakesSlot(time.Now())).
+		Where("start_time >= ? AND start_time < ?", from, to)
+
+	if len(taxonomyIDs) > 0 {
+		query = query.Where(`product_id IN (SELECT products.id FROM products
+			JOIN posts_taxonomies ON posts_taxonomies.post_id = products.post_id
+			WHERE posts_taxonomies.taxonomy_id IN (?))`, taxonomyIDs)
+	}
+
+	err = query.Order("start_time").Find(&reservations).Error
+
+	return
+}
+
+func (_i *repo) GetPostTaxonomyIDs(postID uint64) (taxonomyIDs []uint64, err error) {
+	err = _i.DB.Main.Table("posts_taxonomies").
+		Where("post_id = ?", postID).
+		Pluck("taxonomy_id", &taxonomyIDs).Error
+
+	return
+}
+
+// ExpireHolds gives up the holds that were not paid in time.
+func (_i *repo) ExpireHolds(heldBefore time.Time) (expired int64, err error) {
+	result := _i.DB.Main.Model(&schema.Reservation{}).
+		Where("status = ? AND created_at <= ?", schema.ReservationStatusHeld, heldBefore).
+		Update("status", schema.ReservationStatusExpired)
+
+	return result.RowsAffected, result.Error
+}
+
+// CompleteEnded completes the reservations in use whose time is over. The reservations of the products
+// other than washing machines are never turned on, so they complete once paid and ended.
+func (_i *repo) CompleteEnded(now time.Time) (completed int64, err error) {
+	result := _i.DB.Main.Model(&schema.Reservation{}).
+		Where("end_time <= ?", now).
+		Where(`status = ? OR (status = ? AND product_id IN (SELECT id FROM products WHERE COALESCE(variant_type, '') <> ?))`,
+			schema.ReservationStatusInUse, schema.ReservationStatusReserved, schema.ProductVariantTypeWashingMachine).
+		Update("status", schema.ReservationStatusCompleted)
+
+	return result.RowsAffected, result.Error
+}
+
+// ReserveSeats counts the seats of the slot under a lock on the product, so two checkouts can not sell
+// the last seat twice. In the transaction of the order when there is one, the lock is kept until it ends.
+func (_i *repo) ReserveSeats(reservation *schema.Reservation, capacity int, maxPerUser int, tx *gorm.DB) error {
+	db := _i.DB.Main
+	if tx != nil {
+		db = tx
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", reservation.ProductID).Error; err != nil {
+			return err
+		}
+
+		var taken struct {
+			Seats     int
+			UserSeats int
+		}
+		if err := tx.Model(&schema.Reservation{}).
+			Select("COALESCE(SUM(seats), 0) AS seats, COALESCE(SUM(seats) FILTER (WHERE user_id = ?), 0) AS user_seats", reservation.UserID).
+			Where("product_id = ? AND start_time < ? AND end_time > ?", reservation.ProductID, reservation.EndTime, reservation.StartTime).
+			Scopes(schema.ReservationTakesSlot(time.Now())).
+			Scan(&taken).Error; err != nil {
+			return err
+		}
+
+		if remaining := capacity - taken.Seats; reservation.Seats > remaining {
+			if remaining <= 0 {
+				return &fiber.Error{Code: fiber.StatusBadRequest, Message: "ظرفیت این نوبت تکمیل شده است"}
+			}
+			return &fiber.Error{Code: fiber.StatusBadRequest, Message: fmt.Sprintf("تنها %d جای خالی در این نوبت باقی مانده است", remaining)}
+		}
+		if maxPerUser > 0 && taken.UserSeats+reservation.Seats > maxPerUser {
+			return &fiber.Error{Code: fiber.StatusBadRequest, Message: fmt.Sprintf("هر کاربر حداکثر %d جا در هر نوبت می‌تواند رزرو کند", maxPerUser)}
+		}
+
+		return tx.Create(reservation).Error
+	})
 }
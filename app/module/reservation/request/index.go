@@ -16,22 +16,61 @@ type Reservation struct {
 }
 
 type Reservations struct {
-	BusinessID          uint64
-	UserID              uint64
-	Mobile              string
-	FullName            string
-	ProductID           uint64
-	CityID              uint64
-	WorkspaceID         uint64
-	DormitoryID         uint64
-	StartTime           *time.Time
-	EndTime             *time.Time
-	WithUsageCount      uint64
-	Taxonomies          []uint64
-	Status              schema.ReservationStatus
-	Pagination          *paginator.Pagination
-	TurnOnReminderSent  *bool // Filter for turn on reminder sent status
-	TurnOffReminderSent *bool // Filter for turn off reminder sent status
+	BusinessID     uint64
+	UserID         uint64
+	Mobile         string
+	FullName       string
+	ProductID      uint64
+	CityID         uint64
+	WorkspaceID    uint64
+	DormitoryID    uint64
+	StartTime      *time.Time
+	EndTime        *time.Time
+	WithUsageCount uint64
+	Taxonomies     []uint64
+	Status         schema.ReservationStatus
+	Statuses       []schema.ReservationStatus
+	Pagination     *paginator.Pagination
+}
+
+type Availability struct {
+	BusinessID uint64
+	StartDate  time.Time
+	EndDate    time.Time
+	TaxonomyID uint64
+	PostID     uint64
+	ProductID  uint64
+}
+
+type NoShowReport struct {
+	BusinessID uint64
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+type Usage struct {
+	BusinessID uint64
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+type Quota struct {
+	BusinessID            uint64
+	MaxActiveReservations int                            `example:"3" validate:"min=0"`
+	MaxWeeklyHours        float64                        `example:"6" validate:"min=0"`
+	MaxPeakSlotsPerDay    int                            `example:"1" validate:"min=0"`
+	PeakHours             []schema.ProductMetaDeviceHour `validate:"dive"`
+	ExemptUserIDs         []uint64                       `example:"1,2"`
+}
+
+func (req *Quota) ToDomain() schema.BusinessMetaQuota {
+	return schema.BusinessMetaQuota{
+		MaxActiveReservations: req.MaxActiveReservations,
+		MaxWeeklyHours:        req.MaxWeeklyHours,
+		MaxPeakSlotsPerDay:    req.MaxPeakSlotsPerDay,
+		PeakHours:             req.PeakHours,
+		ExemptUserIDs:         req.ExemptUserIDs,
+	}
 }
 
 func (req *Reservation) ToDomain() *schema.Reservation {
@@ -5,6 +5,7 @@ import (
 	"go-fiber-starter/app/database/schema"
 	bresponse "go-fiber-starter/app/module/business/response"
 	uresponse "go-fiber-starter/app/module/user/response"
+	"go-fiber-starter/utils/timezone"
 	"time"
 )
 
@@ -62,8 +63,93 @@ func FromDomain(item *schema.Reservation) (res *Reservation) {
 		}
 	}
 
-	res.EndTimeDisplay = ptime.New(res.EndTime).Format("HH:mm - yyyy/MM/dd")
-	res.StartTimeDisplay = ptime.New(res.StartTime).Format("HH:mm - yyyy/MM/dd")
+	loc := timezone.In(item.BusinessID)
+	res.EndTimeDisplay = ptime.New(res.EndTime.In(loc)).Format("HH:mm - yyyy/MM/dd")
+	res.StartTimeDisplay = ptime.New(res.StartTime.In(loc)).Format("HH:mm - yyyy/MM/dd")
 
 	return res
 }
+
+type AvailabilityStatus string
+
+const (
+	AvailabilityStatusFree        AvailabilityStatus = "free"
+	AvailabilityStatusHeld        AvailabilityStatus = "held"   // in the payment window of another order
+	AvailabilityStatusBooked      AvailabilityStatus = "booked" // paid reservation
+	AvailabilityStatusUnavailable AvailabilityStatus = "unavailable"
+)
+
+type AvailabilityReason string
+
+const (
+	AvailabilityReasonMachineOff      AvailabilityReason = "machineOff"
+	AvailabilityReasonPast            AvailabilityReason = "past"
+	AvailabilityReasonOutsideSchedule AvailabilityReason = "outsideSchedule"
+)
+
+type AvailabilitySlot struct {
+	ID        string
+	Date      string
+	From      string
+	To        string
+	StartTime time.Time
+	EndTime   time.Time
+	Status    AvailabilityStatus
+	Reason    AvailabilityReason `json:",omitempty"`
+	// classes only, the seats are shared between the users
+	Capacity       int  `json:",omitempty"`
+	RemainingSeats *int `json:",omitempty"`
+}
+
+type MachineAvailability struct {
+	ProductID     uint64
+	PostID        uint64
+	PostTitle     string
+	ProductSKU    string
+	MachineStatus schema.UniWashMachineStatus `json:",omitempty"`
+	Slots         []AvailabilitySlot
+}
+
+type NoShowReport struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Total     int64
+	NoShows   int64
+	Rate      float64 // percent of the reservations in the range
+	Users     []*NoShowUser
+}
+
+type NoShowUser struct {
+	UserID         uint64
+	FullName       string
+	Mobile         uint64
+	NoShows        int64
+	ActiveStrikes  int64
+	IsSuspended    bool
+	SuspendedUntil *time.Time `json:",omitempty"`
+}
+
+type QuotaUsage struct {
+	MaxActiveReservations int                            `json:",omitempty"`
+	MaxWeeklyHours        float64                        `json:",omitempty"`
+	MaxPeakSlotsPerDay    int                            `json:",omitempty"`
+	PeakHours             []schema.ProductMetaDeviceHour `json:",omitempty"`
+	IsExempt              bool
+	ActiveReservations    int
+	WeeklyHours           float64
+}
+
+type Usage struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Booked    int64   // paid, whatever happened to them later
+	Used      int64   // turned on or completed
+	Rate      float64 // percent of the booked reservations that were used
+	Statuses  []*UsageStatus
+}
+
+type UsageStatus struct {
+	Status schema.ReservationStatus
+	Title  string
+	Count  int64
+}
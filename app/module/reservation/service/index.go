@@ -1,14 +1,28 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"go-fiber-starter/app/database/schema"
 	oirequest "go-fiber-starter/app/module/orderItem/request"
+	outboxService "go-fiber-starter/app/module/outbox/service"
 	prepository "go-fiber-starter/app/module/product/repository"
+	prequest "go-fiber-starter/app/module/product/request"
 	"go-fiber-starter/app/module/reservation/repository"
 	"go-fiber-starter/app/module/reservation/request"
 	"go-fiber-starter/app/module/reservation/response"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/config"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	ptime "github.com/yaa110/go-persian-calendar"
+	"gorm.io/gorm"
 )
 
 type IService interface {
@@ -18,17 +32,50 @@ type IService interface {
 	Update(id uint64, req request.Reservation) (err error)
 	Destroy(id uint64) error
 	IsReservable(req oirequest.OrderItem, businessID uint64) (err error)
+	Availability(req request.Availability) (machines []*response.MachineAvailability, err error)
+	MarkNoShows() error
+	LiftSuspensions() error
+	CheckBookingRights(userID uint64, businessID uint64) error
+	NoShowReport(req request.NoShowReport) (report *response.NoShowReport, err error)
+	Quota(businessID uint64) (quota schema.BusinessMetaQuota, err error)
+	UpdateQuota(req request.Quota) error
+	QuotaUsage(businessID uint64, userID uint64) (usage *response.QuotaUsage, err error)
+	CheckQuota(item oirequest.OrderItem, product *schema.Product, userID uint64, businessID uint64, movingID uint64) error
+	ReserveSeats(item oirequest.OrderItem, product *schema.Product, userID uint64, businessID uint64, tx *gorm.DB) (reservationID *uint64, err error)
+	AdvanceLifecycle() error
+	Usage(req request.Usage) (usage *response.Usage, err error)
 }
 
-func Service(Repo repository.IRepository, pRepo prepository.IRepository) IService {
-	return &service{Repo, pRepo}
+func Service(Repo repository.IRepository, pRepo prepository.IRepository, cfg *config.Config, outboxService outboxService.IService) IService {
+	return &service{
+		Repo:              Repo,
+		ProductRepo:       pRepo,
+		Outbox:            outboxService,
+		NoShow:            newNoShowPolicy(cfg),
+		availabilityCache: map[request.Availability]availabilityCacheItem{},
+	}
 }
 
 type service struct {
 	Repo        repository.IRepository
 	ProductRepo prepository.IRepository
+	Outbox      outboxService.IService
+	NoShow      noShowPolicy
+
+	availabilityMu    sync.Mutex
+	availabilityCache map[request.Availability]availabilityCacheItem
 }
 
+type availabilityCacheItem struct {
+	expiresAt time.Time
+	machines  []*response.MachineAvailability
+}
+
+const (
+	availabilityCacheTTL = 10 * time.Second
+	availabilityMaxDays  = 14
+)
+
 func (_i *service) Index(req request.Reservations) (reservations []*response.Reservation, paging paginator.Pagination, err error) {
 	results, paging, err := _i.Repo.GetAll(req)
 	if err != nil {
@@ -82,3 +129,567 @@ func (_i *service) IsReservable(req oirequest.OrderItem, businessID uint64) (err
 
 	return nil
 }
+
+// Availability builds the slot grid of every reservable machine in the range,
+// with all the overlapping reservations loaded in one query.
+func (_i *service) Availability(req request.Availability) (machines []*response.MachineAvailability, err error) {
+	if req.EndDate.Before(req.StartDate) {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "تاریخ شروع پس از پایان است"}
+	}
+	if req.EndDate.Sub(req.StartDate) > availabilityMaxDays*24*time.Hour {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: fmt.Sprintf("بازه زمانی حداکثر %d روز می تواند باشد", availabilityMaxDays)}
+	}
+
+	_i.availabilityMu.Lock()
+	cached, ok := _i.availabilityCache[req]
+	_i.availabilityMu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.machines, nil
+	}
+
+	products, err := _i.ProductRepo.GetReservableVariants(prequest.ReservableVariants{
+		BusinessID: req.BusinessID,
+		TaxonomyID: req.TaxonomyID,
+		PostID:     req.PostID,
+		ProductID:  req.ProductID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	machines = make([]*response.MachineAvailability, 0, len(products))
+	if len(products) > 0 {
+		rangeEnd := req.EndDate.AddDate(0, 0, 1)
+
+		productIDs := make([]uint64, 0, len(products))
+		for _, product := range products {
+			productIDs = append(productIDs, product.ID)
+		}
+
+		reservations, err := _i.Repo.GetActiveInRange(productIDs, req.StartDate, rangeEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		byProduct := map[uint64][]*schema.Reservation{}
+		for _, reservation := range reservations {
+			byProduct[reservation.ProductID] = append(byProduct[reservation.ProductID], reservation)
+		}
+
+		now := time.Now()
+		for _, product := range products {
+			machines = append(machines, machineAvailability(product, byProduct[product.ID], req.StartDate, rangeEnd, now))
+		}
+	}
+
+	_i.availabilityMu.Lock()
+	for key, item := range _i.availabilityCache {
+		if time.Now().After(item.expiresAt) {
+			delete(_i.availabilityCache, key)
+		}
+	}
+	_i.availabilityCache[req] = availabilityCacheItem{expiresAt: time.Now().Add(availabilityCacheTTL), machines: machines}
+	_i.availabilityMu.Unlock()
+
+	return machines, nil
+}
+
+func machineAvailability(product *schema.Product, reservations []*schema.Reservation, start time.Time, end time.Time, now time.Time) *response.MachineAvailability {
+	machine := &response.MachineAvailability{
+		ProductID:     product.ID,
+		PostID:        product.PostID,
+		PostTitle:     product.Post.Title,
+		ProductSKU:    product.Meta.SKU,
+		MachineStatus: product.Meta.UniWashMachineStatus,
+		Slots:         []response.AvailabilitySlot{},
+	}
+
+	options := product.Meta.GetReservationOptions()
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+
+		for _, hour := range options[day.Weekday()] {
+			slotStart, slotEnd := hour.TimesOn(day)
+			slot := response.AvailabilitySlot{
+				ID:        hour.ID,
+				Date:      date,
+				From:      hour.From,
+				To:        hour.To,
+				StartTime: slotStart,
+				EndTime:   slotEnd,
+				Status:    response.AvailabilityStatusFree,
+			}
+
+			switch {
+			case product.Meta.UniWashMachineStatus == schema.UniWashMachineStatusOFF:
+				slot.Status, slot.Reason = response.AvailabilityStatusUnavailable, response.AvailabilityReasonMachineOff
+			case product.Meta.IsBlackoutDate(date),
+				!product.Meta.CouldReserveUntil.IsZero() && slotStart.After(product.Meta.CouldReserveUntil):
+				slot.Status, slot.Reason = response.AvailabilityStatusUnavailable, response.AvailabilityReasonOutsideSchedule
+			case slotStart.Before(now):
+				slot.Status, slot.Reason = response.AvailabilityStatusUnavailable, response.AvailabilityReasonPast
+			}
+
+			if capacity := product.Meta.SeatCapacity(); capacity > 1 {
+				slot.Capacity = capacity
+				if slot.Status == response.AvailabilityStatusFree {
+					slot.Status, slot.RemainingSeats = seatsStatus(reservations, slotStart, slotEnd, capacity)
+				}
+			} else if slot.Status == response.AvailabilityStatusFree {
+				for _, reservation := range reservations {
+					if !reservation.StartTime.Before(slotEnd) || !reservation.EndTime.After(slotStart) {
+						continue
+					}
+
+					if reservation.Status == schema.ReservationStatusHeld {
+						slot.Status = response.AvailabilityStatusHeld
+					} else {
+						slot.Status = response.AvailabilityStatusBooked
+						break
+					}
+				}
+			}
+
+			machine.Slots = append(machine.Slots, slot)
+		}
+	}
+
+	return machine
+}
+
+// seatsStatus is free while a seat is left, a full slot is held if any of its seats is only held.
+func seatsStatus(reservations []*schema.Reservation, slotStart time.Time, slotEnd time.Time, capacity int) (response.AvailabilityStatus, *int) {
+	taken, held := 0, false
+	for _, reservation := range reservations {
+		if !reservation.StartTime.Before(slotEnd) || !reservation.EndTime.After(slotStart) {
+			continue
+		}
+
+		taken += max(reservation.Seats, 1)
+		held = held || reservation.Status == schema.ReservationStatusHeld
+	}
+
+	remaining := max(capacity-taken, 0)
+	switch {
+	case remaining > 0:
+		return response.AvailabilityStatusFree, &remaining
+	case held:
+		return response.AvailabilityStatusHeld, &remaining
+	default:
+		return response.AvailabilityStatusBooked, &remaining
+	}
+}
+
+// noShowPolicy decides when a reservation counts as a no-show and when the user loses booking rights.
+type noShowPolicy struct {
+	MaxStrikes   int64
+	StrikeWindow time.Duration
+	Suspension   time.Duration
+}
+
+const (
+	// the user has this long after the start of the slot to turn the machine on
+	noShowGrace = 10 * time.Minute
+	// older reservations are left alone, so a long outage of the cron does not strike everyone
+	noShowLookBack  = 24 * time.Hour
+	noShowReportTop = 50
+)
+
+func newNoShowPolicy(cfg *config.Config) noShowPolicy {
+	policy := noShowPolicy{
+		MaxStrikes:   int64(cfg.Services.NoShow.MaxStrikes),
+		StrikeWindow: cfg.Services.NoShow.StrikeWindow * time.Second,
+		Suspension:   cfg.Services.NoShow.Suspension * time.Second,
+	}
+
+	if policy.MaxStrikes <= 0 {
+		policy.MaxStrikes = 3
+	}
+	if policy.StrikeWindow <= 0 {
+		policy.StrikeWindow = 30 * 24 * time.Hour
+	}
+	if policy.Suspension <= 0 {
+		policy.Suspension = 7 * 24 * time.Hour
+	}
+
+	return policy
+}
+
+// MarkNoShows marks the reservations whose machine was never turned on and strikes their users.
+func (_i *service) MarkNoShows() error {
+	now := time.Now()
+	reservations, err := _i.Repo.GetNoShowCandidates(now.Add(-noShowGrace), now.Add(-noShowLookBack))
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, reservation := range reservations {
+		if err := _i.markNoShow(reservation, now); err != nil {
+			errs = append(errs, fmt.Errorf("reservation %d: %w", reservation.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (_i *service) markNoShow(reservation *schema.Reservation, now time.Time) error {
+	marked, err := _i.Repo.MarkNoShow(reservation.ID)
+	if err != nil || !marked {
+		return err
+	}
+
+	err = _i.Repo.CreateStrike(&schema.UserStrike{
+		UserID:        reservation.UserID,
+		BusinessID:    reservation.BusinessID,
+		ReservationID: reservation.ID,
+		Reason:        schema.StrikeReasonNoShow,
+	})
+	if err != nil {
+		return err
+	}
+
+	strikes, err := _i.Repo.CountStrikes(reservation.UserID, now.Add(-_i.NoShow.StrikeWindow))
+	if err != nil {
+		return err
+	}
+	if strikes < _i.NoShow.MaxStrikes || isSuspended(&reservation.User) {
+		return nil
+	}
+
+	until := now.Add(_i.NoShow.Suspension)
+	reason := fmt.Sprintf("عدم حضور در %d نوبت رزرو شده", strikes)
+	if err = _i.Repo.SuspendUser(reservation.UserID, reason, until); err != nil {
+		return err
+	}
+
+	// the suspension stands even if the user could not be told about it
+	message := schema.NewSms(schema.OutboxPurposeReservation, reservation.User.Mobile, schema.SmsNoShowSuspension,
+		fmt.Sprintf("%d", strikes),
+		ptime.New(until.In(timezone.In(reservation.BusinessID))).Format("HH:mm - yyyy/MM/dd"),
+	)
+	message.BusinessID = &reservation.BusinessID
+	_ = _i.Outbox.Enqueue(nil, message)
+
+	return nil
+}
+
+func (_i *service) LiftSuspensions() error {
+	_, err := _i.Repo.LiftExpiredSuspensions(time.Now())
+	return err
+}
+
+// CheckBookingRights reads the user from the database, as the token may predate the suspension.
+func (_i *service) CheckBookingRights(userID uint64, businessID uint64) error {
+	user, err := _i.Repo.GetUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !isSuspended(user) {
+		return nil
+	}
+
+	if user.Meta != nil && user.Meta.SuspendedUntil != nil {
+		if user.Meta.SuspendedUntil.Before(time.Now()) {
+			return nil
+		}
+
+		return &fiber.Error{
+			Code: fiber.StatusForbidden,
+			Message: fmt.Sprintf(
+				"به دلیل عدم حضور در نوبت‌های قبلی، امکان رزرو تا %s از شما گرفته شده است",
+				ptime.New(user.Meta.SuspendedUntil.In(timezone.In(businessID))).Format("HH:mm - yyyy/MM/dd"),
+			),
+		}
+	}
+
+	return &fiber.Error{
+		Code:    fiber.StatusForbidden,
+		Message: "حساب کاربری شما تعلیق شده است و امکان رزرو ندارید",
+	}
+}
+
+// the order the statuses are reported in
+var reservationLifecycle = []schema.ReservationStatus{
+	schema.ReservationStatusHeld,
+	schema.ReservationStatusReserved,
+	schema.ReservationStatusInUse,
+	schema.ReservationStatusCompleted,
+	schema.ReservationStatusCanceled,
+	schema.ReservationStatusNoShow,
+	schema.ReservationStatusExpired,
+}
+
+// isBooked reports whether the reservation was paid and kept, whether or not it was used.
+func isBooked(status schema.ReservationStatus) bool {
+	switch status {
+	case schema.ReservationStatusReserved, schema.ReservationStatusInUse, schema.ReservationStatusCompleted, schema.ReservationStatusNoShow:
+		return true
+	}
+	return false
+}
+
+// AdvanceLifecycle moves the reservations on by the time: unpaid holds expire and the used ones complete.
+// Turning the machine on and off moves them too, in the uniwash service.
+func (_i *service) AdvanceLifecycle() error {
+	now := time.Now()
+
+	if _, err := _i.Repo.ExpireHolds(now.Add(-schema.ReservationHoldDuration)); err != nil {
+		return err
+	}
+
+	_, err := _i.Repo.CompleteEnded(now)
+	return err
+}
+
+func (_i *service) Usage(req request.Usage) (usage *response.Usage, err error) {
+	counts, err := _i.Repo.CountByStatus(req.BusinessID, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	usage = &response.Usage{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		Used:      counts[schema.ReservationStatusInUse] + counts[schema.ReservationStatusCompleted],
+	}
+	for _, status := range reservationLifecycle {
+		if isBooked(status) {
+			usage.Booked += counts[status]
+		}
+		usage.Statuses = append(usage.Statuses, &response.UsageStatus{
+			Status: status,
+			Title:  schema.ReservationStatusProxy[status],
+			Count:  counts[status],
+		})
+	}
+	if usage.Booked > 0 {
+		usage.Rate = float64(usage.Used) * 100 / float64(usage.Booked)
+	}
+
+	return usage, nil
+}
+
+func isSuspended(user *schema.User) bool {
+	return user.IsSuspended != nil && *user.IsSuspended
+}
+
+func (_i *service) NoShowReport(req request.NoShowReport) (report *response.NoShowReport, err error) {
+	counts, err := _i.Repo.CountByStatus(req.BusinessID, req.StartDate, req.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	report = &response.NoShowReport{
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		NoShows:   counts[schema.ReservationStatusNoShow],
+		Users:     []*response.NoShowUser{},
+	}
+	for status, count := range counts {
+		if isBooked(status) {
+			report.Total += count
+		}
+	}
+	if report.Total > 0 {
+		report.Rate = float64(report.NoShows) * 100 / float64(report.Total)
+	}
+
+	users, err := _i.Repo.GetNoShowUsers(req.BusinessID, req.StartDate, req.EndDate, noShowReportTop)
+	if err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-_i.NoShow.StrikeWindow)
+	for _, item := range users {
+		user, err := _i.Repo.GetUser(item.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		strikes, err := _i.Repo.CountStrikes(item.UserID, since)
+		if err != nil {
+			return nil, err
+		}
+
+		row := &response.NoShowUser{
+			UserID:        user.ID,
+			FullName:      user.FullName(),
+			Mobile:        user.Mobile,
+			NoShows:       item.NoShows,
+			ActiveStrikes: strikes,
+			IsSuspended:   isSuspended(user),
+		}
+		if user.Meta != nil {
+			row.SuspendedUntil = user.Meta.SuspendedUntil
+		}
+
+		report.Users = append(report.Users, row)
+	}
+
+	return report, nil
+}
+
+func (_i *service) Quota(businessID uint64) (quota schema.BusinessMetaQuota, err error) {
+	business, err := _i.Repo.GetBusiness(businessID)
+	if err != nil {
+		return quota, err
+	}
+
+	return business.Meta.Quota, nil
+}
+
+func (_i *service) UpdateQuota(req request.Quota) error {
+	return _i.Repo.UpdateQuota(req.BusinessID, req.ToDomain())
+}
+
+func (_i *service) QuotaUsage(businessID uint64, userID uint64) (usage *response.QuotaUsage, err error) {
+	quota, err := _i.Quota(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	upcoming, err := _i.Repo.GetUserActiveReservations(userID, businessID, now, now.AddDate(1, 0, 0), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	weekStart := timezone.ForBusiness(businessID).StartOfWeek(now)
+	weekly, err := _i.Repo.GetUserActiveReservations(userID, businessID, weekStart, weekStart.AddDate(0, 0, 7), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.QuotaUsage{
+		MaxActiveReservations: quota.MaxActiveReservations,
+		MaxWeeklyHours:        quota.MaxWeeklyHours,
+		MaxPeakSlotsPerDay:    quota.MaxPeakSlotsPerDay,
+		PeakHours:             quota.PeakHours,
+		IsExempt:              quota.IsExempt(userID),
+		ActiveReservations:    len(upcoming),
+		WeeklyHours:           reservedHours(weekly),
+	}, nil
+}
+
+// CheckQuota runs before the slot is held, the holds of the earlier items of the same order count too.
+// The reservation that is being moved to the slot, if any, is left out of the usage.
+func (_i *service) CheckQuota(item oirequest.OrderItem, product *schema.Product, userID uint64, businessID uint64, movingID uint64) error {
+	quota, err := _i.Quota(businessID)
+	if err != nil {
+		return err
+	}
+	if quota.IsExempt(userID) {
+		return nil
+	}
+
+	settings := timezone.ForBusiness(businessID)
+	start, end := item.GetStartDateTime(settings.Location), item.GetEndDateTime(settings.Location)
+
+	if quota.MaxActiveReservations > 0 {
+		now := time.Now()
+		upcoming, err := _i.activeReservations(userID, businessID, now, now.AddDate(1, 0, 0), nil, movingID)
+		if err != nil {
+			return err
+		}
+		if len(upcoming) >= quota.MaxActiveReservations {
+			return &fiber.Error{
+				Code:    fiber.StatusBadRequest,
+				Message: fmt.Sprintf("شما حداکثر %d رزرو فعال می‌توانید داشته باشید، پس از استفاده از نوبت‌های قبلی دوباره تلاش کنید", quota.MaxActiveReservations),
+			}
+		}
+	}
+
+	if quota.MaxWeeklyHours > 0 {
+		weekStart := settings.StartOfWeek(start)
+		weekly, err := _i.activeReservations(userID, businessID, weekStart, weekStart.AddDate(0, 0, 7), nil, movingID)
+		if err != nil {
+			return err
+		}
+		if reservedHours(weekly)+end.Sub(start).Hours() > quota.MaxWeeklyHours {
+			return &fiber.Error{
+				Code:    fiber.StatusBadRequest,
+				Message: fmt.Sprintf("سقف رزرو هر کاربر %s ساعت در هفته است و با این رزرو از آن بیشتر می‌شود", strconv.FormatFloat(quota.MaxWeeklyHours, 'f', -1, 64)),
+			}
+		}
+	}
+
+	if quota.MaxPeakSlotsPerDay > 0 && quota.IsPeak(item.StartTime, item.EndTime) {
+		var taxonomyIDs []uint64
+		if product.PostID != 0 {
+			if taxonomyIDs, err = _i.Repo.GetPostTaxonomyIDs(product.PostID); err != nil {
+				return err
+			}
+		}
+
+		day := utils.StartOfDay(start)
+		sameDay, err := _i.activeReservations(userID, businessID, day, day.AddDate(0, 0, 1), taxonomyIDs, movingID)
+		if err != nil {
+			return err
+		}
+
+		peaks := 0
+		for _, reservation := range sameDay {
+			if quota.IsPeak(clock(reservation.StartTime, settings.Location), clock(reservation.EndTime, settings.Location)) {
+				peaks++
+			}
+		}
+		if peaks >= quota.MaxPeakSlotsPerDay {
+			return &fiber.Error{
+				Code:    fiber.StatusBadRequest,
+				Message: fmt.Sprintf("در هر روز تنها %d نوبت در ساعات پرتردد این خوابگاه می‌توانید رزرو کنید", quota.MaxPeakSlotsPerDay),
+			}
+		}
+	}
+
+	return nil
+}
+
+// ReserveSeats holds the seats of the order item, its quantity, until the payment. The slots of the
+// products without a capacity have a single seat, so they work like a court booked by one user.
+func (_i *service) ReserveSeats(item oirequest.OrderItem, product *schema.Product, userID uint64, businessID uint64, tx *gorm.DB) (reservationID *uint64, err error) {
+	seats := max(item.Quantity, 1)
+	capacity := product.Meta.SeatCapacity()
+
+	loc := timezone.In(businessID)
+	reservation := &schema.Reservation{
+		UserID:     userID,
+		BusinessID: businessID,
+		ProductID:  product.ID,
+		StartTime:  item.GetStartDateTime(loc),
+		EndTime:    item.GetEndDateTime(loc),
+		Status:     schema.ReservationStatusHeld,
+		Seats:      seats,
+		Shared:     capacity > 1,
+	}
+	if err = _i.Repo.ReserveSeats(reservation, capacity, int(product.Meta.ReservationMaxSeatsPerUser), tx); err != nil {
+		return nil, err
+	}
+
+	return &reservation.ID, nil
+}
+
+func (_i *service) activeReservations(userID uint64, businessID uint64, from time.Time, to time.Time, taxonomyIDs []uint64, exceptID uint64) ([]*schema.Reservation, error) {
+	reservations, err := _i.Repo.GetUserActiveReservations(userID, businessID, from, to, taxonomyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(reservations, func(reservation *schema.Reservation) bool {
+		return reservation.ID == exceptID
+	}), nil
+}
+
+func reservedHours(reservations []*schema.Reservation) (hours float64) {
+	for _, reservation := range reservations {
+		hours += reservation.EndTime.Sub(reservation.StartTime).Hours()
+	}
+
+	return hours
+}
+
+// clock formats the time like the reservation options of the products.
+func clock(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(time.TimeOnly)
+}
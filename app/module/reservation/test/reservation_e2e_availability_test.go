@@ -0,0 +1,91 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+)
+
+// =============================================================================
+// AVAILABILITY TESTS - GET /v1/user/business/:businessID/reservations/availability
+// =============================================================================
+
+func TestAvailability_SlotStatuses(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+
+	loc, _ := time.LoadLocation("Asia/Tehran")
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 1)
+	day := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, loc)
+
+	post := ta.CreateTestPost(t, "Machine", schema.PostTypeProduct, setup.Business.ID, setup.User.ID)
+	variantType := schema.ProductVariantTypeWashingMachine
+	product := ta.CreateTestProduct(t, post.ID, setup.Business.ID, 10000, schema.ProductTypeVariant, &variantType)
+	product.Meta.ReservationOptions = schema.ProductMetaReservationOptions{
+		day.Weekday(): {{From: "10:00:00", To: "12:00:00"}},
+	}
+	ta.DB.Save(product)
+
+	ta.CreateTestReservation(t, setup.User.ID, product.ID, setup.Business.ID, day.Add(10*time.Hour), day.Add(11*time.Hour), schema.ReservationStatusReserved)
+
+	date := day.Format(time.DateOnly)
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/user/business/%d/reservations/availability?StartDate=%s&EndDate=%s", setup.Business.ID, date, date), nil, setup.Token)
+	AssertOK(t, resp)
+
+	machines := GetDataFromResponse(t, resp)
+	if len(machines) != 1 {
+		t.Fatalf("expected 1 machine, got %d", len(machines))
+	}
+
+	slots, _ := machines[0].(map[string]interface{})["Slots"].([]interface{})
+	if len(slots) != 2 {
+		t.Fatalf("expected 2 slots, got %d", len(slots))
+	}
+
+	if status := slots[0].(map[string]interface{})["Status"]; status != "booked" {
+		t.Errorf("expected first slot to be booked, got %v", status)
+	}
+	if status := slots[1].(map[string]interface{})["Status"]; status != "free" {
+		t.Errorf("expected second slot to be free, got %v", status)
+	}
+}
+
+func TestAvailability_MachineOff(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+
+	post := ta.CreateTestPost(t, "Machine", schema.PostTypeProduct, setup.Business.ID, setup.User.ID)
+	variantType := schema.ProductVariantTypeWashingMachine
+	product := ta.CreateTestProduct(t, post.ID, setup.Business.ID, 10000, schema.ProductTypeVariant, &variantType)
+	product.Meta.UniWashMachineStatus = schema.UniWashMachineStatusOFF
+	ta.DB.Save(product)
+
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/user/business/%d/reservations/availability?ProductID=%d", setup.Business.ID, product.ID), nil, setup.Token)
+	AssertOK(t, resp)
+
+	machines := GetDataFromResponse(t, resp)
+	if len(machines) != 1 {
+		t.Fatalf("expected 1 machine, got %d", len(machines))
+	}
+
+	for _, slot := range machines[0].(map[string]interface{})["Slots"].([]interface{}) {
+		if reason := slot.(map[string]interface{})["Reason"]; reason != "machineOff" {
+			t.Fatalf("expected all slots unavailable because the machine is off, got %v", reason)
+		}
+	}
+}
+
+func TestAvailability_Unauthorized(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	resp := ta.MakeUnauthenticatedRequest(t, http.MethodGet, "/v1/user/business/1/reservations/availability", nil)
+	AssertUnauthorized(t, resp)
+}
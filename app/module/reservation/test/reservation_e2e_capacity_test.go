@@ -0,0 +1,98 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
+)
+
+// =============================================================================
+// CAPACITY TESTS - classes, a slot is shared by the users until its seats run out
+// =============================================================================
+
+func createTestClass(t *testing.T, ta *TestApp, setup *TestSetup, day time.Time, capacity uint, maxSeatsPerUser uint) *schema.Product {
+	t.Helper()
+
+	post := ta.CreateTestPost(t, "Yoga", schema.PostTypeProduct, setup.Business.ID, setup.User.ID)
+	product := ta.CreateTestProduct(t, post.ID, setup.Business.ID, 50000, schema.ProductTypeReservable, nil)
+	product.Meta.ReservationCapacity = capacity
+	product.Meta.ReservationMaxSeatsPerUser = maxSeatsPerUser
+	product.Meta.ReservationOptions = schema.ProductMetaReservationOptions{
+		day.Weekday(): {{From: "18:00:00", To: "19:00:00"}},
+	}
+	ta.DB.Save(product)
+
+	return product
+}
+
+func TestCapacity_SeatsRunOut(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+
+	loc, _ := time.LoadLocation("Asia/Tehran")
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 1)
+	day := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, loc)
+	class := createTestClass(t, ta, setup, day, 3, 2)
+
+	item := func(seats int) oirequest.OrderItem {
+		return oirequest.OrderItem{Quantity: seats, ProductID: class.ID, Date: day.Format(time.DateOnly), StartTime: "18:00:00", EndTime: "19:00:00"}
+	}
+
+	other := ta.CreateTestUser(t, 9300000001, "", "Other", "User", setup.Business.ID, nil)
+	third := ta.CreateTestUser(t, 9300000002, "", "Third", "User", setup.Business.ID, nil)
+
+	// two users share the slot
+	if _, err := ta.Service.ReserveSeats(item(2), class, setup.User.ID, setup.Business.ID, nil); err != nil {
+		t.Fatalf("expected the first seats to be reserved, got %v", err)
+	}
+	if _, err := ta.Service.ReserveSeats(item(3), class, other.ID, setup.Business.ID, nil); err == nil {
+		t.Error("expected more seats than the user limit to be refused")
+	}
+	if _, err := ta.Service.ReserveSeats(item(1), class, setup.User.ID, setup.Business.ID, nil); err == nil {
+		t.Error("expected the user limit to count the seats the user already has")
+	}
+	if _, err := ta.Service.ReserveSeats(item(2), class, other.ID, setup.Business.ID, nil); err == nil {
+		t.Error("expected more seats than are left to be refused")
+	}
+
+	// the class has a single slot a week, so the end of the range only changes the cached answer
+	slotOf := func(endDate time.Time) map[string]interface{} {
+		path := fmt.Sprintf("/v1/user/business/%d/reservations/availability?ProductID=%d&StartDate=%s&EndDate=%s", setup.Business.ID, class.ID, day.Format(time.DateOnly), endDate.Format(time.DateOnly))
+		resp := ta.MakeRequest(t, http.MethodGet, path, nil, setup.Token)
+		AssertOK(t, resp)
+
+		machines := GetDataFromResponse(t, resp)
+		if len(machines) != 1 {
+			t.Fatalf("expected 1 class, got %d", len(machines))
+		}
+		slots, _ := machines[0].(map[string]interface{})["Slots"].([]interface{})
+		if len(slots) != 1 {
+			t.Fatalf("expected 1 slot, got %d", len(slots))
+		}
+
+		return slots[0].(map[string]interface{})
+	}
+
+	slot := slotOf(day)
+	if slot["Status"] != "free" || slot["RemainingSeats"] != float64(1) || slot["Capacity"] != float64(3) {
+		t.Errorf("expected a free slot with 1 of 3 seats left, got %v", slot)
+	}
+
+	if _, err := ta.Service.ReserveSeats(item(1), class, other.ID, setup.Business.ID, nil); err != nil {
+		t.Fatalf("expected the last seat to be reserved, got %v", err)
+	}
+	if _, err := ta.Service.ReserveSeats(item(1), class, third.ID, setup.Business.ID, nil); err == nil {
+		t.Error("expected a full slot to be refused")
+	}
+
+	// the seats of the held reservations fill the slot
+	if slot = slotOf(day.AddDate(0, 0, 1)); slot["Status"] != "held" || slot["RemainingSeats"] != float64(0) {
+		t.Errorf("expected a held slot without seats, got %v", slot)
+	}
+}
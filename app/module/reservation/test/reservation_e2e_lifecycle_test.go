@@ -0,0 +1,118 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+)
+
+// =============================================================================
+// LIFECYCLE TESTS
+// =============================================================================
+
+func TestLifecycle_AdvanceExpiresHoldsAndCompletesEnded(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	machine := ta.createNoShowMachine(t, setup)
+	post := ta.CreateTestPost(t, "Court", schema.PostTypeProduct, setup.Business.ID, setup.User.ID)
+	variantType := schema.ProductVariantTypeReservable
+	court := ta.CreateTestProduct(t, post.ID, setup.Business.ID, 10000, schema.ProductTypeVariant, &variantType)
+
+	now := time.Now()
+	oldHold := ta.CreateTestReservation(t, setup.User.ID, machine.ID, setup.Business.ID, now.Add(2*time.Hour), now.Add(3*time.Hour), schema.ReservationStatusHeld)
+	ta.DB.Exec("UPDATE reservations SET created_at = ? WHERE id = ?", now.Add(-schema.ReservationHoldDuration-time.Minute), oldHold.ID)
+	newHold := ta.CreateTestReservation(t, setup.User.ID, machine.ID, setup.Business.ID, now.Add(4*time.Hour), now.Add(5*time.Hour), schema.ReservationStatusHeld)
+	used := ta.CreateTestReservation(t, setup.User.ID, machine.ID, setup.Business.ID, now.Add(-2*time.Hour), now.Add(-time.Hour), schema.ReservationStatusInUse)
+	running := ta.CreateTestReservation(t, setup.User.ID, machine.ID, setup.Business.ID, now.Add(-30*time.Minute), now.Add(30*time.Minute), schema.ReservationStatusInUse)
+	missed := ta.CreateTestReservation(t, setup.User.ID, machine.ID, setup.Business.ID, now.Add(-4*time.Hour), now.Add(-3*time.Hour), schema.ReservationStatusReserved)
+	played := ta.CreateTestReservation(t, setup.User.ID, court.ID, setup.Business.ID, now.Add(-2*time.Hour), now.Add(-time.Hour), schema.ReservationStatusReserved)
+
+	if err := ta.Service.AdvanceLifecycle(); err != nil {
+		t.Fatalf("AdvanceLifecycle failed: %v", err)
+	}
+
+	expected := map[uint64]schema.ReservationStatus{
+		oldHold.ID: schema.ReservationStatusExpired,
+		newHold.ID: schema.ReservationStatusHeld,
+		used.ID:    schema.ReservationStatusCompleted,
+		running.ID: schema.ReservationStatusInUse,
+		missed.ID:  schema.ReservationStatusReserved, // left to the no-show detection
+		played.ID:  schema.ReservationStatusCompleted,
+	}
+	for id, status := range expected {
+		var reservation schema.Reservation
+		ta.DB.First(&reservation, id)
+		if reservation.Status != status {
+			t.Errorf("reservation %d: expected %s, got %s", id, status, reservation.Status)
+		}
+	}
+}
+
+func TestLifecycle_IndexHidesUnpaidUnlessAsked(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	productData := ta.CreateTestProductWithPost(t, setup.Business.ID, setup.User.ID, 10000)
+
+	now := time.Now()
+	ta.CreateTestReservation(t, setup.User.ID, productData.Product.ID, setup.Business.ID, now.Add(time.Hour), now.Add(2*time.Hour), schema.ReservationStatusReserved)
+	ta.CreateTestReservation(t, setup.User.ID, productData.Product.ID, setup.Business.ID, now.Add(3*time.Hour), now.Add(4*time.Hour), schema.ReservationStatusHeld)
+	ta.CreateTestReservation(t, setup.User.ID, productData.Product.ID, setup.Business.ID, now.Add(5*time.Hour), now.Add(6*time.Hour), schema.ReservationStatusExpired)
+	ta.CreateTestReservation(t, setup.User.ID, productData.Product.ID, setup.Business.ID, now.Add(-3*time.Hour), now.Add(-2*time.Hour), schema.ReservationStatusCompleted)
+
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/reservations", setup.Business.ID), nil, setup.Token)
+	AssertOK(t, resp)
+	AssertDataCount(t, resp, 2)
+
+	resp = ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/reservations?Status=held,expired", setup.Business.ID), nil, setup.Token)
+	AssertOK(t, resp)
+	AssertDataCount(t, resp, 2)
+
+	resp = ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/reservations?Status=unknown", setup.Business.ID), nil, setup.Token)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an unknown status, got %d", resp.StatusCode)
+	}
+}
+
+func TestLifecycle_UsageReport(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	product := ta.createNoShowMachine(t, setup)
+
+	start := time.Now().Add(-5 * time.Hour)
+	statuses := []schema.ReservationStatus{
+		schema.ReservationStatusCompleted,
+		schema.ReservationStatusCompleted,
+		schema.ReservationStatusCompleted,
+		schema.ReservationStatusNoShow,
+		schema.ReservationStatusExpired,
+		schema.ReservationStatusCanceled,
+	}
+	for i, status := range statuses {
+		from := start.Add(time.Duration(i) * 30 * time.Minute)
+		ta.CreateTestReservation(t, setup.User.ID, product.ID, setup.Business.ID, from, from.Add(30*time.Minute), status)
+	}
+
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/reservations/usage", setup.Business.ID), nil, setup.Token)
+	AssertOK(t, resp)
+
+	result := ParseResponse(t, resp)
+	data, _ := result["Data"].(map[string]interface{})
+	if data["Booked"] != float64(4) || data["Used"] != float64(3) {
+		t.Fatalf("expected 3 used out of 4 booked, got %v out of %v", data["Used"], data["Booked"])
+	}
+	if data["Rate"] != float64(75) {
+		t.Errorf("expected a usage rate of 75, got %v", data["Rate"])
+	}
+	if rows, _ := data["Statuses"].([]interface{}); len(rows) != 7 {
+		t.Errorf("expected a row for each of the 7 statuses, got %d", len(rows))
+	}
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+)
+
+// =============================================================================
+// NO-SHOW TESTS
+// =============================================================================
+
+func (ta *TestApp) createNoShowMachine(t *testing.T, setup *TestSetup) *schema.Product {
+	t.Helper()
+
+	post := ta.CreateTestPost(t, "Machine", schema.PostTypeProduct, setup.Business.ID, setup.User.ID)
+	variantType := schema.ProductVariantTypeWashingMachine
+	return ta.CreateTestProduct(t, post.ID, setup.Business.ID, 10000, schema.ProductTypeVariant, &variantType)
+}
+
+// createMissedReservation books a slot that started before the grace period was over
+func (ta *TestApp) createMissedReservation(t *testing.T, userID uint64, product *schema.Product, startedAgo time.Duration) *schema.Reservation {
+	t.Helper()
+
+	start := time.Now().Add(-startedAgo)
+	return ta.CreateTestReservation(t, userID, product.ID, product.BusinessID, start, start.Add(time.Hour), schema.ReservationStatusReserved)
+}
+
+func TestNoShow_MarksReservationAndStrikesUser(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	product := ta.createNoShowMachine(t, setup)
+	missed := ta.createMissedReservation(t, setup.User.ID, product, 20*time.Minute)
+	recent := ta.createMissedReservation(t, setup.User.ID, product, 5*time.Minute)
+
+	if err := ta.Service.MarkNoShows(); err != nil {
+		t.Fatalf("MarkNoShows failed: %v", err)
+	}
+
+	var reservation schema.Reservation
+	ta.DB.First(&reservation, missed.ID)
+	if reservation.Status != schema.ReservationStatusNoShow {
+		t.Errorf("expected the missed reservation to be noShow, got %s", reservation.Status)
+	}
+
+	ta.DB.First(&reservation, recent.ID)
+	if reservation.Status != schema.ReservationStatusReserved {
+		t.Errorf("expected the reservation in its grace period to stay reserved, got %s", reservation.Status)
+	}
+
+	var strikes int64
+	ta.DB.Model(&schema.UserStrike{}).Where("user_id = ?", setup.User.ID).Count(&strikes)
+	if strikes != 1 {
+		t.Errorf("expected 1 strike, got %d", strikes)
+	}
+
+	// a second run must not strike the same reservation again
+	if err := ta.Service.MarkNoShows(); err != nil {
+		t.Fatalf("MarkNoShows failed: %v", err)
+	}
+	ta.DB.Model(&schema.UserStrike{}).Where("user_id = ?", setup.User.ID).Count(&strikes)
+	if strikes != 1 {
+		t.Errorf("expected 1 strike after the second run, got %d", strikes)
+	}
+}
+
+func TestNoShow_SkipsReservationsWithTurnOnOrDowntime(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	product := ta.createNoShowMachine(t, setup)
+	turnedOn := ta.createMissedReservation(t, setup.User.ID, product, 20*time.Minute)
+	ta.DB.Create(&schema.MachineCommand{
+		BusinessID:    product.BusinessID,
+		ProductID:     product.ID,
+		ReservationID: &turnedOn.ID,
+		IssuerID:      setup.User.ID,
+		IssuedBy:      schema.MachineCommandIssuerUser,
+		Command:       schema.UniWashCommandON,
+		Status:        schema.MachineCommandStatusFailed,
+	})
+
+	broken := ta.createNoShowMachine(t, setup)
+	outOfService := ta.createMissedReservation(t, setup.User.ID, broken, 30*time.Minute)
+	ta.DB.Create(&schema.MachineDowntime{
+		BusinessID: broken.BusinessID,
+		ProductID:  broken.ID,
+		Reason:     schema.MachineDowntimeReasonManual,
+		StartedAt:  time.Now().Add(-2 * time.Hour),
+	})
+
+	if err := ta.Service.MarkNoShows(); err != nil {
+		t.Fatalf("MarkNoShows failed: %v", err)
+	}
+
+	for _, id := range []uint64{turnedOn.ID, outOfService.ID} {
+		var reservation schema.Reservation
+		ta.DB.First(&reservation, id)
+		if reservation.Status != schema.ReservationStatusReserved {
+			t.Errorf("expected reservation %d to stay reserved, got %s", id, reservation.Status)
+		}
+	}
+}
+
+func TestNoShow_SuspendsAfterMaxStrikes(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	product := ta.createNoShowMachine(t, setup)
+	for i := 1; i <= 3; i++ {
+		ta.createMissedReservation(t, setup.User.ID, product, time.Duration(i)*time.Hour)
+	}
+
+	if err := ta.Service.CheckBookingRights(setup.User.ID, setup.Business.ID); err != nil {
+		t.Fatalf("expected the user to be able to book before the strikes, got %v", err)
+	}
+
+	if err := ta.Service.MarkNoShows(); err != nil {
+		t.Fatalf("MarkNoShows failed: %v", err)
+	}
+
+	var user schema.User
+	ta.DB.First(&user, setup.User.ID)
+	if user.IsSuspended == nil || !*user.IsSuspended {
+		t.Fatal("expected the user to be suspended")
+	}
+	if user.Meta == nil || user.Meta.SuspendedUntil == nil || user.Meta.SuspendedUntil.Before(time.Now()) {
+		t.Fatalf("expected the suspension to end in the future, got %+v", user.Meta)
+	}
+
+	if err := ta.Service.CheckBookingRights(setup.User.ID, setup.Business.ID); err == nil {
+		t.Error("expected the suspended user to be refused")
+	}
+
+	var queued int64
+	ta.DB.Model(&schema.OutboxMessage{}).Where("purpose = ? AND template = ?", schema.OutboxPurposeReservation, schema.SmsNoShowSuspension).Count(&queued)
+	if queued != 1 {
+		t.Errorf("expected the suspension sms to be queued once, got %d", queued)
+	}
+}
+
+func TestNoShow_LiftsExpiredSuspension(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	if err := ta.ReservationRepo.SuspendUser(setup.User.ID, "test", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("SuspendUser failed: %v", err)
+	}
+
+	// an expired suspension does not block booking even before the cron lifts it
+	if err := ta.Service.CheckBookingRights(setup.User.ID, setup.Business.ID); err != nil {
+		t.Errorf("expected the expired suspension to allow booking, got %v", err)
+	}
+
+	if err := ta.Service.LiftSuspensions(); err != nil {
+		t.Fatalf("LiftSuspensions failed: %v", err)
+	}
+
+	var user schema.User
+	ta.DB.First(&user, setup.User.ID)
+	if user.IsSuspended != nil && *user.IsSuspended {
+		t.Error("expected the suspension to be lifted")
+	}
+	if user.Meta != nil && user.Meta.SuspendedUntil != nil {
+		t.Error("expected SuspendedUntil to be cleared")
+	}
+}
+
+func TestNoShow_Report(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	product := ta.createNoShowMachine(t, setup)
+	ta.createMissedReservation(t, setup.User.ID, product, 20*time.Minute)
+	ta.createMissedReservation(t, setup.User.ID, product, 2*time.Hour)
+	start := time.Now().Add(-4 * time.Hour)
+	ta.CreateTestReservation(t, setup.User.ID, product.ID, setup.Business.ID, start, start.Add(time.Hour), schema.ReservationStatusReserved)
+	ta.DB.Exec("UPDATE reservations SET meta = jsonb_set(COALESCE(meta, '{}'::jsonb), '{UniWashLastCommand}', '\"ON\"') WHERE start_time = ?", start)
+
+	if err := ta.Service.MarkNoShows(); err != nil {
+		t.Fatalf("MarkNoShows failed: %v", err)
+	}
+
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/reservations/no-shows", setup.Business.ID), nil, setup.Token)
+	AssertOK(t, resp)
+
+	result := ParseResponse(t, resp)
+	data, _ := result["Data"].(map[string]interface{})
+	if data["Total"] != float64(3) || data["NoShows"] != float64(2) {
+		t.Fatalf("expected 2 no-shows out of 3, got %v out of %v", data["NoShows"], data["Total"])
+	}
+
+	users, _ := data["Users"].([]interface{})
+	if len(users) != 1 {
+		t.Fatalf("expected 1 user in the report, got %d", len(users))
+	}
+	if strikes := users[0].(map[string]interface{})["ActiveStrikes"]; strikes != float64(2) {
+		t.Errorf("expected 2 active strikes, got %v", strikes)
+	}
+}
@@ -0,0 +1,147 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
+)
+
+// =============================================================================
+// QUOTA TESTS
+// =============================================================================
+
+func (ta *TestApp) setQuota(t *testing.T, businessID uint64, quota schema.BusinessMetaQuota) {
+	t.Helper()
+
+	if err := ta.ReservationRepo.UpdateQuota(businessID, quota); err != nil {
+		t.Fatalf("failed to set the quota: %v", err)
+	}
+}
+
+// quotaDay returns tomorrow in Tehran, so every slot of the tests is in the future
+func quotaDay() time.Time {
+	loc, _ := time.LoadLocation("Asia/Tehran")
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 1)
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, loc)
+}
+
+func quotaItem(product *schema.Product, day time.Time, from string, to string) oirequest.OrderItem {
+	return oirequest.OrderItem{
+		ProductID: product.ID,
+		Date:      day.Format(time.DateOnly),
+		StartTime: from,
+		EndTime:   to,
+	}
+}
+
+func TestQuota_UpdateAndShow(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+
+	body := map[string]interface{}{
+		"MaxActiveReservations": 2,
+		"MaxWeeklyHours":        4.5,
+		"PeakHours":             []map[string]string{{"From": "18:00:00", "To": "22:00:00"}},
+		"ExemptUserIDs":         []uint64{setup.User.ID},
+	}
+	resp := ta.MakeRequest(t, http.MethodPut, fmt.Sprintf("/v1/business/%d/reservations/quota", setup.Business.ID), body, setup.Token)
+	AssertOK(t, resp)
+
+	resp = ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/reservations/quota", setup.Business.ID), nil, setup.Token)
+	AssertOK(t, resp)
+
+	data, _ := ParseResponse(t, resp)["Data"].(map[string]interface{})
+	if data["MaxActiveReservations"] != float64(2) || data["MaxWeeklyHours"] != 4.5 {
+		t.Errorf("unexpected quota: %v", data)
+	}
+	if exempt, _ := data["ExemptUserIDs"].([]interface{}); len(exempt) != 1 {
+		t.Errorf("expected 1 exempt user, got %v", data["ExemptUserIDs"])
+	}
+
+	var business schema.Business
+	ta.DB.First(&business, setup.Business.ID)
+	if business.Title != setup.Business.Title {
+		t.Errorf("expected the rest of the business to be untouched, got title %q", business.Title)
+	}
+}
+
+func TestQuota_MaxActiveReservations(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	product := ta.createNoShowMachine(t, setup)
+	day := quotaDay()
+	ta.CreateTestReservation(t, setup.User.ID, product.ID, setup.Business.ID, day.Add(8*time.Hour), day.Add(9*time.Hour), schema.ReservationStatusReserved)
+
+	ta.setQuota(t, setup.Business.ID, schema.BusinessMetaQuota{MaxActiveReservations: 1})
+
+	item := quotaItem(product, day, "10:00:00", "11:00:00")
+	if err := ta.Service.CheckQuota(item, product, setup.User.ID, setup.Business.ID, 0); err == nil {
+		t.Error("expected the second active reservation to be refused")
+	}
+
+	ta.setQuota(t, setup.Business.ID, schema.BusinessMetaQuota{MaxActiveReservations: 1, ExemptUserIDs: []uint64{setup.User.ID}})
+	if err := ta.Service.CheckQuota(item, product, setup.User.ID, setup.Business.ID, 0); err != nil {
+		t.Errorf("expected the exempt user to pass, got %v", err)
+	}
+}
+
+func TestQuota_MaxWeeklyHours(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	product := ta.createNoShowMachine(t, setup)
+	day := quotaDay()
+	ta.CreateTestReservation(t, setup.User.ID, product.ID, setup.Business.ID, day.Add(8*time.Hour), day.Add(9*time.Hour+30*time.Minute), schema.ReservationStatusReserved)
+
+	ta.setQuota(t, setup.Business.ID, schema.BusinessMetaQuota{MaxWeeklyHours: 2})
+
+	if err := ta.Service.CheckQuota(quotaItem(product, day, "10:00:00", "11:00:00"), product, setup.User.ID, setup.Business.ID, 0); err == nil {
+		t.Error("expected the slot over the weekly hours to be refused")
+	}
+	if err := ta.Service.CheckQuota(quotaItem(product, day, "10:00:00", "10:30:00"), product, setup.User.ID, setup.Business.ID, 0); err != nil {
+		t.Errorf("expected the slot within the weekly hours to pass, got %v", err)
+	}
+}
+
+func TestQuota_PeakSlotPerDormitory(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	setup := ta.SetupTestUser(t)
+	dormA := ta.CreateTestTaxonomy(t, "Dorm A", schema.TaxonomyTypeCategory, setup.Business.ID, nil)
+	dormB := ta.CreateTestTaxonomy(t, "Dorm B", schema.TaxonomyTypeCategory, setup.Business.ID, nil)
+
+	machineA1 := ta.createNoShowMachine(t, setup)
+	machineA2 := ta.createNoShowMachine(t, setup)
+	machineB := ta.createNoShowMachine(t, setup)
+	ta.AttachTaxonomyToPost(t, machineA1.PostID, dormA.ID)
+	ta.AttachTaxonomyToPost(t, machineA2.PostID, dormA.ID)
+	ta.AttachTaxonomyToPost(t, machineB.PostID, dormB.ID)
+
+	day := quotaDay()
+	ta.CreateTestReservation(t, setup.User.ID, machineA1.ID, setup.Business.ID, day.Add(18*time.Hour), day.Add(19*time.Hour), schema.ReservationStatusReserved)
+
+	ta.setQuota(t, setup.Business.ID, schema.BusinessMetaQuota{
+		MaxPeakSlotsPerDay: 1,
+		PeakHours:          []schema.ProductMetaDeviceHour{{From: "18:00:00", To: "22:00:00"}},
+	})
+
+	if err := ta.Service.CheckQuota(quotaItem(machineA2, day, "20:00:00", "21:00:00"), machineA2, setup.User.ID, setup.Business.ID, 0); err == nil {
+		t.Error("expected the second peak slot in the same dormitory to be refused")
+	}
+	if err := ta.Service.CheckQuota(quotaItem(machineB, day, "20:00:00", "21:00:00"), machineB, setup.User.ID, setup.Business.ID, 0); err != nil {
+		t.Errorf("expected the peak slot in another dormitory to pass, got %v", err)
+	}
+	if err := ta.Service.CheckQuota(quotaItem(machineA2, day, "10:00:00", "11:00:00"), machineA2, setup.User.ID, setup.Business.ID, 0); err != nil {
+		t.Errorf("expected the off-peak slot to pass, got %v", err)
+	}
+}
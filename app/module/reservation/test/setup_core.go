@@ -6,11 +6,14 @@ import (
 	"testing"
 
 	"go-fiber-starter/app/database/schema"
+	outboxRepo "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
 	productRepo "go-fiber-starter/app/module/product/repository"
 	"go-fiber-starter/app/module/reservation"
 	"go-fiber-starter/app/module/reservation/controller"
 	"go-fiber-starter/app/module/reservation/repository"
 	"go-fiber-starter/app/module/reservation/service"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/config"
 
@@ -27,6 +30,7 @@ type TestApp struct {
 	Config            *config.Config
 	Cleanup           func()
 	ReservationRepo   repository.IRepository
+	Service           service.IService
 	ReservationRouter *reservation.Router
 }
 
@@ -60,8 +64,8 @@ func createTestErrorHandler() fiber.ErrorHandler {
 func migrateTestModels(db *gorm.DB) error {
 	// Drop existing tables to ensure clean state
 	tablesToDrop := []string{
-		"posts_taxonomies", "taxonomies", "reservations", "products",
-		"posts", "business_users", "businesses", "users",
+		"posts_taxonomies", "taxonomies", "outbox_messages", "user_strikes", "machine_downtimes", "machine_commands",
+		"reservations", "products", "posts", "business_users", "businesses", "users",
 	}
 	for _, table := range tablesToDrop {
 		db.Exec("DROP TABLE IF EXISTS " + table + " CASCADE")
@@ -92,6 +96,18 @@ func migrateTestModels(db *gorm.DB) error {
 	if err := createPostsTaxonomiesTable(db); err != nil {
 		return err
 	}
+	if err := createUserStrikesTable(db); err != nil {
+		return err
+	}
+	if err := createMachineCommandsTable(db); err != nil {
+		return err
+	}
+	if err := createMachineDowntimesTable(db); err != nil {
+		return err
+	}
+	if err := createOutboxMessagesTable(db); err != nil {
+		return err
+	}
 
 	// Create indexes
 	createIndexes(db)
@@ -116,6 +132,7 @@ func createUsersTable(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -207,6 +224,8 @@ func createReservationsTable(db *gorm.DB) error {
 			business_id BIGINT NOT NULL,
 			meta JSONB,
 			user_usage_count BIGINT DEFAULT 0,
+			seats INT NOT NULL DEFAULT 1,
+			shared BOOLEAN NOT NULL DEFAULT FALSE,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
 			deleted_at TIMESTAMPTZ
@@ -242,6 +261,92 @@ func createPostsTaxonomiesTable(db *gorm.DB) error {
 	`).Error
 }
 
+func createUserStrikesTable(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS user_strikes (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			business_id BIGINT NOT NULL,
+			reservation_id BIGINT NOT NULL UNIQUE,
+			reason VARCHAR(20) NOT NULL,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error
+}
+
+func createMachineCommandsTable(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS machine_commands (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL,
+			product_id BIGINT NOT NULL,
+			reservation_id BIGINT,
+			issuer_id BIGINT NOT NULL,
+			issued_by VARCHAR(50) NOT NULL,
+			command VARCHAR(50) NOT NULL,
+			driver VARCHAR(50),
+			reference_id VARCHAR(255),
+			status VARCHAR(50) DEFAULT 'sent',
+			error VARCHAR(500),
+			history JSONB,
+			last_checked_at TIMESTAMPTZ,
+			delivered_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error
+}
+
+func createMachineDowntimesTable(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS machine_downtimes (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL,
+			product_id BIGINT NOT NULL,
+			reason VARCHAR(20) NOT NULL,
+			detail VARCHAR(500),
+			started_at TIMESTAMPTZ NOT NULL,
+			ended_at TIMESTAMPTZ,
+			opened_by_id BIGINT,
+			closed_by_id BIGINT,
+			note VARCHAR(500),
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error
+}
+
+func createOutboxMessagesTable(db *gorm.DB) error {
+	return db.Exec(`
+		CREATE TABLE IF NOT EXISTS outbox_messages (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT,
+			purpose VARCHAR(30) NOT NULL,
+			priority BIGINT NOT NULL DEFAULT 1,
+			template VARCHAR(50) NOT NULL DEFAULT '',
+			provider VARCHAR(30),
+			mobile VARCHAR(20) NOT NULL,
+			params TEXT[],
+			channels TEXT[],
+			bale_chat_id BIGINT,
+			status VARCHAR(20) NOT NULL DEFAULT 'queued',
+			attempts BIGINT NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMPTZ NOT NULL,
+			locked_until TIMESTAMPTZ,
+			reference_id VARCHAR(100),
+			last_error VARCHAR(500),
+			sent_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error
+}
+
 func createIndexes(db *gorm.DB) {
 	indexes := []string{
 		"CREATE UNIQUE INDEX IF NOT EXISTS idx_users_mobile ON users(mobile)",
@@ -297,8 +402,9 @@ func SetupTestApp(t *testing.T) *TestApp {
 	reservationRepo := repository.Repository(dbWrapper)
 	productRepository := productRepo.Repository(dbWrapper)
 
-	// Create reservation service
-	reservationSvc := service.Service(reservationRepo, productRepository)
+	// Create reservation service, its messages are queued in the outbox
+	outboxSvc := outboxService.Service(outboxRepo.Repository(dbWrapper), sms.NewService(cfg, logger, nil, dbWrapper), cfg)
+	reservationSvc := service.Service(reservationRepo, productRepository, cfg, outboxSvc)
 
 	// Create reservation controller
 	reservationController := controller.Controllers(reservationSvc)
@@ -313,8 +419,8 @@ func SetupTestApp(t *testing.T) *TestApp {
 	// Cleanup function
 	cleanup := func() {
 		cleanupTables := []string{
-			"posts_taxonomies", "taxonomies", "reservations", "products",
-			"posts", "business_users", "businesses", "users",
+			"posts_taxonomies", "taxonomies", "outbox_messages", "user_strikes", "machine_downtimes", "machine_commands",
+			"reservations", "products", "posts", "business_users", "businesses", "users",
 		}
 		for _, table := range cleanupTables {
 			dbWrapper.Main.Exec("DELETE FROM " + table)
@@ -328,6 +434,7 @@ func SetupTestApp(t *testing.T) *TestApp {
 		Config:            cfg,
 		Cleanup:           cleanup,
 		ReservationRepo:   reservationRepo,
+		Service:           reservationSvc,
 		ReservationRouter: reservationRouter,
 	}
 }
@@ -342,8 +449,8 @@ func (ta *TestApp) CleanupReservations(t *testing.T) {
 func (ta *TestApp) CleanupAll(t *testing.T) {
 	t.Helper()
 	tables := []string{
-		"posts_taxonomies", "taxonomies", "reservations", "products",
-		"posts", "business_users", "businesses", "users",
+		"posts_taxonomies", "taxonomies", "outbox_messages", "user_strikes", "machine_downtimes", "machine_commands",
+		"reservations", "products", "posts", "business_users", "businesses", "users",
 	}
 	for _, table := range tables {
 		ta.DB.Exec("DELETE FROM " + table)
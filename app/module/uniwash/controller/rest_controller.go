@@ -1,10 +1,12 @@
 package controller
 
 import (
+	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/module/uniwash/request"
 	"go-fiber-starter/app/module/uniwash/service"
 	"go-fiber-starter/utils"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
 	"go-fiber-starter/utils/response"
 	"time"
 
@@ -16,6 +18,19 @@ type IRestController interface {
 	IndexReservedMachines(c *fiber.Ctx) error
 	CheckLastCommandStatus(c *fiber.Ctx) error
 	GetReservationOptions(c *fiber.Ctx) error
+	DeviceState(c *fiber.Ctx) error
+	MachineCommands(c *fiber.Ctx) error
+	ReservationCommands(c *fiber.Ctx) error
+	ReportFault(c *fiber.Ctx) error
+	MachineFaults(c *fiber.Ctx) error
+	MachineHealth(c *fiber.Ctx) error
+	Maintenances(c *fiber.Ctx) error
+	OpenMaintenance(c *fiber.Ctx) error
+	CloseMaintenance(c *fiber.Ctx) error
+	AffectedReservations(c *fiber.Ctx) error
+	Compensate(c *fiber.Ctx) error
+	Compensations(c *fiber.Ctx) error
+	Reschedule(c *fiber.Ctx) error
 	SendDeviceIsOffMsgToUser(c *fiber.Ctx) error
 	SendFullCouponToUser(c *fiber.Ctx) error
 }
@@ -53,6 +68,7 @@ func (_i *controller) SendCommand(c *fiber.Ctx) error {
 	if utils.IsForUser(c) {
 		req.UserID = user.ID
 	}
+	req.IssuerID = user.ID
 	req.BusinessID = businessID
 	err = _i.service.SendCommand(*req, utils.IsForUser(c))
 	if err != nil {
@@ -87,8 +103,7 @@ func (_i *controller) IndexReservedMachines(c *fiber.Ctx) error {
 	req.BusinessID = businessID
 
 	if c.Query("Date") != "" {
-		loc, _ := time.LoadLocation("Asia/Tehran")
-		date, err := time.ParseInLocation(time.DateOnly, c.Query("Date"), loc)
+		date, err := time.ParseInLocation(time.DateOnly, c.Query("Date"), timezone.In(businessID))
 		if err != nil {
 			return err
 		}
@@ -148,11 +163,404 @@ func (_i *controller) CheckLastCommandStatus(c *fiber.Ctx) error {
 // @Summary      Device reservations options
 // @Tags         UniWash
 // @Param        businessID path int true "Business ID"
-// @Param        reservationID path int true "Reservation ID"
+// @Param        ProductID query int false "Product ID"
 // @Router       /business/:businessID/uni-wash/device/reservation-options [get]
 func (_i *controller) GetReservationOptions(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	productID, _ := utils.GetUintInQueries(c, "ProductID")
+
+	options, err := _i.service.GetReservationOptions(businessID, productID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: options,
+	})
+}
+
+// DeviceState
+// @Summary      Current state of the device, reported by its driver
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        ProductID query int true "Product ID"
+// @Router       /business/:businessID/uni-wash/device/state [get]
+func (_i *controller) DeviceState(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	productID, _ := utils.GetUintInQueries(c, "ProductID")
+
+	state, err := _i.service.DeviceState(businessID, productID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: state,
+	})
+}
+
+// MachineCommands
+// @Summary      Timeline of the commands sent to the machines
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        ProductID query int false "Product ID"
+// @Param        ReservationID query int false "Reservation ID"
+// @Param        IssuerID query int false "Issuer ID"
+// @Param        Status query string false "Status"
+// @Router       /business/:businessID/uni-wash/commands [get]
+func (_i *controller) MachineCommands(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.MachineCommands
+	req.BusinessID = businessID
+	req.Pagination = paginate
+	req.ProductID, _ = utils.GetUintInQueries(c, "ProductID")
+	req.ReservationID, _ = utils.GetUintInQueries(c, "ReservationID")
+	req.IssuerID, _ = utils.GetUintInQueries(c, "IssuerID")
+	req.Status = schema.MachineCommandStatus(c.Query("Status"))
+
+	commands, paging, err := _i.service.MachineCommands(req, false)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: commands,
+		Meta: paging,
+	})
+}
+
+// ReservationCommands
+// @Summary      Timeline of the commands of a reservation of the user
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        reservationID path int true "Reservation ID"
+// @Router       /user/business/:businessID/uni-wash/reservations/:reservationID/commands [get]
+func (_i *controller) ReservationCommands(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	reservationID, err := utils.GetIntInParams(c, "reservationID")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	commands, err := _i.service.ReservationCommands(businessID, user.ID, reservationID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: commands,
+	})
+}
+
+// ReportFault
+// @Summary      Report a fault of a washing machine
+// @Tags         UniWash
+// @Param 		 fault body request.MachineFault true "Fault details"
+// @Param        businessID path int true "Business ID"
+// @Router       /user/business/:businessID/uni-wash/faults [post]
+func (_i *controller) ReportFault(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.MachineFault)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	req.UserID = user.ID
+	if err = _i.service.ReportFault(*req); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
+
+// MachineFaults
+// @Summary      Faults reported by the users
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        ProductID query int false "Product ID"
+// @Router       /business/:businessID/uni-wash/faults [get]
+func (_i *controller) MachineFaults(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.MachineFaults
+	req.BusinessID = businessID
+	req.Pagination = paginate
+	req.ProductID, _ = utils.GetUintInQueries(c, "ProductID")
+
+	faults, paging, err := _i.service.MachineFaults(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: faults,
+		Meta: paging,
+	})
+}
+
+// MachineHealth
+// @Summary      Health and uptime of the machines
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        From query string false "From date (2006-01-02), defaults to 30 days ago"
+// @Param        To query string false "To date (2006-01-02), defaults to today"
+// @Router       /business/:businessID/uni-wash/health [get]
+func (_i *controller) MachineHealth(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+
+	var req request.MachineHealth
+	req.BusinessID = businessID
+	req.From = *utils.GetDateInQueries(c, "From")
+	req.To = *utils.GetDateInQueries(c, "To")
+	if !req.To.IsZero() {
+		req.To = req.To.AddDate(0, 0, 1) // the whole last day
+	}
+
+	health, err := _i.service.MachineHealth(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: health,
+	})
+}
+
+// Maintenances
+// @Summary      Maintenance log of the machines
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        ProductID query int false "Product ID"
+// @Param        OnlyOpen query bool false "Only the machines that are still out of service"
+// @Router       /business/:businessID/uni-wash/maintenance [get]
+func (_i *controller) Maintenances(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.Maintenances
+	req.BusinessID = businessID
+	req.Pagination = paginate
+	req.ProductID, _ = utils.GetUintInQueries(c, "ProductID")
+	req.OnlyOpen = c.QueryBool("OnlyOpen")
+
+	downtimes, paging, err := _i.service.Maintenances(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: downtimes,
+		Meta: paging,
+	})
+}
+
+// OpenMaintenance
+// @Summary      Take a machine out of service
+// @Tags         UniWash
+// @Param 		 maintenance body request.OpenMaintenance true "Maintenance details"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/uni-wash/maintenance [post]
+func (_i *controller) OpenMaintenance(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.OpenMaintenance)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	req.OperatorID = user.ID
+	downtime, err := _i.service.OpenMaintenance(*req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: downtime,
+	})
+}
+
+// CloseMaintenance
+// @Summary      Bring a machine back into service
+// @Tags         UniWash
+// @Param 		 maintenance body request.CloseMaintenance true "Maintenance details"
+// @Param        businessID path int true "Business ID"
+// @Param        id path int true "Maintenance ID"
+// @Router       /business/:businessID/uni-wash/maintenance/:id/close [put]
+func (_i *controller) CloseMaintenance(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.CloseMaintenance)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.ID = id
+	req.BusinessID = businessID
+	req.OperatorID = user.ID
+	if err = _i.service.CloseMaintenance(*req); err != nil {
+		return err
+	}
+
 	return response.Resp(c, response.Response{
-		Data: _i.service.GetReservationOptions(),
+		Messages: response.Messages{"success"},
+	})
+}
+
+// AffectedReservations
+// @Summary      Reservations that can not be served because of a downtime
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        id path int true "Maintenance ID"
+// @Router       /business/:businessID/uni-wash/maintenance/:id/affected [get]
+func (_i *controller) AffectedReservations(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	reservations, err := _i.service.AffectedReservations(businessID, id)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: reservations,
+	})
+}
+
+// Compensate
+// @Summary      Cancel and refund, issue coupons or move the affected reservations at once
+// @Tags         UniWash
+// @Param 		 compensation body request.Compensate true "Compensation details"
+// @Param        businessID path int true "Business ID"
+// @Param        id path int true "Maintenance ID"
+// @Router       /business/:businessID/uni-wash/maintenance/:id/compensate [post]
+func (_i *controller) Compensate(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.Compensate)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.DowntimeID = id
+	req.BusinessID = businessID
+	req.OperatorID = user.ID
+	compensation, err := _i.service.Compensate(*req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: compensation,
+	})
+}
+
+// Compensations
+// @Summary      Reports of the compensations of a downtime
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        id path int true "Maintenance ID"
+// @Router       /business/:businessID/uni-wash/maintenance/:id/compensations [get]
+func (_i *controller) Compensations(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	compensations, err := _i.service.Compensations(businessID, id)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: compensations,
 	})
 }
 
@@ -203,3 +611,43 @@ func (_i *controller) SendFullCouponToUser(c *fiber.Ctx) error {
 
 	return c.JSON("success")
 }
+
+// Reschedule
+// @Summary      Move a paid reservation of the user to another free slot
+// @Tags         UniWash
+// @Param        businessID path int true "Business ID"
+// @Param        reservationID path int true "Reservation ID"
+// @Param 		 reschedule body request.Reschedule true "New machine and slot"
+// @Router       /user/business/:businessID/uni-wash/reservations/:reservationID/reschedule [post]
+func (_i *controller) Reschedule(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	reservationID, err := utils.GetIntInParams(c, "reservationID")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.Reschedule)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	req.ReservationID = reservationID
+	req.UserID = user.ID
+	rescheduled, err := _i.service.Reschedule(*req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data:     rescheduled,
+		Messages: response.Messages{"success"},
+	})
+}
@@ -0,0 +1,40 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/uniwash/service"
+	"go-fiber-starter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+type CommandDeliveryPoller struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+}
+
+func RunCommandDeliveryPoller(
+	logger zerolog.Logger,
+	uniwashService service.IService,
+	cronService *internal.CronService,
+) *CommandDeliveryPoller {
+	poller := &CommandDeliveryPoller{
+		Logger:   logger,
+		Service:  uniwashService,
+		CronSpec: "@every 30s",
+	}
+
+	err := cronService.AddJob(poller.CronSpec, poller.RefreshDeliveries)
+	if err != nil {
+		poller.Logger.Fatal().Err(err).Msg("failed to add RunCommandDeliveryPoller job")
+	}
+
+	return poller
+}
+
+// RefreshDeliveries updates the delivery status of the sent machine commands
+func (_s *CommandDeliveryPoller) RefreshDeliveries() {
+	if err := _s.Service.RefreshCommandDeliveries(); err != nil {
+		_s.Logger.Err(err).Msg("Failed to refresh machine command deliveries")
+	}
+}
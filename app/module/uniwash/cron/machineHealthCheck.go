@@ -0,0 +1,40 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/uniwash/service"
+	"go-fiber-starter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+type MachineHealthCheck struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+}
+
+func RunMachineHealthCheck(
+	logger zerolog.Logger,
+	uniwashService service.IService,
+	cronService *internal.CronService,
+) *MachineHealthCheck {
+	check := &MachineHealthCheck{
+		Logger:   logger,
+		Service:  uniwashService,
+		CronSpec: "@every 5m",
+	}
+
+	err := cronService.AddJob(check.CronSpec, check.CheckMachines)
+	if err != nil {
+		check.Logger.Fatal().Err(err).Msg("failed to add RunMachineHealthCheck job")
+	}
+
+	return check
+}
+
+// CheckMachines takes the unhealthy machines out of service
+func (_s *MachineHealthCheck) CheckMachines() {
+	if err := _s.Service.CheckMachineHealth(); err != nil {
+		_s.Logger.Err(err).Msg("Failed to check the health of the machines")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	mdl "go-fiber-starter/app/middleware"
 	"go-fiber-starter/app/module/uniwash/controller"
+	"go-fiber-starter/app/module/uniwash/cron"
 	"go-fiber-starter/app/module/uniwash/repository"
 	"go-fiber-starter/app/module/uniwash/service"
 	"go-fiber-starter/utils/config"
@@ -26,11 +27,24 @@ func (_i *Router) RegisterRoutes(cfg *config.Config) {
 		router.Post("/send-full-coupon-to-user/:reservationID", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.SendFullCouponToUser)
 		router.Get("/check-last-command-status/:reservationID", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.CheckLastCommandStatus)
 		router.Get("/device/reservation-options", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.GetReservationOptions)
+		router.Get("/device/state", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.DeviceState)
+		router.Get("/commands", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.MachineCommands)
+		router.Get("/health", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.MachineHealth)
+		router.Get("/faults", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.MachineFaults)
+		router.Get("/maintenance", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.Maintenances)
+		router.Post("/maintenance", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PUpdate), c.OpenMaintenance)
+		router.Put("/maintenance/:id/close", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PUpdate), c.CloseMaintenance)
+		router.Get("/maintenance/:id/affected", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.AffectedReservations)
+		router.Post("/maintenance/:id/compensate", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PUpdate), c.Compensate)
+		router.Get("/maintenance/:id/compensations", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DProduct, mdl.PReadAll), c.Compensations)
 	})
 
 	_i.App.Route("/v1/user/business/:businessID/uni-wash", func(router fiber.Router) {
 		router.Post("/send-command", mdl.Protected(cfg), mdl.ForUser, c.SendCommand)
 		router.Get("/reserved-machines", mdl.Protected(cfg), mdl.ForUser, c.IndexReservedMachines)
+		router.Get("/reservations/:reservationID/commands", mdl.Protected(cfg), mdl.ForUser, c.ReservationCommands)
+		router.Post("/reservations/:reservationID/reschedule", mdl.Protected(cfg), mdl.ForUser, c.Reschedule)
+		router.Post("/faults", mdl.Protected(cfg), mdl.ForUser, c.ReportFault)
 	})
 }
 
@@ -49,4 +63,7 @@ var Module = fx.Options(
 	fx.Provide(controller.Controllers),
 
 	fx.Provide(newRouter),
+
+	fx.Invoke(cron.RunCommandDeliveryPoller),
+	fx.Invoke(cron.RunMachineHealthCheck),
 )
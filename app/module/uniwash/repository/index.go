@@ -1,26 +1,60 @@
 package repository
 
 import (
+	"errors"
 	"go-fiber-starter/app/database/schema"
 	oirequest "go-fiber-starter/app/module/orderItem/request"
 	"go-fiber-starter/app/module/uniwash/request"
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
 type IRepository interface {
 	GetReservation(req request.SendCommand) (reservation *schema.Reservation, err error)
 	GetSingleReservation(BusinessID uint64, id uint64) (reservation *schema.Reservation, err error)
+	GetLastCommandedReservation(productID uint64) (reservation *schema.Reservation, err error)
 	UpdateReservation(reservation *schema.Reservation) error
-	ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64) (reservationID *uint64, err error)
+	ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64, tx *gorm.DB) (reservationID *uint64, err error)
 	IsReservable(req oirequest.OrderItem, businessID uint64) error
 	IndexReservedMachines(req request.ReservedMachinesRequest) (reservations []*schema.Reservation, paging paginator.Pagination, err error)
 	Reserve(reservationID uint64) error
+	Release(reservationID uint64) error
+	CreateMachineCommand(command *schema.MachineCommand) error
+	UpdateMachineCommand(command *schema.MachineCommand) error
+	GetMachineCommands(req request.MachineCommands) (commands []*schema.MachineCommand, paging paginator.Pagination, err error)
+	GetUnsettledMachineCommands(limit int) (commands []*schema.MachineCommand, err error)
+	LastDeliveredAt(productID uint64) (at *time.Time, err error)
+	UndeliveredStreak(productID uint64, since time.Time) (count int64, firstAt *time.Time, err error)
+	GetMachines(businessID uint64) (products []*schema.Product, err error)
+	SetMachineStatus(productID uint64, status schema.UniWashMachineStatus) error
+	GetBusinessOwner(businessID uint64) (owner *schema.User, err error)
+	CreateMachineFault(fault *schema.MachineFault) error
+	GetMachineFaults(req request.MachineFaults) (faults []*schema.MachineFault, paging paginator.Pagination, err error)
+	CountFaultReporters(productID uint64, since time.Time) (count int64, err error)
+	CreateDowntime(downtime *schema.MachineDowntime) error
+	CloseDowntime(downtime *schema.MachineDowntime) error
+	GetDowntime(businessID uint64, id uint64) (downtime *schema.MachineDowntime, err error)
+	GetOpenDowntime(productID uint64) (downtime *schema.MachineDowntime, err error)
+	LastDowntimeEnd(productID uint64) (at *time.Time, err error)
+	GetDowntimes(req request.Maintenances) (downtimes []*schema.MachineDowntime, paging paginator.Pagination, err error)
+	GetDowntimesBetween(businessID uint64, from time.Time, to time.Time) (downtimes []*schema.MachineDowntime, err error)
+	GetAffectedReservations(downtime *schema.MachineDowntime, now time.Time) (reservations []*schema.Reservation, err error)
+	PaidAmount(reservationID uint64) (amount float64, err error)
+	IsSlotFree(productID uint64, start time.Time, end time.Time, exceptID uint64) (free bool, err error)
+	MoveReservation(reservation *schema.Reservation, productID uint64) error
+	RescheduleReservation(reservation *schema.Reservation, tx *gorm.DB) error
+	GetReservationOrderID(reservationID uint64) (orderID *uint64, err error)
+	CancelReservation(reservation *schema.Reservation, tx *gorm.DB) error
+	CreateCompensation(compensation *schema.MachineCompensation) error
+	GetCompensations(businessID uint64, downtimeID uint64) (compensations []*schema.MachineCompensation, err error)
+	BeginTransaction() (*gorm.DB, error)
 }
 
 func Repository(db *database.Database) IRepository {
@@ -38,8 +72,8 @@ func (_i *repo) GetReservation(req request.SendCommand) (reservation *schema.Res
 			ProductID:  req.ProductID,
 			BusinessID: req.BusinessID,
 			ID:         req.ReservationID,
-			Status:     schema.ReservationStatusReserved,
-		}).Debug().
+		}).
+		Where("status IN ?", []schema.ReservationStatus{schema.ReservationStatusReserved, schema.ReservationStatusInUse}).Debug().
 		First(&reservation).Error; err != nil {
 		return nil, err
 	}
@@ -61,6 +95,19 @@ func (_i *repo) GetSingleReservation(BusinessID uint64, id uint64) (reservation
 	return reservation, nil
 }
 
+// GetLastCommandedReservation returns the reservation of the product that received the latest command.
+func (_i *repo) GetLastCommandedReservation(productID uint64) (reservation *schema.Reservation, err error) {
+	if err := _i.DB.Main.
+		Where(&schema.Reservation{ProductID: productID}).
+		Where("meta->>'UniWashLastCommandReferenceID' <> ''").
+		Order("updated_at desc").
+		First(&reservation).Error; err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
 func (_i *repo) UpdateReservation(reservation *schema.Reservation) (err error) {
 	if err := _i.DB.Main.Model(&schema.Reservation{}).
 		Where(&schema.Reservation{ID: reservation.ID, BusinessID: reservation.BusinessID}).
@@ -70,18 +117,47 @@ func (_i *repo) UpdateReservation(reservation *schema.Reservation) (err error) {
 	return nil
 }
 
-func (_i *repo) ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64) (reservationID *uint64, err error) {
+// errSlotTaken is the answer when the database refuses an overlapping reservation.
+var errSlotTaken = &fiber.Error{
+	Code:    fiber.StatusBadRequest,
+	Message: "این ساعت دستگاه رزرو شده است",
+}
+
+func slotTakenError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.ConstraintName == schema.ReservationNoOverlapConstraint {
+		return errSlotTaken
+	}
+	return err
+}
+
+// ReserveReservation holds the slot, in the transaction of the order when there is one so the slot is only taken
+// if the order is saved. Two checkouts of the same slot can not both get here, the database refuses the second.
+func (_i *repo) ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64, tx *gorm.DB) (reservationID *uint64, err error) {
+	db := _i.DB.Main
+	if tx != nil {
+		db = tx
+	}
+	loc := timezone.In(businessID)
+
+	// the holds nobody paid for still take their slot in the database until the lifecycle cron expires them
+	if err = db.Model(&schema.Reservation{}).
+		Where("product_id = ? AND status = ? AND created_at <= ?", req.ProductID, schema.ReservationStatusHeld, time.Now().Add(-schema.ReservationHoldDuration)).
+		Where("start_time < ? AND end_time > ?", req.GetEndDateTime(loc), req.GetStartDateTime(loc)).
+		Update("status", schema.ReservationStatusExpired).Error; err != nil {
+		return nil, err
+	}
+
 	r := schema.Reservation{
 		UserID:     userID,
 		BusinessID: businessID,
 		ProductID:  req.ProductID,
-		EndTime:    req.GetEndDateTime(),
-		StartTime:  req.GetStartDateTime(),
-		Status:     schema.ReservationStatusReserved,
-		Base:       schema.Base{DeletedAt: gorm.DeletedAt{Time: time.Now().Add(10 * time.Minute), Valid: true}},
+		EndTime:    req.GetEndDateTime(loc),
+		StartTime:  req.GetStartDateTime(loc),
+		Status:     schema.ReservationStatusHeld,
 	}
-	if err = _i.DB.Main.Create(&r).Error; err != nil {
-		return nil, err
+	if err = db.Create(&r).Error; err != nil {
+		return nil, slotTakenError(err)
 	}
 
 	return &r.ID, nil
@@ -89,15 +165,15 @@ func (_i *repo) ReserveReservation(req oirequest.OrderItem, userID uint64, busin
 
 func (_i *repo) IsReservable(req oirequest.OrderItem, businessID uint64) error {
 	var reservation schema.Reservation
+	loc := timezone.In(businessID)
 	if err := _i.DB.Main.
 		Where(&schema.Reservation{
 			BusinessID: businessID,
 			ProductID:  req.ProductID,
-			EndTime:    req.GetEndDateTime(),
-			StartTime:  req.GetStartDateTime(),
-			Status:     schema.ReservationStatusReserved,
+			EndTime:    req.GetEndDateTime(loc),
+			StartTime:  req.GetStartDateTime(loc),
 		}).
-		Unscoped().Where("deleted_at > ? OR deleted_at IS NULL", time.Now()).
+		Scopes(schema.ReservationTakesSlot(time.Now())).
 		First(&reservation).Error; err == nil {
 		return &fiber.Error{
 			Code:    fiber.StatusBadRequest,
@@ -121,9 +197,10 @@ func (_i *repo) IndexReservedMachines(req request.ReservedMachinesRequest) (rese
 	}
 
 	if req.With == "reservedReservations" {
-		query.Unscoped().Where("deleted_at > ? OR deleted_at IS NULL", time.Now())
+		query.Scopes(schema.ReservationTakesSlot(time.Now()))
 	} else if req.UserID > 0 {
 		query.Where(&schema.Reservation{UserID: req.UserID}).
+			Where("status NOT IN ?", []schema.ReservationStatus{schema.ReservationStatusHeld, schema.ReservationStatusExpired}).
 			Preload("Product", func(db *gorm.DB) *gorm.DB {
 				return db.Unscoped() // This will include soft-deleted posts
 			}).
@@ -151,11 +228,450 @@ func (_i *repo) IndexReservedMachines(req request.ReservedMachinesRequest) (rese
 	return
 }
 
+// Reserve confirms the hold once its payment is done, even when the payment came back after the hold expired.
 func (_i *repo) Reserve(id uint64) (err error) {
-	if err := _i.DB.Main.Model(&schema.Reservation{}).Unscoped().
-		Where(&schema.Reservation{ID: id}).
-		Update("deleted_at", nil).Error; err != nil {
-		return err
+	if err := _i.DB.Main.Model(&schema.Reservation{}).
+		Where("id = ? AND status IN ?", id, []schema.ReservationStatus{schema.ReservationStatusHeld, schema.ReservationStatusExpired}).
+		Update("status", schema.ReservationStatusReserved).Error; err != nil {
+		return slotTakenError(err)
+	}
+	return nil
+}
+
+// Release frees the slot of a hold whose payment failed.
+func (_i *repo) Release(id uint64) error {
+	return _i.DB.Main.Model(&schema.Reservation{}).
+		Where("id = ? AND status = ?", id, schema.ReservationStatusHeld).
+		Update("status", schema.ReservationStatusCanceled).Error
+}
+
+func (_i *repo) CreateMachineCommand(command *schema.MachineCommand) error {
+	return _i.DB.Main.Create(command).Error
+}
+
+func (_i *repo) UpdateMachineCommand(command *schema.MachineCommand) error {
+	return _i.DB.Main.Model(&schema.MachineCommand{}).
+		Where("id = ?", command.ID).
+		Select("status", "history", "last_checked_at", "delivered_at").
+		Updates(command).Error
+}
+
+func (_i *repo) GetMachineCommands(req request.MachineCommands) (commands []*schema.MachineCommand, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.MachineCommand{}).
+		Where(&schema.MachineCommand{BusinessID: req.BusinessID})
+
+	if req.ProductID > 0 {
+		query.Where(&schema.MachineCommand{ProductID: req.ProductID})
+	}
+
+	if req.ReservationID > 0 {
+		query.Where("reservation_id = ?", req.ReservationID)
+	}
+
+	if req.IssuerID > 0 {
+		query.Where(&schema.MachineCommand{IssuerID: req.IssuerID})
+	}
+
+	if req.Status != "" {
+		query.Where(&schema.MachineCommand{Status: req.Status})
+	}
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.
+		Preload("Issuer").
+		Preload("Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Order("created_at desc").
+		Find(&commands).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+// GetUnsettledMachineCommands returns the sent commands that wait for a delivery report, least recently checked first.
+func (_i *repo) GetUnsettledMachineCommands(limit int) (commands []*schema.MachineCommand, err error) {
+	err = _i.DB.Main.
+		Preload("Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Where(&schema.MachineCommand{Status: schema.MachineCommandStatusSent}).
+		Where("reference_id <> ''").
+		Order("last_checked_at asc nulls first").
+		Limit(limit).
+		Find(&commands).Error
+
+	return
+}
+
+func (_i *repo) LastDeliveredAt(productID uint64) (at *time.Time, err error) {
+	err = _i.DB.Main.Model(&schema.MachineCommand{}).
+		Where(&schema.MachineCommand{ProductID: productID, Status: schema.MachineCommandStatusDelivered}).
+		Select("MAX(delivered_at)").
+		Scan(&at).Error
+
+	return
+}
+
+// UndeliveredStreak counts the failed and unconfirmed commands since the last delivered one,
+// with the time of the first of them.
+func (_i *repo) UndeliveredStreak(productID uint64, since time.Time) (count int64, firstAt *time.Time, err error) {
+	lastDelivered, err := _i.LastDeliveredAt(productID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if lastDelivered != nil && lastDelivered.After(since) {
+		since = *lastDelivered
+	}
+
+	var streak struct {
+		Count   int64
+		FirstAt *time.Time
+	}
+	err = _i.DB.Main.Model(&schema.MachineCommand{}).
+		Where(&schema.MachineCommand{ProductID: productID}).
+		Where("status IN ?", []schema.MachineCommandStatus{schema.MachineCommandStatusFailed, schema.MachineCommandStatusUnconfirmed}).
+		Where("created_at > ?", since).
+		Select("COUNT(*) AS count, MIN(created_at) AS first_at").
+		Scan(&streak).Error
+
+	return streak.Count, streak.FirstAt, err
+}
+
+// GetMachines returns the washing machines of the business, or of every business when businessID is zero.
+func (_i *repo) GetMachines(businessID uint64) (products []*schema.Product, err error) {
+	query := _i.DB.Main.
+		Where("variant_type = ?", schema.ProductVariantTypeWashingMachine)
+
+	if businessID > 0 {
+		query.Where(&schema.Product{BusinessID: businessID})
+	}
+
+	err = query.Order("id").Find(&products).Error
+
+	return
+}
+
+// SetMachineStatus only touches the status in the meta, so it never overwrites an edit of the product.
+func (_i *repo) SetMachineStatus(productID uint64, status schema.UniWashMachineStatus) error {
+	return _i.DB.Main.Model(&schema.Product{}).
+		Where("id = ?", productID).
+		Update("meta", gorm.Expr("jsonb_set(COALESCE(meta, '{}'::jsonb), '{UniWashMachineStatus}', to_jsonb(?::text))", string(status))).Error
+}
+
+func (_i *repo) GetBusinessOwner(businessID uint64) (owner *schema.User, err error) {
+	var business schema.Business
+	if err = _i.DB.Main.Preload("Owner").First(&business, businessID).Error; err != nil {
+		return nil, err
+	}
+
+	return &business.Owner, nil
+}
+
+func (_i *repo) CreateMachineFault(fault *schema.MachineFault) error {
+	return _i.DB.Main.Create(fault).Error
+}
+
+func (_i *repo) GetMachineFaults(req request.MachineFaults) (faults []*schema.MachineFault, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.MachineFault{}).
+		Where(&schema.MachineFault{BusinessID: req.BusinessID})
+
+	if req.ProductID > 0 {
+		query.Where(&schema.MachineFault{ProductID: req.ProductID})
+	}
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.
+		Preload("User").
+		Preload("Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Order("created_at desc").
+		Find(&faults).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+// CountFaultReporters counts the distinct users that reported a fault of the machine since the given time.
+func (_i *repo) CountFaultReporters(productID uint64, since time.Time) (count int64, err error) {
+	err = _i.DB.Main.Model(&schema.MachineFault{}).
+		Where(&schema.MachineFault{ProductID: productID}).
+		Where("created_at > ?", since).
+		Distinct("user_id").
+		Count(&count).Error
+
+	return
+}
+
+func (_i *repo) CreateDowntime(downtime *schema.MachineDowntime) error {
+	return _i.DB.Main.Create(downtime).Error
+}
+
+func (_i *repo) CloseDowntime(downtime *schema.MachineDowntime) error {
+	return _i.DB.Main.Model(&schema.MachineDowntime{}).
+		Where("id = ? AND ended_at IS NULL", downtime.ID).
+		Select("ended_at", "closed_by_id", "note").
+		Updates(downtime).Error
+}
+
+func (_i *repo) GetDowntime(businessID uint64, id uint64) (downtime *schema.MachineDowntime, err error) {
+	if err = _i.DB.Main.
+		Preload("Product").
+		Where(&schema.MachineDowntime{BusinessID: businessID}).
+		First(&downtime, id).Error; err != nil {
+		return nil, err
+	}
+
+	return downtime, nil
+}
+
+func (_i *repo) GetOpenDowntime(productID uint64) (downtime *schema.MachineDowntime, err error) {
+	if err = _i.DB.Main.
+		Where(&schema.MachineDowntime{ProductID: productID}).
+		Where("ended_at IS NULL").
+		Order("started_at desc").
+		First(&downtime).Error; err != nil {
+		return nil, err
+	}
+
+	return downtime, nil
+}
+
+func (_i *repo) LastDowntimeEnd(productID uint64) (at *time.Time, err error) {
+	err = _i.DB.Main.Model(&schema.MachineDowntime{}).
+		Where(&schema.MachineDowntime{ProductID: productID}).
+		Select("MAX(ended_at)").
+		Scan(&at).Error
+
+	return
+}
+
+func (_i *repo) GetDowntimes(req request.Maintenances) (downtimes []*schema.MachineDowntime, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.MachineDowntime{}).
+		Where(&schema.MachineDowntime{BusinessID: req.BusinessID})
+
+	if req.ProductID > 0 {
+		query.Where(&schema.MachineDowntime{ProductID: req.ProductID})
+	}
+
+	if req.OnlyOpen {
+		query.Where("ended_at IS NULL")
 	}
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.
+		Preload("OpenedBy").
+		Preload("ClosedBy").
+		Preload("Product", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Order("started_at desc").
+		Find(&downtimes).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+// GetDowntimesBetween returns the downtime windows of the business overlapping the period.
+func (_i *repo) GetDowntimesBetween(businessID uint64, from time.Time, to time.Time) (downtimes []*schema.MachineDowntime, err error) {
+	err = _i.DB.Main.
+		Where(&schema.MachineDowntime{BusinessID: businessID}).
+		Where("started_at < ? AND (ended_at IS NULL OR ended_at > ?)", to, from).
+		Order("started_at").
+		Find(&downtimes).Error
+
+	return
+}
+
+// GetAffectedReservations returns the confirmed reservations of the machine that are not over yet
+// and fall in the downtime window.
+func (_i *repo) GetAffectedReservations(downtime *schema.MachineDowntime, now time.Time) (reservations []*schema.Reservation, err error) {
+	from := downtime.StartedAt
+	if now.After(from) {
+		from = now
+	}
+
+	query := _i.DB.Main.
+		Where(&schema.Reservation{
+			ProductID:  downtime.ProductID,
+			BusinessID: downtime.BusinessID,
+			Status:     schema.ReservationStatusReserved,
+		}).
+		Where("end_time > ?", from)
+
+	if downtime.EndedAt != nil {
+		query.Where("start_time < ?", *downtime.EndedAt)
+	}
+
+	err = query.
+		Preload("User").
+		Preload("Product").
+		Order("start_time").
+		Find(&reservations).Error
+
+	return
+}
+
+// PaidAmount is the share of the reservation in the total of its completed order, after the tax and the coupon.
+// It is zero when nothing was paid for it.
+func (_i *repo) PaidAmount(reservationID uint64) (amount float64, err error) {
+	orders := _i.DB.Main.Model(&schema.OrderItem{}).
+		Select("order_id").
+		Where("reservation_id = ?", reservationID)
+	totals := _i.DB.Main.Model(&schema.OrderItem{}).
+		Select("order_id, SUM(subtotal) AS subtotal").
+		Where("order_id IN (?)", orders).
+		Group("order_id")
+
+	err = _i.DB.Main.Model(&schema.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id AND orders.deleted_at IS NULL").
+		Joins("JOIN (?) AS totals ON totals.order_id = order_items.order_id", totals).
+		Where("order_items.reservation_id = ? AND orders.status = ?", reservationID, schema.OrderStatusCompleted).
+		Select("COALESCE(SUM(orders.total_amt * order_items.subtotal / NULLIF(totals.subtotal, 0)), 0)").
+		Scan(&amount).Error
+
+	return
+}
+
+// IsSlotFree reports whether no reservation, held for payment or confirmed, overlaps the range on the machine.
+// The reservation of exceptID, which is being moved, does not take the slot.
+func (_i *repo) IsSlotFree(productID uint64, start time.Time, end time.Time, exceptID uint64) (free bool, err error) {
+	var count int64
+	err = _i.DB.Main.Model(&schema.Reservation{}).
+		Where(&schema.Reservation{ProductID: productID}).
+		Scopes(schema.ReservationTakesSlot(time.Now())).
+		Where("start_time < ? AND end_time > ?", end, start).
+		Where("id <> ?", exceptID).
+		Count(&count).Error
+
+	return count == 0, err
+}
+
+func (_i *repo) MoveReservation(reservation *schema.Reservation, productID uint64) error {
+	return slotTakenError(_i.DB.Main.Model(&schema.Reservation{}).
+		Where("id = ? AND status = ?", reservation.ID, schema.ReservationStatusReserved).
+		Update("product_id", productID).Error)
+}
+
+// RescheduleReservation saves the new machine, time and meta of a reservation that is still reserved, the
+// reminders sent for the old time go again for the new one.
+func (_i *repo) RescheduleReservation(reservation *schema.Reservation, tx *gorm.DB) error {
+	db := _i.DB.Main
+	if tx != nil {
+		db = tx
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&schema.Reservation{}).
+			Where("id = ? AND status = ?", reservation.ID, schema.ReservationStatusReserved).
+			Updates(map[string]any{
+				"product_id": reservation.ProductID,
+				"start_time": reservation.StartTime,
+				"end_time":   reservation.EndTime,
+				"meta":       reservation.Meta,
+			})
+		if result.Error != nil {
+			return slotTakenError(result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Where("reservation_id = ?", reservation.ID).Delete(&schema.ReservationReminder{}).Error
+	})
+}
+
+func (_i *repo) GetReservationOrderID(reservationID uint64) (orderID *uint64, err error) {
+	var item schema.OrderItem
+	if err = _i.DB.Main.Where("reservation_id = ?", reservationID).First(&item).Error; err != nil {
+		return nil, err
+	}
+
+	return &item.OrderID, nil
+}
+
+// CancelReservation cancels a reservation that is still reserved, it gives gorm.ErrRecordNotFound when
+// the reservation has been canceled or used since, so it is not paid back twice.
+func (_i *repo) CancelReservation(reservation *schema.Reservation, tx *gorm.DB) error {
+	db := _i.DB.Main
+	if tx != nil {
+		db = tx
+	}
+
+	result := db.Model(&schema.Reservation{}).
+		Where("id = ? AND status = ?", reservation.ID, schema.ReservationStatusReserved).
+		Update("status", schema.ReservationStatusCanceled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
 	return nil
 }
+
+func (_i *repo) CreateCompensation(compensation *schema.MachineCompensation) error {
+	return _i.DB.Main.Create(compensation).Error
+}
+
+func (_i *repo) GetCompensations(businessID uint64, downtimeID uint64) (compensations []*schema.MachineCompensation, err error) {
+	err = _i.DB.Main.
+		Where(&schema.MachineCompensation{BusinessID: businessID, DowntimeID: downtimeID}).
+		Preload("Operator").
+		Order("created_at desc").
+		Find(&compensations).Error
+
+	return
+}
+
+func (_i *repo) BeginTransaction() (*gorm.DB, error) {
+	tx := _i.DB.Main.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx, nil
+}
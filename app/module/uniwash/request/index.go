@@ -2,7 +2,9 @@ package request
 
 import (
 	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
 	"time"
 )
 
@@ -12,6 +14,67 @@ type SendCommand struct {
 	ReservationID uint64                `example:"1" validate:"required,number"`
 	ProductID     uint64                `example:"1" validate:"required,number"`
 	Command       schema.UniWashCommand `example:"ON" validate:"required,oneof=ON OFF REWASH EVACUATION"`
+	IssuerID      uint64
+}
+
+type MachineCommands struct {
+	BusinessID    uint64
+	ProductID     uint64
+	ReservationID uint64
+	IssuerID      uint64
+	Status        schema.MachineCommandStatus
+	Pagination    *paginator.Pagination
+}
+
+type MachineFault struct {
+	BusinessID    uint64
+	UserID        uint64
+	ProductID     uint64 `example:"1" validate:"required,number"`
+	ReservationID uint64 `example:"1" validate:"omitempty,number"`
+	Description   string `example:"دستگاه آبگیری نمی کند" validate:"required,min=2,max=500"`
+}
+
+type MachineFaults struct {
+	BusinessID uint64
+	ProductID  uint64
+	Pagination *paginator.Pagination
+}
+
+type OpenMaintenance struct {
+	BusinessID uint64
+	OperatorID uint64
+	ProductID  uint64 `example:"1" validate:"required,number"`
+	Note       string `example:"تعویض پمپ" validate:"omitempty,max=500"`
+}
+
+type CloseMaintenance struct {
+	ID         uint64
+	BusinessID uint64
+	OperatorID uint64
+	Note       string `example:"پمپ تعویض شد" validate:"omitempty,max=500"`
+}
+
+type Maintenances struct {
+	BusinessID uint64
+	ProductID  uint64
+	OnlyOpen   bool
+	Pagination *paginator.Pagination
+}
+
+type Compensate struct {
+	DowntimeID      uint64
+	BusinessID      uint64
+	OperatorID      uint64
+	Action          schema.CompensationAction `example:"move" validate:"required,oneof=refund coupon move"`
+	Fallback        schema.CompensationAction `example:"coupon" validate:"required_if=Action move,omitempty,oneof=refund coupon"` // for the reservations that can not be moved
+	ReservationIDs  []uint64                  `validate:"omitempty,dive,min=1"`                                                   // all the affected reservations when empty
+	CouponValidDays int                       `example:"8" validate:"omitempty,min=1,max=365"`
+}
+
+type MachineHealth struct {
+	BusinessID uint64
+	From       time.Time
+	To         time.Time
 }
 
 type ReservedMachinesRequest struct {
@@ -34,14 +97,12 @@ type StoreUniWash struct {
 }
 
 func (s StoreUniWash) GetStartDateTime() time.Time {
-	loc, _ := time.LoadLocation("Asia/Tehran")
-	startTime, _ := time.ParseInLocation(time.DateTime, s.Date+" "+s.StartTime, loc)
+	startTime, _ := time.ParseInLocation(time.DateTime, s.Date+" "+s.StartTime, timezone.In(s.BusinessID))
 	return startTime
 }
 
 func (s StoreUniWash) GetEndDateTime() time.Time {
-	loc, _ := time.LoadLocation("Asia/Tehran")
-	endTime, _ := time.ParseInLocation(time.DateTime, s.Date+" "+s.EndTime, loc)
+	endTime, _ := time.ParseInLocation(time.DateTime, s.Date+" "+s.EndTime, timezone.In(s.BusinessID))
 
 	// if hour is 00 should store in next day
 	if endTime.Hour() == 0 {
@@ -50,3 +111,24 @@ func (s StoreUniWash) GetEndDateTime() time.Time {
 
 	return endTime
 }
+
+type Reschedule struct {
+	ReservationID uint64
+	BusinessID    uint64
+	UserID        uint64
+	ProductID     uint64 `example:"1" validate:"required,number,min=1"`
+	Date          string `example:"2024-01-01" validate:"required,datetime=2006-01-02"`
+	StartTime     string `example:"10:00:00" validate:"required,datetime=15:04:05"`
+	EndTime       string `example:"11:00:00" validate:"required,datetime=15:04:05"`
+}
+
+func (req *Reschedule) ToOrderItem(product *schema.Product) oirequest.OrderItem {
+	return oirequest.OrderItem{
+		Quantity:  1,
+		PostID:    product.PostID,
+		ProductID: product.ID,
+		Date:      req.Date,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	ptime "github.com/yaa110/go-persian-calendar"
 	"go-fiber-starter/app/database/schema"
 	tresponse "go-fiber-starter/app/module/taxonomy/response"
+	"go-fiber-starter/utils/timezone"
 	"time"
 )
 
@@ -36,12 +37,203 @@ func FromDomain(item *schema.Reservation) (res *Reservation) {
 		LastCommand:   item.Meta.UniWashLastCommand,
 	}
 
-	p.EndTimeDisplay = ptime.New(p.EndTime).Format("HH:mm - yyyy/MM/dd")
-	p.StartTimeDisplay = ptime.New(p.StartTime).Format("HH:mm - yyyy/MM/dd")
+	loc := timezone.In(item.BusinessID)
+	p.EndTimeDisplay = ptime.New(p.EndTime.In(loc)).Format("HH:mm - yyyy/MM/dd")
+	p.StartTimeDisplay = ptime.New(p.StartTime.In(loc)).Format("HH:mm - yyyy/MM/dd")
 
 	return p
 }
 
+type MachineCommand struct {
+	ID            uint64                       `json:",omitempty"`
+	ReservationID *uint64                      `json:",omitempty"`
+	ProductID     uint64                       `json:",omitempty"`
+	ProductSKU    string                       `json:",omitempty"`
+	Command       schema.UniWashCommand        `json:",omitempty"`
+	IssuedBy      schema.MachineCommandIssuer  `json:",omitempty"`
+	IssuerID      uint64                       `json:",omitempty"`
+	IssuerName    string                       `json:",omitempty"`
+	Driver        schema.UniWashDriver         `json:",omitempty"`
+	ReferenceID   string                       `json:",omitempty"`
+	Status        schema.MachineCommandStatus  `json:",omitempty"`
+	StatusDisplay string                       `json:",omitempty"`
+	Error         string                       `json:",omitempty"`
+	History       schema.MachineCommandHistory `json:",omitempty"`
+	CreatedAt     time.Time                    `json:",omitempty"`
+	DeliveredAt   *time.Time                   `json:",omitempty"`
+}
+
+func MachineCommandFromDomain(item *schema.MachineCommand, forUser bool) *MachineCommand {
+	res := &MachineCommand{
+		ID:            item.ID,
+		ReservationID: item.ReservationID,
+		ProductID:     item.ProductID,
+		ProductSKU:    item.Product.Meta.SKU,
+		Command:       item.Command,
+		IssuedBy:      item.IssuedBy,
+		Status:        item.Status,
+		StatusDisplay: schema.MachineCommandStatusProxy[item.Status],
+		History:       item.History,
+		CreatedAt:     item.CreatedAt,
+		DeliveredAt:   item.DeliveredAt,
+	}
+
+	// provider details and operators are only shown to the operators
+	if !forUser {
+		res.IssuerID = item.IssuerID
+		res.IssuerName = item.Issuer.FullName()
+		res.Driver = item.Driver
+		res.ReferenceID = item.ReferenceID
+		res.Error = item.Error
+	}
+
+	return res
+}
+
+type MachineDowntime struct {
+	ID            uint64                       `json:",omitempty"`
+	ProductID     uint64                       `json:",omitempty"`
+	ProductSKU    string                       `json:",omitempty"`
+	Reason        schema.MachineDowntimeReason `json:",omitempty"`
+	ReasonDisplay string                       `json:",omitempty"`
+	Detail        string                       `json:",omitempty"`
+	StartedAt     time.Time                    `json:",omitempty"`
+	EndedAt       *time.Time                   `json:",omitempty"`
+	OpenedByID    *uint64                      `json:",omitempty"`
+	OpenedByName  string                       `json:",omitempty"`
+	ClosedByID    *uint64                      `json:",omitempty"`
+	ClosedByName  string                       `json:",omitempty"`
+	Note          string                       `json:",omitempty"`
+
+	AffectedReservations int `json:",omitempty"` // confirmed reservations in the window, when it is opened
+}
+
+func MachineDowntimeFromDomain(item *schema.MachineDowntime) *MachineDowntime {
+	if item == nil {
+		return nil
+	}
+
+	res := &MachineDowntime{
+		ID:            item.ID,
+		ProductID:     item.ProductID,
+		ProductSKU:    item.Product.Meta.SKU,
+		Reason:        item.Reason,
+		ReasonDisplay: schema.MachineDowntimeReasonProxy[item.Reason],
+		Detail:        item.Detail,
+		StartedAt:     item.StartedAt,
+		EndedAt:       item.EndedAt,
+		OpenedByID:    item.OpenedByID,
+		ClosedByID:    item.ClosedByID,
+		Note:          item.Note,
+	}
+	if item.OpenedBy != nil {
+		res.OpenedByName = item.OpenedBy.FullName()
+	}
+	if item.ClosedBy != nil {
+		res.ClosedByName = item.ClosedBy.FullName()
+	}
+
+	return res
+}
+
+type MachineFault struct {
+	ID            uint64    `json:",omitempty"`
+	ProductID     uint64    `json:",omitempty"`
+	ProductSKU    string    `json:",omitempty"`
+	UserID        uint64    `json:",omitempty"`
+	UserName      string    `json:",omitempty"`
+	ReservationID *uint64   `json:",omitempty"`
+	Description   string    `json:",omitempty"`
+	CreatedAt     time.Time `json:",omitempty"`
+}
+
+func MachineFaultFromDomain(item *schema.MachineFault) *MachineFault {
+	return &MachineFault{
+		ID:            item.ID,
+		ProductID:     item.ProductID,
+		ProductSKU:    item.Product.Meta.SKU,
+		UserID:        item.UserID,
+		UserName:      item.User.FullName(),
+		ReservationID: item.ReservationID,
+		Description:   item.Description,
+		CreatedAt:     item.CreatedAt,
+	}
+}
+
+type MachineHealth struct {
+	ProductID         uint64                      `json:",omitempty"`
+	ProductSKU        string                      `json:",omitempty"`
+	Status            schema.UniWashMachineStatus `json:",omitempty"`
+	UndeliveredStreak int64
+	RecentFaults      int64
+	LastDeliveredAt   *time.Time       `json:",omitempty"`
+	OpenDowntime      *MachineDowntime `json:",omitempty"`
+	DowntimeSeconds   int64
+	Uptime            float64 // percent of the period the machine was in service
+}
+
+type AffectedReservation struct {
+	ID               uint64    `json:",omitempty"`
+	StartTime        time.Time `json:",omitempty"`
+	EndTime          time.Time `json:",omitempty"`
+	StartTimeDisplay string    `json:",omitempty"`
+	UserID           uint64    `json:",omitempty"`
+	UserName         string    `json:",omitempty"`
+	UserMobile       uint64    `json:",omitempty"`
+	Amount           float64   `json:",omitempty"` // paid for the reservation
+}
+
+func AffectedReservationFromDomain(item *schema.Reservation, amount float64) *AffectedReservation {
+	return &AffectedReservation{
+		ID:               item.ID,
+		StartTime:        item.StartTime,
+		EndTime:          item.EndTime,
+		StartTimeDisplay: ptime.New(item.StartTime.In(timezone.In(item.BusinessID))).Format("HH:mm - yyyy/MM/dd"),
+		UserID:           item.UserID,
+		UserName:         item.User.FullName(),
+		UserMobile:       item.User.Mobile,
+		Amount:           amount,
+	}
+}
+
+type MachineCompensation struct {
+	ID           uint64                    `json:",omitempty"`
+	DowntimeID   uint64                    `json:",omitempty"`
+	OperatorID   uint64                    `json:",omitempty"`
+	OperatorName string                    `json:",omitempty"`
+	Action       schema.CompensationAction `json:",omitempty"`
+	Fallback     schema.CompensationAction `json:",omitempty"`
+	Items        []MachineCompensationItem
+	CreatedAt    time.Time `json:",omitempty"`
+}
+
+type MachineCompensationItem struct {
+	schema.MachineCompensationItem
+	ResultDisplay string `json:",omitempty"`
+}
+
+func MachineCompensationFromDomain(item *schema.MachineCompensation) *MachineCompensation {
+	res := &MachineCompensation{
+		ID:           item.ID,
+		DowntimeID:   item.DowntimeID,
+		OperatorID:   item.OperatorID,
+		OperatorName: item.Operator.FullName(),
+		Action:       item.Action,
+		Fallback:     item.Fallback,
+		Items:        make([]MachineCompensationItem, 0, len(item.Items)),
+		CreatedAt:    item.CreatedAt,
+	}
+
+	for _, compensated := range item.Items {
+		res.Items = append(res.Items, MachineCompensationItem{
+			MachineCompensationItem: compensated,
+			ResultDisplay:           schema.CompensationResultProxy[compensated.Result],
+		})
+	}
+
+	return res
+}
+
 func filterAttributes(attributes []schema.Taxonomy) (attrs []tresponse.Taxonomy) {
 	for _, attr := range attributes {
 		attrs = append(attrs, tresponse.Taxonomy{
@@ -52,3 +244,9 @@ func filterAttributes(attributes []schema.Taxonomy) (attrs []tresponse.Taxonomy)
 	}
 	return attrs
 }
+
+type Rescheduled struct {
+	Reservation          *Reservation
+	PriceDifference      float64 // charged from the wallet when positive, credited when negative
+	RemainingReschedules int
+}
@@ -6,32 +6,60 @@ import (
 	"go-fiber-starter/app/database/schema"
 	request2 "go-fiber-starter/app/module/coupon/request"
 	cservice "go-fiber-starter/app/module/coupon/service"
+	nservice "go-fiber-starter/app/module/notification/service"
 	oirequest "go-fiber-starter/app/module/orderItem/request"
+	oservice "go-fiber-starter/app/module/outbox/service"
 	prepository "go-fiber-starter/app/module/product/repository"
+	prequest "go-fiber-starter/app/module/product/request"
+	rservice "go-fiber-starter/app/module/reservation/service"
+	trepository "go-fiber-starter/app/module/transaction/repository"
 	"go-fiber-starter/app/module/uniwash/repository"
 	"go-fiber-starter/app/module/uniwash/request"
 	"go-fiber-starter/app/module/uniwash/response"
-	"go-fiber-starter/internal"
+	waitlistservice "go-fiber-starter/app/module/waitlist/service"
+	wservice "go-fiber-starter/app/module/wallet/service"
+	"go-fiber-starter/internal/device"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/config"
 	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+	"math"
 	"time"
 
 	ptime "github.com/yaa110/go-persian-calendar"
+	"golang.org/x/exp/slices"
 	"gorm.io/gorm"
 
-	MessageWay "github.com/MessageWay/MessageWayGolang"
 	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
 )
 
 type IService interface {
-	ValidateReservation(req oirequest.OrderItem) error
+	ValidateReservation(req oirequest.OrderItem, product *schema.Product) error
 	IsReservable(req oirequest.OrderItem, businessID uint64) error
-	ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64) (reservationID *uint64, err error)
+	ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64, tx *gorm.DB) (reservationID *uint64, err error)
 	Reserve(reservationID uint64) error
+	Release(reservationID uint64) error
 	SendCommand(req request.SendCommand, isForUser bool) error
 	IndexReservedMachines(req request.ReservedMachinesRequest) (reserved []*response.Reservation, paging paginator.Pagination, err error)
-	CheckLastCommandStatus(businessID uint64, reservationID uint64) (status *MessageWay.StatusResponse, err error)
-	GetReservationOptions() (reservationOptions schema.ProductMetaReservationOptions)
+	CheckLastCommandStatus(businessID uint64, reservationID uint64) (status *sms.Status, err error)
+	GetReservationOptions(businessID uint64, productID uint64) (reservationOptions schema.ProductMetaReservationOptions, err error)
+	DeviceState(businessID uint64, productID uint64) (state *device.State, err error)
+	MachineCommands(req request.MachineCommands, forUser bool) (commands []*response.MachineCommand, paging paginator.Pagination, err error)
+	ReservationCommands(businessID uint64, userID uint64, reservationID uint64) (commands []*response.MachineCommand, err error)
+	RefreshCommandDeliveries() error
+	ReportFault(req request.MachineFault) error
+	MachineFaults(req request.MachineFaults) (faults []*response.MachineFault, paging paginator.Pagination, err error)
+	CheckMachineHealth() error
+	MachineHealth(req request.MachineHealth) (health []*response.MachineHealth, err error)
+	Maintenances(req request.Maintenances) (downtimes []*response.MachineDowntime, paging paginator.Pagination, err error)
+	OpenMaintenance(req request.OpenMaintenance) (downtime *response.MachineDowntime, err error)
+	CloseMaintenance(req request.CloseMaintenance) error
+	AffectedReservations(businessID uint64, downtimeID uint64) (reservations []*response.AffectedReservation, err error)
+	Compensate(req request.Compensate) (compensation *response.MachineCompensation, err error)
+	Compensations(businessID uint64, downtimeID uint64) (compensations []*response.MachineCompensation, err error)
+	Reschedule(req request.Reschedule) (rescheduled *response.Rescheduled, err error)
 	SendDeviceIsOffMsgToUser(businessID uint64, reservationID uint64) (err error)
 	SendFullCouponToUser(businessID uint64, reservationID uint64) (err error)
 }
@@ -40,25 +68,51 @@ func Service(
 	repo repository.IRepository,
 	couponService cservice.IService,
 	productRepo prepository.IRepository,
-	messageWay *internal.MessageWayService,
+	smsService *sms.Service,
+	devices *device.Registry,
+	cfg *config.Config,
+	walletService wservice.IService,
+	transactionRepo trepository.IRepository,
+	reserveService rservice.IService,
+	waitlistService waitlistservice.IService,
+	notificationService nservice.IService,
+	outboxService oservice.IService,
 ) IService {
 	return &service{
 		repo,
 		productRepo,
-		messageWay,
+		smsService,
 		couponService,
+		devices,
+		newHealthThresholds(cfg),
+		newReschedulePolicy(cfg),
+		walletService,
+		transactionRepo,
+		reserveService,
+		waitlistService,
+		notificationService,
+		outboxService,
 	}
 }
 
 type service struct {
-	Repo          repository.IRepository
-	ProductRepo   prepository.IRepository
-	MessageWay    *internal.MessageWayService
-	CouponService cservice.IService
+	Repo            repository.IRepository
+	ProductRepo     prepository.IRepository
+	Sms             *sms.Service
+	CouponService   cservice.IService
+	Devices         *device.Registry
+	Health          healthThresholds
+	Rescheduling    reschedulePolicy
+	WalletService   wservice.IService
+	Transactions    trepository.IRepository
+	ReserveService  rservice.IService
+	WaitlistService waitlistservice.IService
+	Notifications   nservice.IService
+	Outbox          oservice.IService
 }
 
-func (_i *service) ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64) (reservationID *uint64, err error) {
-	reservationID, err = _i.Repo.ReserveReservation(req, userID, businessID)
+func (_i *service) ReserveReservation(req oirequest.OrderItem, userID uint64, businessID uint64, tx *gorm.DB) (reservationID *uint64, err error) {
+	reservationID, err = _i.Repo.ReserveReservation(req, userID, businessID, tx)
 	if err != nil {
 		return nil, err
 	}
@@ -72,23 +126,31 @@ func (_i *service) IsReservable(req oirequest.OrderItem, businessID uint64) (err
 	return nil
 }
 
-func (_i *service) ValidateReservation(req oirequest.OrderItem) error {
+func (_i *service) ValidateReservation(req oirequest.OrderItem, product *schema.Product) error {
 	invalidErr := &fiber.Error{
 		Code:    fiber.StatusBadRequest,
 		Message: "در این بازه زمانی شما اجازه رزرو ندارید",
 	}
 
-	loc, _ := time.LoadLocation("Asia/Tehran")
+	loc := timezone.In(product.BusinessID)
 	parsedDate, err := time.ParseInLocation(time.DateOnly, req.Date, loc)
 	if err != nil {
 		return invalidErr
 	}
 
-	if req.GetStartDateTime().Before(time.Now()) {
+	if req.GetStartDateTime(loc).Before(time.Now()) {
 		return invalidErr
 	}
 
-	reservationOptions := _i.GetReservationOptions()
+	if product.Meta.IsBlackoutDate(req.Date) {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "رزرو دستگاه در این تاریخ امکان پذیر نیست"}
+	}
+
+	if !product.Meta.CouldReserveUntil.IsZero() && req.GetStartDateTime(loc).After(product.Meta.CouldReserveUntil) {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "امکان رزرو برای این تاریخ هنوز فراهم نشده است"}
+	}
+
+	reservationOptions := product.Meta.GetReservationOptions()
 	day := reservationOptions[parsedDate.Weekday()]
 	for _, hours := range day {
 		if hours.From == req.StartTime && hours.To == req.EndTime {
@@ -101,15 +163,21 @@ func (_i *service) ValidateReservation(req oirequest.OrderItem) error {
 
 func (_i *service) SendCommand(req request.SendCommand, isForUser bool) (err error) {
 	reservation, err := _i.Repo.GetReservation(req)
-
-	if isForUser {
-		if err != nil {
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if isForUser {
 			return &fiber.Error{
 				Code:    fiber.StatusBadRequest,
 				Message: "شما این دستگاه را رزرو نکرده اید",
 			}
 		}
+		// the operators send the commands of the reserved and in use reservations only
+		return &fiber.Error{Code: fiber.StatusNotFound, Message: "رزرو فعالی با این شناسه برای این دستگاه یافت نشد"}
+	}
 
+	if isForUser {
 		// check the 10 min before start time is after now
 		if !time.Now().After(reservation.StartTime.Add(-10 * time.Minute)) {
 			return &fiber.Error{
@@ -142,26 +210,54 @@ func (_i *service) SendCommand(req request.SendCommand, isForUser bool) (err err
 		}
 	}
 
-	send, err := _i.MessageWay.Send(MessageWay.Message{
-		Provider:   3, // با سر شماره 9000
-		TemplateID: 8698,
-		Method:     "sms",
-		Params:     []string{commandProxy[req.Command]},
-		Mobile:     product.Meta.UniWashMobileNumber,
-	})
-
+	driver, target, err := _i.Devices.For(product)
 	if err != nil {
 		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "ارسال دستور با خطا مواجه شد، دوباره امتحان کنید."}
 	}
 
-	if send.Status == "error" {
+	command := &schema.MachineCommand{
+		BusinessID:    req.BusinessID,
+		ProductID:     product.ID,
+		IssuerID:      req.IssuerID,
+		IssuedBy:      schema.MachineCommandIssuerOperator,
+		Command:       req.Command,
+		Driver:        _i.Devices.Name(product),
+		ReservationID: &reservation.ID,
+	}
+	if isForUser {
+		command.IssuedBy = schema.MachineCommandIssuerUser
+	}
+
+	referenceID, err := driver.SendCommand(target, req.Command)
+	if err != nil {
+		command.Error = err.Error()
+		command.SetStatus(schema.MachineCommandStatusFailed, "", time.Now())
+	} else {
+		command.ReferenceID = referenceID
+		command.SetStatus(schema.MachineCommandStatusSent, "", time.Now())
+	}
+	// the log must never block controlling the machine
+	if logErr := _i.Repo.CreateMachineCommand(command); logErr != nil {
+		log.Error().Err(logErr).Uint64("productID", product.ID).Msg("failed to log the machine command")
+	}
+
+	if errors.Is(err, device.ErrRejected) {
 		return &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "ارسال دستور با خطا مواجه شد کد ۵۰۶، با پشتیبانی در میان بگذارید."}
 	}
+	if err != nil {
+		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "ارسال دستور با خطا مواجه شد، دوباره امتحان کنید."}
+	}
 
 	t := time.Now()
 	reservation.Meta.UniWashLastCommandTime = &t
 	reservation.Meta.UniWashLastCommand = req.Command
-	reservation.Meta.UniWashLastCommandReferenceID = send.ReferenceID
+	reservation.Meta.UniWashLastCommandReferenceID = referenceID
+	switch {
+	case req.Command == schema.UniWashCommandON && reservation.Status == schema.ReservationStatusReserved:
+		reservation.Status = schema.ReservationStatusInUse
+	case req.Command == schema.UniWashCommandOFF && reservation.Status == schema.ReservationStatusInUse:
+		reservation.Status = schema.ReservationStatusCompleted
+	}
 	if err := _i.Repo.UpdateReservation(reservation); err != nil {
 		return err
 	}
@@ -186,23 +282,59 @@ func (_i *service) Reserve(reservationID uint64) (err error) {
 	if err := _i.Repo.Reserve(reservationID); err != nil {
 		return err
 	}
+
+	// the confirmation is only a courtesy, the reservation is done without it
+	if reservation, err := _i.Repo.GetSingleReservation(0, reservationID); err == nil {
+		start := ptime.New(reservation.StartTime.In(timezone.In(reservation.BusinessID))).Format("yyyy/MM/dd ساعت HH:mm")
+		_ = _i.Notifications.Push(&schema.Notification{
+			ReceiverID: reservation.UserID,
+			BusinessID: reservation.BusinessID,
+			Content:    fmt.Sprintf("رزرو شما برای %s تأیید شد.", start),
+		})
+
+		// on Bale only, an sms of every reservation costs more than it is worth
+		message := schema.NewBale(schema.OutboxPurposeReservation, reservation.User.Mobile, schema.SmsReservationConfirmed,
+			reservation.Product.Meta.SKU,
+			start,
+		)
+		message.BusinessID = &reservation.BusinessID
+		_ = _i.Outbox.Enqueue(nil, message)
+	}
+
 	return nil
 }
 
-func (_i *service) CheckLastCommandStatus(businessID uint64, reservationID uint64) (status *MessageWay.StatusResponse, err error) {
+func (_i *service) Release(reservationID uint64) error {
+	return _i.Repo.Release(reservationID)
+}
+
+func (_i *service) CheckLastCommandStatus(businessID uint64, reservationID uint64) (status *sms.Status, err error) {
 	reservation, err := _i.Repo.GetSingleReservation(businessID, reservationID)
 	if err != nil {
 		return nil, err
 	}
 
 	if reservation.Meta.UniWashLastCommand == "" {
-		return &MessageWay.StatusResponse{
-			OTPStatus: "دستوری ارسال نشده",
-			Status:    "error",
-		}, nil
+		return &sms.Status{Status: "دستوری ارسال نشده"}, nil
+	}
+
+	driver, target, err := _i.Devices.For(&reservation.Product)
+	if err != nil {
+		return nil, err
 	}
+	target.ReferenceID = reservation.Meta.UniWashLastCommandReferenceID
 
-	return _i.MessageWay.GetStatus(MessageWay.StatusRequest{ReferenceID: reservation.Meta.UniWashLastCommandReferenceID})
+	delivery, err := driver.Delivery(target)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "دریافت وضعیت دستور با خطا مواجه شد، دوباره امتحان کنید."}
+	}
+
+	status = &sms.Status{Provider: string(_i.Devices.Name(&reservation.Product)), Status: delivery.Detail}
+	if status.Status == "" {
+		status.Status = string(delivery.Status)
+	}
+
+	return status, nil
 }
 
 func (_i *service) SendDeviceIsOffMsgToUser(businessID uint64, reservationID uint64) (err error) {
@@ -211,22 +343,12 @@ func (_i *service) SendDeviceIsOffMsgToUser(businessID uint64, reservationID uin
 		return err
 	}
 
-	send, err := _i.MessageWay.Send(MessageWay.Message{
-		Provider:   5, // با سرشماره 5000
-		TemplateID: 16622,
-		Method:     "sms",
-		Params:     []string{reservation.Product.Meta.SKU},
-		Mobile:     fmt.Sprintf("0%d", reservation.User.Mobile),
-	})
-
-	if err != nil {
+	message := schema.NewSms(schema.OutboxPurposeMachine, reservation.User.Mobile, schema.SmsDeviceOff, reservation.Product.Meta.SKU)
+	message.BusinessID = &reservation.BusinessID
+	if err = _i.Outbox.Enqueue(nil, message); err != nil {
 		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "ارسال دستور با خطا مواجه شد، دوباره امتحان کنید."}
 	}
 
-	if send.Status == "error" {
-		return &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "ارسال دستور با خطا مواجه شد کد ۵۰۶، با پشتیبانی در میان بگذارید."}
-	}
-
 	return nil
 }
 
@@ -236,16 +358,52 @@ func (_i *service) SendFullCouponToUser(businessID uint64, reservationID uint64)
 		return err
 	}
 
-	loc, _ := time.LoadLocation("Asia/Tehran")
-	t := time.Now()
-	startTime := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
-	endTime := startTime.Add(8 * 24 * time.Hour).Add(-time.Second)
-	code := utils.GenerateRandomString(8)
+	startTime := timezone.ForBusiness(reservation.BusinessID).Today()
+	endTime := startTime.AddDate(0, 0, 8).Add(-time.Second)
+	code, err := _i.uniqueCouponCode(reservation.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	coupon := request2.Coupon{
+		BusinessID: reservation.BusinessID,
+		Value:      reservation.Product.Price * 1.1, // 10% tax
+		Type:       schema.CouponTypeFixedAmount,
+		EndTime:    endTime.Format(time.DateTime),
+		StartTime:  startTime.Format(time.DateTime),
+		Code:       code,
+		Title:      fmt.Sprintf("خرابی %s | %s", reservation.Product.Meta.SKU, reservation.User.FullName()),
+		Meta: schema.CouponMeta{
+			MaxUsage: 1,
+		},
+	}
+
+	err = _i.CouponService.Store(coupon, nil)
+	if err != nil {
+		return err
+	}
+
+	// a compensation for the reservation, it is not marketing
+	message := schema.NewSms(schema.OutboxPurposeReservation, reservation.User.Mobile, schema.SmsCoupon,
+		reservation.User.FullName(),
+		coupon.Code,
+		ptime.New(endTime).Format("yyyy/MM/dd"),
+	)
+	message.BusinessID = &reservation.BusinessID
+	if err = _i.Outbox.Enqueue(nil, message); err != nil {
+		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "ارسال دستور با خطا مواجه شد، دوباره امتحان کنید."}
+	}
+
+	return nil
+}
+
+func (_i *service) uniqueCouponCode(businessID uint64) (code string, err error) {
+	code = utils.GenerateRandomString(8)
 
 	for i := 0; i < 30; i++ {
-		_coupon, err := _i.CouponService.Show(reservation.BusinessID, nil, &code)
+		_coupon, err := _i.CouponService.Show(businessID, nil, &code)
 		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-			return err
+			return "", err
 		}
 
 		if _coupon == nil {
@@ -255,77 +413,911 @@ func (_i *service) SendFullCouponToUser(businessID uint64, reservationID uint64)
 		}
 	}
 
-	coupon := request2.Coupon{
+	return code, nil
+}
+
+func (_i *service) GetReservationOptions(businessID uint64, productID uint64) (reservationOptions schema.ProductMetaReservationOptions, err error) {
+	if productID == 0 {
+		return schema.ProductMeta{}.GetReservationOptions(), nil
+	}
+
+	product, err := _i.ProductRepo.GetOneVariant(businessID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	return product.Meta.GetReservationOptions(), nil
+}
+
+// DeviceState asks the driver of the machine for its current state.
+func (_i *service) DeviceState(businessID uint64, productID uint64) (state *device.State, err error) {
+	product, err := _i.ProductRepo.GetOneVariant(businessID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, target, err := _i.Devices.For(product)
+	if err != nil {
+		return nil, err
+	}
+
+	if reservation, err := _i.Repo.GetLastCommandedReservation(productID); err == nil {
+		target.ReferenceID = reservation.Meta.UniWashLastCommandReferenceID
+	}
+
+	state, err = driver.State(target)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "دریافت وضعیت دستگاه با خطا مواجه شد، دوباره امتحان کنید."}
+	}
+
+	return state, nil
+}
+
+func (_i *service) MachineCommands(req request.MachineCommands, forUser bool) (commands []*response.MachineCommand, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetMachineCommands(req)
+	if err != nil {
+		return
+	}
+
+	commands = make([]*response.MachineCommand, 0, len(results))
+	for _, result := range results {
+		commands = append(commands, response.MachineCommandFromDomain(result, forUser))
+	}
+
+	return
+}
+
+// ReservationCommands is the timeline of the commands of a reservation of the user.
+func (_i *service) ReservationCommands(businessID uint64, userID uint64, reservationID uint64) (commands []*response.MachineCommand, err error) {
+	reservation, err := _i.Repo.GetSingleReservation(businessID, reservationID)
+	if err != nil || reservation.UserID != userID {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "رزرو یافت نشد"}
+	}
+
+	commands, _, err = _i.MachineCommands(request.MachineCommands{BusinessID: businessID, ReservationID: reservationID}, true)
+	return
+}
+
+// commandDeliveryWindow is how long the delivery of a command is tracked before giving up.
+const commandDeliveryWindow = 2 * time.Hour
+
+// RefreshCommandDeliveries asks the drivers for the delivery of the sent commands.
+func (_i *service) RefreshCommandDeliveries() error {
+	commands, err := _i.Repo.GetUnsettledMachineCommands(100)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, command := range commands {
+		now := time.Now()
+		command.LastCheckedAt = &now
+
+		if now.Sub(command.CreatedAt) > commandDeliveryWindow {
+			command.SetStatus(schema.MachineCommandStatusUnconfirmed, "", now)
+		} else if driver, target, err := _i.Devices.For(&command.Product); err != nil {
+			errs = append(errs, fmt.Errorf("machine command %d: %w", command.ID, err))
+		} else {
+			target.ReferenceID = command.ReferenceID
+			delivery, err := driver.Delivery(target)
+			switch {
+			case err != nil:
+				errs = append(errs, fmt.Errorf("machine command %d: %w", command.ID, err))
+			case delivery.Status == device.DeliveryDelivered:
+				command.SetStatus(schema.MachineCommandStatusDelivered, delivery.Detail, now)
+			case delivery.Status == device.DeliveryFailed:
+				command.SetStatus(schema.MachineCommandStatusFailed, delivery.Detail, now)
+			}
+		}
+
+		if err := _i.Repo.UpdateMachineCommand(command); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// healthThresholds decide when a washing machine is taken out of service automatically.
+type healthThresholds struct {
+	MaxUndelivered    int64
+	MaxFaultReports   int64
+	FaultReportWindow time.Duration
+	NoDeliveryGap     time.Duration
+}
+
+func newHealthThresholds(cfg *config.Config) healthThresholds {
+	thresholds := healthThresholds{
+		MaxUndelivered:    int64(cfg.Services.MachineHealth.MaxUndelivered),
+		MaxFaultReports:   int64(cfg.Services.MachineHealth.MaxFaultReports),
+		FaultReportWindow: cfg.Services.MachineHealth.FaultReportWindow * time.Second,
+		NoDeliveryGap:     cfg.Services.MachineHealth.NoDeliveryGap * time.Second,
+	}
+
+	if thresholds.MaxUndelivered <= 0 {
+		thresholds.MaxUndelivered = 3
+	}
+	if thresholds.MaxFaultReports <= 0 {
+		thresholds.MaxFaultReports = 2
+	}
+	if thresholds.FaultReportWindow <= 0 {
+		thresholds.FaultReportWindow = 24 * time.Hour
+	}
+	if thresholds.NoDeliveryGap <= 0 {
+		thresholds.NoDeliveryGap = 12 * time.Hour
+	}
+
+	return thresholds
+}
+
+type healthSignals struct {
+	UndeliveredStreak int64
+	FailingSince      *time.Time // first command of the undelivered streak
+	FaultReporters    int64
+	LastDeliveredAt   *time.Time
+}
+
+// healthSignals only looks at what happened since the machine last came back into service.
+func (_i *service) healthSignals(machine *schema.Product, now time.Time) (signals healthSignals, err error) {
+	since := machine.CreatedAt
+	end, err := _i.Repo.LastDowntimeEnd(machine.ID)
+	if err != nil {
+		return signals, err
+	}
+	if end != nil && end.After(since) {
+		since = *end
+	}
+
+	if signals.UndeliveredStreak, signals.FailingSince, err = _i.Repo.UndeliveredStreak(machine.ID, since); err != nil {
+		return signals, err
+	}
+
+	faultsSince := now.Add(-_i.Health.FaultReportWindow)
+	if since.After(faultsSince) {
+		faultsSince = since
+	}
+	if signals.FaultReporters, err = _i.Repo.CountFaultReporters(machine.ID, faultsSince); err != nil {
+		return signals, err
+	}
+
+	if signals.LastDeliveredAt, err = _i.Repo.LastDeliveredAt(machine.ID); err != nil {
+		return signals, err
+	}
+
+	return signals, nil
+}
+
+// checkMachine takes the machine out of service when one of the thresholds is reached.
+func (_i *service) checkMachine(machine *schema.Product, now time.Time) (downtime *schema.MachineDowntime, err error) {
+	signals, err := _i.healthSignals(machine, now)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case signals.UndeliveredStreak >= _i.Health.MaxUndelivered:
+		return _i.takeOffline(machine, schema.MachineDowntimeReasonUndelivered,
+			fmt.Sprintf("%d دستور متوالی به دستگاه نرسید", signals.UndeliveredStreak), nil, "", now)
+	case signals.FaultReporters >= _i.Health.MaxFaultReports:
+		return _i.takeOffline(machine, schema.MachineDowntimeReasonFaultReports,
+			fmt.Sprintf("%d کاربر خرابی دستگاه را گزارش کردند", signals.FaultReporters), nil, "", now)
+	case signals.FailingSince != nil && now.Sub(*signals.FailingSince) > _i.Health.NoDeliveryGap:
+		return _i.takeOffline(machine, schema.MachineDowntimeReasonNoDelivery,
+			fmt.Sprintf("از %s هیچ دستوری به دستگاه نرسیده است", ptime.New(signals.FailingSince.In(timezone.In(machine.BusinessID))).Format("HH:mm - yyyy/MM/dd")), nil, "", now)
+	}
+
+	return nil, nil
+}
+
+// takeOffline turns the machine OFF, which blocks booking it, and opens a downtime window.
+func (_i *service) takeOffline(machine *schema.Product, reason schema.MachineDowntimeReason, detail string, operatorID *uint64, note string, now time.Time) (downtime *schema.MachineDowntime, err error) {
+	if err = _i.Repo.SetMachineStatus(machine.ID, schema.UniWashMachineStatusOFF); err != nil {
+		return nil, err
+	}
+	machine.Meta.UniWashMachineStatus = schema.UniWashMachineStatusOFF
+
+	downtime = &schema.MachineDowntime{
+		BusinessID: machine.BusinessID,
+		ProductID:  machine.ID,
+		Reason:     reason,
+		Detail:     detail,
+		StartedAt:  now,
+		OpenedByID: operatorID,
+		Note:       note,
+	}
+	if err = _i.Repo.CreateDowntime(downtime); err != nil {
+		return nil, err
+	}
+	downtime.Product = *machine
+
+	if operatorID == nil {
+		// a failed alert must not keep the machine in service
+		_ = _i.alertOperators(downtime)
+	}
+
+	return downtime, nil
+}
+
+func (_i *service) alertOperators(downtime *schema.MachineDowntime) error {
+	owner, err := _i.Repo.GetBusinessOwner(downtime.BusinessID)
+	if err != nil {
+		return err
+	}
+
+	message := schema.NewSms(schema.OutboxPurposeMachine, owner.Mobile, schema.SmsDowntimeAlert,
+		downtime.Product.Meta.SKU,
+		schema.MachineDowntimeReasonProxy[downtime.Reason],
+	)
+	message.BusinessID = &downtime.BusinessID
+	// the operators should know before the next reservations come
+	message.Priority = schema.OutboxPriorityHigh
+
+	return _i.Outbox.Enqueue(nil, message)
+}
+
+// CheckMachineHealth keeps the maintenance log in line with the status of every machine
+// and takes the unhealthy ones out of service.
+func (_i *service) CheckMachineHealth() error {
+	machines, err := _i.Repo.GetMachines(0)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var errs []error
+	for _, machine := range machines {
+		if err := _i.reconcileMachine(machine, now); err != nil {
+			errs = append(errs, fmt.Errorf("machine %d: %w", machine.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (_i *service) reconcileMachine(machine *schema.Product, now time.Time) error {
+	open, err := _i.Repo.GetOpenDowntime(machine.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	// the status is still editable through the product, so the windows follow it
+	if machine.Meta.UniWashMachineStatus == schema.UniWashMachineStatusOFF {
+		if open == nil {
+			return _i.Repo.CreateDowntime(&schema.MachineDowntime{
+				BusinessID: machine.BusinessID,
+				ProductID:  machine.ID,
+				Reason:     schema.MachineDowntimeReasonManual,
+				Detail:     "خاموش شده از طریق ویرایش محصول",
+				StartedAt:  now,
+			})
+		}
+		return nil
+	}
+
+	if open != nil {
+		open.EndedAt = &now
+		if err := _i.Repo.CloseDowntime(open); err != nil {
+			return err
+		}
+	}
+
+	_, err = _i.checkMachine(machine, now)
+	return err
+}
+
+func (_i *service) ReportFault(req request.MachineFault) error {
+	machine, err := _i.ProductRepo.GetOneVariant(req.BusinessID, req.ProductID)
+	if err != nil {
+		return &fiber.Error{Code: fiber.StatusNotFound, Message: "دستگاه یافت نشد"}
+	}
+
+	fault := &schema.MachineFault{
+		BusinessID:  req.BusinessID,
+		ProductID:   machine.ID,
+		UserID:      req.UserID,
+		Description: req.Description,
+	}
+	if req.ReservationID > 0 {
+		reservation, err := _i.Repo.GetSingleReservation(req.BusinessID, req.ReservationID)
+		if err != nil || reservation.UserID != req.UserID || reservation.ProductID != machine.ID {
+			return &fiber.Error{Code: fiber.StatusNotFound, Message: "رزرو یافت نشد"}
+		}
+		fault.ReservationID = &reservation.ID
+	}
+
+	if err = _i.Repo.CreateMachineFault(fault); err != nil {
+		return err
+	}
+
+	if machine.Meta.UniWashMachineStatus == schema.UniWashMachineStatusOFF {
+		return nil
+	}
+
+	_, err = _i.checkMachine(machine, time.Now())
+	return err
+}
+
+func (_i *service) MachineFaults(req request.MachineFaults) (faults []*response.MachineFault, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetMachineFaults(req)
+	if err != nil {
+		return
+	}
+
+	faults = make([]*response.MachineFault, 0, len(results))
+	for _, result := range results {
+		faults = append(faults, response.MachineFaultFromDomain(result))
+	}
+
+	return
+}
+
+func (_i *service) Maintenances(req request.Maintenances) (downtimes []*response.MachineDowntime, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetDowntimes(req)
+	if err != nil {
+		return
+	}
+
+	downtimes = make([]*response.MachineDowntime, 0, len(results))
+	for _, result := range results {
+		downtimes = append(downtimes, response.MachineDowntimeFromDomain(result))
+	}
+
+	return
+}
+
+// OpenMaintenance takes a machine out of service by hand.
+func (_i *service) OpenMaintenance(req request.OpenMaintenance) (downtime *response.MachineDowntime, err error) {
+	machine, err := _i.ProductRepo.GetOneVariant(req.BusinessID, req.ProductID)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "دستگاه یافت نشد"}
+	}
+
+	if open, _ := _i.Repo.GetOpenDowntime(machine.ID); open != nil {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "این دستگاه هم اکنون از سرویس خارج است"}
+	}
+
+	now := time.Now()
+	result, err := _i.takeOffline(machine, schema.MachineDowntimeReasonManual, "", &req.OperatorID, req.Note, now)
+	if err != nil {
+		return nil, err
+	}
+
+	downtime = response.MachineDowntimeFromDomain(result)
+	if affected, err := _i.Repo.GetAffectedReservations(result, now); err == nil {
+		downtime.AffectedReservations = len(affected)
+	}
+
+	return downtime, nil
+}
+
+// CloseMaintenance brings the machine back into service.
+func (_i *service) CloseMaintenance(req request.CloseMaintenance) error {
+	downtime, err := _i.Repo.GetDowntime(req.BusinessID, req.ID)
+	if err != nil {
+		return &fiber.Error{Code: fiber.StatusNotFound, Message: "سابقه تعمیر یافت نشد"}
+	}
+
+	if !downtime.IsOpen() {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "این دستگاه پیش از این به سرویس بازگشته است"}
+	}
+
+	now := time.Now()
+	downtime.EndedAt = &now
+	downtime.ClosedByID = &req.OperatorID
+	if req.Note != "" {
+		downtime.Note = req.Note
+	}
+
+	if err = _i.Repo.CloseDowntime(downtime); err != nil {
+		return err
+	}
+
+	return _i.Repo.SetMachineStatus(downtime.ProductID, schema.UniWashMachineStatusON)
+}
+
+// MachineHealth reports the health signals and the uptime of every machine of the business in the period.
+func (_i *service) MachineHealth(req request.MachineHealth) (health []*response.MachineHealth, err error) {
+	now := time.Now()
+	to := req.To
+	if to.IsZero() || to.After(now) {
+		to = now
+	}
+	from := req.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -30)
+	}
+	if !from.Before(to) {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "بازه زمانی نامعتبر است"}
+	}
+
+	machines, err := _i.Repo.GetMachines(req.BusinessID)
+	if err != nil {
+		return nil, err
+	}
+
+	downtimes, err := _i.Repo.GetDowntimesBetween(req.BusinessID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	downtimesOf := map[uint64][]*schema.MachineDowntime{}
+	for _, downtime := range downtimes {
+		downtimesOf[downtime.ProductID] = append(downtimesOf[downtime.ProductID], downtime)
+	}
+
+	health = make([]*response.MachineHealth, 0, len(machines))
+	for _, machine := range machines {
+		signals, err := _i.healthSignals(machine, now)
+		if err != nil {
+			return nil, err
+		}
+
+		item := &response.MachineHealth{
+			ProductID:         machine.ID,
+			ProductSKU:        machine.Meta.SKU,
+			Status:            machine.Meta.UniWashMachineStatus,
+			UndeliveredStreak: signals.UndeliveredStreak,
+			RecentFaults:      signals.FaultReporters,
+			LastDeliveredAt:   signals.LastDeliveredAt,
+			Uptime:            100,
+		}
+
+		// the machine can not be down before it existed
+		start := from
+		if machine.CreatedAt.After(start) {
+			start = machine.CreatedAt
+		}
+
+		var down time.Duration
+		for _, downtime := range downtimesOf[machine.ID] {
+			if downtime.IsOpen() {
+				downtime.Product = *machine
+				item.OpenDowntime = response.MachineDowntimeFromDomain(downtime)
+			}
+
+			windowStart, windowEnd := downtime.StartedAt, to
+			if windowStart.Before(start) {
+				windowStart = start
+			}
+			if downtime.EndedAt != nil && downtime.EndedAt.Before(windowEnd) {
+				windowEnd = *downtime.EndedAt
+			}
+			if windowEnd.After(windowStart) {
+				down += windowEnd.Sub(windowStart)
+			}
+		}
+
+		item.DowntimeSeconds = int64(down.Seconds())
+		if period := to.Sub(start); period > 0 {
+			item.Uptime = math.Round(10000*(1-down.Seconds()/period.Seconds())) / 100
+		}
+
+		health = append(health, item)
+	}
+
+	return health, nil
+}
+
+// AffectedReservations lists the confirmed reservations that can not be served because of the downtime.
+func (_i *service) AffectedReservations(businessID uint64, downtimeID uint64) (reservations []*response.AffectedReservation, err error) {
+	downtime, err := _i.Repo.GetDowntime(businessID, downtimeID)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "سابقه تعمیر یافت نشد"}
+	}
+
+	results, err := _i.Repo.GetAffectedReservations(downtime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	reservations = make([]*response.AffectedReservation, 0, len(results))
+	for _, result := range results {
+		amount, err := _i.paidAmount(result)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, response.AffectedReservationFromDomain(result, amount))
+	}
+
+	return reservations, nil
+}
+
+// paidAmount is what the user paid for the reservation in tomans, a free or fully discounted one is worth nothing.
+func (_i *service) paidAmount(reservation *schema.Reservation) (amount float64, err error) {
+	amount, err = _i.Repo.PaidAmount(reservation.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return math.Round(amount), nil
+}
+
+// Compensate handles the affected reservations of a downtime in one action and keeps a report of it.
+func (_i *service) Compensate(req request.Compensate) (compensation *response.MachineCompensation, err error) {
+	downtime, err := _i.Repo.GetDowntime(req.BusinessID, req.DowntimeID)
+	if err != nil {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "سابقه تعمیر یافت نشد"}
+	}
+
+	reservations, err := _i.Repo.GetAffectedReservations(downtime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(req.ReservationIDs) > 0 {
+		reservations = slices.DeleteFunc(reservations, func(reservation *schema.Reservation) bool {
+			return !slices.Contains(req.ReservationIDs, reservation.ID)
+		})
+	}
+	if len(reservations) == 0 {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "رزروی برای جبران خسارت یافت نشد"}
+	}
+
+	if req.CouponValidDays <= 0 {
+		req.CouponValidDays = 8
+	}
+
+	result := &schema.MachineCompensation{
+		BusinessID: req.BusinessID,
+		DowntimeID: downtime.ID,
+		OperatorID: req.OperatorID,
+		Action:     req.Action,
+		Fallback:   req.Fallback,
+	}
+	for _, reservation := range reservations {
+		result.Items = append(result.Items, _i.compensate(reservation, req))
+	}
+
+	if err = _i.Repo.CreateCompensation(result); err != nil {
+		return nil, err
+	}
+
+	return response.MachineCompensationFromDomain(result), nil
+}
+
+// compensate never stops at a failure, the report tells the operator what is left to do.
+func (_i *service) compensate(reservation *schema.Reservation, req request.Compensate) (item schema.MachineCompensationItem) {
+	item = schema.MachineCompensationItem{
+		ReservationID: reservation.ID,
+		UserID:        reservation.UserID,
+	}
+
+	action := req.Action
+	if action == schema.CompensationActionMove {
+		target, err := _i.moveToFreeMachine(reservation)
+		if err != nil {
+			item.Error = err.Error()
+		}
+		if target != nil {
+			item.Result = schema.CompensationResultMoved
+			item.MovedToProductID = target.ID
+			item.MovedToSKU = target.Meta.SKU
+			item.Notified = _i.notify(reservation, schema.SmsReservationMoved,
+				reservation.User.FullName(),
+				reservation.Product.Meta.SKU,
+				target.Meta.SKU,
+				ptime.New(reservation.StartTime.In(timezone.In(reservation.BusinessID))).Format("HH:mm - yyyy/MM/dd"),
+			)
+			return item
+		}
+		action = req.Fallback
+	}
+
+	fail := func(err error) schema.MachineCompensationItem {
+		item.Result = schema.CompensationResultFailed
+		item.Error = err.Error()
+		return item
+	}
+
+	amount, err := _i.paidAmount(reservation)
+	if err != nil {
+		return fail(err)
+	}
+	item.Amount = amount
+
+	// the reservation is canceled only together with its refund or coupon
+	tx, err := _i.Repo.BeginTransaction()
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback()
+
+	if err = _i.Repo.CancelReservation(reservation, tx); err != nil {
+		return fail(err)
+	}
+
+	var (
+		code    string
+		endTime time.Time
+	)
+	switch {
+	case amount == 0:
+		// nothing was paid for it, it is only canceled
+	case action == schema.CompensationActionRefund:
+		err = _i.refundToWallet(reservation, amount, tx)
+	case action == schema.CompensationActionCoupon:
+		code, endTime, err = _i.issueCompensationCoupon(reservation, amount, req.CouponValidDays, tx)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if err = tx.Commit().Error; err != nil {
+		return fail(err)
+	}
+
+	switch {
+	case amount == 0:
+		item.Result = schema.CompensationResultCanceled
+		item.Notified = _i.notify(reservation, schema.SmsDeviceOff, reservation.Product.Meta.SKU)
+	case action == schema.CompensationActionRefund:
+		item.Result = schema.CompensationResultRefunded
+		item.Notified = _i.notify(reservation, schema.SmsReservationRefunded,
+			reservation.User.FullName(),
+			reservation.Product.Meta.SKU,
+			fmt.Sprintf("%.0f", amount),
+		)
+	case action == schema.CompensationActionCoupon:
+		item.Result = schema.CompensationResultCoupon
+		item.CouponCode = code
+		item.Notified = _i.notify(reservation, schema.SmsCoupon,
+			reservation.User.FullName(),
+			code,
+			ptime.New(endTime).Format("yyyy/MM/dd"),
+		)
+	}
+
+	return item
+}
+
+// moveToFreeMachine moves the reservation to a machine of the same post that is free in its time.
+func (_i *service) moveToFreeMachine(reservation *schema.Reservation) (target *schema.Product, err error) {
+	candidates, err := _i.ProductRepo.GetReservableVariants(prequest.ReservableVariants{
 		BusinessID: reservation.BusinessID,
-		Value:      reservation.Product.Price * 1.1, // 10% tax
+		PostID:     reservation.Product.PostID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	date := reservation.StartTime.In(timezone.In(reservation.BusinessID)).Format(time.DateOnly)
+	for _, candidate := range candidates {
+		if candidate.ID == reservation.ProductID ||
+			candidate.Meta.UniWashMachineStatus == schema.UniWashMachineStatusOFF ||
+			candidate.Meta.IsBlackoutDate(date) {
+			continue
+		}
+
+		free, err := _i.Repo.IsSlotFree(candidate.ID, reservation.StartTime, reservation.EndTime, reservation.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !free {
+			continue
+		}
+
+		if err = _i.Repo.MoveReservation(reservation, candidate.ID); err != nil {
+			return nil, err
+		}
+		return candidate, nil
+	}
+
+	return nil, nil
+}
+
+func (_i *service) refundToWallet(reservation *schema.Reservation, amount float64, tx *gorm.DB) error {
+	return _i.settleWithWallet(reservation, amount, fmt.Sprintf("بازگشت وجه رزرو به دلیل خرابی %s", reservation.Product.Meta.SKU), tx)
+}
+
+// settleWithWallet credits the wallet of the user when the amount is positive and charges it when negative.
+func (_i *service) settleWithWallet(reservation *schema.Reservation, amount float64, description string, tx *gorm.DB) error {
+	wallet, err := _i.WalletService.GetOrCreateWallet(&reservation.UserID, nil, tx)
+	if err != nil {
+		return err
+	}
+
+	if err = _i.WalletService.AddAmount(wallet.ID, amount, tx); err != nil {
+		return err
+	}
+
+	orderID, _ := _i.Repo.GetReservationOrderID(reservation.ID)
+	return _i.Transactions.Create(&schema.Transaction{
+		Amount:             amount,
+		UserID:             reservation.UserID,
+		WalletID:           wallet.ID,
+		OrderID:            orderID,
+		Description:        description,
+		OrderPaymentMethod: schema.OrderPaymentMethodWallet,
+		Status:             schema.TransactionStatusSuccess,
+	}, tx)
+}
+
+func (_i *service) issueCompensationCoupon(reservation *schema.Reservation, amount float64, validDays int, tx *gorm.DB) (code string, endTime time.Time, err error) {
+	code, err = _i.uniqueCouponCode(reservation.BusinessID)
+	if err != nil {
+		return "", endTime, err
+	}
+
+	startTime := timezone.ForBusiness(reservation.BusinessID).Today()
+	endTime = startTime.AddDate(0, 0, validDays).Add(-time.Second)
+
+	err = _i.CouponService.Store(request2.Coupon{
+		BusinessID: reservation.BusinessID,
+		Value:      amount,
 		Type:       schema.CouponTypeFixedAmount,
 		EndTime:    endTime.Format(time.DateTime),
 		StartTime:  startTime.Format(time.DateTime),
 		Code:       code,
 		Title:      fmt.Sprintf("خرابی %s | %s", reservation.Product.Meta.SKU, reservation.User.FullName()),
 		Meta: schema.CouponMeta{
-			MaxUsage: 1,
+			MaxUsage:       1,
+			IncludeUserIDs: []uint64{reservation.UserID},
 		},
-	}
+	}, tx)
+
+	return code, endTime, err
+}
+
+// notify reports whether the sms is queued, the outbox keeps trying to send it after that.
+func (_i *service) notify(reservation *schema.Reservation, template string, params ...string) bool {
+	message := schema.NewSms(schema.OutboxPurposeReservation, reservation.User.Mobile, template, params...)
+	message.BusinessID = &reservation.BusinessID
+
+	return _i.Outbox.Enqueue(nil, message) == nil
+}
 
-	err = _i.CouponService.Store(coupon)
+func (_i *service) Compensations(businessID uint64, downtimeID uint64) (compensations []*response.MachineCompensation, err error) {
+	results, err := _i.Repo.GetCompensations(businessID, downtimeID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	send, err := _i.MessageWay.Send(MessageWay.Message{
-		Provider:   5, // با سرشماره 5000
-		TemplateID: 12109,
-		Method:     "sms",
-		Params: []string{
-			reservation.User.FullName(),
-			coupon.Code,
-			ptime.New(endTime).Format("yyyy/MM/dd"),
-		},
-		Mobile: fmt.Sprintf("0%d", reservation.User.Mobile),
-	})
+	compensations = make([]*response.MachineCompensation, 0, len(results))
+	for _, result := range results {
+		compensations = append(compensations, response.MachineCompensationFromDomain(result))
+	}
 
-	if err != nil {
-		return &fiber.Error{Code: fiber.StatusInternalServerError, Message: "ارسال دستور با خطا مواجه شد، دوباره امتحان کنید."}
+	return compensations, nil
+}
+
+// reschedulePolicy limits how late and how many times a user can move a paid reservation.
+type reschedulePolicy struct {
+	Deadline       time.Duration
+	MaxReschedules int
+}
+
+func newReschedulePolicy(cfg *config.Config) reschedulePolicy {
+	policy := reschedulePolicy{
+		Deadline:       cfg.Services.Reschedule.Deadline * time.Second,
+		MaxReschedules: cfg.Services.Reschedule.MaxReschedules,
 	}
 
-	if send.Status == "error" {
-		return &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "ارسال دستور با خطا مواجه شد کد ۵۰۶، با پشتیبانی در میان بگذارید."}
+	if policy.Deadline <= 0 {
+		policy.Deadline = time.Hour
+	}
+	if policy.MaxReschedules <= 0 {
+		policy.MaxReschedules = 2
 	}
 
-	return nil
+	return policy
 }
 
-func (_i *service) GetReservationOptions() (reservationOptions schema.ProductMetaReservationOptions) {
-	options := schema.ProductMetaReservationOptions{}
-	// Sunday is 0
-	dayNumbers := []time.Weekday{0, 1, 2, 3, 4, 5, 6}
+// Reschedule moves a paid reservation of the user to another free slot of the business. The reservation keeps
+// its id, so the order item still points to it, and the price difference of the machines goes through the wallet.
+func (_i *service) Reschedule(req request.Reschedule) (rescheduled *response.Rescheduled, err error) {
+	reservation, err := _i.Repo.GetSingleReservation(req.BusinessID, req.ReservationID)
+	if err != nil || reservation.UserID != req.UserID || reservation.Status != schema.ReservationStatusReserved {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "رزرو یافت نشد"}
+	}
 
-	for _, dayNum := range dayNumbers {
-		options[dayNum] = []schema.ProductMetaDeviceHour{}
-		for hour := 0; hour < 24; hour++ {
-			to := ""
-			if hour+1 == 24 {
-				to = "00:00:00"
-			} else {
-				to = fmt.Sprintf("%02d:00:00", hour+1)
-			}
+	if time.Until(reservation.StartTime) < _i.Rescheduling.Deadline {
+		return nil, &fiber.Error{
+			Code:    fiber.StatusBadRequest,
+			Message: fmt.Sprintf("زمان رزرو را تنها تا %d دقیقه پیش از شروع آن می‌توانید تغییر دهید", int(_i.Rescheduling.Deadline.Minutes())),
+		}
+	}
+
+	if len(reservation.Meta.Reschedules) >= _i.Rescheduling.MaxReschedules {
+		return nil, &fiber.Error{
+			Code:    fiber.StatusBadRequest,
+			Message: fmt.Sprintf("زمان هر رزرو حداکثر %d بار قابل تغییر است", _i.Rescheduling.MaxReschedules),
+		}
+	}
+
+	product, err := _i.ProductRepo.GetOneVariant(req.BusinessID, req.ProductID)
+	if err != nil || product.VariantType == nil || *product.VariantType != schema.ProductVariantTypeWashingMachine {
+		return nil, &fiber.Error{Code: fiber.StatusNotFound, Message: "دستگاه یافت نشد"}
+	}
+	if product.Meta.UniWashMachineStatus == schema.UniWashMachineStatusOFF {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "این دستگاه در حال حاضر در دسترس نیست"}
+	}
+
+	item := req.ToOrderItem(product)
+	loc := timezone.In(req.BusinessID)
+	start, end := item.GetStartDateTime(loc), item.GetEndDateTime(loc)
+	if product.ID == reservation.ProductID && start.Equal(reservation.StartTime) && end.Equal(reservation.EndTime) {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "زمان انتخاب شده با زمان فعلی رزرو یکسان است"}
+	}
+
+	// the same checks as booking a new slot
+	if err = _i.ValidateReservation(item, product); err != nil {
+		return nil, err
+	}
+	free, err := _i.Repo.IsSlotFree(product.ID, start, end, reservation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !free {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "این ساعت دستگاه رزرو شده است"}
+	}
+	if err = _i.WaitlistService.CheckHold(item, req.UserID, req.BusinessID); err != nil {
+		return nil, err
+	}
+	if err = _i.ReserveService.CheckBookingRights(req.UserID, req.BusinessID); err != nil {
+		return nil, err
+	}
+	if err = _i.ReserveService.CheckQuota(item, product, req.UserID, req.BusinessID, reservation.ID); err != nil {
+		return nil, err
+	}
+
+	difference := math.Round((product.Price - reservation.Product.Price) * 1.1) // 10% tax
+
+	// the move, the charge and its transaction are saved together
+	tx, err := _i.Repo.BeginTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-			deviceHour := schema.ProductMetaDeviceHour{
-				ID:   fmt.Sprintf("%d-%02d", dayNum, hour),
-				From: fmt.Sprintf("%02d:00:00", hour),
-				To:   to,
+	if difference > 0 {
+		wallet, err := _i.WalletService.GetOrCreateWallet(&req.UserID, nil, tx)
+		if err != nil {
+			return nil, err
+		}
+		// another payment of the user waits until this one is saved, so the balance can not be spent twice
+		if wallet, err = _i.WalletService.Lock(wallet.ID, tx); err != nil {
+			return nil, err
+		}
+		if wallet.Amount < difference {
+			return nil, &fiber.Error{
+				Code:    fiber.StatusPaymentRequired,
+				Message: fmt.Sprintf("برای این تغییر %.0f تومان مابه‌التفاوت لازم است، ابتدا کیف پول خود را شارژ کنید", difference),
 			}
-			options[dayNum] = append(options[dayNum], deviceHour)
 		}
+	}
 
+	previous := *reservation
+	reservation.Meta.Reschedules = append(reservation.Meta.Reschedules, schema.ReservationReschedule{
+		FromProductID:   previous.ProductID,
+		FromStartTime:   previous.StartTime,
+		FromEndTime:     previous.EndTime,
+		PriceDifference: difference,
+		At:              time.Now(),
+	})
+	reservation.ProductID = product.ID
+	reservation.StartTime = start
+	reservation.EndTime = end
+	if err = _i.Repo.RescheduleReservation(reservation, tx); err != nil {
+		return nil, err
 	}
-	return options
-}
+	reservation.Product = *product
+
+	if difference != 0 {
+		description := fmt.Sprintf("مابه‌التفاوت تغییر رزرو از %s به %s", previous.Product.Meta.SKU, product.Meta.SKU)
+		if err = _i.settleWithWallet(reservation, -difference, description, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	_i.notify(reservation, schema.SmsRescheduled,
+		product.Meta.SKU,
+		ptime.New(start.In(loc)).Format("HH:mm - yyyy/MM/dd"),
+	)
 
-var commandProxy = map[schema.UniWashCommand]string{
-	schema.UniWashCommandON:         "on",
-	schema.UniWashCommandOFF:        "off",
-	schema.UniWashCommandRewash:     "10",
-	schema.UniWashCommandEvacuation: "9",
+	return &response.Rescheduled{
+		Reservation:          response.FromDomain(reservation),
+		PriceDifference:      difference,
+		RemainingReschedules: _i.Rescheduling.MaxReschedules - len(reservation.Meta.Reschedules),
+	}, nil
 }
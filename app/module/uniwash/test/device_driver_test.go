@@ -0,0 +1,185 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/uniwash/request"
+	"go-fiber-starter/internal/device"
+	"go-fiber-starter/utils/config"
+
+	"github.com/rs/zerolog"
+)
+
+// =============================================================================
+// SIMULATOR DRIVER - pure tests
+// =============================================================================
+
+func TestSimulator_WashCycle(t *testing.T) {
+	now := time.Now()
+	simulator := device.NewSimulator(time.Minute)
+	simulator.SetClock(func() time.Time { return now })
+	target := device.Target{ProductID: 1}
+
+	if _, err := simulator.SendCommand(target, schema.UniWashCommandON); err != nil {
+		t.Fatalf("send command: %v", err)
+	}
+
+	state, _ := simulator.State(target)
+	if state.Power != device.PowerON || state.Phase != "washing" || state.LastCommand != schema.UniWashCommandON {
+		t.Fatalf("expected a washing machine, got %+v", state)
+	}
+
+	now = now.Add(2 * time.Minute)
+	state, _ = simulator.State(target)
+	if state.Power != device.PowerOFF || state.Phase != "done" {
+		t.Fatalf("expected the cycle to be done, got %+v", state)
+	}
+}
+
+func TestSimulator_OfflineMachineRejectsCommands(t *testing.T) {
+	simulator := device.NewSimulator(time.Minute)
+	target := device.Target{ProductID: 1}
+	simulator.SetOnline(target.ProductID, false)
+
+	if _, err := simulator.SendCommand(target, schema.UniWashCommandON); err != device.ErrRejected {
+		t.Fatalf("expected ErrRejected, got %v", err)
+	}
+
+	state, _ := simulator.State(target)
+	if state.Online {
+		t.Errorf("expected the machine to be offline")
+	}
+}
+
+// =============================================================================
+// HTTP DRIVER - pure tests
+// =============================================================================
+
+func TestHTTPDriver_OnlyReachesTheConfiguredHosts(t *testing.T) {
+	var tokens []string
+	controller := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"ReferenceID": "42"}`)
+	}))
+	defer controller.Close()
+
+	target := device.Target{ProductID: 1, Address: controller.URL}
+
+	driver := device.NewHTTPDriver("secret", []string{"10.0.0.12:8080"}, time.Second, zerolog.Nop())
+	if _, err := driver.SendCommand(target, schema.UniWashCommandON); err != device.ErrHostNotAllowed {
+		t.Fatalf("expected ErrHostNotAllowed, got %v", err)
+	}
+	if len(tokens) != 0 {
+		t.Fatalf("expected the token not to be sent out of the configured hosts")
+	}
+
+	u, _ := url.Parse(controller.URL)
+	driver.Hosts = []string{u.Host}
+	referenceID, err := driver.SendCommand(target, schema.UniWashCommandON)
+	if err != nil || referenceID != "42" {
+		t.Fatalf("expected the command to be sent, got %q, %v", referenceID, err)
+	}
+	if len(tokens) != 1 || tokens[0] != "Bearer secret" {
+		t.Errorf("expected the token to be sent to the configured host, got %v", tokens)
+	}
+}
+
+func TestRegistry_CheckRefusesTheSimulatorInProduction(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Services.Device.HttpHosts = []string{"10.0.0.12:8080"}
+
+	registry := device.NewRegistry(cfg, zerolog.Nop(), nil)
+	if err := registry.Check(schema.ProductMeta{UniWashDriver: schema.UniWashDriverSimulator}); err != nil {
+		t.Errorf("expected the simulator out of production, got %v", err)
+	}
+	if err := registry.Check(schema.ProductMeta{UniWashDriver: schema.UniWashDriverHTTP, UniWashDeviceURL: "http://169.254.169.254/latest"}); err != device.ErrHostNotAllowed {
+		t.Errorf("expected ErrHostNotAllowed, got %v", err)
+	}
+	if err := registry.Check(schema.ProductMeta{UniWashDriver: schema.UniWashDriverHTTP, UniWashDeviceURL: "http://10.0.0.12:8080"}); err != nil {
+		t.Errorf("expected the configured host, got %v", err)
+	}
+
+	cfg.App.Production = true
+	registry = device.NewRegistry(cfg, zerolog.Nop(), nil)
+	if err := registry.Check(schema.ProductMeta{UniWashDriver: schema.UniWashDriverSimulator}); err != device.ErrUnknownDriver {
+		t.Errorf("expected ErrUnknownDriver in production, got %v", err)
+	}
+}
+
+// =============================================================================
+// SEND COMMAND THROUGH THE SIMULATOR - e2e
+// =============================================================================
+
+func setupSimulatedMachine(t *testing.T, ta *TestApp) (token string, business *schema.Business, product *schema.Product, reservation *schema.Reservation) {
+	t.Helper()
+
+	user := ta.CreateTestUser(t, 9123456789, "testPassword123", "Test", "User", 0, nil)
+	business = ta.CreateTestBusiness(t, "Test Business", schema.BTypeGymManager, user.ID)
+	user.Permissions[business.ID] = []schema.UserRole{schema.URBusinessOwner}
+	ta.DB.Save(user)
+
+	post := ta.CreateTestPost(t, "Washing Machine 1", business.ID, user.ID)
+	product = ta.CreateTestProduct(t, post.ID, business.ID, 50000, "", schema.UniWashMachineStatusON)
+	product.Meta.UniWashDriver = schema.UniWashDriverSimulator
+	ta.DB.Save(product)
+
+	now := time.Now()
+	reservation = ta.CreateTestReservation(t, user.ID, product.ID, business.ID, now.Add(-5*time.Minute), now.Add(55*time.Minute), schema.ReservationStatusReserved)
+
+	return ta.GenerateTestToken(t, user), business, product, reservation
+}
+
+func TestSendCommand_SimulatorDriver(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/send-command", business.ID), request.SendCommand{
+		ReservationID: reservation.ID,
+		ProductID:     product.ID,
+		Command:       schema.UniWashCommandON,
+	}, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	var updated schema.Reservation
+	ta.DB.First(&updated, reservation.ID)
+	if updated.Meta.UniWashLastCommandReferenceID == "" {
+		t.Errorf("expected the reference of the simulator to be stored")
+	}
+
+	resp = ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/uni-wash/device/state?ProductID=%d", business.ID, product.ID), nil, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	state, _ := ParseResponse(t, resp)["Data"].(map[string]interface{})
+	if state["Power"] != string(device.PowerON) || state["Phase"] != "washing" {
+		t.Errorf("expected the simulated machine to be washing, got %v", state)
+	}
+}
+
+func TestSendCommand_SimulatorDriver_OfflineMachine(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+	ta.Devices.Simulator().SetOnline(product.ID, false)
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/send-command", business.ID), request.SendCommand{
+		ReservationID: reservation.ID,
+		ProductID:     product.ID,
+		Command:       schema.UniWashCommandON,
+	}, token)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", resp.StatusCode)
+	}
+}
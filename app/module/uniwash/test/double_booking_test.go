@@ -0,0 +1,180 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// =============================================================================
+// DOUBLE BOOKING - e2e
+// =============================================================================
+
+// guardOverlaps adds the constraint of the migrated database, the other tests build overlapping
+// reservations on purpose. The returned func drops it again.
+func guardOverlaps(t *testing.T, ta *TestApp) func() {
+	t.Helper()
+
+	for _, command := range schema.ReservationNoOverlapCommands {
+		if err := ta.DB.Exec(command).Error; err != nil {
+			t.Fatalf("failed to add the overlap guard: %v", err)
+		}
+	}
+
+	return func() {
+		ta.DB.Exec("ALTER TABLE reservations DROP CONSTRAINT IF EXISTS " + schema.ReservationNoOverlapConstraint)
+	}
+}
+
+func assertSlotTaken(t *testing.T, err error) {
+	t.Helper()
+
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) || fiberErr.Code != fiber.StatusBadRequest || fiberErr.Message != "این ساعت دستگاه رزرو شده است" {
+		t.Errorf("expected the slot taken error, got %v", err)
+	}
+}
+
+func TestDoubleBooking_ParallelCheckouts(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+	defer guardOverlaps(t, ta)()
+
+	f := setupReschedule(t, ta, 50000, 0)
+	item := oirequest.OrderItem{ProductID: f.machine.ID, Date: f.day.Format(time.DateOnly), StartTime: "14:00:00", EndTime: "15:00:00"}
+
+	const checkouts = 8
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		results = make([]error, checkouts)
+	)
+	for i := 0; i < checkouts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			// the same steps as the checkout of an order
+			tx := ta.DB.Begin()
+			defer tx.Rollback()
+
+			if err := ta.Service.IsReservable(item, f.business.ID); err != nil {
+				results[i] = err
+				return
+			}
+			if _, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, tx); err != nil {
+				results[i] = err
+				return
+			}
+			results[i] = tx.Commit().Error
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	var succeeded int
+	for _, err := range results {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assertSlotTaken(t, err)
+	}
+	if succeeded != 1 {
+		t.Errorf("expected exactly 1 checkout to succeed, got %d", succeeded)
+	}
+
+	var held int64
+	ta.DB.Model(&schema.Reservation{}).
+		Where("product_id = ? AND status = ?", f.machine.ID, schema.ReservationStatusHeld).
+		Count(&held)
+	if held != 1 {
+		t.Errorf("expected 1 held reservation, got %d", held)
+	}
+}
+
+func TestDoubleBooking_OverlappingRangeRejected(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+	defer guardOverlaps(t, ta)()
+
+	f := setupReschedule(t, ta, 50000, 0)
+
+	// starts in the middle of the booked 10:00 to 11:00
+	item := oirequest.OrderItem{ProductID: f.machine.ID, Date: f.day.Format(time.DateOnly), StartTime: "10:30:00", EndTime: "11:30:00"}
+	_, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, nil)
+	assertSlotTaken(t, err)
+
+	// the next slot only touches the booked one
+	item = oirequest.OrderItem{ProductID: f.machine.ID, Date: f.day.Format(time.DateOnly), StartTime: "11:00:00", EndTime: "12:00:00"}
+	if _, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, nil); err != nil {
+		t.Errorf("expected the adjacent slot to be reservable, got %v", err)
+	}
+}
+
+func TestDoubleBooking_ExpiredHoldGivesSlotBack(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+	defer guardOverlaps(t, ta)()
+
+	f := setupReschedule(t, ta, 50000, 0)
+	item := oirequest.OrderItem{ProductID: f.machine.ID, Date: f.day.Format(time.DateOnly), StartTime: "14:00:00", EndTime: "15:00:00"}
+
+	first, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, nil)
+	if err != nil {
+		t.Fatalf("reserve reservation: %v", err)
+	}
+	ta.DB.Exec("UPDATE reservations SET created_at = ? WHERE id = ?", time.Now().Add(-schema.ReservationHoldDuration-time.Minute), *first)
+
+	// not swept by the lifecycle cron yet
+	if _, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, nil); err != nil {
+		t.Fatalf("expected the slot of the unpaid hold to be reservable, got %v", err)
+	}
+	if status := reservationStatus(ta, *first); status != schema.ReservationStatusExpired {
+		t.Errorf("expected the unpaid hold to be expired, got %s", status)
+	}
+}
+
+func TestDoubleBooking_OverlapsResolvedBeforeTheGuard(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	// booked twice before there was a guard
+	f := setupReschedule(t, ta, 50000, 0)
+	later := ta.CreateTestReservation(t, f.customer.ID, f.machine.ID, f.business.ID, f.day.Add(10*time.Hour+30*time.Minute), f.day.Add(11*time.Hour+30*time.Minute), schema.ReservationStatusReserved)
+	held := ta.CreateTestReservation(t, f.customer.ID, f.machine.ID, f.business.ID, f.day.Add(10*time.Hour), f.day.Add(11*time.Hour), schema.ReservationStatusHeld)
+	// overlaps only the canceled one, so it stays
+	next := ta.CreateTestReservation(t, f.customer.ID, f.machine.ID, f.business.ID, f.day.Add(11*time.Hour), f.day.Add(12*time.Hour), schema.ReservationStatusReserved)
+
+	canceled, err := schema.ResolveReservationOverlaps(ta.DB)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(canceled) != 2 {
+		t.Fatalf("expected the 2 bookings over the first one to be canceled, got %v", canceled)
+	}
+
+	for id, status := range map[uint64]schema.ReservationStatus{
+		f.reservation.ID: schema.ReservationStatusReserved,
+		later.ID:         schema.ReservationStatusCanceled,
+		held.ID:          schema.ReservationStatusExpired,
+		next.ID:          schema.ReservationStatusReserved,
+	} {
+		if got := reservationStatus(ta, id); got != status {
+			t.Errorf("reservation %d: expected %s, got %s", id, status, got)
+		}
+	}
+
+	// the guard goes on the resolved rows
+	defer guardOverlaps(t, ta)()
+	if canceled, err = schema.ResolveReservationOverlaps(ta.DB); err != nil || len(canceled) != 0 {
+		t.Errorf("expected nothing to do once the guard is there, got %v, %v", canceled, err)
+	}
+}
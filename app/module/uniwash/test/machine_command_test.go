@@ -0,0 +1,173 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/uniwash/request"
+)
+
+// =============================================================================
+// MACHINE COMMAND LOG - e2e
+// =============================================================================
+
+func sendSimulatedCommand(t *testing.T, ta *TestApp, token string, business *schema.Business, product *schema.Product, reservation *schema.Reservation) *http.Response {
+	t.Helper()
+
+	return ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/send-command", business.ID), request.SendCommand{
+		ReservationID: reservation.ID,
+		ProductID:     product.ID,
+		Command:       schema.UniWashCommandON,
+	}, token)
+}
+
+func TestMachineCommand_LoggedAndDelivered(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+
+	resp := sendSimulatedCommand(t, ta, token, business, product, reservation)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	var command schema.MachineCommand
+	if err := ta.DB.Where("product_id = ?", product.ID).First(&command).Error; err != nil {
+		t.Fatalf("expected the command to be logged: %v", err)
+	}
+	if command.Status != schema.MachineCommandStatusSent || command.ReferenceID == "" {
+		t.Fatalf("expected a sent command with a reference, got %+v", command)
+	}
+	if command.ReservationID == nil || *command.ReservationID != reservation.ID {
+		t.Errorf("expected the command to be linked to reservation %d", reservation.ID)
+	}
+	if command.Driver != schema.UniWashDriverSimulator {
+		t.Errorf("expected driver simulator, got %s", command.Driver)
+	}
+
+	if err := ta.Service.RefreshCommandDeliveries(); err != nil {
+		t.Fatalf("refresh deliveries: %v", err)
+	}
+
+	ta.DB.First(&command, command.ID)
+	if command.Status != schema.MachineCommandStatusDelivered || command.DeliveredAt == nil {
+		t.Errorf("expected the command to be delivered, got %+v", command)
+	}
+	if len(command.History) != 2 {
+		t.Errorf("expected 2 history events, got %d", len(command.History))
+	}
+}
+
+func TestMachineCommand_FailedCommandIsLogged(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+	ta.Devices.Simulator().SetOnline(product.ID, false)
+
+	resp := sendSimulatedCommand(t, ta, token, business, product, reservation)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", resp.StatusCode)
+	}
+
+	var command schema.MachineCommand
+	if err := ta.DB.Where("product_id = ?", product.ID).First(&command).Error; err != nil {
+		t.Fatalf("expected the failed command to be logged: %v", err)
+	}
+	if command.Status != schema.MachineCommandStatusFailed || command.Error == "" {
+		t.Errorf("expected a failed command with an error, got %+v", command)
+	}
+}
+
+func TestMachineCommand_OperatorCommandWithoutActiveReservation(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+	ta.DB.Model(reservation).Update("status", schema.ReservationStatusCompleted)
+
+	resp := sendSimulatedCommand(t, ta, token, business, product, reservation)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	var count int64
+	ta.DB.Model(&schema.MachineCommand{}).Where("product_id = ?", product.ID).Count(&count)
+	if count != 0 {
+		t.Errorf("expected no command to be sent, got %d", count)
+	}
+}
+
+func TestMachineCommand_OperatorTimeline(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+	sendSimulatedCommand(t, ta, token, business, product, reservation)
+
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/uni-wash/commands?ProductID=%d", business.ID, product.ID), nil, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	data, _ := ParseResponse(t, resp)["Data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(data))
+	}
+	item := data[0].(map[string]interface{})
+	if item["Driver"] != string(schema.UniWashDriverSimulator) || item["ReferenceID"] == nil {
+		t.Errorf("expected the operator to see the driver details, got %v", item)
+	}
+}
+
+func TestMachineCommand_UserTimeline(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+	sendSimulatedCommand(t, ta, token, business, product, reservation)
+
+	path := fmt.Sprintf("/v1/user/business/%d/uni-wash/reservations/%d/commands", business.ID, reservation.ID)
+	resp := ta.MakeRequest(t, http.MethodGet, path, nil, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	data, _ := ParseResponse(t, resp)["Data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(data))
+	}
+	item := data[0].(map[string]interface{})
+	if _, ok := item["ReferenceID"]; ok {
+		t.Errorf("expected the reference to be hidden from the user, got %v", item)
+	}
+
+	other := ta.CreateTestUser(t, 9123456780, "testPassword123", "Other", "User", 0, nil)
+	resp = ta.MakeRequest(t, http.MethodGet, path, nil, ta.GenerateTestToken(t, other))
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for another user, got %d", resp.StatusCode)
+	}
+}
+
+func TestCheckLastCommandStatus_AsksTheDriverOfTheMachine(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+
+	resp := sendSimulatedCommand(t, ta, token, business, product, reservation)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	status, err := ta.Service.CheckLastCommandStatus(business.ID, reservation.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status.Provider != string(schema.UniWashDriverSimulator) || status.Status != "delivered" {
+		t.Errorf("expected the simulator to report the command delivered, got %+v", status)
+	}
+}
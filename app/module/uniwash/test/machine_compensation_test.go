@@ -0,0 +1,286 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/uniwash/request"
+)
+
+// =============================================================================
+// BULK COMPENSATION - e2e
+// =============================================================================
+
+type compensationFixture struct {
+	token       string
+	business    *schema.Business
+	broken      *schema.Product
+	spare       *schema.Product
+	customer    *schema.User
+	reservation *schema.Reservation
+	downtimeID  uint64
+}
+
+// setupBrokenMachine books a machine for tomorrow and takes it out of service, with a spare machine in the same post.
+func setupBrokenMachine(t *testing.T, ta *TestApp) *compensationFixture {
+	t.Helper()
+
+	owner := ta.CreateTestUser(t, 9123456789, "testPassword123", "Test", "Owner", 0, nil)
+	business := ta.CreateTestBusiness(t, "Test Business", schema.BTypeWMReservation, owner.ID)
+	owner.Permissions[business.ID] = []schema.UserRole{schema.URBusinessOwner}
+	ta.DB.Save(owner)
+	token := ta.GenerateTestToken(t, owner)
+
+	post := ta.CreateTestPost(t, "Washing Machines", business.ID, owner.ID)
+	broken := ta.CreateTestProduct(t, post.ID, business.ID, 50000, "", schema.UniWashMachineStatusON)
+	spare := ta.CreateTestProduct(t, post.ID, business.ID, 50000, "", schema.UniWashMachineStatusON)
+	spare.Meta.SKU = "WM-002"
+	ta.DB.Save(spare)
+
+	customer := ta.CreateTestUser(t, 9123456780, "testPassword123", "Test", "Customer", 0, nil)
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	reservation := ta.CreateTestReservation(t, customer.ID, broken.ID, business.ID, start, start.Add(time.Hour), schema.ReservationStatusReserved)
+	pay(t, ta, 55000, reservation)
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/maintenance", business.ID), request.OpenMaintenance{
+		ProductID: broken.ID,
+	}, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+	downtime, _ := ParseResponse(t, resp)["Data"].(map[string]interface{})
+	if downtime["AffectedReservations"] != float64(1) {
+		t.Errorf("expected 1 affected reservation, got %v", downtime["AffectedReservations"])
+	}
+
+	return &compensationFixture{
+		token:       token,
+		business:    business,
+		broken:      broken,
+		spare:       spare,
+		customer:    customer,
+		reservation: reservation,
+		downtimeID:  uint64(downtime["ID"].(float64)),
+	}
+}
+
+// pay records a completed order of the reservations that cost the user the total, each with a subtotal of 50000.
+func pay(t *testing.T, ta *TestApp, total float64, reservations ...*schema.Reservation) {
+	t.Helper()
+
+	var orderID uint64
+	if err := ta.DB.Raw("INSERT INTO orders (status, total_amt, user_id, business_id) VALUES (?, ?, ?, ?) RETURNING id",
+		schema.OrderStatusCompleted, total, reservations[0].UserID, reservations[0].BusinessID).Scan(&orderID).Error; err != nil {
+		t.Fatalf("failed to create test order: %v", err)
+	}
+	for _, reservation := range reservations {
+		ta.DB.Exec("INSERT INTO order_items (order_id, type, price, subtotal, tax_amt, reservation_id) VALUES (?, 'reservation', 50000, 50000, 5000, ?)", orderID, reservation.ID)
+	}
+}
+
+func (f *compensationFixture) compensate(t *testing.T, ta *TestApp, req request.Compensate) map[string]interface{} {
+	t.Helper()
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/maintenance/%d/compensate", f.business.ID, f.downtimeID), req, f.token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	report, _ := ParseResponse(t, resp)["Data"].(map[string]interface{})
+	items, _ := report["Items"].([]interface{})
+	if len(items) != 1 {
+		t.Fatalf("expected 1 compensated reservation, got %d", len(items))
+	}
+	return items[0].(map[string]interface{})
+}
+
+func TestCompensation_AffectedReservations(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupBrokenMachine(t, ta)
+	past := time.Now().Add(-3 * time.Hour)
+	ta.CreateTestReservation(t, f.customer.ID, f.broken.ID, f.business.ID, past, past.Add(time.Hour), schema.ReservationStatusReserved)
+
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/uni-wash/maintenance/%d/affected", f.business.ID, f.downtimeID), nil, f.token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	data, _ := ParseResponse(t, resp)["Data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("expected only the future reservation, got %d", len(data))
+	}
+	if amount := data[0].(map[string]interface{})["Amount"]; amount != float64(55000) {
+		t.Errorf("expected the paid amount 55000, got %v", amount)
+	}
+}
+
+func TestCompensation_MoveToFreeMachine(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupBrokenMachine(t, ta)
+	item := f.compensate(t, ta, request.Compensate{
+		Action:   schema.CompensationActionMove,
+		Fallback: schema.CompensationActionCoupon,
+	})
+
+	if item["Result"] != string(schema.CompensationResultMoved) || item["MovedToProductID"] != float64(f.spare.ID) {
+		t.Fatalf("expected the reservation to be moved to the spare machine, got %v", item)
+	}
+	if item["Notified"] != true {
+		t.Errorf("expected the user to be notified")
+	}
+
+	var moved schema.Reservation
+	ta.DB.First(&moved, f.reservation.ID)
+	if moved.ProductID != f.spare.ID || moved.Status != schema.ReservationStatusReserved {
+		t.Errorf("expected a reserved reservation on the spare machine, got %+v", moved)
+	}
+
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/uni-wash/maintenance/%d/compensations", f.business.ID, f.downtimeID), nil, f.token)
+	reports, _ := ParseResponse(t, resp)["Data"].([]interface{})
+	if len(reports) != 1 {
+		t.Errorf("expected 1 compensation report, got %d", len(reports))
+	}
+}
+
+func TestCompensation_FallbackToWalletRefund(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupBrokenMachine(t, ta)
+	// the spare machine is taken in the same time
+	ta.CreateTestReservation(t, f.customer.ID, f.spare.ID, f.business.ID, f.reservation.StartTime, f.reservation.EndTime, schema.ReservationStatusReserved)
+
+	item := f.compensate(t, ta, request.Compensate{
+		Action:   schema.CompensationActionMove,
+		Fallback: schema.CompensationActionRefund,
+	})
+	if item["Result"] != string(schema.CompensationResultRefunded) || item["Amount"] != float64(55000) {
+		t.Fatalf("expected a refund of 55000, got %v", item)
+	}
+
+	var canceled schema.Reservation
+	ta.DB.First(&canceled, f.reservation.ID)
+	if canceled.Status != schema.ReservationStatusCanceled {
+		t.Errorf("expected the reservation to be canceled, got %s", canceled.Status)
+	}
+
+	var wallet schema.Wallet
+	ta.DB.Where("user_id = ?", f.customer.ID).First(&wallet)
+	if wallet.Amount != 55000 {
+		t.Errorf("expected 55000 in the wallet, got %v", wallet.Amount)
+	}
+}
+
+func TestCompensation_RefundsTheShareOfTheDiscountedOrder(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupBrokenMachine(t, ta)
+	// the reservation was paid with another one in an order of 110000 that a coupon took down to 66000
+	ta.DB.Exec("DELETE FROM order_items")
+	ta.DB.Exec("DELETE FROM orders")
+	later := ta.CreateTestReservation(t, f.customer.ID, f.spare.ID, f.business.ID, f.reservation.EndTime, f.reservation.EndTime.Add(time.Hour), schema.ReservationStatusReserved)
+	pay(t, ta, 66000, f.reservation, later)
+
+	item := f.compensate(t, ta, request.Compensate{Action: schema.CompensationActionRefund})
+	if item["Result"] != string(schema.CompensationResultRefunded) || item["Amount"] != float64(33000) {
+		t.Fatalf("expected a refund of the paid 33000, got %v", item)
+	}
+
+	var wallet schema.Wallet
+	ta.DB.Where("user_id = ?", f.customer.ID).First(&wallet)
+	if wallet.Amount != 33000 {
+		t.Errorf("expected 33000 in the wallet, got %v", wallet.Amount)
+	}
+}
+
+func TestCompensation_FreeReservationIsOnlyCanceled(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupBrokenMachine(t, ta)
+	// a coupon paid for the whole order
+	ta.DB.Exec("DELETE FROM order_items")
+	ta.DB.Exec("DELETE FROM orders")
+	pay(t, ta, 0, f.reservation)
+
+	item := f.compensate(t, ta, request.Compensate{Action: schema.CompensationActionRefund})
+	if item["Result"] != string(schema.CompensationResultCanceled) || item["Amount"] != nil {
+		t.Fatalf("expected the reservation to be canceled without a refund, got %v", item)
+	}
+
+	var canceled schema.Reservation
+	ta.DB.First(&canceled, f.reservation.ID)
+	if canceled.Status != schema.ReservationStatusCanceled {
+		t.Errorf("expected the reservation to be canceled, got %s", canceled.Status)
+	}
+
+	var transactions int64
+	ta.DB.Model(&schema.Transaction{}).Count(&transactions)
+	if transactions != 0 {
+		t.Errorf("expected nothing to be credited to the wallet, got %d transactions", transactions)
+	}
+}
+
+func TestCompensation_IssueCoupons(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupBrokenMachine(t, ta)
+	item := f.compensate(t, ta, request.Compensate{Action: schema.CompensationActionCoupon})
+
+	code, _ := item["CouponCode"].(string)
+	if item["Result"] != string(schema.CompensationResultCoupon) || code == "" {
+		t.Fatalf("expected a coupon to be issued, got %v", item)
+	}
+
+	var coupon schema.Coupon
+	if err := ta.DB.Where("code = ?", code).First(&coupon).Error; err != nil {
+		t.Fatalf("expected the coupon to be stored: %v", err)
+	}
+	if coupon.Value != 55000 || len(coupon.Meta.IncludeUserIDs) != 1 || coupon.Meta.IncludeUserIDs[0] != f.customer.ID {
+		t.Errorf("expected a 55000 coupon for the customer, got %+v", coupon)
+	}
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/maintenance/%d/compensate", f.business.ID, f.downtimeID), request.Compensate{
+		Action: schema.CompensationActionCoupon,
+	}, f.token)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 when nothing is left to compensate, got %d", resp.StatusCode)
+	}
+}
+
+func TestCompensation_FailedRefundKeepsTheReservation(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupBrokenMachine(t, ta)
+
+	// the transaction record of the refund can not be written
+	ta.DB.Exec("ALTER TABLE transactions RENAME TO transactions_off")
+	defer ta.DB.Exec("ALTER TABLE transactions_off RENAME TO transactions")
+
+	item := f.compensate(t, ta, request.Compensate{Action: schema.CompensationActionRefund})
+	if item["Result"] != string(schema.CompensationResultFailed) {
+		t.Fatalf("expected the refund to fail, got %v", item)
+	}
+
+	var kept schema.Reservation
+	ta.DB.First(&kept, f.reservation.ID)
+	if kept.Status != schema.ReservationStatusReserved {
+		t.Errorf("expected the reservation to stay reserved for another try, got %s", kept.Status)
+	}
+
+	var wallet schema.Wallet
+	ta.DB.Where("user_id = ?", f.customer.ID).First(&wallet)
+	if wallet.Amount != 0 {
+		t.Errorf("expected the wallet credit to be rolled back, got %v", wallet.Amount)
+	}
+}
@@ -0,0 +1,209 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/uniwash/request"
+)
+
+// =============================================================================
+// MACHINE HEALTH - e2e
+// =============================================================================
+
+func createCommand(t *testing.T, ta *TestApp, product *schema.Product, issuerID uint64, status schema.MachineCommandStatus, at time.Time) {
+	t.Helper()
+
+	command := &schema.MachineCommand{
+		BusinessID: product.BusinessID,
+		ProductID:  product.ID,
+		IssuerID:   issuerID,
+		IssuedBy:   schema.MachineCommandIssuerUser,
+		Command:    schema.UniWashCommandON,
+		Status:     status,
+	}
+	command.CreatedAt = at
+	if status == schema.MachineCommandStatusDelivered {
+		command.DeliveredAt = &at
+	}
+
+	if err := ta.DB.Create(command).Error; err != nil {
+		t.Fatalf("failed to create command: %v", err)
+	}
+}
+
+func getProduct(t *testing.T, ta *TestApp, id uint64) *schema.Product {
+	t.Helper()
+
+	var product schema.Product
+	if err := ta.DB.First(&product, id).Error; err != nil {
+		t.Fatalf("failed to get product: %v", err)
+	}
+	return &product
+}
+
+// ageMachine moves the machine into the past, so the commands created before now count
+func ageMachine(ta *TestApp, product *schema.Product) {
+	ta.DB.Model(&schema.Product{}).Where("id = ?", product.ID).Update("created_at", time.Now().AddDate(0, 0, -1))
+}
+
+func getOpenDowntime(ta *TestApp, productID uint64) *schema.MachineDowntime {
+	var downtime schema.MachineDowntime
+	if err := ta.DB.Where("product_id = ? AND ended_at IS NULL", productID).First(&downtime).Error; err != nil {
+		return nil
+	}
+	return &downtime
+}
+
+func TestMachineHealth_UndeliveredCommandsTakeMachineOffline(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	_, _, product, reservation := setupSimulatedMachine(t, ta)
+	ageMachine(ta, product)
+	now := time.Now()
+	createCommand(t, ta, product, reservation.UserID, schema.MachineCommandStatusDelivered, now.Add(-time.Hour))
+	for i := 3; i > 0; i-- {
+		createCommand(t, ta, product, reservation.UserID, schema.MachineCommandStatusUnconfirmed, now.Add(-time.Duration(i)*time.Minute))
+	}
+
+	if err := ta.Service.CheckMachineHealth(); err != nil {
+		t.Fatalf("check machine health: %v", err)
+	}
+
+	if status := getProduct(t, ta, product.ID).Meta.UniWashMachineStatus; status != schema.UniWashMachineStatusOFF {
+		t.Errorf("expected the machine to be OFF, got %s", status)
+	}
+	downtime := getOpenDowntime(ta, product.ID)
+	if downtime == nil || downtime.Reason != schema.MachineDowntimeReasonUndelivered {
+		t.Fatalf("expected an open undelivered downtime, got %+v", downtime)
+	}
+}
+
+func TestMachineHealth_DeliveredCommandResetsStreak(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	_, _, product, reservation := setupSimulatedMachine(t, ta)
+	ageMachine(ta, product)
+	now := time.Now()
+	createCommand(t, ta, product, reservation.UserID, schema.MachineCommandStatusFailed, now.Add(-4*time.Minute))
+	createCommand(t, ta, product, reservation.UserID, schema.MachineCommandStatusFailed, now.Add(-3*time.Minute))
+	createCommand(t, ta, product, reservation.UserID, schema.MachineCommandStatusDelivered, now.Add(-2*time.Minute))
+	createCommand(t, ta, product, reservation.UserID, schema.MachineCommandStatusFailed, now.Add(-time.Minute))
+
+	if err := ta.Service.CheckMachineHealth(); err != nil {
+		t.Fatalf("check machine health: %v", err)
+	}
+
+	if status := getProduct(t, ta, product.ID).Meta.UniWashMachineStatus; status != schema.UniWashMachineStatusON {
+		t.Errorf("expected the machine to stay ON, got %s", status)
+	}
+}
+
+func TestMachineHealth_FaultReportsTakeMachineOffline(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, _ := setupSimulatedMachine(t, ta)
+	other := ta.CreateTestUser(t, 9123456780, "testPassword123", "Other", "User", 0, nil)
+
+	path := fmt.Sprintf("/v1/user/business/%d/uni-wash/faults", business.ID)
+	for _, userToken := range []string{token, token, ta.GenerateTestToken(t, other)} {
+		resp := ta.MakeRequest(t, http.MethodPost, path, request.MachineFault{
+			ProductID:   product.ID,
+			Description: "دستگاه آبگیری نمی کند",
+		}, userToken)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+		}
+	}
+
+	if status := getProduct(t, ta, product.ID).Meta.UniWashMachineStatus; status != schema.UniWashMachineStatusOFF {
+		t.Errorf("expected the machine to be OFF after reports of two users, got %s", status)
+	}
+	downtime := getOpenDowntime(ta, product.ID)
+	if downtime == nil || downtime.Reason != schema.MachineDowntimeReasonFaultReports {
+		t.Fatalf("expected an open fault reports downtime, got %+v", downtime)
+	}
+
+	var count int64
+	ta.DB.Model(&schema.MachineDowntime{}).Where("product_id = ?", product.ID).Count(&count)
+	if count != 1 {
+		t.Errorf("expected one downtime window, got %d", count)
+	}
+}
+
+func TestMachineHealth_MaintenanceWindowAndUptime(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, _ := setupSimulatedMachine(t, ta)
+	ta.DB.Model(&schema.Product{}).Where("id = ?", product.ID).Update("created_at", time.Now().AddDate(0, 0, -60))
+
+	resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/maintenance", business.ID), request.OpenMaintenance{
+		ProductID: product.ID,
+		Note:      "تعویض پمپ",
+	}, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+	if status := getProduct(t, ta, product.ID).Meta.UniWashMachineStatus; status != schema.UniWashMachineStatusOFF {
+		t.Fatalf("expected the machine to be OFF, got %s", status)
+	}
+
+	// the machine has been out of service for 3 of the last 30 days
+	downtime := getOpenDowntime(ta, product.ID)
+	ta.DB.Model(downtime).Update("started_at", time.Now().AddDate(0, 0, -3))
+
+	resp = ta.MakeRequest(t, http.MethodPut, fmt.Sprintf("/v1/business/%d/uni-wash/maintenance/%d/close", business.ID, downtime.ID), request.CloseMaintenance{}, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+	if status := getProduct(t, ta, product.ID).Meta.UniWashMachineStatus; status != schema.UniWashMachineStatusON {
+		t.Errorf("expected the machine to be ON, got %s", status)
+	}
+
+	resp = ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/uni-wash/health", business.ID), nil, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	data, _ := ParseResponse(t, resp)["Data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("expected 1 machine, got %d", len(data))
+	}
+	uptime, _ := data[0].(map[string]interface{})["Uptime"].(float64)
+	if uptime < 89.9 || uptime > 90.1 {
+		t.Errorf("expected an uptime of 90%%, got %v", uptime)
+	}
+}
+
+func TestMachineHealth_ProductEditIsLogged(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	_, _, product, _ := setupSimulatedMachine(t, ta)
+
+	product.Meta.UniWashMachineStatus = schema.UniWashMachineStatusOFF
+	ta.DB.Save(product)
+	if err := ta.Service.CheckMachineHealth(); err != nil {
+		t.Fatalf("check machine health: %v", err)
+	}
+	downtime := getOpenDowntime(ta, product.ID)
+	if downtime == nil || downtime.Reason != schema.MachineDowntimeReasonManual {
+		t.Fatalf("expected an open manual downtime, got %+v", downtime)
+	}
+
+	product.Meta.UniWashMachineStatus = schema.UniWashMachineStatusON
+	ta.DB.Save(product)
+	if err := ta.Service.CheckMachineHealth(); err != nil {
+		t.Fatalf("check machine health: %v", err)
+	}
+	if getOpenDowntime(ta, product.ID) != nil {
+		t.Errorf("expected the downtime to be closed")
+	}
+}
@@ -0,0 +1,261 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/uniwash/request"
+)
+
+// =============================================================================
+// SELF-SERVICE RESCHEDULE - e2e
+// =============================================================================
+
+type rescheduleFixture struct {
+	token       string
+	business    *schema.Business
+	machine     *schema.Product
+	other       *schema.Product
+	customer    *schema.User
+	reservation *schema.Reservation
+	day         time.Time
+}
+
+// setupReschedule books 10:00 to 11:00 on the first of two machines, two days ahead in Tehran.
+func setupReschedule(t *testing.T, ta *TestApp, otherPrice float64, balance float64) *rescheduleFixture {
+	t.Helper()
+
+	owner := ta.CreateTestUser(t, 9123456789, "testPassword123", "Test", "Owner", 0, nil)
+	business := ta.CreateTestBusiness(t, "Test Business", schema.BTypeWMReservation, owner.ID)
+
+	loc, _ := time.LoadLocation("Asia/Tehran")
+	later := time.Now().In(loc).AddDate(0, 0, 2)
+	day := time.Date(later.Year(), later.Month(), later.Day(), 0, 0, 0, 0, loc)
+
+	post := ta.CreateTestPost(t, "Washing Machines", business.ID, owner.ID)
+	machine := ta.CreateTestProduct(t, post.ID, business.ID, 50000, "", schema.UniWashMachineStatusON)
+	other := ta.CreateTestProduct(t, post.ID, business.ID, otherPrice, "", schema.UniWashMachineStatusON)
+	other.Meta.SKU = "WM-002"
+	for _, product := range []*schema.Product{machine, other} {
+		product.Meta.ReservationOptions = schema.ProductMetaReservationOptions{
+			day.Weekday(): {{From: "08:00:00", To: "20:00:00"}},
+		}
+		product.Meta.ReservationSlotDuration = 60
+		ta.DB.Save(product)
+	}
+
+	customer := ta.CreateTestUser(t, 9123456780, "testPassword123", "Test", "Customer", 0, nil)
+	reservation := ta.CreateTestReservation(t, customer.ID, machine.ID, business.ID, day.Add(10*time.Hour), day.Add(11*time.Hour), schema.ReservationStatusReserved)
+	ta.DB.Exec("INSERT INTO order_items (order_id, type, price, subtotal, tax_amt, reservation_id) VALUES (7, 'reservation', 50000, 50000, 5000, ?)", reservation.ID)
+	ta.DB.Exec("INSERT INTO wallets (user_id, amount) VALUES (?, ?)", customer.ID, balance)
+
+	return &rescheduleFixture{
+		token:       ta.GenerateTestToken(t, customer),
+		business:    business,
+		machine:     machine,
+		other:       other,
+		customer:    customer,
+		reservation: reservation,
+		day:         day,
+	}
+}
+
+func (f *rescheduleFixture) reschedule(t *testing.T, ta *TestApp, productID uint64, from string, to string) *http.Response {
+	t.Helper()
+
+	path := fmt.Sprintf("/v1/user/business/%d/uni-wash/reservations/%d/reschedule", f.business.ID, f.reservation.ID)
+	return ta.MakeRequest(t, http.MethodPost, path, request.Reschedule{
+		ProductID: productID,
+		Date:      f.day.Format(time.DateOnly),
+		StartTime: from,
+		EndTime:   to,
+	}, f.token)
+}
+
+func (f *rescheduleFixture) walletAmount(ta *TestApp) float64 {
+	var wallet schema.Wallet
+	ta.DB.Where("user_id = ?", f.customer.ID).First(&wallet)
+	return wallet.Amount
+}
+
+func TestReschedule_ToPricierMachineChargesWallet(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 60000, 20000)
+	resp := f.reschedule(t, ta, f.other.ID, "14:00:00", "15:00:00")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	data, _ := ParseResponse(t, resp)["Data"].(map[string]interface{})
+	if data["PriceDifference"] != float64(11000) || data["RemainingReschedules"] != float64(1) {
+		t.Errorf("unexpected result: %v", data)
+	}
+
+	var moved schema.Reservation
+	ta.DB.First(&moved, f.reservation.ID)
+	if moved.ProductID != f.other.ID || !moved.StartTime.Equal(f.day.Add(14*time.Hour)) {
+		t.Errorf("expected the reservation to be on the other machine at 14:00, got %d at %v", moved.ProductID, moved.StartTime)
+	}
+	if len(moved.Meta.Reschedules) != 1 || moved.Meta.Reschedules[0].FromProductID != f.machine.ID {
+		t.Errorf("expected the previous slot to be kept, got %+v", moved.Meta.Reschedules)
+	}
+
+	var linked int64
+	ta.DB.Model(&schema.OrderItem{}).Where("reservation_id = ? AND order_id = 7", f.reservation.ID).Count(&linked)
+	if linked != 1 {
+		t.Error("expected the order item to still point to the reservation")
+	}
+
+	if amount := f.walletAmount(ta); amount != 9000 {
+		t.Errorf("expected 9000 left in the wallet, got %v", amount)
+	}
+
+	var transaction schema.Transaction
+	ta.DB.Where("user_id = ?", f.customer.ID).First(&transaction)
+	if transaction.Amount != -11000 || transaction.OrderID == nil || *transaction.OrderID != 7 {
+		t.Errorf("expected a charge of 11000 on order 7, got %v on %v", transaction.Amount, transaction.OrderID)
+	}
+}
+
+func TestReschedule_ToCheaperMachineCreditsWallet(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 40000, 0)
+	resp := f.reschedule(t, ta, f.other.ID, "10:00:00", "11:00:00")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+
+	if amount := f.walletAmount(ta); amount != 11000 {
+		t.Errorf("expected 11000 credited to the wallet, got %v", amount)
+	}
+}
+
+func TestReschedule_FailedChargeKeepsTheReservation(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 60000, 20000)
+
+	// the transaction record of the charge can not be written
+	ta.DB.Exec("ALTER TABLE transactions RENAME TO transactions_off")
+	defer ta.DB.Exec("ALTER TABLE transactions_off RENAME TO transactions")
+
+	if resp := f.reschedule(t, ta, f.other.ID, "14:00:00", "15:00:00"); resp.StatusCode == http.StatusOK {
+		t.Fatal("expected the reschedule to fail")
+	}
+
+	var kept schema.Reservation
+	ta.DB.First(&kept, f.reservation.ID)
+	if kept.ProductID != f.machine.ID || !kept.StartTime.Equal(f.reservation.StartTime) || len(kept.Meta.Reschedules) != 0 {
+		t.Errorf("expected the reservation to stay on its slot, got %d at %v", kept.ProductID, kept.StartTime)
+	}
+	if amount := f.walletAmount(ta); amount != 20000 {
+		t.Errorf("expected the wallet to be left as it was, got %v", amount)
+	}
+}
+
+func TestReschedule_ConcurrentChargesSpendTheBalanceOnce(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	// enough for one of the two 11000 differences
+	f := setupReschedule(t, ta, 60000, 11000)
+	second := ta.CreateTestReservation(t, f.customer.ID, f.machine.ID, f.business.ID, f.day.Add(16*time.Hour), f.day.Add(17*time.Hour), schema.ReservationStatusReserved)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, move := range []struct {
+		reservationID uint64
+		from, to      string
+	}{
+		{f.reservation.ID, "12:00:00", "13:00:00"},
+		{second.ID, "18:00:00", "19:00:00"},
+	} {
+		wg.Add(1)
+		go func(i int, reservationID uint64, from string, to string) {
+			defer wg.Done()
+			_, errs[i] = ta.Service.Reschedule(request.Reschedule{
+				ReservationID: reservationID,
+				BusinessID:    f.business.ID,
+				UserID:        f.customer.ID,
+				ProductID:     f.other.ID,
+				Date:          f.day.Format(time.DateOnly),
+				StartTime:     from,
+				EndTime:       to,
+			})
+		}(i, move.reservationID, move.from, move.to)
+	}
+	wg.Wait()
+
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("expected exactly one of the reschedules to be paid, got %v and %v", errs[0], errs[1])
+	}
+	if amount := f.walletAmount(ta); amount != 0 {
+		t.Errorf("expected the wallet to be charged once, got %v", amount)
+	}
+}
+
+func TestReschedule_RefusedSlots(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 60000, 0)
+	someone := ta.CreateTestUser(t, 9123456781, "testPassword123", "Some", "One", 0, nil)
+	ta.CreateTestReservation(t, someone.ID, f.machine.ID, f.business.ID, f.day.Add(12*time.Hour), f.day.Add(13*time.Hour), schema.ReservationStatusReserved)
+
+	cases := []struct {
+		name      string
+		productID uint64
+		from, to  string
+		status    int
+	}{
+		{"taken slot", f.machine.ID, "12:00:00", "13:00:00", http.StatusBadRequest},
+		{"outside the schedule", f.machine.ID, "21:00:00", "22:00:00", http.StatusBadRequest},
+		{"same slot", f.machine.ID, "10:00:00", "11:00:00", http.StatusBadRequest},
+		{"not enough in the wallet", f.other.ID, "14:00:00", "15:00:00", http.StatusPaymentRequired},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := f.reschedule(t, ta, tc.productID, tc.from, tc.to)
+			if resp.StatusCode != tc.status {
+				t.Errorf("expected status %d, got %d, response: %v", tc.status, resp.StatusCode, ParseResponse(t, resp))
+			}
+		})
+	}
+
+	// an overlapping slot of the same reservation is not taken by itself
+	if resp := f.reschedule(t, ta, f.machine.ID, "11:00:00", "12:00:00"); resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200, got %d, response: %v", resp.StatusCode, ParseResponse(t, resp))
+	}
+}
+
+func TestReschedule_DeadlineAndLimit(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 50000, 0)
+	f.reservation.Meta.Reschedules = []schema.ReservationReschedule{{At: time.Now()}, {At: time.Now()}}
+	ta.DB.Save(f.reservation)
+
+	if resp := f.reschedule(t, ta, f.machine.ID, "14:00:00", "15:00:00"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected the third reschedule to be refused, got %d", resp.StatusCode)
+	}
+
+	soon := time.Now().Add(30 * time.Minute)
+	f.reservation.Meta.Reschedules = nil
+	f.reservation.StartTime = soon
+	f.reservation.EndTime = soon.Add(time.Hour)
+	ta.DB.Save(f.reservation)
+
+	if resp := f.reschedule(t, ta, f.machine.ID, "14:00:00", "15:00:00"); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected the reschedule after the deadline to be refused, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
+	"go-fiber-starter/app/module/uniwash/request"
+)
+
+// =============================================================================
+// RESERVATION LIFECYCLE - e2e
+// =============================================================================
+
+func reservationStatus(ta *TestApp, id uint64) schema.ReservationStatus {
+	var reservation schema.Reservation
+	ta.DB.Unscoped().First(&reservation, id)
+	return reservation.Status
+}
+
+func TestLifecycle_HoldIsReservedAfterPayment(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 50000, 0)
+	item := oirequest.OrderItem{ProductID: f.machine.ID, Date: f.day.Format(time.DateOnly), StartTime: "14:00:00", EndTime: "15:00:00"}
+
+	id, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, nil)
+	if err != nil {
+		t.Fatalf("reserve reservation: %v", err)
+	}
+	if status := reservationStatus(ta, *id); status != schema.ReservationStatusHeld {
+		t.Fatalf("expected the new reservation to be held, got %s", status)
+	}
+	if err := ta.Service.IsReservable(item, f.business.ID); err == nil {
+		t.Error("expected the held slot to be taken")
+	}
+
+	if err := ta.Service.Reserve(*id); err != nil {
+		t.Fatalf("reserve: %v", err)
+	}
+	if status := reservationStatus(ta, *id); status != schema.ReservationStatusReserved {
+		t.Errorf("expected the paid hold to be reserved, got %s", status)
+	}
+}
+
+func TestLifecycle_FailedPaymentReleasesHold(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 50000, 0)
+	item := oirequest.OrderItem{ProductID: f.machine.ID, Date: f.day.Format(time.DateOnly), StartTime: "14:00:00", EndTime: "15:00:00"}
+
+	id, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, nil)
+	if err != nil {
+		t.Fatalf("reserve reservation: %v", err)
+	}
+
+	if err := ta.Service.Release(*id); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if status := reservationStatus(ta, *id); status != schema.ReservationStatusCanceled {
+		t.Errorf("expected the released hold to be canceled, got %s", status)
+	}
+	if err := ta.Service.IsReservable(item, f.business.ID); err != nil {
+		t.Errorf("expected the released slot to be free, got %v", err)
+	}
+
+	// a paid reservation is never released
+	if err := ta.Service.Release(f.reservation.ID); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if status := reservationStatus(ta, f.reservation.ID); status != schema.ReservationStatusReserved {
+		t.Errorf("expected the paid reservation to stay reserved, got %s", status)
+	}
+}
+
+func TestLifecycle_ExpiredHoldFreesSlot(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := setupReschedule(t, ta, 50000, 0)
+	item := oirequest.OrderItem{ProductID: f.machine.ID, Date: f.day.Format(time.DateOnly), StartTime: "14:00:00", EndTime: "15:00:00"}
+
+	id, err := ta.Service.ReserveReservation(item, f.customer.ID, f.business.ID, nil)
+	if err != nil {
+		t.Fatalf("reserve reservation: %v", err)
+	}
+	ta.DB.Exec("UPDATE reservations SET created_at = ? WHERE id = ?", time.Now().Add(-schema.ReservationHoldDuration-time.Minute), *id)
+
+	if err := ta.Service.IsReservable(item, f.business.ID); err != nil {
+		t.Errorf("expected the slot of an expired hold to be free, got %v", err)
+	}
+}
+
+func TestLifecycle_CommandsMoveReservation(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	token, business, product, reservation := setupSimulatedMachine(t, ta)
+
+	send := func(command schema.UniWashCommand) {
+		t.Helper()
+		resp := ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/business/%d/uni-wash/send-command", business.ID), request.SendCommand{
+			ReservationID: reservation.ID,
+			ProductID:     product.ID,
+			Command:       command,
+		}, token)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d, response: %v", command, resp.StatusCode, ParseResponse(t, resp))
+		}
+	}
+
+	send(schema.UniWashCommandON)
+	if status := reservationStatus(ta, reservation.ID); status != schema.ReservationStatusInUse {
+		t.Fatalf("expected the reservation to be in use after ON, got %s", status)
+	}
+
+	// the machine can still be controlled while in use
+	send(schema.UniWashCommandMoreWater)
+	if status := reservationStatus(ta, reservation.ID); status != schema.ReservationStatusInUse {
+		t.Fatalf("expected the reservation to stay in use, got %s", status)
+	}
+
+	send(schema.UniWashCommandOFF)
+	if status := reservationStatus(ta, reservation.ID); status != schema.ReservationStatusCompleted {
+		t.Errorf("expected the reservation to be completed after OFF, got %s", status)
+	}
+}
@@ -0,0 +1,121 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+)
+
+func TestProductMeta_GetReservationOptions_SlotDurationAndBuffer(t *testing.T) {
+	meta := schema.ProductMeta{
+		ReservationOptions: schema.ProductMetaReservationOptions{
+			time.Monday: {{From: "08:00:00", To: "12:00:00"}},
+		},
+		ReservationSlotDuration: 90,
+		ReservationBuffer:       15,
+	}
+
+	options := meta.GetReservationOptions()
+
+	monday := options[time.Monday]
+	if len(monday) != 2 {
+		t.Fatalf("expected 2 slots on monday, got %d: %v", len(monday), monday)
+	}
+
+	if monday[0].From != "08:00:00" || monday[0].To != "09:30:00" {
+		t.Errorf("unexpected first slot: %+v", monday[0])
+	}
+
+	if monday[1].From != "09:45:00" || monday[1].To != "11:15:00" {
+		t.Errorf("unexpected second slot: %+v", monday[1])
+	}
+
+	if len(options[time.Sunday]) != 0 {
+		t.Errorf("expected no slots on sunday, got %d", len(options[time.Sunday]))
+	}
+}
+
+func TestProductMeta_GetReservationOptions_MidnightWindow(t *testing.T) {
+	meta := schema.ProductMeta{
+		ReservationOptions: schema.ProductMetaReservationOptions{
+			time.Friday: {{From: "22:00:00", To: "00:00:00"}},
+		},
+	}
+
+	friday := meta.GetReservationOptions()[time.Friday]
+	if len(friday) != 2 {
+		t.Fatalf("expected 2 slots on friday, got %d: %v", len(friday), friday)
+	}
+
+	if friday[1].From != "23:00:00" || friday[1].To != "00:00:00" {
+		t.Errorf("expected last slot to end at midnight, got: %+v", friday[1])
+	}
+}
+
+func TestProductMeta_ValidateReservationSchedule(t *testing.T) {
+	cases := []struct {
+		name    string
+		meta    schema.ProductMeta
+		wantErr bool
+	}{
+		{"empty schedule", schema.ProductMeta{}, false},
+		{"valid window", schema.ProductMeta{ReservationOptions: schema.ProductMetaReservationOptions{time.Monday: {{From: "08:00:00", To: "12:00:00"}}}}, false},
+		{"reversed window", schema.ProductMeta{ReservationOptions: schema.ProductMetaReservationOptions{time.Monday: {{From: "12:00:00", To: "08:00:00"}}}}, true},
+		{"invalid time", schema.ProductMeta{ReservationOptions: schema.ProductMetaReservationOptions{time.Monday: {{From: "8", To: "12:00:00"}}}}, true},
+		{"invalid blackout date", schema.ProductMeta{ReservationBlackoutDates: []string{"1403/01/01"}}, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.meta.ValidateReservationSchedule()
+			if (err != nil) != tc.wantErr {
+				t.Errorf("expected error: %v, got: %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetReservationOptions_ProductSchedule(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	user := ta.CreateTestUser(t, 9123456789, "testPassword123", "Test", "User", 0, nil)
+	business := ta.CreateTestBusiness(t, "Test Business", schema.BTypeWMReservation, user.ID)
+	user.Permissions[business.ID] = []schema.UserRole{schema.URBusinessOwner}
+	ta.DB.Save(user)
+
+	post := ta.CreateTestPost(t, "Washing Machine", business.ID, user.ID)
+	product := ta.CreateTestProduct(t, post.ID, business.ID, 50000, "09123456789", schema.UniWashMachineStatusON)
+	product.Meta.ReservationOptions = schema.ProductMetaReservationOptions{
+		time.Monday: {{From: "08:00:00", To: "10:00:00"}},
+	}
+	ta.DB.Save(product)
+
+	token := ta.GenerateTestToken(t, user)
+	resp := ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/uni-wash/device/reservation-options?ProductID=%d", business.ID, product.ID), nil, token)
+
+	if resp.StatusCode != http.StatusOK {
+		result := ParseResponse(t, resp)
+		t.Errorf("expected status 200, got %d, response: %v", resp.StatusCode, result)
+		return
+	}
+
+	result := ParseResponse(t, resp)
+	data, ok := result["Data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected Data map in response, got: %v", result)
+	}
+
+	monday, _ := data["1"].([]interface{})
+	if len(monday) != 2 {
+		t.Errorf("expected 2 slots on monday, got %d", len(monday))
+	}
+
+	sunday, _ := data["0"].([]interface{})
+	if len(sunday) != 0 {
+		t.Errorf("expected no slots on sunday, got %d", len(sunday))
+	}
+}
@@ -15,15 +15,28 @@ import (
 	"go-fiber-starter/app/middleware"
 	couponRepo "go-fiber-starter/app/module/coupon/repository"
 	couponService "go-fiber-starter/app/module/coupon/service"
+	notificationRepo "go-fiber-starter/app/module/notification/repository"
+	notificationService "go-fiber-starter/app/module/notification/service"
+	outboxRepo "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
 	productRepo "go-fiber-starter/app/module/product/repository"
+	reservationRepo "go-fiber-starter/app/module/reservation/repository"
+	reservationService "go-fiber-starter/app/module/reservation/service"
+	transactionRepo "go-fiber-starter/app/module/transaction/repository"
 	"go-fiber-starter/app/module/uniwash"
 	"go-fiber-starter/app/module/uniwash/controller"
 	"go-fiber-starter/app/module/uniwash/repository"
 	"go-fiber-starter/app/module/uniwash/service"
 	userRepo "go-fiber-starter/app/module/user/repository"
 	userService "go-fiber-starter/app/module/user/service"
+	waitlistRepo "go-fiber-starter/app/module/waitlist/repository"
+	waitlistService "go-fiber-starter/app/module/waitlist/service"
+	walletRepo "go-fiber-starter/app/module/wallet/repository"
+	walletService "go-fiber-starter/app/module/wallet/service"
 	"go-fiber-starter/internal"
 	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/internal/device"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/utils/config"
 	"go-fiber-starter/utils/helpers"
 
@@ -31,9 +44,19 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/rs/zerolog"
+	"go.uber.org/fx"
 	"gorm.io/gorm"
 )
 
+// testLifecycle collects the hooks of the fx lifecycle, they are run by the tests that need them
+type testLifecycle struct {
+	hooks []fx.Hook
+}
+
+func (l *testLifecycle) Append(hook fx.Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
 // TestApp holds the test application components
 type TestApp struct {
 	App           *fiber.App
@@ -41,6 +64,8 @@ type TestApp struct {
 	Config        *config.Config
 	Cleanup       func()
 	UniWashRouter *uniwash.Router
+	Devices       *device.Registry
+	Service       service.IService
 }
 
 // getProjectRoot returns the project root directory
@@ -72,6 +97,17 @@ func createTestErrorHandler() fiber.ErrorHandler {
 // migrateTestModels creates the necessary tables for uniwash testing
 func migrateTestModels(db *gorm.DB) error {
 	// Drop existing tables to ensure clean state
+	db.Exec("DROP TABLE IF EXISTS outbox_messages CASCADE")
+	db.Exec("DROP TABLE IF EXISTS waitlists CASCADE")
+	db.Exec("DROP TABLE IF EXISTS machine_compensations CASCADE")
+	db.Exec("DROP TABLE IF EXISTS machine_faults CASCADE")
+	db.Exec("DROP TABLE IF EXISTS order_items CASCADE")
+	db.Exec("DROP TABLE IF EXISTS orders CASCADE")
+	db.Exec("DROP TABLE IF EXISTS transactions CASCADE")
+	db.Exec("DROP TABLE IF EXISTS wallets CASCADE")
+	db.Exec("DROP TABLE IF EXISTS machine_downtimes CASCADE")
+	db.Exec("DROP TABLE IF EXISTS machine_commands CASCADE")
+	db.Exec("DROP TABLE IF EXISTS reservation_reminders CASCADE")
 	db.Exec("DROP TABLE IF EXISTS reservations CASCADE")
 	db.Exec("DROP TABLE IF EXISTS products CASCADE")
 	db.Exec("DROP TABLE IF EXISTS posts CASCADE")
@@ -97,6 +133,7 @@ func migrateTestModels(db *gorm.DB) error {
 			workspace_id BIGINT,
 			dormitory_id BIGINT,
 			reservation_count BIGINT DEFAULT 0,
+			referral_code VARCHAR(20) UNIQUE,
 			meta JSONB,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
@@ -193,6 +230,8 @@ func migrateTestModels(db *gorm.DB) error {
 			business_id BIGINT NOT NULL,
 			meta JSONB,
 			user_usage_count BIGINT DEFAULT 0,
+			seats INT NOT NULL DEFAULT 1,
+			shared BOOLEAN NOT NULL DEFAULT FALSE,
 			created_at TIMESTAMPTZ,
 			updated_at TIMESTAMPTZ,
 			deleted_at TIMESTAMPTZ
@@ -224,6 +263,227 @@ func migrateTestModels(db *gorm.DB) error {
 		return err
 	}
 
+	// Create machine_commands table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS machine_commands (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL,
+			product_id BIGINT NOT NULL,
+			reservation_id BIGINT,
+			issuer_id BIGINT NOT NULL,
+			issued_by VARCHAR(50) NOT NULL,
+			command VARCHAR(50) NOT NULL,
+			driver VARCHAR(50),
+			reference_id VARCHAR(255),
+			status VARCHAR(50) DEFAULT 'sent',
+			error VARCHAR(500),
+			history JSONB,
+			last_checked_at TIMESTAMPTZ,
+			delivered_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create reservation_reminders table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS reservation_reminders (
+			id BIGSERIAL PRIMARY KEY,
+			reservation_id BIGINT NOT NULL,
+			rule_id BIGINT NOT NULL,
+			channel VARCHAR(20) NOT NULL,
+			reference_id VARCHAR(100),
+			sent_at TIMESTAMPTZ NOT NULL,
+			UNIQUE (reservation_id, rule_id)
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create machine_downtimes table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS machine_downtimes (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL,
+			product_id BIGINT NOT NULL,
+			reason VARCHAR(50) NOT NULL,
+			detail VARCHAR(500),
+			started_at TIMESTAMPTZ NOT NULL,
+			ended_at TIMESTAMPTZ,
+			opened_by_id BIGINT,
+			closed_by_id BIGINT,
+			note VARCHAR(500),
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create machine_faults table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS machine_faults (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL,
+			product_id BIGINT NOT NULL,
+			user_id BIGINT NOT NULL,
+			reservation_id BIGINT,
+			description VARCHAR(500),
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create machine_compensations table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS machine_compensations (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT NOT NULL,
+			downtime_id BIGINT NOT NULL,
+			operator_id BIGINT NOT NULL,
+			action VARCHAR(20) NOT NULL,
+			fallback VARCHAR(20),
+			items JSONB,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create wallets table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS wallets (
+			id BIGSERIAL PRIMARY KEY,
+			amount FLOAT DEFAULT 0,
+			user_id BIGINT,
+			business_id BIGINT,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create transactions table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS transactions (
+			id BIGSERIAL PRIMARY KEY,
+			amount FLOAT NOT NULL,
+			status VARCHAR(20) DEFAULT 'pending' NOT NULL,
+			description VARCHAR(255) NOT NULL DEFAULT '',
+			order_payment_method VARCHAR(20) DEFAULT 'online' NOT NULL,
+			gateway_transaction_id VARCHAR(255),
+			wallet_id BIGINT,
+			order_id BIGINT,
+			user_id BIGINT NOT NULL,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create order_items table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS order_items (
+			id BIGSERIAL PRIMARY KEY,
+			type VARCHAR(50) NOT NULL DEFAULT 'lineItem',
+			order_id BIGINT NOT NULL,
+			post_id BIGINT,
+			quantity INT NOT NULL DEFAULT 1,
+			price FLOAT NOT NULL,
+			subtotal FLOAT NOT NULL DEFAULT 0,
+			tax_amt FLOAT NOT NULL DEFAULT 0,
+			reservation_id BIGINT,
+			meta JSONB,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create orders table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS orders (
+			id BIGSERIAL PRIMARY KEY,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			total_amt FLOAT NOT NULL DEFAULT 0,
+			payment_method VARCHAR(20) NOT NULL DEFAULT 'online',
+			meta JSONB,
+			user_id BIGINT NOT NULL,
+			business_id BIGINT NOT NULL,
+			coupon_id BIGINT,
+			parent_id BIGINT,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create waitlists table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS waitlists (
+			id BIGSERIAL PRIMARY KEY,
+			user_id BIGINT NOT NULL,
+			business_id BIGINT NOT NULL,
+			product_id BIGINT,
+			taxonomy_id BIGINT,
+			start_time TIMESTAMPTZ NOT NULL,
+			end_time TIMESTAMPTZ NOT NULL,
+			status VARCHAR(50) NOT NULL DEFAULT 'waiting',
+			offered_product_id BIGINT,
+			offer_expires_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
+	// Create outbox_messages table
+	if err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS outbox_messages (
+			id BIGSERIAL PRIMARY KEY,
+			business_id BIGINT,
+			purpose VARCHAR(30) NOT NULL,
+			priority BIGINT NOT NULL DEFAULT 1,
+			template VARCHAR(50) NOT NULL DEFAULT '',
+			provider VARCHAR(30),
+			mobile VARCHAR(20) NOT NULL,
+			params TEXT[],
+			channels TEXT[],
+			bale_chat_id BIGINT,
+			status VARCHAR(20) NOT NULL DEFAULT 'queued',
+			attempts BIGINT NOT NULL DEFAULT 0,
+			next_attempt_at TIMESTAMPTZ NOT NULL,
+			locked_until TIMESTAMPTZ,
+			reference_id VARCHAR(100),
+			last_error VARCHAR(500),
+			sent_at TIMESTAMPTZ,
+			created_at TIMESTAMPTZ,
+			updated_at TIMESTAMPTZ,
+			deleted_at TIMESTAMPTZ
+		)
+	`).Error; err != nil {
+		return err
+	}
+
 	// Create indexes
 	db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_users_mobile ON users(mobile)")
 	db.Exec("CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at)")
@@ -273,18 +533,36 @@ func SetupTestApp(t *testing.T) *TestApp {
 	productRepository := productRepo.Repository(dbWrapper)
 	userRepository := userRepo.Repository(dbWrapper)
 	couponRepository := couponRepo.Repository(dbWrapper)
+	transactionRepository := transactionRepo.Repository(dbWrapper)
 
 	// Create user service
 	userSvc := userService.Service(userRepository)
 
-	// Create mock MessageWay service
-	mockMW := internal.NewMessageWay(cfg, logger)
+	// the fake sms provider of the test config
+	smsSvc := sms.NewService(cfg, logger, nil, dbWrapper)
+
+	// Create outbox service, the sms are only queued as the worker is not running here
+	outboxSvc := outboxService.Service(outboxRepo.Repository(dbWrapper), smsSvc, cfg)
 
 	// Create coupon service
-	couponSvc := couponService.Service(couponRepository, userSvc, mockMW)
+	couponSvc := couponService.Service(couponRepository, userSvc, outboxSvc)
+
+	// Create wallet service
+	walletSvc := walletService.Service(walletRepo.Repository(dbWrapper))
+
+	// Create device drivers
+	devices := device.NewRegistry(cfg, logger, smsSvc)
+
+	// Create reservation and waitlist services for the booking checks
+	reservationRepository := reservationRepo.Repository(dbWrapper)
+	reservationSvc := reservationService.Service(reservationRepository, productRepository, cfg, outboxSvc)
+	waitlistSvc := waitlistService.Service(waitlistRepo.Repository(dbWrapper), productRepository, reservationRepository, outboxSvc)
+
+	// Create notification service, the hub is not started as no stream is opened here
+	notificationSvc := notificationService.Service(notificationRepo.Repository(dbWrapper), outboxSvc, internal.NewNotificationHub(&testLifecycle{}, cfg, dbWrapper, logger))
 
 	// Create uniwash service
-	uniwashSvc := service.Service(uniwashRepo, couponSvc, productRepository, mockMW)
+	uniwashSvc := service.Service(uniwashRepo, couponSvc, productRepository, smsSvc, devices, cfg, walletSvc, transactionRepository, reservationSvc, waitlistSvc, notificationSvc, outboxSvc)
 
 	// Create uniwash controller
 	uniwashController := controller.Controllers(uniwashSvc)
@@ -299,6 +577,16 @@ func SetupTestApp(t *testing.T) *TestApp {
 	// Cleanup function
 	cleanup := func() {
 		// Clean up test data
+		dbWrapper.Main.Exec("DELETE FROM outbox_messages")
+		dbWrapper.Main.Exec("DELETE FROM waitlists")
+		dbWrapper.Main.Exec("DELETE FROM machine_compensations")
+		dbWrapper.Main.Exec("DELETE FROM order_items")
+		dbWrapper.Main.Exec("DELETE FROM orders")
+		dbWrapper.Main.Exec("DELETE FROM transactions")
+		dbWrapper.Main.Exec("DELETE FROM wallets")
+		dbWrapper.Main.Exec("DELETE FROM machine_faults")
+		dbWrapper.Main.Exec("DELETE FROM machine_downtimes")
+		dbWrapper.Main.Exec("DELETE FROM machine_commands")
 		dbWrapper.Main.Exec("DELETE FROM reservations")
 		dbWrapper.Main.Exec("DELETE FROM coupons")
 		dbWrapper.Main.Exec("DELETE FROM products")
@@ -315,6 +603,8 @@ func SetupTestApp(t *testing.T) *TestApp {
 		Config:        cfg,
 		Cleanup:       cleanup,
 		UniWashRouter: uniwashRouter,
+		Devices:       devices,
+		Service:       uniwashSvc,
 	}
 }
 
@@ -534,6 +824,14 @@ func (ta *TestApp) CleanupReservations(t *testing.T) {
 // CleanupAll removes all test data from the database
 func (ta *TestApp) CleanupAll(t *testing.T) {
 	t.Helper()
+	ta.DB.Exec("DELETE FROM machine_compensations")
+	ta.DB.Exec("DELETE FROM order_items")
+	ta.DB.Exec("DELETE FROM orders")
+	ta.DB.Exec("DELETE FROM transactions")
+	ta.DB.Exec("DELETE FROM wallets")
+	ta.DB.Exec("DELETE FROM machine_faults")
+	ta.DB.Exec("DELETE FROM machine_downtimes")
+	ta.DB.Exec("DELETE FROM machine_commands")
 	ta.DB.Exec("DELETE FROM reservations")
 	ta.DB.Exec("DELETE FROM coupons")
 	ta.DB.Exec("DELETE FROM products")
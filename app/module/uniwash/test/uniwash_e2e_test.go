@@ -774,6 +774,12 @@ func TestSendDeviceIsOffMsgToUser_Success(t *testing.T) {
 	if result["result"] != "success" {
 		t.Errorf("expected success response, got: %v", result)
 	}
+
+	var queued int64
+	ta.DB.Model(&schema.OutboxMessage{}).Where("template = ? AND business_id = ?", schema.SmsDeviceOff, business.ID).Count(&queued)
+	if queued != 1 {
+		t.Errorf("expected the sms to be queued, got %d", queued)
+	}
 }
 
 func TestSendDeviceIsOffMsgToUser_ReservationNotFound(t *testing.T) {
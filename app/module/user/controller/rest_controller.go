@@ -11,9 +11,11 @@ import (
 	"go-fiber-starter/app/module/user/service"
 	"go-fiber-starter/utils"
 	"go-fiber-starter/utils/config"
+	"go-fiber-starter/utils/optout"
 	"go-fiber-starter/utils/paginator"
 	"go-fiber-starter/utils/response"
 	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/xuri/excelize/v2"
@@ -26,8 +28,12 @@ type IRestController interface {
 	Store(c *fiber.Ctx) error
 	Update(c *fiber.Ctx) error
 	UpdateAccount(c *fiber.Ctx) error
+	Account(c *fiber.Ctx) error
 	Delete(c *fiber.Ctx) error
 
+	Unsubscribe(c *fiber.Ctx) error
+	InboundSms(c *fiber.Ctx) error
+
 	BusinessUsers(c *fiber.Ctx) error
 	InsertUser(c *fiber.Ctx) error
 	DeleteUser(c *fiber.Ctx) error
@@ -170,6 +176,87 @@ func (_i *controller) UpdateAccount(c *fiber.Ctx) error {
 	return c.JSON("success")
 }
 
+// Account
+// @Summary      Get the account of the user with its notification preferences
+// @Security     Bearer
+// @Tags         Users
+// @Router       /users/user/account [get]
+func (_i *controller) Account(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	account, err := _i.service.Account(user.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: account,
+	})
+}
+
+// Unsubscribe
+// @Summary      Stop the marketing notifications of the user of the opt-out link
+// @Tags         Users
+// @Param        token path string true "Opt-out token"
+// @Router       /unsubscribe/:token [get]
+func (_i *controller) Unsubscribe(c *fiber.Ctx) error {
+	userID, err := optout.Parse(_i.Config.Middleware.Jwt.Secret, c.Params("token"))
+	if err != nil {
+		return &fiber.Error{Code: fiber.StatusNotFound, Message: err.Error()}
+	}
+
+	if err = _i.service.OptOutMarketing(userID); err != nil {
+		return err
+	}
+
+	// opened in the browser of the phone
+	c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	return c.SendString("پیامک‌ها و اعلان‌های تبلیغاتی برای شما ارسال نخواهد شد.")
+}
+
+// InboundSms
+// @Summary      Receive the replies of the users from the sms provider
+// @Description  The opt-out keyword stops the marketing notifications of the user of the mobile.
+// @Tags         Users
+// @Param        secret query string true "Inbound secret"
+// @Param        sms body request.InboundSms true "Reply"
+// @Router       /sms/inbound [post]
+func (_i *controller) InboundSms(c *fiber.Ctx) error {
+	secret := _i.Config.Services.SMS.InboundSecret
+	if secret == "" || c.Query("secret") != secret {
+		return fiber.ErrForbidden
+	}
+
+	req := new(request.InboundSms)
+	if err := c.BodyParser(req); err != nil {
+		return err
+	}
+
+	if !optout.IsKeyword(_i.Config.Services.SMS.OptOutKeyword, req.Message) {
+		// only the opt-outs are read
+		return c.JSON("success")
+	}
+
+	// e.g. 09123456789, +989123456789 or 00989123456789
+	mobile := strings.TrimLeft(req.From, "+0")
+	if len(mobile) == 12 {
+		mobile = strings.TrimPrefix(mobile, "98")
+	}
+	number, err := strconv.ParseUint(mobile, 10, 64)
+	if err != nil {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "تلفن همراه معتبر نمی باشد"}
+	}
+
+	if err = _i.service.OptOutMarketingByMobile(number); err != nil {
+		return err
+	}
+
+	return c.JSON("success")
+}
+
 // Delete
 // @Summary      delete user
 // @Tags         Users
@@ -573,9 +660,10 @@ func (_i *controller) OrderStatus(c *fiber.Ctx) error {
 	}
 
 	url := fmt.Sprintf(
-		"%s/card/payment/result?Status=%s",
+		"%s/card/payment/result?Status=%s&OrderID=%d",
 		_i.Config.App.FrontendDomain,
 		status,
+		orderID,
 	)
 
 	return c.Redirect(url)
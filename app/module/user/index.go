@@ -27,9 +27,14 @@ func (_i *Router) RegisterRoutes(cfg *config.Config) {
 		router.Put("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DUser, mdl.PUpdate), c.Update)
 		router.Delete("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DUser, mdl.PDelete), c.Delete)
 
+		router.Get("/user/account", mdl.Protected(cfg), c.Account)
 		router.Put("/user/account", mdl.Protected(cfg), c.UpdateAccount)
 	})
 
+	// the opt-outs of the marketing notifications, they need no login
+	_i.App.Get("/v1/unsubscribe/:token", c.Unsubscribe)
+	_i.App.Post("/v1/sms/inbound", c.InboundSms)
+
 	_i.App.Route("/v1/business/:businessID", func(router fiber.Router) {
 		router.Get("/users", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DBusiness, mdl.PReadSingle), c.BusinessUsers)
 		router.Post("/users/:userID", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DBusiness, mdl.PReadSingle), c.InsertUser)
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"encoding/json"
 	"errors"
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/module/user/request"
@@ -16,17 +17,19 @@ import (
 type IRepository interface {
 	GetAll(req request.Users) (users []*schema.User, paging paginator.Pagination, err error)
 	GetOne(id uint64) (user *schema.User, err error)
-	Create(user *schema.User) (err error)
+	Create(user *schema.User, tx *gorm.DB) (err error)
 	Update(id uint64, user *schema.User) (err error)
 	Delete(id uint64) (err error)
 
 	FindUserByMobile(mobile uint64) (user *schema.User, err error)
+	UpdateNotificationPreferences(id uint64, preferences schema.NotificationPreferences) (err error)
 
 	GetUsers(req request.BusinessUsers) (users []*schema.User, paging paginator.Pagination, err error)
 	GetPostObservers(postID uint64) (users []*schema.User, err error)
 	GetUser(req request.BusinessUsersStoreRole) (user *schema.User, err error)
 	InsertUser(businessID uint64, userID uint64) (err error)
 	DeleteUser(businessID uint64, userID uint64) (err error)
+	BeginTransaction() (*gorm.DB, error)
 }
 
 func Repository(DB *database.Database) IRepository {
@@ -81,8 +84,11 @@ func (_i *repo) GetOne(id uint64) (user *schema.User, err error) {
 	return user, nil
 }
 
-func (_i *repo) Create(user *schema.User) (err error) {
-	return _i.DB.Main.Create(user).Error
+func (_i *repo) Create(user *schema.User, tx *gorm.DB) (err error) {
+	if tx == nil {
+		tx = _i.DB.Main
+	}
+	return tx.Create(user).Error
 }
 
 func (_i *repo) Update(id uint64, user *schema.User) (err error) {
@@ -95,6 +101,18 @@ func (_i *repo) Delete(id uint64) error {
 	return _i.DB.Main.Delete(&schema.User{}, id).Error
 }
 
+// UpdateNotificationPreferences sets the preferences in the meta of the user, the rest of the meta is kept.
+func (_i *repo) UpdateNotificationPreferences(id uint64, preferences schema.NotificationPreferences) (err error) {
+	value, err := json.Marshal(preferences)
+	if err != nil {
+		return err
+	}
+
+	return _i.DB.Main.Model(&schema.User{}).
+		Where("id = ?", id).
+		Update("meta", gorm.Expr("jsonb_set(COALESCE(meta, '{}'::jsonb), '{NotificationPreferences}', ?::jsonb)", string(value))).Error
+}
+
 func (_i *repo) FindUserByMobile(mobile uint64) (user *schema.User, err error) {
 	if err := _i.DB.Main.Where("mobile = ?", mobile).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -122,7 +140,7 @@ func (_i *repo) GetUsers(req request.BusinessUsers) (users []*schema.User, pagin
 	}
 
 	// Build the LEFT JOIN condition dynamically with parameterized queries
-	joinCondition := "LEFT JOIN reservations ON reservations.user_id = users.id AND reservations.deleted_at IS NULL AND reservations.status = 'reserved'"
+	joinCondition := "LEFT JOIN reservations ON reservations.user_id = users.id AND reservations.deleted_at IS NULL AND reservations.status IN ('reserved', 'inUse', 'completed')"
 	joinArgs := []interface{}{}
 
 	if req.StartTime != nil && !req.StartTime.IsZero() {
@@ -246,3 +264,11 @@ func (_i *repo) DeleteUser(businessID uint64, userID uint64) (err error) {
 
 	return nil
 }
+
+func (_i *repo) BeginTransaction() (*gorm.DB, error) {
+	tx := _i.DB.Main.Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tx, nil
+}
@@ -24,6 +24,14 @@ type UpdateUserAccount struct {
 	CityID      uint64 `example:"1" validate:"number"`
 	WorkspaceID uint64 `example:"1" validate:"number"`
 	DormitoryID uint64 `example:"1" validate:"number"`
+
+	NotificationPreferences schema.NotificationPreferences `json:",omitempty" example:"{marketing: {Sms: false}}"` // unchanged when empty
+}
+
+// InboundSms is a reply of a user the sms provider posts
+type InboundSms struct {
+	From    string `json:"from" form:"from"`
+	Message string `json:"message" form:"message"`
 }
 
 type BusinessUsers struct {
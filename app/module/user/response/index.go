@@ -23,6 +23,9 @@ type User struct {
 	Permissions      schema.UserPermissions ``
 	Roles            []schema.UserRole      `json:",omitempty"`
 	Meta             schema.UserMeta        `json:",omitempty"`
+
+	NotificationPreferences schema.NotificationPreferences `json:",omitempty"` // only of the account of the user
+	BaleLinked              bool                           `json:",omitempty"` // only of the account of the user
 }
 
 func FromDomain(item *schema.User, businessID *uint64) (res *User) {
@@ -7,6 +7,8 @@ import (
 	"go-fiber-starter/app/module/user/response"
 	"go-fiber-starter/utils/paginator"
 	"slices"
+
+	"github.com/gofiber/fiber/v2"
 )
 
 type IService interface {
@@ -15,6 +17,9 @@ type IService interface {
 	Store(req request.User) (err error)
 	Update(id uint64, req request.User) (err error)
 	UpdateAccount(req request.UpdateUserAccount) (err error)
+	Account(id uint64) (user *response.User, err error)
+	OptOutMarketing(id uint64) (err error)
+	OptOutMarketingByMobile(mobile uint64) (err error)
 	Destroy(id uint64) error
 
 	GetPostObservers(postId uint64) (users []*response.User, err error)
@@ -57,7 +62,11 @@ func (_i *service) Show(id uint64) (user *response.User, err error) {
 }
 
 func (_i *service) Store(req request.User) (err error) {
-	return _i.Repo.Create(req.ToDomain())
+	user := req.ToDomain()
+	code := schema.NewReferralCode()
+	user.ReferralCode = &code
+
+	return _i.Repo.Create(user, nil)
 }
 
 func (_i *service) Update(id uint64, req request.User) (err error) {
@@ -65,8 +74,64 @@ func (_i *service) Update(id uint64, req request.User) (err error) {
 }
 
 func (_i *service) UpdateAccount(req request.UpdateUserAccount) (err error) {
+	if err = req.NotificationPreferences.Validate(); err != nil {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: err.Error()}
+	}
+
 	u := req.ToDomain()
-	return _i.Repo.Update(u.ID, u)
+	if err = _i.Repo.Update(u.ID, u); err != nil {
+		return err
+	}
+
+	if req.NotificationPreferences != nil {
+		return _i.Repo.UpdateNotificationPreferences(u.ID, req.NotificationPreferences)
+	}
+
+	return nil
+}
+
+func (_i *service) Account(id uint64) (user *response.User, err error) {
+	result, err := _i.Repo.GetOne(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user = response.FromDomain(result, nil)
+	if result.Meta != nil {
+		user.NotificationPreferences = result.Meta.NotificationPreferences
+		user.BaleLinked = result.Meta.BaleChatID != 0
+	}
+
+	return user, nil
+}
+
+// OptOutMarketing turns the marketing off over all the channels, for the opt-out link and keyword.
+func (_i *service) OptOutMarketing(id uint64) (err error) {
+	user, err := _i.Repo.GetOne(id)
+	if err != nil {
+		return err
+	}
+
+	return _i.optOutMarketing(user)
+}
+
+func (_i *service) OptOutMarketingByMobile(mobile uint64) (err error) {
+	user, err := _i.Repo.FindUserByMobile(mobile)
+	if err != nil {
+		return err
+	}
+
+	return _i.optOutMarketing(user)
+}
+
+func (_i *service) optOutMarketing(user *schema.User) error {
+	preferences := schema.NotificationPreferences{}
+	if user.Meta != nil && user.Meta.NotificationPreferences != nil {
+		preferences = user.Meta.NotificationPreferences
+	}
+	preferences.Turn(schema.NotificationCategoryMarketing, false, schema.NotificationChannels...)
+
+	return _i.Repo.UpdateNotificationPreferences(user.ID, preferences)
 }
 
 func (_i *service) Destroy(id uint64) error {
@@ -180,6 +245,9 @@ func (_i *service) BusinessUsersToggleSuspense(req request.BusinessUsersToggleSu
 	} else {
 		isSuspended := false
 		user.IsSuspended = &isSuspended
+		if user.Meta != nil {
+			user.Meta.SuspendedUntil = nil
+		}
 	}
 
 	if len(req.SuspenseReason) == 0 {
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"net/http"
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/optout"
+)
+
+func TestAccount_UpdatesNotificationPreferences(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	user := ta.CreateTestUser(t, 9123456789, "pass", "Ali", "Rezaei")
+	token := ta.GenerateToken(t, user)
+
+	account := map[string]any{
+		"ID":        user.ID,
+		"FirstName": "Ali",
+		"LastName":  "Rezaei",
+		"Mobile":    user.Mobile,
+	}
+
+	account["NotificationPreferences"] = map[string]any{"news": map[string]bool{"Sms": false}}
+	resp := ta.MakeAuthenticatedRequest(t, http.MethodPut, "/v1/users/user/account", account, token)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected an unknown category to be rejected, got %d", resp.StatusCode)
+	}
+
+	account["NotificationPreferences"] = map[string]any{"reminders": map[string]bool{"Sms": false}}
+	resp = ta.MakeAuthenticatedRequest(t, http.MethodPut, "/v1/users/user/account", account, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	prefs := ta.preferencesOf(user.ID)
+	if prefs.Allows(schema.NotificationCategoryReminders, schema.TSms) || !prefs.Allows(schema.NotificationCategoryReminders, schema.TNotification) {
+		t.Errorf("expected only the reminder sms to be turned off, got %v", prefs)
+	}
+	if !prefs.Allows(schema.NotificationCategoryTransactional, schema.TSms) {
+		t.Errorf("expected the other categories to stay on, got %v", prefs)
+	}
+
+	resp = ta.MakeAuthenticatedRequest(t, http.MethodGet, "/v1/users/user/account", nil, token)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	data := ParseResponse(t, resp)["Data"].(map[string]interface{})
+	if _, ok := data["NotificationPreferences"].(map[string]interface{})["reminders"]; !ok {
+		t.Errorf("expected the preferences in the account, got %v", data)
+	}
+}
+
+func TestUnsubscribe_TurnsTheMarketingOff(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	user := ta.CreateTestUser(t, 9123456789, "pass", "Ali", "Rezaei")
+
+	resp := ta.MakeRequest(t, http.MethodGet, "/v1/unsubscribe/"+optout.Token("wrong secret", user.ID), nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a forged link to be rejected, got %d", resp.StatusCode)
+	}
+
+	resp = ta.MakeRequest(t, http.MethodGet, "/v1/unsubscribe/"+optout.Token(ta.Config.Middleware.Jwt.Secret, user.ID), nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	prefs := ta.preferencesOf(user.ID)
+	for _, channel := range schema.NotificationChannels {
+		if prefs.Allows(schema.NotificationCategoryMarketing, channel) {
+			t.Errorf("expected the marketing %s to be off", channel)
+		}
+	}
+	if !prefs.Allows(schema.NotificationCategoryTransactional, schema.TSms) {
+		t.Errorf("expected the transactional sms to stay on")
+	}
+}
+
+func TestInboundSms_OptsOutWithTheKeyword(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	ta.Config.Services.SMS.InboundSecret = "inbound-secret"
+	user := ta.CreateTestUser(t, 9123456789, "pass", "Ali", "Rezaei")
+	other := ta.CreateTestUser(t, 9111111111, "pass", "Sara", "Ahmadi")
+
+	resp := ta.MakeRequest(t, http.MethodPost, "/v1/sms/inbound?secret=wrong", map[string]string{
+		"from":    "989123456789",
+		"message": optout.DefaultKeyword,
+	})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected status 403 without the secret, got %d", resp.StatusCode)
+	}
+
+	for _, reply := range []map[string]string{
+		{"from": "+989123456789", "message": " " + optout.DefaultKeyword + " "},
+		{"from": "09111111111", "message": "ممنون"},
+	} {
+		resp = ta.MakeRequest(t, http.MethodPost, "/v1/sms/inbound?secret=inbound-secret", reply)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+	}
+
+	if ta.preferencesOf(user.ID).Allows(schema.NotificationCategoryMarketing, schema.TSms) {
+		t.Errorf("expected the marketing sms of the user to be off")
+	}
+	if !ta.preferencesOf(other.ID).Allows(schema.NotificationCategoryMarketing, schema.TSms) {
+		t.Errorf("expected the other replies to be ignored")
+	}
+}
+
+func (ta *TestApp) preferencesOf(userID uint64) schema.NotificationPreferences {
+	var user schema.User
+	ta.DB.First(&user, userID)
+	if user.Meta == nil {
+		return nil
+	}
+
+	return user.Meta.NotificationPreferences
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"go-fiber-starter/app/module/userBot/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Link(c *fiber.Ctx) error
+	Unlink(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// Link to the Bale bot
+// @Summary      Get a link that connects a Bale chat to the account of the user
+// @Description  The link works for 10 minutes, the reservation messages, the reminders and the compensation coupons go to the chat first after that.
+// @Tags         Users
+// @Security     Bearer
+// @Router       /users/user/bale-bot/link [get]
+func (_i *controller) Link(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	link, err := _i.service.Link(user.ID)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: link,
+	})
+}
+
+// Unlink from the Bale bot
+// @Summary      Unlink the Bale chat from the account of the user
+// @Tags         Users
+// @Security     Bearer
+// @Router       /users/user/bale-bot [delete]
+func (_i *controller) Unlink(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.Unlink(user.ID); err != nil {
+		return err
+	}
+
+	return c.JSON("success")
+}
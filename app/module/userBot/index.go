@@ -0,0 +1,48 @@
+package userbot
+
+import (
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/userBot/controller"
+	"go-fiber-starter/app/module/userBot/poller"
+	"go-fiber-starter/app/module/userBot/repository"
+	"go-fiber-starter/app/module/userBot/service"
+	"go-fiber-starter/utils/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes, each user links the own account
+	_i.App.Route("/v1/users/user/bale-bot", func(router fiber.Router) {
+		router.Get("/link", mdl.Protected(cfg), c.Link)
+		router.Delete("/", mdl.Protected(cfg), c.Unlink)
+	})
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+
+	fx.Invoke(poller.RunUpdatePoller),
+)
@@ -0,0 +1,54 @@
+package poller
+
+import (
+	"context"
+	"go-fiber-starter/app/module/userBot/service"
+	"go-fiber-starter/internal"
+	"go-fiber-starter/utils"
+
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+)
+
+type UpdatePoller struct {
+	Logger  zerolog.Logger
+	Service service.IService
+	Bot     *internal.UserBot
+}
+
+func RunUpdatePoller(
+	lc fx.Lifecycle,
+	logger zerolog.Logger,
+	userBotService service.IService,
+	bot *internal.UserBot,
+) *UpdatePoller {
+	poller := &UpdatePoller{
+		Logger:  logger,
+		Service: userBotService,
+		Bot:     bot,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			// like the crons, only one process gets the updates
+			if !bot.Connected || utils.IsChildProcess() {
+				return nil
+			}
+
+			go poller.Poll(ctx)
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+
+	return poller
+}
+
+// Poll gets the messages of the chats and replies to them until ctx is done.
+func (_p *UpdatePoller) Poll(ctx context.Context) {
+	_p.Bot.Poll(ctx, _p.Logger, _p.Service.Handle)
+}
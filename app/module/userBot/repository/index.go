@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/bootstrap/database"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+type IRepository interface {
+	GetUser(id uint64) (user *schema.User, err error)
+	Link(userID uint64, chatID int64) (err error)
+	Unlink(userID uint64) (err error)
+	UnlinkChat(chatID int64) (unlinked bool, err error)
+}
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+func (_i *repo) GetUser(id uint64) (user *schema.User, err error) {
+	err = _i.DB.Main.First(&user, id).Error
+	return
+}
+
+// Link sets the chat in the meta of the user, a chat linked to another account before moves to this one.
+func (_i *repo) Link(userID uint64, chatID int64) (err error) {
+	return _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		if err := unlinkChat(tx, chatID).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&schema.User{}).
+			Where("id = ?", userID).
+			Update("meta", gorm.Expr("jsonb_set(COALESCE(meta, '{}'::jsonb), '{BaleChatID}', to_jsonb(?::bigint))", chatID)).Error
+	})
+}
+
+func (_i *repo) Unlink(userID uint64) (err error) {
+	return _i.DB.Main.Model(&schema.User{}).
+		Where("id = ?", userID).
+		Update("meta", gorm.Expr("meta - 'BaleChatID'")).Error
+}
+
+func (_i *repo) UnlinkChat(chatID int64) (unlinked bool, err error) {
+	result := unlinkChat(_i.DB.Main, chatID)
+	return result.RowsAffected > 0, result.Error
+}
+
+func unlinkChat(tx *gorm.DB, chatID int64) *gorm.DB {
+	return tx.Model(&schema.User{}).
+		Where("meta->>'BaleChatID' = ?", strconv.FormatInt(chatID, 10)).
+		Update("meta", gorm.Expr("meta - 'BaleChatID'"))
+}
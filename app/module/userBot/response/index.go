@@ -0,0 +1,9 @@
+package response
+
+import "time"
+
+// Link opens the bot and links the chat to the user who asked for it
+type Link struct {
+	Link      string
+	ExpiresAt time.Time
+}
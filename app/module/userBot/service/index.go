@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/module/userBot/repository"
+	"go-fiber-starter/app/module/userBot/response"
+	"go-fiber-starter/internal"
+	"go-fiber-starter/utils/config"
+	"go-fiber-starter/utils/deeplink"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// the purpose of the link tokens, so the tokens of the operator bot are not taken for them
+const linkPurpose = "userBot"
+
+// how long a link works after it is given
+const linkLifetime = 10 * time.Minute
+
+const help = `با اتصال حساب کاربری به این گفتگو، تأیید رزروها، یادآوری‌ها و کدهای جبرانی به جای پیامک اینجا ارسال می‌شود.
+لینک اتصال را از بخش حساب کاربری در سایت بگیرید.
+
+/stop — قطع اتصال این گفتگو`
+
+var errChatNotLinked = &fiber.Error{Code: fiber.StatusNotFound, Message: "این گفتگو به حسابی متصل نیست."}
+
+type IService interface {
+	Link(userID uint64) (link *response.Link, err error)
+	Unlink(userID uint64) (err error)
+
+	Handle(chatID int64, text string) (reply string, err error)
+}
+
+func Service(repo repository.IRepository, bot *internal.UserBot, cfg *config.Config) IService {
+	return &service{
+		repo,
+		bot,
+		cfg,
+	}
+}
+
+type service struct {
+	Repo   repository.IRepository
+	Bot    *internal.UserBot
+	Config *config.Config
+}
+
+func (_i *service) Link(userID uint64) (link *response.Link, err error) {
+	if _i.Bot.Name == "" {
+		return nil, &fiber.Error{Code: fiber.StatusServiceUnavailable, Message: "ربات بله راه‌اندازی نشده است"}
+	}
+
+	expiresAt := time.Now().Add(linkLifetime)
+	token := deeplink.Token(_i.Config.Middleware.Jwt.Secret, linkPurpose, expiresAt, userID)
+
+	return &response.Link{Link: deeplink.Link(_i.Bot.Name, token), ExpiresAt: expiresAt}, nil
+}
+
+func (_i *service) Unlink(userID uint64) (err error) {
+	return _i.Repo.Unlink(userID)
+}
+
+// Handle links the chat to the account of the start token and unlinks it, the bot takes no other commands.
+func (_i *service) Handle(chatID int64, text string) (reply string, err error) {
+	command, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	command, _, _ = strings.Cut(command, "@")
+
+	switch strings.ToLower(command) {
+	case "/start":
+		if args = strings.TrimSpace(args); args != "" {
+			return _i.link(chatID, args)
+		}
+	case "/stop":
+		unlinked, err := _i.Repo.UnlinkChat(chatID)
+		if err != nil {
+			return "", err
+		}
+		if !unlinked {
+			return "", errChatNotLinked
+		}
+		return "اتصال این گفتگو قطع شد، پیام‌ها از این پس پیامک می‌شود.", nil
+	}
+
+	return help, nil
+}
+
+// link links the chat to the user of the token
+func (_i *service) link(chatID int64, token string) (reply string, err error) {
+	ids, err := deeplink.Parse(_i.Config.Middleware.Jwt.Secret, linkPurpose, token, time.Now())
+	if err != nil || len(ids) != 1 {
+		return "", &fiber.Error{Code: fiber.StatusBadRequest, Message: deeplink.ErrInvalidToken.Error()}
+	}
+
+	user, err := _i.Repo.GetUser(ids[0])
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", &fiber.Error{Code: fiber.StatusBadRequest, Message: deeplink.ErrInvalidToken.Error()}
+		}
+		return "", err
+	}
+
+	if err = _i.Repo.Link(user.ID, chatID); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("حساب %s به این گفتگو متصل شد، تأیید رزروها، یادآوری‌ها و کدهای جبرانی از این پس اینجا ارسال می‌شود.\n\n/stop — قطع اتصال این گفتگو", user.FullName()), nil
+}
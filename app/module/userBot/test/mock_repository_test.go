@@ -0,0 +1,88 @@
+package test
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// MockUserBotRepository keeps the users and their chats in memory
+type MockUserBotRepository struct {
+	mu    sync.Mutex
+	users map[uint64]*schema.User
+	chats map[uint64]int64
+}
+
+func NewMockUserBotRepository(users ...*schema.User) *MockUserBotRepository {
+	repo := &MockUserBotRepository{
+		users: map[uint64]*schema.User{},
+		chats: map[uint64]int64{},
+	}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+
+	return repo
+}
+
+// GetUser implements repository.IRepository
+func (_m *MockUserBotRepository) GetUser(id uint64) (*schema.User, error) {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	user, ok := _m.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return user, nil
+}
+
+// Link implements repository.IRepository
+func (_m *MockUserBotRepository) Link(userID uint64, chatID int64) error {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	_m.unlinkChat(chatID)
+	_m.chats[userID] = chatID
+
+	return nil
+}
+
+// Unlink implements repository.IRepository
+func (_m *MockUserBotRepository) Unlink(userID uint64) error {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	delete(_m.chats, userID)
+
+	return nil
+}
+
+// UnlinkChat implements repository.IRepository
+func (_m *MockUserBotRepository) UnlinkChat(chatID int64) (bool, error) {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	return _m.unlinkChat(chatID), nil
+}
+
+func (_m *MockUserBotRepository) unlinkChat(chatID int64) (unlinked bool) {
+	for userID, linked := range _m.chats {
+		if linked == chatID {
+			delete(_m.chats, userID)
+			unlinked = true
+		}
+	}
+
+	return unlinked
+}
+
+// ChatOf returns the chat linked to the user, 0 when none is
+func (_m *MockUserBotRepository) ChatOf(userID uint64) int64 {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+
+	return _m.chats[userID]
+}
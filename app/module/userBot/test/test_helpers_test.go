@@ -0,0 +1,69 @@
+package test
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/userBot/service"
+	"go-fiber-starter/internal"
+	"go-fiber-starter/utils/config"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TestBot is the service of the bot on the mock repository
+type TestBot struct {
+	Service service.IService
+	Repo    *MockUserBotRepository
+}
+
+func newTestBot(botName string, users ...*schema.User) *TestBot {
+	cfg := &config.Config{}
+	cfg.Middleware.Jwt.Secret = "test-secret"
+
+	tb := &TestBot{
+		Repo: NewMockUserBotRepository(users...),
+	}
+	tb.Service = service.Service(tb.Repo, &internal.UserBot{ChatBot: internal.ChatBot{Name: botName}}, cfg)
+
+	return tb
+}
+
+// ===== User Factory =====
+
+func newUser(id uint64) *schema.User {
+	return &schema.User{
+		ID:        id,
+		FirstName: "Student",
+		LastName:  "User",
+		Mobile:    9120000000 + id,
+	}
+}
+
+// ===== Helpers =====
+
+func startParam(t *testing.T, link string) string {
+	t.Helper()
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatalf("invalid link %q: %v", link, err)
+	}
+	return parsed.Query().Get("start")
+}
+
+// ===== Test Assertions =====
+
+// assertFiberError asserts that the bot refused the message with the status
+func assertFiberError(t *testing.T, err error, code int) {
+	t.Helper()
+
+	var fiberErr *fiber.Error
+	if !errors.As(err, &fiberErr) {
+		t.Fatalf("expected an error with status %d, got %v", code, err)
+	}
+	if fiberErr.Code != code {
+		t.Errorf("expected status %d, got %d (%s)", code, fiberErr.Code, fiberErr.Message)
+	}
+}
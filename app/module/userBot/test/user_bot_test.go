@@ -0,0 +1,95 @@
+package test
+
+import (
+	"go-fiber-starter/utils/deeplink"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandle_StartLinksTheAccount(t *testing.T) {
+	user := newUser(10)
+	tb := newTestBot("zciti_bot", user)
+
+	link, err := tb.Service.Link(user.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(link.Link, "https://ble.ir/zciti_bot?start=") {
+		t.Errorf("expected a link to the bot, got %s", link.Link)
+	}
+
+	reply, err := tb.Service.Handle(100, "/start "+startParam(t, link.Link))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(reply, user.FullName()) {
+		t.Errorf("expected the reply to name the linked account, got %q", reply)
+	}
+	if tb.Repo.ChatOf(user.ID) != 100 {
+		t.Errorf("expected the chat to be linked to the user")
+	}
+}
+
+func TestHandle_StartRejectsBadTokens(t *testing.T) {
+	user := newUser(10)
+	tb := newTestBot("zciti_bot", user)
+
+	expired := deeplink.Token("test-secret", "userBot", time.Now().Add(-time.Minute), user.ID)
+	forged := deeplink.Token("another-secret", "userBot", time.Now().Add(time.Minute), user.ID)
+	// the links of the operator bot are signed with the same secret
+	operator := deeplink.Token("test-secret", "operatorBot", time.Now().Add(time.Minute), user.ID, 1)
+	unknown := deeplink.Token("test-secret", "userBot", time.Now().Add(time.Minute), 99)
+	for _, token := range []string{expired, forged, operator, unknown, "abc"} {
+		_, err := tb.Service.Handle(100, "/start "+token)
+		assertFiberError(t, err, http.StatusBadRequest)
+	}
+
+	if tb.Repo.ChatOf(user.ID) != 0 {
+		t.Errorf("expected no chat to be linked")
+	}
+}
+
+func TestHandle_StopUnlinksTheChat(t *testing.T) {
+	user := newUser(10)
+	other := newUser(11)
+	tb := newTestBot("zciti_bot", user, other)
+
+	for _, u := range []uint64{user.ID, other.ID} {
+		link, err := tb.Service.Link(u)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err = tb.Service.Handle(100, "/start@zciti_bot "+startParam(t, link.Link)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// a chat is of one account only, the last one linked
+	if tb.Repo.ChatOf(user.ID) != 0 || tb.Repo.ChatOf(other.ID) != 100 {
+		t.Fatalf("expected the chat to move to the other account")
+	}
+
+	if _, err := tb.Service.Handle(100, "/stop"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tb.Repo.ChatOf(other.ID) != 0 {
+		t.Errorf("expected the chat to be unlinked")
+	}
+
+	_, err := tb.Service.Handle(100, "/stop")
+	assertFiberError(t, err, http.StatusNotFound)
+}
+
+func TestLink_NeedsTheBot(t *testing.T) {
+	user := newUser(10)
+	tb := newTestBot("", user)
+
+	_, err := tb.Service.Link(user.ID)
+	assertFiberError(t, err, http.StatusServiceUnavailable)
+
+	reply, err := tb.Service.Handle(100, "/start")
+	if err != nil || !strings.Contains(reply, "/stop") {
+		t.Errorf("expected the help without a token, got %q, %v", reply, err)
+	}
+}
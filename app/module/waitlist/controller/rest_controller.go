@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/waitlist/request"
+	"go-fiber-starter/app/module/waitlist/service"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/response"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type IRestController interface {
+	Index(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+	MyWaitlists(c *fiber.Ctx) error
+	Store(c *fiber.Ctx) error
+	Cancel(c *fiber.Ctx) error
+}
+
+func RestController(s service.IService) IRestController {
+	return &controller{s}
+}
+
+type controller struct {
+	service service.IService
+}
+
+// Index all Waitlists
+// @Summary      Get all waitlist entries of the business
+// @Tags         Waitlists
+// @Security     Bearer
+// @Param        businessID path int true "Business ID"
+// @Param        ProductID query int false "Product ID"
+// @Param        TaxonomyID query int false "Taxonomy ID"
+// @Param        Status query string false "Status"
+// @Router       /business/:businessID/waitlists [get]
+func (_i *controller) Index(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.Waitlists
+	req.BusinessID = businessID
+	req.Pagination = paginate
+	req.ProductID, _ = utils.GetUintInQueries(c, "ProductID")
+	req.TaxonomyID, _ = utils.GetUintInQueries(c, "TaxonomyID")
+	req.Status = schema.WaitlistStatus(c.Query("Status"))
+
+	waitlists, paging, err := _i.service.Index(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: waitlists,
+		Meta: paging,
+	})
+}
+
+// Delete waitlist entry
+// @Summary      Cancel a waitlist entry of the business
+// @Tags         Waitlists
+// @Security     Bearer
+// @Param        id path int true "Waitlist ID"
+// @Param        businessID path int true "Business ID"
+// @Router       /business/:businessID/waitlists/:id [delete]
+func (_i *controller) Delete(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.Cancel(id, businessID, 0); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
+
+// MyWaitlists
+// @Summary      Get waitlist entries of the authenticated user
+// @Tags         Waitlists
+// @Security     Bearer
+// @Param        Status query string false "Status"
+// @Router       /user/waitlists [get]
+func (_i *controller) MyWaitlists(c *fiber.Ctx) error {
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+	paginate, err := paginator.Paginate(c)
+	if err != nil {
+		return err
+	}
+
+	var req request.Waitlists
+	req.UserID = user.ID
+	req.Pagination = paginate
+	req.Status = schema.WaitlistStatus(c.Query("Status"))
+
+	waitlists, paging, err := _i.service.Index(req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: waitlists,
+		Meta: paging,
+	})
+}
+
+// Store waitlist entry
+// @Summary      Join the waitlist of a fully booked slot
+// @Tags         Waitlists
+// @Security     Bearer
+// @Param 		 waitlist body request.Waitlist true "Waitlist details"
+// @Param        businessID path int true "Business ID"
+// @Router       /user/business/:businessID/waitlists [post]
+func (_i *controller) Store(c *fiber.Ctx) error {
+	businessID, err := utils.GetIntInParams(c, "businessID")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	req := new(request.Waitlist)
+	if err := response.ParseAndValidate(c, req); err != nil {
+		return err
+	}
+
+	req.BusinessID = businessID
+	req.UserID = user.ID
+	waitlist, err := _i.service.Join(*req)
+	if err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Data: waitlist,
+	})
+}
+
+// Cancel waitlist entry
+// @Summary      Leave the waitlist
+// @Tags         Waitlists
+// @Security     Bearer
+// @Param        id path int true "Waitlist ID"
+// @Router       /user/waitlists/:id [delete]
+func (_i *controller) Cancel(c *fiber.Ctx) error {
+	id, err := utils.GetIntInParams(c, "id")
+	if err != nil {
+		return err
+	}
+	user, err := utils.GetAuthenticatedUser(c)
+	if err != nil {
+		return err
+	}
+
+	if err = _i.service.Cancel(id, 0, user.ID); err != nil {
+		return err
+	}
+
+	return response.Resp(c, response.Response{
+		Messages: response.Messages{"success"},
+	})
+}
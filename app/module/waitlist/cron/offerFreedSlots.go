@@ -0,0 +1,40 @@
+package cron
+
+import (
+	"go-fiber-starter/app/module/waitlist/service"
+	"go-fiber-starter/internal"
+
+	"github.com/rs/zerolog"
+)
+
+type WaitlistOfferService struct {
+	CronSpec string
+	Logger   zerolog.Logger
+	Service  service.IService
+}
+
+func RunWaitlistOffers(
+	logger zerolog.Logger,
+	waitlistService service.IService,
+	cronService *internal.CronService,
+) *WaitlistOfferService {
+	s := &WaitlistOfferService{
+		Logger:   logger,
+		Service:  waitlistService,
+		CronSpec: "@every 1m",
+	}
+
+	err := cronService.AddJob(s.CronSpec, s.OfferFreedSlots)
+	if err != nil {
+		s.Logger.Fatal().Err(err).Msg("failed to add RunWaitlistOffers job")
+	}
+
+	return s
+}
+
+// OfferFreedSlots hands the freed machines to the waiting users
+func (_s *WaitlistOfferService) OfferFreedSlots() {
+	if err := _s.Service.ProcessWaitlist(); err != nil {
+		_s.Logger.Err(err).Msg("Failed to process waitlist")
+	}
+}
@@ -0,0 +1,57 @@
+package waitlist
+
+import (
+	mdl "go-fiber-starter/app/middleware"
+	"go-fiber-starter/app/module/waitlist/controller"
+	"go-fiber-starter/app/module/waitlist/cron"
+	"go-fiber-starter/app/module/waitlist/repository"
+	"go-fiber-starter/app/module/waitlist/service"
+	"go-fiber-starter/utils/config"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/fx"
+)
+
+type Router struct {
+	App        fiber.Router
+	Controller *controller.Controller
+}
+
+func (_i *Router) RegisterRoutes(cfg *config.Config) {
+	// define controllers
+	c := _i.Controller.RestController
+
+	// define routes
+	_i.App.Route("/v1/business/:businessID/waitlists", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PReadAll), c.Index)
+		router.Delete("/:id", mdl.Protected(cfg), mdl.BusinessPermission(mdl.DReservation, mdl.PDelete), c.Delete)
+	})
+
+	_i.App.Route("/v1/user/business/:businessID/waitlists", func(router fiber.Router) {
+		router.Post("/", mdl.Protected(cfg), mdl.ForUser, c.Store)
+	})
+
+	_i.App.Route("/v1/user/waitlists", func(router fiber.Router) {
+		router.Get("/", mdl.Protected(cfg), mdl.ForUser, c.MyWaitlists)
+		router.Delete("/:id", mdl.Protected(cfg), mdl.ForUser, c.Cancel)
+	})
+}
+
+func newRouter(fiber *fiber.App, controller *controller.Controller) *Router {
+	return &Router{
+		App:        fiber,
+		Controller: controller,
+	}
+}
+
+var Module = fx.Options(
+	fx.Provide(repository.Repository),
+
+	fx.Provide(service.Service),
+
+	fx.Provide(controller.Controllers),
+
+	fx.Provide(newRouter),
+
+	fx.Invoke(cron.RunWaitlistOffers),
+)
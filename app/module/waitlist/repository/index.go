@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/module/waitlist/request"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/paginator"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type IRepository interface {
+	GetAll(req request.Waitlists) (waitlists []*schema.Waitlist, paging paginator.Pagination, err error)
+	GetOne(id uint64) (waitlist *schema.Waitlist, err error)
+	Create(waitlist *schema.Waitlist) (err error)
+	ExistsActive(waitlist *schema.Waitlist) (exists bool, err error)
+	Position(waitlist *schema.Waitlist) (position int64, err error)
+	GetWaiting(now time.Time) (waitlists []*schema.Waitlist, err error)
+	GetActiveOffers(productIDs []uint64, start time.Time, end time.Time, now time.Time) (waitlists []*schema.Waitlist, err error)
+	Offer(id uint64, productID uint64, expiresAt time.Time) (offered bool, err error)
+	Fulfill(reservationID uint64) (err error)
+	SetStatus(id uint64, status schema.WaitlistStatus) (err error)
+	ExpireOffers(now time.Time) (err error)
+	CleanupPassed(now time.Time) (err error)
+}
+
+func Repository(DB *database.Database) IRepository {
+	return &repo{
+		DB,
+	}
+}
+
+type repo struct {
+	DB *database.Database
+}
+
+var activeStatuses = []schema.WaitlistStatus{schema.WaitlistStatusWaiting, schema.WaitlistStatusOffered}
+
+func (_i *repo) GetAll(req request.Waitlists) (waitlists []*schema.Waitlist, paging paginator.Pagination, err error) {
+	query := _i.DB.Main.Model(&schema.Waitlist{})
+
+	if req.BusinessID != 0 {
+		query.Where(&schema.Waitlist{BusinessID: req.BusinessID})
+	}
+
+	if req.UserID != 0 {
+		query.Where(&schema.Waitlist{UserID: req.UserID})
+	}
+
+	if req.ProductID != 0 {
+		query.Where("product_id = ? OR offered_product_id = ?", req.ProductID, req.ProductID)
+	}
+
+	if req.TaxonomyID != 0 {
+		query.Where("taxonomy_id = ?", req.TaxonomyID)
+	}
+
+	if req.Status != "" {
+		query.Where(&schema.Waitlist{Status: req.Status})
+	}
+
+	if req.Pagination != nil && req.Pagination.Page > 0 {
+		var total int64
+		query.Count(&total)
+		req.Pagination.Total = total
+
+		query.Offset(req.Pagination.Offset)
+		query.Limit(req.Pagination.Limit)
+	}
+
+	err = query.
+		Preload("User").
+		Preload("Taxonomy").
+		Preload("Product.Post").
+		Preload("OfferedProduct").
+		Order("start_time asc, id asc").
+		Find(&waitlists).Error
+	if err != nil {
+		return
+	}
+
+	if req.Pagination != nil {
+		paging = *req.Pagination
+	}
+
+	return
+}
+
+func (_i *repo) GetOne(id uint64) (waitlist *schema.Waitlist, err error) {
+	var item schema.Waitlist
+	if err = _i.DB.Main.First(&item, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (_i *repo) Create(waitlist *schema.Waitlist) (err error) {
+	return _i.DB.Main.Create(waitlist).Error
+}
+
+// ExistsActive reports whether the user is already waiting for the same slot.
+func (_i *repo) ExistsActive(waitlist *schema.Waitlist) (exists bool, err error) {
+	var count int64
+	err = _i.sameSlot(waitlist).
+		Where(&schema.Waitlist{UserID: waitlist.UserID}).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// Position is the 1 based place of the entry among the waiting entries of the same slot.
+func (_i *repo) Position(waitlist *schema.Waitlist) (position int64, err error) {
+	err = _i.sameSlot(waitlist).
+		Where("status = ?", schema.WaitlistStatusWaiting).
+		Where("id <= ?", waitlist.ID).
+		Count(&position).Error
+
+	return
+}
+
+func (_i *repo) sameSlot(waitlist *schema.Waitlist) *gorm.DB {
+	query := _i.DB.Main.Model(&schema.Waitlist{}).
+		Where("status IN (?)", activeStatuses).
+		Where("start_time = ? AND end_time = ?", waitlist.StartTime, waitlist.EndTime)
+
+	if waitlist.ProductID != nil {
+		query.Where("product_id = ?", *waitlist.ProductID)
+	} else {
+		query.Where("taxonomy_id = ?", waitlist.TaxonomyID)
+	}
+
+	return query
+}
+
+// GetWaiting returns the upcoming waiting entries, first come first served.
+func (_i *repo) GetWaiting(now time.Time) (waitlists []*schema.Waitlist, err error) {
+	err = _i.DB.Main.
+		Preload("User").
+		Where("status = ?", schema.WaitlistStatusWaiting).
+		Where("start_time > ?", now).
+		Order("id asc").
+		Find(&waitlists).Error
+
+	return
+}
+
+func (_i *repo) GetActiveOffers(productIDs []uint64, start time.Time, end time.Time, now time.Time) (waitlists []*schema.Waitlist, err error) {
+	err = _i.DB.Main.
+		Where("status = ?", schema.WaitlistStatusOffered).
+		Where("offered_product_id IN (?)", productIDs).
+		Where("offer_expires_at > ?", now).
+		Where("start_time < ? AND end_time > ?", end, start).
+		Find(&waitlists).Error
+
+	return
+}
+
+// Offer holds the machine for a waiting entry. It only succeeds once per entry.
+func (_i *repo) Offer(id uint64, productID uint64, expiresAt time.Time) (offered bool, err error) {
+	result := _i.DB.Main.Model(&schema.Waitlist{}).
+		Where("id = ? AND status = ?", id, schema.WaitlistStatusWaiting).
+		Updates(map[string]any{
+			"status":             schema.WaitlistStatusOffered,
+			"offered_product_id": productID,
+			"offer_expires_at":   expiresAt,
+		})
+
+	return result.RowsAffected > 0, result.Error
+}
+
+// Fulfill closes the active entries of the user for the slot once the user reserved it.
+// Fulfill closes the active entries of the user of the reservation for its slot.
+func (_i *repo) Fulfill(reservationID uint64) (err error) {
+	reservation := _i.DB.Main.Model(&schema.Reservation{}).
+		Select("user_id", "start_time", "end_time").
+		Where("id = ?", reservationID)
+
+	return _i.DB.Main.Model(&schema.Waitlist{}).
+		Where("status IN (?)", activeStatuses).
+		Where("(user_id, start_time, end_time) IN (?)", reservation).
+		Update("status", schema.WaitlistStatusFulfilled).Error
+}
+
+func (_i *repo) SetStatus(id uint64, status schema.WaitlistStatus) (err error) {
+	return _i.DB.Main.Model(&schema.Waitlist{}).
+		Where("id = ?", id).
+		Update("status", status).Error
+}
+
+func (_i *repo) ExpireOffers(now time.Time) (err error) {
+	return _i.DB.Main.Model(&schema.Waitlist{}).
+		Where("status = ? AND offer_expires_at <= ?", schema.WaitlistStatusOffered, now).
+		Update("status", schema.WaitlistStatusExpired).Error
+}
+
+// CleanupPassed expires and removes the entries whose slot has already started.
+func (_i *repo) CleanupPassed(now time.Time) (err error) {
+	return _i.DB.Main.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&schema.Waitlist{}).
+			Where("status IN (?) AND start_time <= ?", activeStatuses, now).
+			Update("status", schema.WaitlistStatusExpired).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("start_time <= ?", now).Delete(&schema.Waitlist{}).Error
+	})
+}
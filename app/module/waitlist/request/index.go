@@ -0,0 +1,57 @@
+package request
+
+import (
+	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+)
+
+type Waitlists struct {
+	BusinessID uint64
+	UserID     uint64
+	ProductID  uint64
+	TaxonomyID uint64
+	Status     schema.WaitlistStatus
+	Pagination *paginator.Pagination
+}
+
+type Waitlist struct {
+	BusinessID uint64
+	UserID     uint64
+	ProductID  uint64 `example:"1" validate:"required_without=TaxonomyID"` // a specific machine
+	TaxonomyID uint64 `example:"1" validate:"required_without=ProductID"`  // any machine of the dormitory
+	Date       string `example:"2024-03-20" validate:"required,datetime=2006-01-02"`
+	StartTime  string `example:"10:00:00" validate:"required"`
+	EndTime    string `example:"11:00:00" validate:"required"`
+}
+
+// Slot returns the requested slot in the shape the reservation helpers expect.
+func (req *Waitlist) Slot() oirequest.OrderItem {
+	return oirequest.OrderItem{
+		ProductID: req.ProductID,
+		Date:      req.Date,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+	}
+}
+
+func (req *Waitlist) ToDomain() *schema.Waitlist {
+	slot := req.Slot()
+	loc := timezone.In(req.BusinessID)
+	item := &schema.Waitlist{
+		UserID:     req.UserID,
+		BusinessID: req.BusinessID,
+		StartTime:  slot.GetStartDateTime(loc),
+		EndTime:    slot.GetEndDateTime(loc),
+		Status:     schema.WaitlistStatusWaiting,
+	}
+
+	if req.ProductID != 0 {
+		item.ProductID = &req.ProductID
+	} else {
+		item.TaxonomyID = &req.TaxonomyID
+	}
+
+	return item
+}
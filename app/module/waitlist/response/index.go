@@ -0,0 +1,66 @@
+package response
+
+import (
+	"go-fiber-starter/app/database/schema"
+	"time"
+)
+
+type Waitlist struct {
+	ID                uint64                `json:",omitempty"`
+	UserID            uint64                `json:",omitempty"`
+	UserName          string                `json:",omitempty"`
+	UserMobile        uint64                `json:",omitempty"`
+	BusinessID        uint64                `json:",omitempty"`
+	ProductID         *uint64               `json:",omitempty"`
+	ProductSKU        string                `json:",omitempty"`
+	PostTitle         string                `json:",omitempty"`
+	TaxonomyID        *uint64               `json:",omitempty"`
+	TaxonomyTitle     string                `json:",omitempty"`
+	StartTime         time.Time             `json:",omitempty"`
+	EndTime           time.Time             `json:",omitempty"`
+	Status            schema.WaitlistStatus `json:",omitempty"`
+	StatusDisplay     string                `json:",omitempty"`
+	Position          int64                 `json:",omitempty"` // 1 based position in the queue of the slot, only while waiting
+	OfferedProductID  *uint64               `json:",omitempty"`
+	OfferedProductSKU string                `json:",omitempty"`
+	OfferExpiresAt    *time.Time            `json:",omitempty"`
+	CreatedAt         time.Time             `json:",omitempty"`
+}
+
+func FromDomain(item *schema.Waitlist) (res *Waitlist) {
+	if item == nil {
+		return nil
+	}
+
+	res = &Waitlist{
+		ID:               item.ID,
+		UserID:           item.UserID,
+		BusinessID:       item.BusinessID,
+		ProductID:        item.ProductID,
+		TaxonomyID:       item.TaxonomyID,
+		StartTime:        item.StartTime,
+		EndTime:          item.EndTime,
+		Status:           item.Status,
+		StatusDisplay:    schema.WaitlistStatusProxy[item.Status],
+		OfferedProductID: item.OfferedProductID,
+		OfferExpiresAt:   item.OfferExpiresAt,
+		CreatedAt:        item.CreatedAt,
+	}
+
+	if item.User.ID != 0 {
+		res.UserName = item.User.FullName()
+		res.UserMobile = item.User.Mobile
+	}
+	if item.Product != nil {
+		res.ProductSKU = item.Product.Meta.SKU
+		res.PostTitle = item.Product.Post.Title
+	}
+	if item.Taxonomy != nil {
+		res.TaxonomyTitle = item.Taxonomy.Title
+	}
+	if item.OfferedProduct != nil {
+		res.OfferedProductSKU = item.OfferedProduct.Meta.SKU
+	}
+
+	return res
+}
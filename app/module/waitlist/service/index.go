@@ -0,0 +1,288 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	prepository "go-fiber-starter/app/module/product/repository"
+	prequest "go-fiber-starter/app/module/product/request"
+	rrepository "go-fiber-starter/app/module/reservation/repository"
+	"go-fiber-starter/app/module/waitlist/repository"
+	"go-fiber-starter/app/module/waitlist/request"
+	"go-fiber-starter/app/module/waitlist/response"
+	"go-fiber-starter/utils/paginator"
+	"go-fiber-starter/utils/timezone"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	ptime "github.com/yaa110/go-persian-calendar"
+)
+
+// OfferHoldDuration is how long a freed machine stays exclusively held for the first waiting user.
+const OfferHoldDuration = 10 * time.Minute
+
+type IService interface {
+	Index(req request.Waitlists) (waitlists []*response.Waitlist, paging paginator.Pagination, err error)
+	Join(req request.Waitlist) (waitlist *response.Waitlist, err error)
+	Cancel(id uint64, businessID uint64, userID uint64) error
+	CheckHold(item oirequest.OrderItem, userID uint64, businessID uint64) error
+	Fulfill(reservationID uint64) error
+	ProcessWaitlist() error
+}
+
+func Service(
+	repo repository.IRepository,
+	productRepo prepository.IRepository,
+	reservationRepo rrepository.IRepository,
+	outboxService outboxService.IService,
+) IService {
+	return &service{
+		repo,
+		productRepo,
+		reservationRepo,
+		outboxService,
+	}
+}
+
+type service struct {
+	Repo            repository.IRepository
+	ProductRepo     prepository.IRepository
+	ReservationRepo rrepository.IRepository
+	Outbox          outboxService.IService
+}
+
+func (_i *service) Index(req request.Waitlists) (waitlists []*response.Waitlist, paging paginator.Pagination, err error) {
+	results, paging, err := _i.Repo.GetAll(req)
+	if err != nil {
+		return
+	}
+
+	for _, result := range results {
+		waitlist := response.FromDomain(result)
+		if result.Status == schema.WaitlistStatusWaiting {
+			if waitlist.Position, err = _i.Repo.Position(result); err != nil {
+				return
+			}
+		}
+		waitlists = append(waitlists, waitlist)
+	}
+
+	return
+}
+
+func (_i *service) Join(req request.Waitlist) (waitlist *response.Waitlist, err error) {
+	item := req.ToDomain()
+	if item.StartTime.IsZero() || !item.StartTime.After(time.Now()) {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "در این بازه زمانی شما اجازه رزرو ندارید"}
+	}
+
+	candidates, err := _i.candidates(item)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "در این بازه زمانی شما اجازه رزرو ندارید"}
+	}
+
+	free, err := _i.freeProduct(candidates, item.StartTime, item.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if free != nil {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "این ساعت هنوز دستگاه خالی دارد، لطفا مستقیما رزرو کنید"}
+	}
+
+	exists, err := _i.Repo.ExistsActive(item)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, &fiber.Error{Code: fiber.StatusBadRequest, Message: "شما قبلا در صف انتظار این ساعت ثبت شده اید"}
+	}
+
+	if err = _i.Repo.Create(item); err != nil {
+		return nil, err
+	}
+
+	waitlist = response.FromDomain(item)
+	waitlist.Position, err = _i.Repo.Position(item)
+
+	return waitlist, err
+}
+
+// Cancel closes an active entry. businessID and userID limit the entries
+// that could be canceled when they are not zero.
+func (_i *service) Cancel(id uint64, businessID uint64, userID uint64) error {
+	item, err := _i.Repo.GetOne(id)
+	if err != nil ||
+		(businessID != 0 && item.BusinessID != businessID) ||
+		(userID != 0 && item.UserID != userID) {
+		return &fiber.Error{Code: fiber.StatusNotFound, Message: "درخواست صف انتظار یافت نشد"}
+	}
+
+	if item.Status != schema.WaitlistStatusWaiting && item.Status != schema.WaitlistStatusOffered {
+		return &fiber.Error{Code: fiber.StatusBadRequest, Message: "این درخواست قبلا بسته شده است"}
+	}
+
+	return _i.Repo.SetStatus(id, schema.WaitlistStatusCanceled)
+}
+
+// CheckHold rejects reserving a machine that is held for another waiting user.
+func (_i *service) CheckHold(item oirequest.OrderItem, userID uint64, businessID uint64) error {
+	loc := timezone.In(businessID)
+	offers, err := _i.Repo.GetActiveOffers([]uint64{item.ProductID}, item.GetStartDateTime(loc), item.GetEndDateTime(loc), time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, offer := range offers {
+		if offer.UserID != userID {
+			return &fiber.Error{Code: fiber.StatusBadRequest, Message: "این ساعت دستگاه برای نفر بعدی صف انتظار نگه داشته شده است"}
+		}
+	}
+
+	return nil
+}
+
+// Fulfill closes the waitlist entries of the user for the slot of the paid reservation.
+func (_i *service) Fulfill(reservationID uint64) error {
+	return _i.Repo.Fulfill(reservationID)
+}
+
+// ProcessWaitlist expires the passed entries and the unused offers, then offers
+// every machine that became free (canceled reservation, expired payment hold or
+// expired offer) to the first waiting user of its slot.
+func (_i *service) ProcessWaitlist() error {
+	now := time.Now()
+	if err := _i.Repo.ExpireOffers(now); err != nil {
+		return err
+	}
+	if err := _i.Repo.CleanupPassed(now); err != nil {
+		return err
+	}
+
+	waiting, err := _i.Repo.GetWaiting(now)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, item := range waiting {
+		candidates, err := _i.candidates(item)
+		if err != nil {
+			return err
+		}
+
+		free, err := _i.freeProduct(candidates, item.StartTime, item.EndTime)
+		if err != nil {
+			return err
+		}
+		if free == nil {
+			continue
+		}
+
+		expiresAt := now.Add(OfferHoldDuration)
+		offered, err := _i.Repo.Offer(item.ID, free.ID, expiresAt)
+		if err != nil {
+			return err
+		}
+		if !offered {
+			continue
+		}
+
+		if err := _i.sendOffer(item, free, expiresAt); err != nil {
+			errs = append(errs, fmt.Errorf("waitlist %d: %w", item.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// candidates are the machines that are ON and have the slot of the entry in their schedule.
+func (_i *service) candidates(item *schema.Waitlist) (products []*schema.Product, err error) {
+	req := prequest.ReservableVariants{BusinessID: item.BusinessID}
+	if item.ProductID != nil {
+		req.ProductID = *item.ProductID
+	} else if item.TaxonomyID != nil {
+		req.TaxonomyID = *item.TaxonomyID
+	}
+
+	variants, err := _i.ProductRepo.GetReservableVariants(req)
+	if err != nil {
+		return nil, err
+	}
+
+	day := item.StartTime.In(timezone.In(item.BusinessID))
+	for _, variant := range variants {
+		if variant.Meta.UniWashMachineStatus == schema.UniWashMachineStatusOFF ||
+			variant.Meta.IsBlackoutDate(day.Format(time.DateOnly)) {
+			continue
+		}
+
+		for _, hour := range variant.Meta.GetReservationOptions()[day.Weekday()] {
+			start, end := hour.TimesOn(day)
+			if start.Equal(item.StartTime) && end.Equal(item.EndTime) {
+				products = append(products, variant)
+				break
+			}
+		}
+	}
+
+	return products, nil
+}
+
+// freeProduct returns the first machine without a reservation, payment hold or waitlist offer in the range.
+func (_i *service) freeProduct(products []*schema.Product, start time.Time, end time.Time) (*schema.Product, error) {
+	if len(products) == 0 {
+		return nil, nil
+	}
+
+	productIDs := make([]uint64, 0, len(products))
+	for _, product := range products {
+		productIDs = append(productIDs, product.ID)
+	}
+
+	reservations, err := _i.ReservationRepo.GetActiveInRange(productIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := _i.Repo.GetActiveOffers(productIDs, start, end, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	taken := map[uint64]bool{}
+	for _, reservation := range reservations {
+		taken[reservation.ProductID] = true
+	}
+	for _, offer := range offers {
+		taken[*offer.OfferedProductID] = true
+	}
+
+	for _, product := range products {
+		if !taken[product.ID] {
+			return product, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (_i *service) sendOffer(item *schema.Waitlist, product *schema.Product, expiresAt time.Time) error {
+	loc := timezone.In(item.BusinessID)
+
+	message := schema.NewSms(schema.OutboxPurposeWaitlist, item.User.Mobile, schema.SmsWaitlistOffer,
+		item.User.FullName(),
+		product.Meta.SKU,
+		ptime.New(item.StartTime.In(loc)).Format("yyyy/MM/dd HH:mm"),
+		ptime.New(expiresAt.In(loc)).Format("HH:mm"),
+	)
+	message.BusinessID = &item.BusinessID
+	// the offer is only held for a few minutes
+	message.Priority = schema.OutboxPriorityHigh
+
+	return _i.Outbox.Enqueue(nil, message)
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"testing"
+
+	"go-fiber-starter/app/database/schema"
+	outboxRepo "go-fiber-starter/app/module/outbox/repository"
+	outboxService "go-fiber-starter/app/module/outbox/service"
+	productRepo "go-fiber-starter/app/module/product/repository"
+	reservationRepo "go-fiber-starter/app/module/reservation/repository"
+	"go-fiber-starter/app/module/waitlist"
+	"go-fiber-starter/app/module/waitlist/controller"
+	"go-fiber-starter/app/module/waitlist/repository"
+	"go-fiber-starter/app/module/waitlist/service"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/internal/testapp"
+)
+
+// TestApp holds the waitlist service on the test app
+type TestApp struct {
+	*testapp.Fixture
+	WaitlistService service.IService
+}
+
+// SetupTestApp initializes the test application with a test database
+func SetupTestApp(t *testing.T) *TestApp {
+	t.Helper()
+
+	f := testapp.New(t, "users", "businesses", "business_users", "posts", "products", "reservations", "taxonomies",
+		"posts_taxonomies", "waitlists", "outbox_messages")
+
+	// the fake sms provider of the test config, the offers are queued in the outbox
+	smsSvc := sms.NewService(f.Config, f.Logger, nil, f.Database)
+
+	waitlistSvc := service.Service(
+		repository.Repository(f.Database),
+		productRepo.Repository(f.Database),
+		reservationRepo.Repository(f.Database),
+		outboxService.Service(outboxRepo.Repository(f.Database), smsSvc, f.Config),
+	)
+
+	waitlistRouter := &waitlist.Router{
+		App:        f.App,
+		Controller: controller.Controllers(waitlistSvc),
+	}
+	waitlistRouter.RegisterRoutes(f.Config)
+
+	return &TestApp{
+		Fixture:         f,
+		WaitlistService: waitlistSvc,
+	}
+}
+
+// GetWaitlist loads the entry including removed ones
+func (ta *TestApp) GetWaitlist(t *testing.T, id uint64) *schema.Waitlist {
+	t.Helper()
+
+	var item schema.Waitlist
+	if err := ta.DB.Unscoped().First(&item, id).Error; err != nil {
+		t.Fatalf("failed to load waitlist: %v", err)
+	}
+
+	return &item
+}
@@ -0,0 +1,247 @@
+package test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	oirequest "go-fiber-starter/app/module/orderItem/request"
+	"go-fiber-starter/internal/testapp"
+)
+
+type waitlistFixture struct {
+	owner    *schema.User
+	business *schema.Business
+	machine  *schema.Product
+	slot     testapp.Slot
+}
+
+// newFullyBookedMachine creates a machine whose slot of tomorrow 10:00 is reserved by the owner
+func newFullyBookedMachine(t *testing.T, ta *TestApp) *waitlistFixture {
+	t.Helper()
+
+	owner := ta.CreateTestUser(t, 9120000000, "Test", "User")
+	business := ta.CreateTestBusiness(t, owner, "Test Business")
+	machine := ta.CreateTestMachine(t, business.ID, owner.ID, "M-1")
+	slot := testapp.TomorrowSlot(10)
+	ta.CreateTestReservation(t, owner.ID, machine, slot)
+
+	return &waitlistFixture{owner: owner, business: business, machine: machine, slot: slot}
+}
+
+func (f *waitlistFixture) join(t *testing.T, ta *TestApp, user *schema.User, body map[string]any) *http.Response {
+	t.Helper()
+
+	if body == nil {
+		body = map[string]any{"ProductID": f.machine.ID}
+	}
+	body["Date"] = f.slot.Date
+	body["StartTime"] = f.slot.StartTime
+	body["EndTime"] = f.slot.EndTime
+
+	return ta.MakeRequest(t, http.MethodPost, fmt.Sprintf("/v1/user/business/%d/waitlists", f.business.ID), body, ta.GenerateTestToken(t, user))
+}
+
+func (f *waitlistFixture) orderItem() oirequest.OrderItem {
+	return oirequest.OrderItem{ProductID: f.machine.ID, Date: f.slot.Date, StartTime: f.slot.StartTime, EndTime: f.slot.EndTime}
+}
+
+func joinedID(t *testing.T, resp *http.Response) uint64 {
+	t.Helper()
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	return uint64(testapp.ParseResponse(t, resp)["Data"].(map[string]any)["ID"].(float64))
+}
+
+func TestJoin_FreeSlotRejected(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := newFullyBookedMachine(t, ta)
+	f.slot = testapp.TomorrowSlot(12)
+	user := ta.CreateTestUser(t, 9120000001, "Test", "User")
+
+	testapp.AssertStatus(t, f.join(t, ta, user, nil), http.StatusBadRequest)
+}
+
+func TestJoin_QueuePositionsAndDuplicate(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := newFullyBookedMachine(t, ta)
+	first := ta.CreateTestUser(t, 9120000001, "Test", "User")
+	second := ta.CreateTestUser(t, 9120000002, "Test", "User")
+
+	resp := f.join(t, ta, first, nil)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if position := testapp.ParseResponse(t, resp)["Data"].(map[string]any)["Position"]; position != float64(1) {
+		t.Errorf("expected position 1, got %v", position)
+	}
+
+	resp = f.join(t, ta, second, nil)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if position := testapp.ParseResponse(t, resp)["Data"].(map[string]any)["Position"]; position != float64(2) {
+		t.Errorf("expected position 2, got %v", position)
+	}
+
+	testapp.AssertStatus(t, f.join(t, ta, first, nil), http.StatusBadRequest)
+}
+
+func TestProcessWaitlist_OffersFreedSlotToFirstUser(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := newFullyBookedMachine(t, ta)
+	first := ta.CreateTestUser(t, 9120000001, "Test", "User")
+	second := ta.CreateTestUser(t, 9120000002, "Test", "User")
+	firstID := joinedID(t, f.join(t, ta, first, nil))
+	secondID := joinedID(t, f.join(t, ta, second, nil))
+
+	// nothing is freed yet
+	if err := ta.WaitlistService.ProcessWaitlist(); err != nil {
+		t.Fatalf("process waitlist: %v", err)
+	}
+	if status := ta.GetWaitlist(t, firstID).Status; status != schema.WaitlistStatusWaiting {
+		t.Fatalf("expected waiting, got %s", status)
+	}
+
+	// the reservation is canceled
+	ta.DB.Exec("DELETE FROM reservations")
+	if err := ta.WaitlistService.ProcessWaitlist(); err != nil {
+		t.Fatalf("process waitlist: %v", err)
+	}
+
+	offered := ta.GetWaitlist(t, firstID)
+	if offered.Status != schema.WaitlistStatusOffered || offered.OfferedProductID == nil || *offered.OfferedProductID != f.machine.ID {
+		t.Fatalf("expected the machine to be offered to the first user, got %+v", offered)
+	}
+	if status := ta.GetWaitlist(t, secondID).Status; status != schema.WaitlistStatusWaiting {
+		t.Errorf("expected the second user to keep waiting, got %s", status)
+	}
+
+	var offers []schema.OutboxMessage
+	ta.DB.Where("purpose = ?", schema.OutboxPurposeWaitlist).Find(&offers)
+	if len(offers) != 1 || offers[0].Mobile != "09120000001" || offers[0].Priority != schema.OutboxPriorityHigh {
+		t.Errorf("expected the offer sms to be queued for the first user, got %+v", offers)
+	}
+
+	if err := ta.WaitlistService.CheckHold(f.orderItem(), second.ID, f.business.ID); err == nil {
+		t.Error("expected the held machine to be rejected for the second user")
+	}
+	if err := ta.WaitlistService.CheckHold(f.orderItem(), first.ID, f.business.ID); err != nil {
+		t.Errorf("expected the held machine to be reservable by the first user, got %v", err)
+	}
+
+	// the offer is closed once the reservation of the first user is paid
+	reservation := ta.CreateTestReservation(t, first.ID, f.machine, f.slot)
+	if err := ta.WaitlistService.Fulfill(reservation.ID); err != nil {
+		t.Fatalf("fulfill: %v", err)
+	}
+	if status := ta.GetWaitlist(t, firstID).Status; status != schema.WaitlistStatusFulfilled {
+		t.Errorf("expected fulfilled, got %s", status)
+	}
+	if status := ta.GetWaitlist(t, secondID).Status; status != schema.WaitlistStatusWaiting {
+		t.Errorf("expected the entry of the second user to stay waiting, got %s", status)
+	}
+}
+
+func TestProcessWaitlist_ExpiredOfferMovesToNextUser(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := newFullyBookedMachine(t, ta)
+	first := ta.CreateTestUser(t, 9120000001, "Test", "User")
+	second := ta.CreateTestUser(t, 9120000002, "Test", "User")
+	firstID := joinedID(t, f.join(t, ta, first, nil))
+	secondID := joinedID(t, f.join(t, ta, second, nil))
+
+	ta.DB.Exec("DELETE FROM reservations")
+	if err := ta.WaitlistService.ProcessWaitlist(); err != nil {
+		t.Fatalf("process waitlist: %v", err)
+	}
+
+	ta.DB.Exec("UPDATE waitlists SET offer_expires_at = ? WHERE id = ?", time.Now().Add(-time.Minute), firstID)
+	if err := ta.WaitlistService.ProcessWaitlist(); err != nil {
+		t.Fatalf("process waitlist: %v", err)
+	}
+
+	if status := ta.GetWaitlist(t, firstID).Status; status != schema.WaitlistStatusExpired {
+		t.Errorf("expected the first offer to expire, got %s", status)
+	}
+	if status := ta.GetWaitlist(t, secondID).Status; status != schema.WaitlistStatusOffered {
+		t.Errorf("expected the machine to be offered to the second user, got %s", status)
+	}
+}
+
+func TestProcessWaitlist_AnyMachineOfTaxonomy(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := newFullyBookedMachine(t, ta)
+	other := ta.CreateTestMachine(t, f.business.ID, f.owner.ID, "M-2")
+	otherReservation := ta.CreateTestReservation(t, f.owner.ID, other, f.slot)
+	taxonomy := ta.CreateTestTaxonomy(t, f.business.ID, "Dormitory", nil, f.machine, other)
+
+	user := ta.CreateTestUser(t, 9120000001, "Test", "User")
+	id := joinedID(t, f.join(t, ta, user, map[string]any{"TaxonomyID": taxonomy.ID}))
+
+	ta.DB.Exec("DELETE FROM reservations WHERE id = ?", otherReservation.ID)
+	if err := ta.WaitlistService.ProcessWaitlist(); err != nil {
+		t.Fatalf("process waitlist: %v", err)
+	}
+
+	offered := ta.GetWaitlist(t, id)
+	if offered.OfferedProductID == nil || *offered.OfferedProductID != other.ID {
+		t.Fatalf("expected the freed machine of the taxonomy to be offered, got %+v", offered)
+	}
+}
+
+func TestProcessWaitlist_CleansUpPassedSlots(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := newFullyBookedMachine(t, ta)
+	user := ta.CreateTestUser(t, 9120000001, "Test", "User")
+	id := joinedID(t, f.join(t, ta, user, nil))
+
+	ta.DB.Exec("UPDATE waitlists SET start_time = ?, end_time = ? WHERE id = ?", time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour), id)
+	if err := ta.WaitlistService.ProcessWaitlist(); err != nil {
+		t.Fatalf("process waitlist: %v", err)
+	}
+
+	item := ta.GetWaitlist(t, id)
+	if item.Status != schema.WaitlistStatusExpired || !item.DeletedAt.Valid {
+		t.Errorf("expected the passed entry to be expired and removed, got %+v", item)
+	}
+}
+
+func TestWaitlists_UserAndOperatorViews(t *testing.T) {
+	ta := SetupTestApp(t)
+	defer ta.Cleanup()
+
+	f := newFullyBookedMachine(t, ta)
+	user := ta.CreateTestUser(t, 9120000001, "Test", "User")
+	id := joinedID(t, f.join(t, ta, user, nil))
+	userToken := ta.GenerateTestToken(t, user)
+
+	resp := ta.MakeRequest(t, http.MethodGet, "/v1/user/waitlists", nil, userToken)
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if data := testapp.ParseResponse(t, resp)["Data"].([]any); len(data) != 1 {
+		t.Errorf("expected 1 entry for the user, got %d", len(data))
+	}
+
+	resp = ta.MakeRequest(t, http.MethodGet, fmt.Sprintf("/v1/business/%d/waitlists", f.business.ID), nil, ta.GenerateTestToken(t, f.owner))
+	testapp.AssertStatus(t, resp, http.StatusOK)
+	if data := testapp.ParseResponse(t, resp)["Data"].([]any); len(data) != 1 {
+		t.Errorf("expected 1 entry for the operator, got %d", len(data))
+	}
+
+	other := ta.CreateTestUser(t, 9120000002, "Test", "User")
+	testapp.AssertStatus(t, ta.MakeRequest(t, http.MethodDelete, fmt.Sprintf("/v1/user/waitlists/%d", id), nil, ta.GenerateTestToken(t, other)), http.StatusNotFound)
+
+	testapp.AssertStatus(t, ta.MakeRequest(t, http.MethodDelete, fmt.Sprintf("/v1/user/waitlists/%d", id), nil, userToken), http.StatusOK)
+	if status := ta.GetWaitlist(t, id).Status; status != schema.WaitlistStatusCanceled {
+		t.Errorf("expected canceled, got %s", status)
+	}
+}
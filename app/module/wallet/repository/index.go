@@ -7,6 +7,7 @@ import (
 	"go-fiber-starter/internal/bootstrap/database"
 	"go-fiber-starter/utils/paginator"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type IRepository interface {
@@ -14,6 +15,8 @@ type IRepository interface {
 	GetOne(id *uint64, userID *uint64, businessID *uint64) (wallet *schema.Wallet, err error)
 	Create(wallet *schema.Wallet, tx *gorm.DB) (err error)
 	Update(id uint64, wallet *schema.Wallet) (err error)
+	AddAmount(id uint64, amount float64, tx *gorm.DB) (err error)
+	Lock(id uint64, tx *gorm.DB) (wallet *schema.Wallet, err error)
 	Delete(id uint64) (err error)
 }
 
@@ -88,6 +91,26 @@ func (_i *repo) Update(id uint64, wallet *schema.Wallet) (err error) {
 		Updates(wallet).Error
 }
 
+func (_i *repo) AddAmount(id uint64, amount float64, tx *gorm.DB) (err error) {
+	db := _i.DB.Main
+	if tx != nil {
+		db = tx
+	}
+
+	return db.Model(&schema.Wallet{}).
+		Where("id = ?", id).
+		Update("amount", gorm.Expr("amount + ?", amount)).Error
+}
+
+// Lock reads the wallet and keeps it locked until tx ends, so its balance stays the same until the charge is saved.
+func (_i *repo) Lock(id uint64, tx *gorm.DB) (wallet *schema.Wallet, err error) {
+	if err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&wallet, id).Error; err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
 func (_i *repo) Delete(id uint64) error {
 	return _i.DB.Main.Delete(&schema.Wallet{}, id).Error
 }
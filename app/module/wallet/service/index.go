@@ -17,6 +17,8 @@ type IService interface {
 	Update(id uint64, req schema.Wallet) (err error)
 	Destroy(id uint64) error
 	GetOrCreateWallet(userID *uint64, businessID *uint64, tx *gorm.DB) (wallet *response.Wallet, err error)
+	AddAmount(id uint64, amount float64, tx *gorm.DB) (err error)
+	Lock(id uint64, tx *gorm.DB) (wallet *response.Wallet, err error)
 }
 
 func Service(Repo repository.IRepository) IService {
@@ -58,6 +60,19 @@ func (_i *service) Update(id uint64, req schema.Wallet) (err error) {
 	return _i.Repo.Update(id, &req)
 }
 
+func (_i *service) AddAmount(id uint64, amount float64, tx *gorm.DB) (err error) {
+	return _i.Repo.AddAmount(id, amount, tx)
+}
+
+func (_i *service) Lock(id uint64, tx *gorm.DB) (wallet *response.Wallet, err error) {
+	result, err := _i.Repo.Lock(id, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.FromDomain(result), nil
+}
+
 func (_i *service) Destroy(id uint64) error {
 	return _i.Repo.Delete(id)
 }
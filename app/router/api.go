@@ -4,18 +4,26 @@ import (
 	"go-fiber-starter/app/module/asset"
 	"go-fiber-starter/app/module/auth"
 	"go-fiber-starter/app/module/business"
+	"go-fiber-starter/app/module/calendar"
+	"go-fiber-starter/app/module/campaign"
 	"go-fiber-starter/app/module/comment"
 	"go-fiber-starter/app/module/coupon"
 	"go-fiber-starter/app/module/notification"
 	notificationtemplate "go-fiber-starter/app/module/notificationTemplate"
+	operatorbot "go-fiber-starter/app/module/operatorBot"
 	"go-fiber-starter/app/module/order"
+	"go-fiber-starter/app/module/outbox"
 	"go-fiber-starter/app/module/post"
 	"go-fiber-starter/app/module/product"
+	"go-fiber-starter/app/module/referral"
+	"go-fiber-starter/app/module/reminder"
 	"go-fiber-starter/app/module/reservation"
 	"go-fiber-starter/app/module/taxonomy"
 	"go-fiber-starter/app/module/transaction"
 	"go-fiber-starter/app/module/uniwash"
 	"go-fiber-starter/app/module/user"
+	userbot "go-fiber-starter/app/module/userBot"
+	"go-fiber-starter/app/module/waitlist"
 	"go-fiber-starter/app/module/wallet"
 	"go-fiber-starter/utils/config"
 
@@ -43,6 +51,14 @@ type Router struct {
 	ReservationsRouter         *reservation.Router
 	NotificationRouter         *notification.Router
 	NotificationTemplateRouter *notificationtemplate.Router
+	ReferralRouter             *referral.Router
+	WaitlistRouter             *waitlist.Router
+	CalendarRouter             *calendar.Router
+	ReminderRouter             *reminder.Router
+	OutboxRouter               *outbox.Router
+	CampaignRouter             *campaign.Router
+	OperatorBotRouter          *operatorbot.Router
+	UserBotRouter              *userbot.Router
 }
 
 func NewRouter(
@@ -65,6 +81,14 @@ func NewRouter(
 	reservationsRouter *reservation.Router,
 	notificationRouter *notification.Router,
 	notificationTemplateRouter *notificationtemplate.Router,
+	referralRouter *referral.Router,
+	waitlistRouter *waitlist.Router,
+	calendarRouter *calendar.Router,
+	reminderRouter *reminder.Router,
+	outboxRouter *outbox.Router,
+	campaignRouter *campaign.Router,
+	operatorBotRouter *operatorbot.Router,
+	userBotRouter *userbot.Router,
 ) *Router {
 	return &Router{
 		App: fiber,
@@ -88,6 +112,14 @@ func NewRouter(
 		//MessageRoomRouter:          messageRoomRouter,
 		NotificationRouter:         notificationRouter,
 		NotificationTemplateRouter: notificationTemplateRouter,
+		ReferralRouter:             referralRouter,
+		WaitlistRouter:             waitlistRouter,
+		CalendarRouter:             calendarRouter,
+		ReminderRouter:             reminderRouter,
+		OutboxRouter:               outboxRouter,
+		CampaignRouter:             campaignRouter,
+		OperatorBotRouter:          operatorBotRouter,
+		UserBotRouter:              userBotRouter,
 	}
 }
 
@@ -111,6 +143,14 @@ func (r *Router) Register() { // Register routes of modules
 	//r.MessageRoomRouter.RegisterRoutes(r.Cfg)
 	r.NotificationRouter.RegisterRoutes(r.Cfg)
 	r.NotificationTemplateRouter.RegisterRoutes(r.Cfg)
+	r.ReferralRouter.RegisterRoutes(r.Cfg)
+	r.WaitlistRouter.RegisterRoutes(r.Cfg)
+	r.CalendarRouter.RegisterRoutes(r.Cfg)
+	r.ReminderRouter.RegisterRoutes(r.Cfg)
+	r.OutboxRouter.RegisterRoutes(r.Cfg)
+	r.CampaignRouter.RegisterRoutes(r.Cfg)
+	r.OperatorBotRouter.RegisterRoutes(r.Cfg)
+	r.UserBotRouter.RegisterRoutes(r.Cfg)
 
 	// Swagger Documentation
 	r.App.Get("/swagger/*", swagger.HandlerDefault)
@@ -5,24 +5,34 @@ import (
 	"go-fiber-starter/app/module/asset"
 	"go-fiber-starter/app/module/auth"
 	"go-fiber-starter/app/module/business"
+	"go-fiber-starter/app/module/calendar"
+	"go-fiber-starter/app/module/campaign"
 	"go-fiber-starter/app/module/comment"
 	"go-fiber-starter/app/module/coupon"
 	"go-fiber-starter/app/module/notification"
 	notificationtemplate "go-fiber-starter/app/module/notificationTemplate"
+	operatorbot "go-fiber-starter/app/module/operatorBot"
 	"go-fiber-starter/app/module/order"
 	"go-fiber-starter/app/module/orderItem"
+	"go-fiber-starter/app/module/outbox"
 	"go-fiber-starter/app/module/post"
 	"go-fiber-starter/app/module/product"
+	"go-fiber-starter/app/module/referral"
+	"go-fiber-starter/app/module/reminder"
 	"go-fiber-starter/app/module/reservation"
 	"go-fiber-starter/app/module/taxonomy"
 	"go-fiber-starter/app/module/transaction"
 	"go-fiber-starter/app/module/uniwash"
 	"go-fiber-starter/app/module/user"
+	userbot "go-fiber-starter/app/module/userBot"
+	"go-fiber-starter/app/module/waitlist"
 	"go-fiber-starter/app/module/wallet"
 	"go-fiber-starter/app/router"
 	"go-fiber-starter/internal"
 	"go-fiber-starter/internal/bootstrap"
 	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/internal/device"
+	"go-fiber-starter/internal/sms"
 	"go-fiber-starter/utils/config"
 
 	"go.uber.org/automaxprocs/maxprocs"
@@ -56,6 +66,8 @@ func main() {
 		fx.Provide(bootstrap.NewLogger),
 		// bale bot
 		fx.Provide(internal.NewBaleBotLogger),
+		fx.Provide(internal.NewOperatorBot),
+		fx.Provide(internal.NewUserBot),
 		// fiber
 		fx.Provide(bootstrap.NewFiber),
 		// database
@@ -71,9 +83,13 @@ func main() {
 		// router
 		fx.Provide(router.NewRouter),
 		// messageWay service
-		fx.Provide(internal.NewMessageWay),
+		fx.Provide(sms.NewService),
 		// cron job service
 		fx.Provide(internal.NewCronService),
+		// user notification streams
+		fx.Provide(internal.NewNotificationHub),
+		// washing machine drivers
+		fx.Provide(device.NewRegistry),
 
 		// provide modules
 		post.Module,
@@ -93,6 +109,14 @@ func main() {
 		reservation.Module,
 		notification.Module,
 		notificationtemplate.Module,
+		referral.Module,
+		waitlist.Module,
+		calendar.Module,
+		reminder.Module,
+		outbox.Module,
+		campaign.Module,
+		operatorbot.Module,
+		userbot.Module,
 		// End provide modules
 
 		// start application
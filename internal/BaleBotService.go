@@ -1,12 +1,23 @@
 package internal
 
 import (
+	"context"
+	"errors"
 	"go-fiber-starter/utils/config"
+	"time"
 
 	baleBotApi "github.com/ghiac/bale-bot-api"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
+// the seconds a request for the updates of a bot waits for a new one
+const pollTimeout = 30
+
+// the wait after a failed request for the updates
+const retryDelay = 3 * time.Second
+
 type BaleBot struct {
 	Bot          *baleBotApi.BotAPI
 	LoggerChatID int64
@@ -63,3 +74,107 @@ func NewBaleBotLogger(cfg *config.Config) *BaleBot {
 		LoggerChatID: cfg.Services.BaleBot.LoggerChatID,
 	}
 }
+
+// ChatBot is a bot the users talk to, it is not connected when its token is not set.
+type ChatBot struct {
+	Bot       *baleBotApi.BotAPI
+	Name      string // the username, for the links
+	Connected bool
+}
+
+func newChatBot(token string, name string, debug bool) ChatBot {
+	chatBot := ChatBot{Name: name}
+	if token == "" {
+		return chatBot
+	}
+
+	bot, err := baleBotApi.NewBaleBotAPI(token)
+	if err != nil {
+		log.Err(err).Str("bot", name).Msg("Error creating the BaleBot")
+		return chatBot
+	}
+	bot.Debug = debug
+
+	chatBot.Bot = bot
+	chatBot.Connected = true
+	if chatBot.Name == "" {
+		chatBot.Name = bot.Self.UserName
+	}
+
+	return chatBot
+}
+
+// Poll gets the messages of the chats and replies to them by handle until ctx is done. The message of a
+// fiber.Error is the reply of the failed ones.
+func (b *ChatBot) Poll(ctx context.Context, logger zerolog.Logger, handle func(chatID int64, text string) (reply string, err error)) {
+	config := baleBotApi.NewUpdate(0)
+	config.Timeout = pollTimeout
+
+	for ctx.Err() == nil {
+		updates, err := b.Bot.GetUpdates(config)
+		if err != nil {
+			logger.Err(err).Str("bot", b.Name).Msg("Failed to get the updates of the bot")
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			if update.UpdateID >= config.Offset {
+				config.Offset = update.UpdateID + 1
+			}
+			if update.Message == nil || update.Message.Chat == nil || update.Message.Text == "" {
+				continue
+			}
+
+			b.reply(logger, update.Message.Chat.ID, update.Message.Text, handle)
+		}
+	}
+}
+
+func (b *ChatBot) reply(logger zerolog.Logger, chatID int64, text string, handle func(chatID int64, text string) (reply string, err error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error().Interface("panic", r).Str("bot", b.Name).Msg("The bot panicked on a message")
+		}
+	}()
+
+	reply, err := handle(chatID, text)
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			reply = fiberErr.Message
+		} else {
+			logger.Err(err).Str("bot", b.Name).Int64("chatID", chatID).Msg("Failed to handle the message of the bot")
+			reply = "خطایی رخ داد، دوباره تلاش کنید."
+		}
+	}
+
+	if _, err := b.Bot.Send(baleBotApi.NewMessage(chatID, reply)); err != nil {
+		logger.Err(err).Str("bot", b.Name).Int64("chatID", chatID).Msg("Failed to reply in the bot")
+	}
+}
+
+// OperatorBot is the bot the operators of the businesses send their commands to.
+type OperatorBot struct {
+	ChatBot
+}
+
+func NewOperatorBot(cfg *config.Config) *OperatorBot {
+	return &OperatorBot{
+		newChatBot(cfg.Services.BaleBot.OperatorBotToken, cfg.Services.BaleBot.OperatorBotName, cfg.Services.BaleBot.Debug),
+	}
+}
+
+// UserBot is the bot the users link their account to, to get their notifications on Bale rather than by sms.
+type UserBot struct {
+	ChatBot
+}
+
+func NewUserBot(cfg *config.Config) *UserBot {
+	return &UserBot{
+		newChatBot(cfg.Services.BaleBot.UserBotToken, cfg.Services.BaleBot.UserBotName, cfg.Services.BaleBot.Debug),
+	}
+}
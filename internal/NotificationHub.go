@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils"
+	"go-fiber-starter/utils/config"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+)
+
+// the postgres channel the events go through
+const hubChannel = "user_events"
+
+// postgres drops the NOTIFY payloads of 8000 bytes and more
+const hubMaxPayload = 7900
+
+// the events a slow stream can fall behind before the new ones are dropped for it
+const hubStreamBuffer = 16
+
+var ErrHubPayloadTooLarge = errors.New("the event is too large to be published")
+
+// HubEvent is an event of a user, Data is sent to the streams of the user as it is.
+type HubEvent struct {
+	UserID uint64
+	Name   string
+	Data   json.RawMessage
+}
+
+// NotificationHub pushes the events of the users to their open streams. With prefork every process has its own
+// streams and any of them may publish, so the events go through postgres NOTIFY and each process hands them
+// to the streams it holds.
+type NotificationHub struct {
+	cfg      *config.Config
+	db       *database.Database
+	logger   zerolog.Logger
+	listener *pq.Listener
+
+	mu      sync.RWMutex
+	streams map[uint64]map[chan HubEvent]struct{}
+	closed  bool
+}
+
+func NewNotificationHub(lc fx.Lifecycle, cfg *config.Config, db *database.Database, logger zerolog.Logger) *NotificationHub {
+	hub := &NotificationHub{
+		cfg:     cfg,
+		db:      db,
+		logger:  logger,
+		streams: map[uint64]map[chan HubEvent]struct{}{},
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return hub.Listen()
+		},
+		OnStop: func(ctx context.Context) error {
+			hub.Close()
+			return nil
+		},
+	})
+
+	return hub
+}
+
+// Listen starts receiving the events of all the processes, the prefork parent serves no streams and skips it.
+func (_h *NotificationHub) Listen() error {
+	if _h.cfg.App.Prefork && !utils.IsChildProcess() {
+		return nil
+	}
+
+	_h.listener = pq.NewListener(_h.cfg.DB.Main.Url, 10*time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			_h.logger.Error().Err(err).Msg("The notification hub lost its connection")
+		}
+	})
+	if err := _h.listener.Listen(hubChannel); err != nil {
+		return err
+	}
+
+	go _h.dispatch(_h.listener.NotificationChannel())
+
+	return nil
+}
+
+// Close ends the open streams, they keep the web server from shutting down otherwise.
+func (_h *NotificationHub) Close() {
+	_h.mu.Lock()
+	defer _h.mu.Unlock()
+
+	if _h.closed {
+		return
+	}
+	_h.closed = true
+
+	if _h.listener != nil {
+		_ = _h.listener.Close()
+	}
+	for _, streams := range _h.streams {
+		for stream := range streams {
+			close(stream)
+		}
+	}
+	_h.streams = map[uint64]map[chan HubEvent]struct{}{}
+}
+
+// Publish sends the event to the streams of the user in all the processes.
+func (_h *NotificationHub) Publish(userID uint64, name string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(HubEvent{UserID: userID, Name: name, Data: raw})
+	if err != nil {
+		return err
+	}
+	if len(payload) > hubMaxPayload {
+		return ErrHubPayloadTooLarge
+	}
+
+	return _h.db.Main.Exec("SELECT pg_notify(?, ?)", hubChannel, string(payload)).Error
+}
+
+// Subscribe opens a stream of the events of the user, the stream is closed with unsubscribe or when the hub closes.
+func (_h *NotificationHub) Subscribe(userID uint64) (events <-chan HubEvent, unsubscribe func()) {
+	stream := make(chan HubEvent, hubStreamBuffer)
+
+	_h.mu.Lock()
+	defer _h.mu.Unlock()
+
+	if _h.closed {
+		close(stream)
+		return stream, func() {}
+	}
+	if _h.streams[userID] == nil {
+		_h.streams[userID] = map[chan HubEvent]struct{}{}
+	}
+	_h.streams[userID][stream] = struct{}{}
+
+	return stream, func() {
+		_h.mu.Lock()
+		defer _h.mu.Unlock()
+
+		if _, ok := _h.streams[userID][stream]; !ok {
+			// already closed by the hub
+			return
+		}
+		delete(_h.streams[userID], stream)
+		if len(_h.streams[userID]) == 0 {
+			delete(_h.streams, userID)
+		}
+		close(stream)
+	}
+}
+
+func (_h *NotificationHub) dispatch(notifications <-chan *pq.Notification) {
+	for notification := range notifications {
+		if notification == nil {
+			// the connection was lost and is back, the events in between are gone
+			continue
+		}
+
+		var event HubEvent
+		if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+			_h.logger.Error().Err(err).Msg("The notification hub received an invalid event")
+			continue
+		}
+
+		_h.mu.RLock()
+		for stream := range _h.streams[event.UserID] {
+			select {
+			case stream <- event:
+			default:
+				_h.logger.Warn().Uint64("userID", event.UserID).Msg("A notification stream is behind, the event is dropped for it")
+			}
+		}
+		_h.mu.RUnlock()
+	}
+}
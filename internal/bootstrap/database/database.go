@@ -4,6 +4,8 @@ import (
 	"go-fiber-starter/app/database/schema"
 	"go-fiber-starter/app/database/seeds"
 	"go-fiber-starter/utils/config"
+	"go-fiber-starter/utils/timezone"
+	"time"
 
 	"github.com/rs/zerolog"
 	"gorm.io/driver/postgres"
@@ -38,6 +40,16 @@ func (_db *Database) ConnectDatabase() {
 
 	_db.Main = mainDB
 
+	if err == nil {
+		timezone.Use(func(businessID uint64) (string, *time.Weekday, error) {
+			var business schema.Business
+			if err := mainDB.Select("id", "meta").First(&business, businessID).Error; err != nil {
+				return "", nil, err
+			}
+			return business.Meta.Timezone, business.Meta.FirstDayOfWeek, nil
+		})
+	}
+
 	//chatDB, err := gorm.Open(postgres.Open(_db.Cfg.DB.Chat.Url), &gorm.Config{})
 	//if err != nil {
 	//	_db.Log.Error().Err(err).Msg("An unknown error occurred when to connect the *Chat* database!")
@@ -82,6 +94,10 @@ func (_db *Database) MigrateModels() {
 		_db.Log.Error().Err(err).Msg("An unknown error occurred when to migrate the *Main* database!")
 		panic("An unknown error occurred when to migrate the *Main* database!")
 	}
+	if err := schema.MainDBMigrateData(_db.Main, _db.Log); err != nil {
+		_db.Log.Error().Err(err).Msg("An unknown error occurred when to migrate the data of the *Main* database!")
+		panic("An unknown error occurred when to migrate the data of the *Main* database!")
+	}
 
 	//if err := _db.Chat.AutoMigrate(schema.ChatDBModels()...); err != nil {
 	//	_db.Log.Error().Err(err).Msg("An unknown error occurred when to migrate the *Chat* database!")
@@ -49,6 +49,7 @@ func Start(
 	lifecycle fx.Lifecycle,
 	database *database.Database,
 	middlewares *middleware.Middleware,
+	notificationHub *internal.NotificationHub,
 ) {
 	lifecycle.Append(
 		fx.Hook{
@@ -100,6 +101,8 @@ func Start(
 			},
 			OnStop: func(ctx context.Context) error {
 				log.Info().Msg("Shutting down the app...")
+				// the open notification streams would keep the shutdown waiting
+				notificationHub.Close()
 				if err := fiber.Shutdown(); err != nil {
 					log.Panic().Err(err).Msg("")
 				}
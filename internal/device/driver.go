@@ -0,0 +1,153 @@
+package device
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/sms"
+	"go-fiber-starter/utils/config"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Driver controls a washing machine controller.
+type Driver interface {
+	// SendCommand delivers the command to the machine and returns the reference of the delivery.
+	SendCommand(target Target, command schema.UniWashCommand) (referenceID string, err error)
+	// State returns the last known state of the machine.
+	State(target Target) (state *State, err error)
+	// Delivery reports whether the command with the reference of the target reached the machine.
+	Delivery(target Target) (delivery *Delivery, err error)
+}
+
+// Target is the machine a driver talks to.
+type Target struct {
+	ProductID   uint64
+	Address     string // mobile number of the sms controllers, base url of the http controllers
+	ReferenceID string // reference of the last command, for the drivers that can't ask the machine itself
+}
+
+type Power string
+
+const (
+	PowerON      Power = "ON"
+	PowerOFF     Power = "OFF"
+	PowerUnknown Power = "UNKNOWN"
+)
+
+type State struct {
+	Online      bool
+	Power       Power
+	Phase       string                `json:",omitempty"` // washing, evacuating, done, ...
+	LastCommand schema.UniWashCommand `json:",omitempty"`
+	Delivery    string                `json:",omitempty"` // delivery status of the last command reported by the provider
+	UpdatedAt   time.Time             `json:",omitempty"`
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+type Delivery struct {
+	Status DeliveryStatus
+	Detail string // raw status of the provider
+}
+
+var (
+	// ErrRejected is returned when the provider received the command but refused to deliver it.
+	ErrRejected = errors.New("device: command rejected by provider")
+	// ErrUnsupportedCommand is returned when the controller does not understand the command.
+	ErrUnsupportedCommand = errors.New("device: unsupported command")
+	// ErrUnknownDriver is returned for a product with a driver that is not registered.
+	ErrUnknownDriver = errors.New("device: unknown driver")
+	// ErrHostNotAllowed is returned for an http controller out of the configured hosts.
+	ErrHostNotAllowed = errors.New("device: controller host is not allowed")
+)
+
+// Registry picks the driver of each product.
+type Registry struct {
+	drivers        map[schema.UniWashDriver]Driver
+	forceSimulator bool
+	simulator      *Simulator
+	http           *HTTPDriver
+}
+
+func NewRegistry(cfg *config.Config, logger zerolog.Logger, smsService *sms.Service) *Registry {
+	simulator := NewSimulator(cfg.Services.Device.SimulatorCycle * time.Second)
+	httpDriver := NewHTTPDriver(cfg.Services.Device.HttpToken, cfg.Services.Device.HttpHosts, cfg.Services.Device.HttpTimeout*time.Second, logger)
+
+	drivers := map[schema.UniWashDriver]Driver{
+		schema.UniWashDriverSMS:  NewSMSDriver(smsService),
+		schema.UniWashDriverHTTP: httpDriver,
+	}
+	// the businesses can't put a real machine on the simulator in production
+	if !cfg.App.Production {
+		drivers[schema.UniWashDriverSimulator] = simulator
+	}
+
+	return &Registry{
+		drivers:        drivers,
+		forceSimulator: cfg.Services.Device.Simulator,
+		simulator:      simulator,
+		http:           httpDriver,
+	}
+}
+
+// Name returns the driver that handles the product.
+func (r *Registry) Name(product *schema.Product) schema.UniWashDriver {
+	if r.forceSimulator {
+		return schema.UniWashDriverSimulator
+	}
+	if product.Meta.UniWashDriver == "" {
+		return schema.UniWashDriverSMS
+	}
+
+	return product.Meta.UniWashDriver
+}
+
+// For returns the driver and target of the product. Every product uses the
+// simulator when it is forced in the config.
+func (r *Registry) For(product *schema.Product) (Driver, Target, error) {
+	target := Target{ProductID: product.ID, Address: product.Meta.UniWashMobileNumber}
+	if r.forceSimulator {
+		return r.simulator, target, nil
+	}
+
+	if err := r.Check(product.Meta); err != nil {
+		return nil, target, err
+	}
+
+	name := r.Name(product)
+	if name == schema.UniWashDriverHTTP {
+		target.Address = product.Meta.UniWashDeviceURL
+	}
+
+	return r.drivers[name], target, nil
+}
+
+// Check returns ErrUnknownDriver for a driver that is not registered and ErrHostNotAllowed for an http
+// controller out of the configured hosts, before the product is saved.
+func (r *Registry) Check(meta schema.ProductMeta) error {
+	name := meta.UniWashDriver
+	if name == "" {
+		name = schema.UniWashDriverSMS
+	}
+
+	if _, ok := r.drivers[name]; !ok {
+		return ErrUnknownDriver
+	}
+	if name == schema.UniWashDriverHTTP && meta.UniWashDeviceURL != "" {
+		return r.http.Allowed(meta.UniWashDeviceURL)
+	}
+
+	return nil
+}
+
+// Simulator returns the in-process simulator, to inspect or break simulated machines.
+func (r *Registry) Simulator() *Simulator {
+	return r.simulator
+}
@@ -0,0 +1,131 @@
+package device
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const defaultHTTPTimeout = 10 * time.Second
+
+// HTTPDriver talks to the controllers that expose a small json api:
+//
+//	POST {address}/commands  {"Command": "ON"}  ->  {"ReferenceID": "..."}
+//	GET  {address}/state                        ->  State
+//
+// Every request carries the shared token as a bearer token, so the controllers are only reached on the
+// configured hosts. The url of a product is set by its business.
+type HTTPDriver struct {
+	Token  string
+	Hosts  []string // host or host:port of the controllers
+	Client *http.Client
+	Logger zerolog.Logger
+}
+
+func NewHTTPDriver(token string, hosts []string, timeout time.Duration, logger zerolog.Logger) *HTTPDriver {
+	if timeout == 0 {
+		timeout = defaultHTTPTimeout
+	}
+
+	return &HTTPDriver{
+		Token:  token,
+		Hosts:  hosts,
+		Client: &http.Client{Timeout: timeout, CheckRedirect: noRedirect},
+		Logger: logger,
+	}
+}
+
+// noRedirect keeps the requests on the allowed host, a controller never redirects.
+func noRedirect(req *http.Request, via []*http.Request) error {
+	return http.ErrUseLastResponse
+}
+
+// Allowed reports ErrHostNotAllowed for a controller url out of the configured hosts.
+func (d *HTTPDriver) Allowed(address string) error {
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return ErrHostNotAllowed
+	}
+
+	host := strings.ToLower(u.Host)
+	if slices.ContainsFunc(d.Hosts, func(allowed string) bool {
+		allowed = strings.ToLower(allowed)
+		return allowed == host || allowed == strings.ToLower(u.Hostname())
+	}) {
+		return nil
+	}
+
+	return ErrHostNotAllowed
+}
+
+func (d *HTTPDriver) SendCommand(target Target, command schema.UniWashCommand) (referenceID string, err error) {
+	body, err := json.Marshal(map[string]schema.UniWashCommand{"Command": command})
+	if err != nil {
+		return "", err
+	}
+
+	var res struct {
+		ReferenceID string
+	}
+	if err = d.do(http.MethodPost, target, "/commands", body, &res); err != nil {
+		return "", err
+	}
+
+	return res.ReferenceID, nil
+}
+
+func (d *HTTPDriver) State(target Target) (state *State, err error) {
+	state = &State{}
+	if err = d.do(http.MethodGet, target, "/state", nil, state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Delivery is immediate for the http controllers, the command is answered by the machine itself.
+func (d *HTTPDriver) Delivery(target Target) (delivery *Delivery, err error) {
+	return &Delivery{Status: DeliveryDelivered}, nil
+}
+
+func (d *HTTPDriver) do(method string, target Target, path string, body []byte, out any) error {
+	if target.Address == "" {
+		return fmt.Errorf("device: product %d has no controller url", target.ProductID)
+	}
+	if err := d.Allowed(target.Address); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(method, strings.TrimRight(target.Address, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if d.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.Token)
+	}
+
+	res, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusUnprocessableEntity:
+		return ErrUnsupportedCommand
+	case res.StatusCode >= 400:
+		d.Logger.Warn().Uint64("productID", target.ProductID).Int("status", res.StatusCode).Msg("device controller rejected request")
+		return ErrRejected
+	}
+
+	return json.NewDecoder(res.Body).Decode(out)
+}
@@ -0,0 +1,87 @@
+package device
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/sms"
+	"strings"
+	"time"
+)
+
+// smsCommands are the params of the command template of the sms controllers.
+var smsCommands = map[schema.UniWashCommand]string{
+	schema.UniWashCommandON:         "on",
+	schema.UniWashCommandOFF:        "off",
+	schema.UniWashCommandRewash:     "10",
+	schema.UniWashCommandEvacuation: "9",
+}
+
+// SMSDriver sends the commands as an sms template to the sim card of the controller, right away rather than
+// through the outbox. The controller never answers, so the state only reflects the delivery of the last command.
+type SMSDriver struct {
+	Sms *sms.Service
+}
+
+func NewSMSDriver(smsService *sms.Service) *SMSDriver {
+	return &SMSDriver{Sms: smsService}
+}
+
+func (d *SMSDriver) SendCommand(target Target, command schema.UniWashCommand) (referenceID string, err error) {
+	param, ok := smsCommands[command]
+	if !ok {
+		return "", ErrUnsupportedCommand
+	}
+
+	receipt, err := d.Sms.Send(sms.Message{
+		Template: schema.SmsMachineCommand,
+		Mobile:   target.Address,
+		Params:   []string{param},
+	})
+	if errors.Is(err, sms.ErrRejected) || errors.Is(err, sms.ErrInvalidMessage) {
+		return "", ErrRejected
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return receipt.Reference(), nil
+}
+
+func (d *SMSDriver) State(target Target) (state *State, err error) {
+	state = &State{Online: true, Power: PowerUnknown, UpdatedAt: time.Now()}
+	if target.ReferenceID == "" {
+		return state, nil
+	}
+
+	status, err := d.Sms.Status(target.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+	state.Delivery = status.Status
+
+	return state, nil
+}
+
+// smsDeliveryFailures are the provider statuses that mean the sms will never reach the controller.
+var smsDeliveryFailures = []string{"fail", "undeliver", "notdeliver", "reject", "expire", "blacklist"}
+
+func (d *SMSDriver) Delivery(target Target) (delivery *Delivery, err error) {
+	status, err := d.Sms.Status(target.ReferenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery = &Delivery{Status: DeliveryPending, Detail: status.Status}
+	detail := strings.ToLower(strings.ReplaceAll(status.Status, " ", ""))
+	for _, failure := range smsDeliveryFailures {
+		if strings.Contains(detail, failure) {
+			delivery.Status = DeliveryFailed
+			return delivery, nil
+		}
+	}
+	if strings.Contains(detail, "deliver") {
+		delivery.Status = DeliveryDelivered
+	}
+
+	return delivery, nil
+}
@@ -0,0 +1,98 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"strconv"
+
+	baleBotApi "github.com/ghiac/bale-bot-api"
+)
+
+// ProviderBale is the user bot on Bale, of the messages to the users that linked their account to it.
+const ProviderBale = "bale"
+
+// ErrNoBale is returned when the user bot is not connected, the messages go by sms instead.
+var ErrNoBale = errors.New("sms: the bale bot is not connected")
+
+// BaleBot sends the messages to the chats, it is the bot api outside the tests.
+type BaleBot interface {
+	Send(c baleBotApi.Chattable) (baleBotApi.Message, error)
+}
+
+// baleTemplate is the text of a message on Bale, with the params of its sms in the same order
+type baleTemplate struct {
+	Text   string
+	Params int
+}
+
+var baleTemplates = map[string]baleTemplate{
+	schema.SmsCoupon:               {"%s عزیز، کد تخفیف %s برای شما صادر شد و تا %s اعتبار دارد.", 3},
+	schema.SmsReminderTurnOn:       {"زمان رزرو شما رسیده است، دستگاه را روشن کنید.", 0},
+	schema.SmsReminderTurnOff:      {"زمان رزرو شما رو به پایان است.", 0},
+	schema.SmsDeviceOff:            {"دستگاه %s در حال حاضر در دسترس نیست.", 1},
+	schema.SmsWaitlistOffer:        {"%s عزیز، دستگاه %s برای %s آزاد شد، تا ساعت %s فرصت رزرو دارید.", 4},
+	schema.SmsDowntimeAlert:        {"دستگاه %s از دسترس خارج شد: %s", 2},
+	schema.SmsReservationMoved:     {"%s عزیز، رزرو شما از دستگاه %s به دستگاه %s در %s منتقل شد.", 4},
+	schema.SmsReservationRefunded:  {"%[1]s عزیز، مبلغ %[3]s تومان رزرو دستگاه %[2]s به کیف پول شما برگشت.", 3},
+	schema.SmsNoShowSuspension:     {"به دلیل %s بار حاضر نشدن در زمان رزرو، تا %s امکان رزرو ندارید.", 2},
+	schema.SmsRescheduled:          {"رزرو شما به دستگاه %s در %s منتقل شد.", 2},
+	schema.SmsNotification:         {"%s", 1},
+	schema.SmsReservationConfirmed: {"رزرو دستگاه %s برای %s ثبت شد.", 2},
+}
+
+// Bale sends the messages as texts to the chats of the users with the user bot. Its otp codes are made by the
+// app like the ones of the fake provider, Bale keeps none.
+type Bale struct {
+	Bot   BaleBot
+	Codes *OTPCodes
+}
+
+func NewBale(bot BaleBot, codes *OTPCodes) *Bale {
+	return &Bale{
+		Bot:   bot,
+		Codes: codes,
+	}
+}
+
+// Send sends the text of the template of the message to the chat.
+func (b *Bale) Send(chatID int64, message Message) (referenceID string, err error) {
+	template, ok := baleTemplates[message.Template]
+	if !ok {
+		return "", fmt.Errorf("%w: bale: %s", ErrNoTemplate, message.Template)
+	}
+	// the marketing messages may carry the opt-out as an extra param, there is no need for it on Bale
+	if len(message.Params) < template.Params {
+		return "", fmt.Errorf("%w: bale: %s needs %d params", ErrInvalidMessage, message.Template, template.Params)
+	}
+
+	params := make([]any, template.Params)
+	for i := range params {
+		params[i] = message.Params[i]
+	}
+
+	return b.send(chatID, fmt.Sprintf(template.Text, params...))
+}
+
+// SendOTP sends a code of the mobile to the chat, it is verified by VerifyOTP.
+func (b *Bale) SendOTP(chatID int64, mobile string) (referenceID string, err error) {
+	code, err := b.Codes.New(mobile)
+	if err != nil {
+		return "", err
+	}
+
+	return b.send(chatID, fmt.Sprintf("کد تایید شما: %s\nاین کد را به کسی ندهید.", code))
+}
+
+func (b *Bale) VerifyOTP(mobile string, code string) (err error) {
+	return b.Codes.Verify(mobile, code)
+}
+
+func (b *Bale) send(chatID int64, text string) (referenceID string, err error) {
+	sent, err := b.Bot.Send(baleBotApi.NewMessage(chatID, text))
+	if err != nil {
+		return "", fmt.Errorf("bale: %w", err)
+	}
+
+	return strconv.Itoa(sent.MessageID), nil
+}
@@ -0,0 +1,132 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// FakeProvider sends nothing, for the development. It logs the messages, appends them to the file as json
+// lines and posts them to the url, when they are set. A failing file or url fails the message, so it can
+// stand in for a provider that is down.
+type FakeProvider struct {
+	File   string
+	Url    string
+	Codes  *OTPCodes
+	Client *http.Client
+	Logger zerolog.Logger
+	Now    func() time.Time
+	mu     sync.Mutex
+}
+
+// FakeMessage is a message of the fake provider, as it is written to the file and posted to the url.
+type FakeMessage struct {
+	ReferenceID string
+	Template    string
+	Mobile      string
+	Params      []string `json:",omitempty"`
+	OTP         string   `json:",omitempty"`
+	SentAt      time.Time
+}
+
+func NewFake(file string, url string, logger zerolog.Logger, codes *OTPCodes) *FakeProvider {
+	return &FakeProvider{
+		File:   file,
+		Url:    url,
+		Codes:  codes,
+		Client: &http.Client{Timeout: 5 * time.Second},
+		Logger: logger,
+		Now:    time.Now,
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+// HasTemplate is true for all the logical names, the fake provider has no templates of its own
+func (p *FakeProvider) HasTemplate(name string) bool {
+	return slices.Contains(schema.SmsNames, name)
+}
+
+func (p *FakeProvider) Send(message Message) (referenceID string, err error) {
+	return p.record(FakeMessage{
+		Template: message.Template,
+		Mobile:   message.Mobile,
+		Params:   message.Params,
+	})
+}
+
+func (p *FakeProvider) SendOTP(mobile string) (referenceID string, err error) {
+	code, err := p.Codes.New(mobile)
+	if err != nil {
+		return "", err
+	}
+
+	return p.record(FakeMessage{
+		Template: schema.SmsOTP,
+		Mobile:   mobile,
+		OTP:      code,
+	})
+}
+
+func (p *FakeProvider) VerifyOTP(mobile string, code string) (err error) {
+	return p.Codes.Verify(mobile, code)
+}
+
+func (p *FakeProvider) Status(referenceID string) (status string, err error) {
+	return "delivered", nil
+}
+
+func (p *FakeProvider) record(message FakeMessage) (referenceID string, err error) {
+	message.SentAt = p.Now()
+	message.ReferenceID = fmt.Sprintf("fake-%d", message.SentAt.UnixNano())
+	p.Logger.Warn().Interface("payload", message).Msg("sending message with this")
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+
+	if p.File != "" {
+		if err = p.append(data); err != nil {
+			return "", err
+		}
+	}
+
+	if p.Url != "" {
+		resp, err := p.Client.Post(p.Url, "application/json", bytes.NewReader(data))
+		if err != nil {
+			return "", err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			return "", fmt.Errorf("%w: fake: %s", ErrRejected, resp.Status)
+		}
+	}
+
+	return message.ReferenceID, nil
+}
+
+func (p *FakeProvider) append(data []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	file, err := os.OpenFile(p.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+
+	return err
+}
@@ -0,0 +1,154 @@
+package sms
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const kavenegarURL = "https://api.kavenegar.com/v1"
+
+// the names of the params of a lookup template, in order
+var kavenegarTokens = []string{"token", "token2", "token3", "token10", "token20"}
+
+// the delivery statuses of kavenegar, in the words of the others
+var kavenegarStatuses = map[int]string{
+	1:   "queued",
+	2:   "scheduled",
+	4:   "sent",
+	5:   "sent",
+	6:   "failed",
+	10:  "delivered",
+	11:  "undelivered",
+	13:  "rejected",
+	14:  "blacklisted",
+	100: "unknown",
+}
+
+// KavenegarProvider sends the lookup templates of kavenegar, they are named in its panel and have their own
+// sender. Kavenegar doesn't keep otp codes, they are made by the app and sent with the otp template.
+type KavenegarProvider struct {
+	ApiKey    string
+	Templates map[string]Template
+	Codes     *OTPCodes
+	BaseURL   string
+	Client    *http.Client
+}
+
+func NewKavenegar(apiKey string, templates map[string]Template, codes *OTPCodes) *KavenegarProvider {
+	return &KavenegarProvider{
+		ApiKey:    apiKey,
+		Templates: templates,
+		Codes:     codes,
+		BaseURL:   kavenegarURL,
+		Client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type kavenegarResponse struct {
+	Return struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	} `json:"return"`
+	Entries []struct {
+		MessageID  int64  `json:"messageid"`
+		Status     int    `json:"status"`
+		StatusText string `json:"statustext"`
+	} `json:"entries"`
+}
+
+func (p *KavenegarProvider) Name() string {
+	return ProviderKavenegar
+}
+
+func (p *KavenegarProvider) HasTemplate(name string) bool {
+	return p.Templates[name].ID != ""
+}
+
+func (p *KavenegarProvider) Send(message Message) (referenceID string, err error) {
+	template, ok := p.Templates[message.Template]
+	if !ok {
+		return "", ErrNoTemplate
+	}
+	if len(message.Params) > len(kavenegarTokens) {
+		return "", fmt.Errorf("%w: kavenegar takes %d params at most", ErrInvalidMessage, len(kavenegarTokens))
+	}
+
+	query := url.Values{"receptor": {message.Mobile}, "template": {template.ID}}
+	for i, param := range message.Params {
+		query.Set(kavenegarTokens[i], param)
+	}
+
+	res, err := p.call("verify/lookup.json", query)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Entries) == 0 {
+		return "", fmt.Errorf("%w: kavenegar returned no message", ErrRejected)
+	}
+
+	return strconv.FormatInt(res.Entries[0].MessageID, 10), nil
+}
+
+func (p *KavenegarProvider) SendOTP(mobile string) (referenceID string, err error) {
+	code, err := p.Codes.New(mobile)
+	if err != nil {
+		return "", err
+	}
+
+	return p.Send(Message{
+		Template: schema.SmsOTP,
+		Mobile:   mobile,
+		Params:   []string{code},
+	})
+}
+
+func (p *KavenegarProvider) VerifyOTP(mobile string, code string) (err error) {
+	return p.Codes.Verify(mobile, code)
+}
+
+func (p *KavenegarProvider) Status(referenceID string) (status string, err error) {
+	res, err := p.call("sms/status.json", url.Values{"messageid": {referenceID}})
+	if err != nil {
+		return "", err
+	}
+	if len(res.Entries) == 0 {
+		return "", fmt.Errorf("%w: kavenegar returned no status", ErrRejected)
+	}
+
+	entry := res.Entries[0]
+	if status, ok := kavenegarStatuses[entry.Status]; ok {
+		return status, nil
+	}
+
+	return entry.StatusText, nil
+}
+
+func (p *KavenegarProvider) call(path string, query url.Values) (*kavenegarResponse, error) {
+	resp, err := p.Client.PostForm(fmt.Sprintf("%s/%s/%s", p.BaseURL, p.ApiKey, path), query)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// the errors come with the same body and an http status of their own
+	res := &kavenegarResponse{}
+	if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return nil, fmt.Errorf("kavenegar: %s", resp.Status)
+	}
+
+	switch res.Return.Status {
+	case http.StatusOK:
+		return res, nil
+	case 411, 431: // invalid receptor, invalid params
+		return nil, fmt.Errorf("%w: kavenegar: %s", ErrInvalidMessage, res.Return.Message)
+	case 424: // template not found
+		return nil, fmt.Errorf("%w: kavenegar: %s", ErrNoTemplate, res.Return.Message)
+	default:
+		return nil, fmt.Errorf("%w: kavenegar: %d %s", ErrRejected, res.Return.Status, res.Return.Message)
+	}
+}
@@ -0,0 +1,108 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"strconv"
+
+	MessageWay "github.com/MessageWay/MessageWayGolang"
+)
+
+// the messages MessageWay refuses before sending them
+var messageWayInvalid = []error{
+	MessageWay.MobileIsRequiredErr,
+	MessageWay.TemplateIDIsRequiredErr,
+	MessageWay.MethodIsRequiredErr,
+	MessageWay.InvalidCountryCodeErr,
+	MessageWay.CodeIsRequiredErr,
+}
+
+// MessageWayProvider sends the templates of MessageWay, the line of a template is its provider number.
+// The otp codes are made and verified by MessageWay itself.
+type MessageWayProvider struct {
+	App       *MessageWay.App
+	Templates map[string]Template
+}
+
+func NewMessageWay(apiKey string, templates map[string]Template) *MessageWayProvider {
+	return &MessageWayProvider{
+		App:       MessageWay.New(MessageWay.Config{ApiKey: apiKey}),
+		Templates: templates,
+	}
+}
+
+func (p *MessageWayProvider) Name() string {
+	return ProviderMessageWay
+}
+
+func (p *MessageWayProvider) HasTemplate(name string) bool {
+	return p.Templates[name].ID != ""
+}
+
+func (p *MessageWayProvider) Send(message Message) (referenceID string, err error) {
+	template, ok := p.Templates[message.Template]
+	if !ok {
+		return "", ErrNoTemplate
+	}
+
+	templateID, err := strconv.Atoi(template.ID)
+	if err != nil {
+		return "", fmt.Errorf("%w: template %q of %s", ErrNoTemplate, template.ID, message.Template)
+	}
+	line, _ := strconv.Atoi(template.Line)
+
+	res, err := p.App.Send(MessageWay.Message{
+		Provider:   line,
+		TemplateID: templateID,
+		Method:     "sms",
+		Mobile:     message.Mobile,
+		Params:     message.Params,
+	})
+	if err != nil {
+		return "", messageWayError(err)
+	}
+	if res.Status == "error" {
+		return "", fmt.Errorf("%w: %v", ErrRejected, res.Error)
+	}
+
+	return res.ReferenceID, nil
+}
+
+func (p *MessageWayProvider) SendOTP(mobile string) (referenceID string, err error) {
+	return p.Send(Message{Template: schema.SmsOTP, Mobile: mobile})
+}
+
+func (p *MessageWayProvider) VerifyOTP(mobile string, code string) (err error) {
+	res, err := p.App.Verify(MessageWay.OTPVerifyRequest{OTP: code, Mobile: mobile})
+	if err != nil {
+		return messageWayError(err)
+	}
+	if res.Status != "success" {
+		return fmt.Errorf("%w: %v", ErrInvalidOTP, res.Error)
+	}
+
+	return nil
+}
+
+func (p *MessageWayProvider) Status(referenceID string) (status string, err error) {
+	res, err := p.App.GetStatus(MessageWay.StatusRequest{ReferenceID: referenceID})
+	if err != nil {
+		return "", err
+	}
+	if res.Status == "error" {
+		return "", fmt.Errorf("%w: %v", ErrRejected, res.Error)
+	}
+
+	return res.OTPStatus, nil
+}
+
+func messageWayError(err error) error {
+	for _, invalid := range messageWayInvalid {
+		if errors.Is(err, invalid) {
+			return fmt.Errorf("%w: %w", ErrInvalidMessage, err)
+		}
+	}
+
+	return err
+}
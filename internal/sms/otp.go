@@ -0,0 +1,105 @@
+package sms
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal/bootstrap/database"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// otpLifetime is how long a code made by the app is valid
+const otpLifetime = 4 * time.Minute
+
+// OTPStore keeps the codes made by the app, for the providers that don't keep them themselves.
+type OTPStore interface {
+	// Save keeps the hash of the new code of the mobile in place of its older one
+	Save(mobile string, hash string, expiresAt time.Time) error
+	// Use takes the hash of the mobile out of the store, used is false when it is not there or has expired
+	Use(mobile string, hash string, now time.Time) (used bool, err error)
+}
+
+// OTPCodes makes random codes and verifies each of them once. Only their hashes are stored, keyed by the secret
+// of the otp codes, so the store can't be read for the codes.
+type OTPCodes struct {
+	Secret string
+	Store  OTPStore
+	Now    func() time.Time
+}
+
+func NewOTPCodes(secret string, store OTPStore) *OTPCodes {
+	return &OTPCodes{
+		Secret: secret,
+		Store:  store,
+		Now:    time.Now,
+	}
+}
+
+// New makes a code of the mobile, the older codes of the mobile are not valid anymore.
+func (c *OTPCodes) New(mobile string) (code string, err error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(100000))
+	if err != nil {
+		return "", err
+	}
+	code = fmt.Sprintf("%05d", n.Int64())
+
+	if err = c.Store.Save(mobile, c.hash(mobile, code), c.Now().Add(otpLifetime)); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Verify uses the code of the mobile, it is invalid once it was verified.
+func (c *OTPCodes) Verify(mobile string, code string) error {
+	used, err := c.Store.Use(mobile, c.hash(mobile, code), c.Now())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidOTP
+	}
+
+	return nil
+}
+
+func (c *OTPCodes) hash(mobile string, code string) string {
+	mac := hmac.New(sha256.New, []byte(c.Secret))
+	_, _ = fmt.Fprintf(mac, "%s:%s", mobile, code)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewOTPStore keeps the codes in the otp_codes table, so every process can verify them.
+func NewOTPStore(db *database.Database) OTPStore {
+	return &otpStore{db}
+}
+
+type otpStore struct {
+	DB *database.Database
+}
+
+func (s *otpStore) Save(mobile string, hash string, expiresAt time.Time) error {
+	return s.DB.Main.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "mobile"}},
+		DoUpdates: clause.AssignmentColumns([]string{"hash", "expires_at", "created_at"}),
+	}).Create(&schema.OtpCode{Mobile: mobile, Hash: hash, ExpiresAt: expiresAt}).Error
+}
+
+// Use deletes the code, so of two requests with the same code only one gets it.
+func (s *otpStore) Use(mobile string, hash string, now time.Time) (used bool, err error) {
+	result := s.DB.Main.
+		Where("mobile = ? AND hash = ? AND expires_at > ?", mobile, hash, now).
+		Delete(&schema.OtpCode{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,93 @@
+package sms
+
+import (
+	"errors"
+	"go-fiber-starter/app/database/schema"
+)
+
+// Provider sends the sms over a vendor.
+type Provider interface {
+	Name() string
+	// Send sends the template of the message and returns its reference at the provider.
+	Send(message Message) (referenceID string, err error)
+	// SendOTP sends a one time code to the mobile, the provider keeps it to verify it later.
+	SendOTP(mobile string) (referenceID string, err error)
+	// VerifyOTP returns ErrInvalidOTP when the code is not the last one sent to the mobile.
+	VerifyOTP(mobile string, code string) (err error)
+	// Status returns the delivery status of the message, e.g. delivered or failed.
+	Status(referenceID string) (status string, err error)
+}
+
+// templater is a provider that only sends the templates it has.
+type templater interface {
+	HasTemplate(name string) bool
+}
+
+// Message is an sms of a template, each provider sends it with the template it maps the name to.
+type Message struct {
+	Template string // the logical name, e.g. schema.SmsCoupon
+	Mobile   string
+	Params   []string
+}
+
+// Template is the template of the message at a provider.
+type Template struct {
+	ID   string
+	Line string // the sender, e.g. the provider number of MessageWay
+}
+
+// Receipt is where a message was sent.
+type Receipt struct {
+	Provider    string
+	ReferenceID string
+}
+
+// Reference is the provider and the reference of the message in one, to ask for its status later.
+func (r *Receipt) Reference() string {
+	return r.Provider + ":" + r.ReferenceID
+}
+
+type Status struct {
+	Provider string `json:",omitempty"`
+	Status   string
+}
+
+const (
+	ProviderMessageWay = "messageway"
+	ProviderKavenegar  = "kavenegar"
+	ProviderFake       = "fake"
+)
+
+var (
+	// ErrInvalidMessage is returned for the messages that can never be sent, e.g. to an invalid mobile.
+	ErrInvalidMessage = errors.New("sms: invalid message")
+	// ErrNoTemplate is returned by a provider that has no template for the message.
+	ErrNoTemplate = errors.New("sms: no template for the message")
+	// ErrRejected is returned when the provider received the message but refused to send it, e.g. out of credit.
+	ErrRejected = errors.New("sms: rejected by the provider")
+	// ErrInvalidOTP is returned for a wrong or expired code.
+	ErrInvalidOTP = errors.New("sms: invalid otp")
+	// ErrUnknownProvider is returned for a reference of a provider that is not configured.
+	ErrUnknownProvider = errors.New("sms: unknown provider")
+)
+
+// the templates of MessageWay the app always had, the config only needs to list the changed ones
+var messageWayTemplates = map[string]Template{
+	schema.SmsOTP:             {ID: "3", Line: "0"},
+	schema.SmsMachineCommand:  {ID: "8698", Line: "3"}, // با سر شماره 9000
+	schema.SmsCoupon:          {ID: "12109", Line: "5"},
+	schema.SmsReminderTurnOn:  {ID: "16620", Line: "5"},
+	schema.SmsReminderTurnOff: {ID: "16621", Line: "5"},
+	schema.SmsDeviceOff:       {ID: "16622", Line: "5"},
+}
+
+// messageWayRequired are the sms without a template of MessageWay by default, they must be set in the config
+var messageWayRequired = []string{
+	schema.SmsWaitlistOffer,
+	schema.SmsDowntimeAlert,
+	schema.SmsReservationMoved,
+	schema.SmsReservationRefunded,
+	schema.SmsNoShowSuspension,
+	schema.SmsRescheduled,
+	schema.SmsNotification,
+}
@@ -0,0 +1,259 @@
+package sms
+
+import (
+	"errors"
+	"fmt"
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/internal"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/config"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// Service sends the sms over the configured providers, each message by the first of them that accepts it.
+// It also sends the messages to the users that linked their account to the user bot, when it is connected.
+type Service struct {
+	providers []Provider
+	logger    zerolog.Logger
+	Bale      *Bale // nil when the user bot is not connected
+}
+
+func NewService(cfg *config.Config, logger zerolog.Logger, bot *internal.UserBot, db *database.Database) *Service {
+	codes := NewOTPCodes(cfg.Services.SMS.OtpSecret, NewOTPStore(db))
+
+	names := cfg.Services.SMS.Providers
+	if len(names) == 0 {
+		names = []string{ProviderFake}
+		if cfg.App.Production {
+			names = []string{ProviderMessageWay}
+		}
+	}
+
+	var providers []Provider
+	for _, name := range names {
+		templates := templatesOf(cfg, name)
+
+		switch name {
+		case ProviderMessageWay:
+			if cfg.Services.MessageWay.ApiKey == "" {
+				logger.Error().Msg("sms: the api key of messageway is not set")
+				continue
+			}
+			if missing := missingTemplates(templates, messageWayRequired); len(missing) > 0 {
+				logger.Panic().Strs("templates", missing).Msg("sms: the templates of messageway are not set")
+			}
+			providers = append(providers, NewMessageWay(cfg.Services.MessageWay.ApiKey, templates))
+		case ProviderKavenegar:
+			if cfg.Services.SMS.Kavenegar.ApiKey == "" {
+				logger.Error().Msg("sms: the api key of kavenegar is not set")
+				continue
+			}
+			providers = append(providers, NewKavenegar(cfg.Services.SMS.Kavenegar.ApiKey, templates, codes))
+		case ProviderFake:
+			providers = append(providers, NewFake(cfg.Services.SMS.Fake.File, cfg.Services.SMS.Fake.Url, logger, codes))
+		default:
+			logger.Error().Str("provider", name).Msg("sms: unknown provider")
+		}
+	}
+
+	if len(providers) == 0 {
+		logger.Error().Msg("sms: no provider is configured, the messages are only logged")
+		providers = append(providers, NewFake("", "", logger, codes))
+	}
+
+	service := New(logger, providers...)
+	if bot != nil && bot.Connected {
+		service.Bale = NewBale(bot.Bot, codes)
+	}
+
+	return service
+}
+
+// New returns a service over the providers, the first one is the primary.
+func New(logger zerolog.Logger, providers ...Provider) *Service {
+	return &Service{
+		providers: providers,
+		logger:    logger,
+	}
+}
+
+// templatesOf are the templates of the provider in the config, over its defaults
+func templatesOf(cfg *config.Config, provider string) map[string]Template {
+	templates := map[string]Template{}
+	if provider == ProviderMessageWay {
+		for name, template := range messageWayTemplates {
+			templates[name] = template
+		}
+	}
+
+	for name, template := range cfg.Services.SMS.Templates[provider] {
+		templates[name] = Template{ID: template.ID, Line: template.Line}
+	}
+
+	return templates
+}
+
+func missingTemplates(templates map[string]Template, names []string) []string {
+	var missing []string
+	for _, name := range names {
+		if templates[name].ID == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	return missing
+}
+
+// HasTemplate reports whether one of the providers can send the sms of the logical name.
+func (s *Service) HasTemplate(name string) bool {
+	for _, provider := range s.providers {
+		if t, ok := provider.(templater); !ok || t.HasTemplate(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Send sends the message by the first provider that accepts it. The error wraps ErrInvalidMessage only when
+// none of them ever could.
+func (s *Service) Send(message Message) (*Receipt, error) {
+	return s.failover(message.Template, func(provider Provider) (string, error) {
+		return provider.Send(message)
+	})
+}
+
+// SendOTP sends a code to the mobile by the first provider that accepts it, it is verified by the same one.
+func (s *Service) SendOTP(mobile string) (*Receipt, error) {
+	return s.failover(schema.SmsOTP, func(provider Provider) (string, error) {
+		return provider.SendOTP(mobile)
+	})
+}
+
+// SendBale sends the message to the chat of the user on Bale.
+func (s *Service) SendBale(chatID int64, message Message) (*Receipt, error) {
+	if s.Bale == nil {
+		return nil, ErrNoBale
+	}
+
+	referenceID, err := s.Bale.Send(chatID, message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Receipt{Provider: ProviderBale, ReferenceID: referenceID}, nil
+}
+
+// SendBaleOTP sends a code of the mobile to the chat of its user on Bale, it is verified as of ProviderBale.
+func (s *Service) SendBaleOTP(chatID int64, mobile string) (*Receipt, error) {
+	if s.Bale == nil {
+		return nil, ErrNoBale
+	}
+
+	referenceID, err := s.Bale.SendOTP(chatID, mobile)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Receipt{Provider: ProviderBale, ReferenceID: referenceID}, nil
+}
+
+// VerifyOTP verifies the code with the provider that sent it, the primary one when it is not known.
+func (s *Service) VerifyOTP(provider string, mobile string, code string) error {
+	if provider == ProviderBale && s.Bale != nil {
+		return s.Bale.VerifyOTP(mobile, code)
+	}
+
+	p, err := s.provider(provider)
+	if err != nil {
+		return err
+	}
+
+	return p.VerifyOTP(mobile, code)
+}
+
+// Status returns the delivery status of the message of the reference of its Receipt. The references without a
+// provider are from before there were others, they are of MessageWay or of the fake one outside production.
+func (s *Service) Status(reference string) (*Status, error) {
+	provider, referenceID, ok := strings.Cut(reference, ":")
+	if !ok {
+		provider, referenceID = ProviderMessageWay, reference
+	}
+	// a message on Bale is delivered once the bot sent it
+	if provider == ProviderBale {
+		return &Status{Provider: ProviderBale, Status: "delivered"}, nil
+	}
+
+	p, err := s.provider(provider)
+	if !ok && errors.Is(err, ErrUnknownProvider) {
+		p, err = s.providers[0], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := p.Status(referenceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Status{Provider: p.Name(), Status: status}, nil
+}
+
+func (s *Service) provider(name string) (Provider, error) {
+	if name == "" {
+		return s.providers[0], nil
+	}
+
+	for _, provider := range s.providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, name)
+}
+
+func (s *Service) failover(template string, send func(provider Provider) (string, error)) (*Receipt, error) {
+	var errs []error
+	for _, provider := range s.providers {
+		referenceID, err := send(provider)
+		if err == nil {
+			if len(errs) > 0 {
+				s.logger.Warn().Err(errors.Join(errs...)).Str("template", template).Str("provider", provider.Name()).
+					Msg("sms: sent by a fallback provider")
+			}
+			return &Receipt{Provider: provider.Name(), ReferenceID: referenceID}, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	for _, err := range errs {
+		if !isInvalid(err) {
+			// a provider may accept it later, so it is not reported as invalid even if the others refused it
+			return nil, errors.Join(unwrapInvalid(errs)...)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrInvalidMessage, errors.Join(errs...))
+}
+
+func isInvalid(err error) bool {
+	return errors.Is(err, ErrInvalidMessage) || errors.Is(err, ErrNoTemplate)
+}
+
+// unwrapInvalid keeps the errors of the providers that could never send the message as text only
+func unwrapInvalid(errs []error) []error {
+	result := make([]error, len(errs))
+	for i, err := range errs {
+		result[i] = err
+		if isInvalid(err) {
+			result[i] = errors.New(err.Error())
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,175 @@
+package testapp
+
+import (
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/helpers"
+)
+
+// CreateTestUser creates a test user
+func (f *Fixture) CreateTestUser(t *testing.T, mobile uint64, firstName string, lastName string) *schema.User {
+	t.Helper()
+
+	isSuspended := false
+	user := &schema.User{
+		Mobile:      mobile,
+		FirstName:   firstName,
+		LastName:    lastName,
+		Password:    helpers.Hash([]byte("testPassword123")),
+		Permissions: schema.UserPermissions{},
+		IsSuspended: &isSuspended,
+	}
+
+	if err := f.DB.Create(user).Error; err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+
+	return user
+}
+
+// CreateTestBusiness creates a test business owned by the user
+func (f *Fixture) CreateTestBusiness(t *testing.T, owner *schema.User, title string) *schema.Business {
+	t.Helper()
+
+	business := &schema.Business{
+		Title:   title,
+		Type:    schema.BTypeGymManager,
+		OwnerID: owner.ID,
+		Account: schema.BusinessAccountDefault,
+	}
+
+	if err := f.DB.Create(business).Error; err != nil {
+		t.Fatalf("failed to create test business: %v", err)
+	}
+
+	owner.Permissions[business.ID] = []schema.UserRole{schema.URBusinessOwner}
+	f.DB.Save(owner)
+
+	return business
+}
+
+// CreateTestMachine creates a published post with a washing machine variant
+func (f *Fixture) CreateTestMachine(t *testing.T, businessID uint64, authorID uint64, sku string) *schema.Product {
+	t.Helper()
+
+	post := &schema.Post{
+		Title:      "Machine " + sku,
+		Content:    "Test content",
+		Status:     schema.PostStatusPublished,
+		Type:       schema.PostTypeProduct,
+		BusinessID: businessID,
+		AuthorID:   authorID,
+	}
+	if err := f.DB.Create(post).Error; err != nil {
+		t.Fatalf("failed to create test post: %v", err)
+	}
+
+	variantType := schema.ProductVariantTypeWashingMachine
+	product := &schema.Product{
+		PostID:      post.ID,
+		BusinessID:  businessID,
+		Price:       10000,
+		Type:        schema.ProductTypeVariant,
+		VariantType: &variantType,
+		StockStatus: schema.ProductStockStatusInStock,
+		Meta: schema.ProductMeta{
+			SKU:                  sku,
+			UniWashMachineStatus: schema.UniWashMachineStatusON,
+		},
+	}
+	if err := f.DB.Create(product).Error; err != nil {
+		t.Fatalf("failed to create test product: %v", err)
+	}
+
+	return product
+}
+
+// CreateTestTaxonomy creates a category under the parent and attaches the posts of the products to it
+func (f *Fixture) CreateTestTaxonomy(t *testing.T, businessID uint64, title string, parent *schema.Taxonomy, products ...*schema.Product) *schema.Taxonomy {
+	t.Helper()
+
+	taxonomy := &schema.Taxonomy{
+		Title:      title,
+		Type:       schema.TaxonomyTypeCategory,
+		Domain:     schema.PostTypeProduct,
+		Slug:       title,
+		BusinessID: businessID,
+	}
+	if parent != nil {
+		taxonomy.ParentID = &parent.ID
+	}
+	if err := f.DB.Create(taxonomy).Error; err != nil {
+		t.Fatalf("failed to create test taxonomy: %v", err)
+	}
+
+	for _, product := range products {
+		if err := f.DB.Exec("INSERT INTO posts_taxonomies (post_id, taxonomy_id) VALUES (?, ?)", product.PostID, taxonomy.ID).Error; err != nil {
+			t.Fatalf("failed to attach taxonomy to post: %v", err)
+		}
+	}
+
+	return taxonomy
+}
+
+// CreateTestReservation creates a paid reservation of the slot
+func (f *Fixture) CreateTestReservation(t *testing.T, userID uint64, product *schema.Product, slot Slot) *schema.Reservation {
+	t.Helper()
+
+	reservation := &schema.Reservation{
+		UserID:     userID,
+		ProductID:  product.ID,
+		BusinessID: product.BusinessID,
+		StartTime:  slot.Start,
+		EndTime:    slot.End,
+		Status:     schema.ReservationStatusReserved,
+	}
+
+	if err := f.DB.Create(reservation).Error; err != nil {
+		t.Fatalf("failed to create test reservation: %v", err)
+	}
+
+	return reservation
+}
+
+// CreateTestTemplate creates a notification template of the business
+func (f *Fixture) CreateTestTemplate(t *testing.T, businessID uint64, content string) *schema.NotificationTemplate {
+	t.Helper()
+
+	template := &schema.NotificationTemplate{
+		Title:      "Test Template",
+		Content:    content,
+		BusinessID: businessID,
+	}
+
+	if err := f.DB.Create(template).Error; err != nil {
+		t.Fatalf("failed to create test template: %v", err)
+	}
+
+	return template
+}
+
+// Slot is an hourly reservation slot of the default machine schedule
+type Slot struct {
+	Date      string
+	StartTime string
+	EndTime   string
+	Start     time.Time
+	End       time.Time
+}
+
+// TomorrowSlot returns the slot of tomorrow at the given hour
+func TomorrowSlot(hour int) Slot {
+	loc, _ := time.LoadLocation("Asia/Tehran")
+	tomorrow := time.Now().In(loc).AddDate(0, 0, 1)
+	start := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, 0, 0, 0, loc)
+
+	return Slot{
+		Date:      start.Format(time.DateOnly),
+		StartTime: start.Format(time.TimeOnly),
+		EndTime:   start.Add(time.Hour).Format(time.TimeOnly),
+		Start:     start,
+		End:       start.Add(time.Hour),
+	}
+}
@@ -0,0 +1,93 @@
+package testapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/app/middleware"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// GenerateTestToken generates a JWT token for test user
+func (f *Fixture) GenerateTestToken(t *testing.T, user *schema.User) string {
+	t.Helper()
+
+	jwtCustomClaim := middleware.JWTCustomClaim{
+		User: schema.User{
+			ID:          user.ID,
+			Mobile:      user.Mobile,
+			LastName:    user.LastName,
+			FirstName:   user.FirstName,
+			Permissions: user.Permissions,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour))},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwtCustomClaim).SignedString([]byte(f.Config.Middleware.Jwt.Secret))
+	if err != nil {
+		t.Fatalf("failed to generate test token: %v", err)
+	}
+
+	return token
+}
+
+// MakeRequest makes an HTTP request to the test server
+func (f *Fixture) MakeRequest(t *testing.T, method, path string, body interface{}, token string) *http.Response {
+	t.Helper()
+
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to marshal request body: %v", err)
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req := httptest.NewRequest(method, path, reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := f.App.Test(req, -1)
+	if err != nil {
+		t.Fatalf("failed to make request: %v", err)
+	}
+
+	return resp
+}
+
+// ParseResponse parses the response body into a map
+func ParseResponse(t *testing.T, resp *http.Response) map[string]interface{} {
+	t.Helper()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response body: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("failed to parse response: %v, body: %s", err, string(body))
+	}
+
+	return result
+}
+
+// AssertStatus asserts the response status code matches expected
+func AssertStatus(t *testing.T, resp *http.Response, expected int) {
+	t.Helper()
+	if resp.StatusCode != expected {
+		result := ParseResponse(t, resp)
+		t.Fatalf("expected status %d, got %d, response: %v", expected, resp.StatusCode, result)
+	}
+}
@@ -0,0 +1,225 @@
+package testapp
+
+// statements create the test tables, their columns match the fields of the schema
+var statements = map[string]string{
+	"users": `CREATE TABLE IF NOT EXISTS users (
+		id BIGSERIAL PRIMARY KEY,
+		first_name VARCHAR(255),
+		last_name VARCHAR(255),
+		mobile BIGINT NOT NULL UNIQUE,
+		mobile_confirmed BOOLEAN DEFAULT FALSE,
+		show_mobile BOOLEAN,
+		is_suspended BOOLEAN DEFAULT FALSE,
+		suspense_reason VARCHAR(500),
+		permissions JSONB NOT NULL DEFAULT '{}',
+		password VARCHAR(255) NOT NULL,
+		city_id BIGINT,
+		workspace_id BIGINT,
+		dormitory_id BIGINT,
+		reservation_count BIGINT DEFAULT 0,
+		referral_code VARCHAR(20) UNIQUE,
+		meta JSONB,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"businesses": `CREATE TABLE IF NOT EXISTS businesses (
+		id BIGSERIAL PRIMARY KEY,
+		title VARCHAR(255) NOT NULL,
+		type VARCHAR(255) NOT NULL,
+		owner_id BIGINT NOT NULL,
+		account VARCHAR(100) DEFAULT 'default',
+		meta JSONB,
+		description VARCHAR(500),
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"business_users": `CREATE TABLE IF NOT EXISTS business_users (
+		business_id BIGINT NOT NULL,
+		user_id BIGINT NOT NULL,
+		PRIMARY KEY (business_id, user_id)
+	)`,
+	"posts": `CREATE TABLE IF NOT EXISTS posts (
+		id BIGSERIAL PRIMARY KEY,
+		title VARCHAR(255),
+		excerpt VARCHAR(255),
+		content TEXT NOT NULL,
+		status VARCHAR(50) DEFAULT 'published',
+		type VARCHAR(50) NOT NULL,
+		parent_id BIGINT,
+		slug VARCHAR(600),
+		author_id BIGINT NOT NULL,
+		business_id BIGINT,
+		meta JSONB,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"products": `CREATE TABLE IF NOT EXISTS products (
+		id BIGSERIAL PRIMARY KEY,
+		post_id BIGINT,
+		is_root BOOLEAN DEFAULT FALSE,
+		type VARCHAR(50) NOT NULL,
+		variant_type VARCHAR(50),
+		price FLOAT NOT NULL,
+		min_price FLOAT NOT NULL DEFAULT 0,
+		max_price FLOAT NOT NULL DEFAULT 0,
+		on_sale BOOLEAN DEFAULT FALSE,
+		stock_status VARCHAR(40) NOT NULL DEFAULT 'inStock',
+		total_sales FLOAT DEFAULT 0,
+		meta JSONB,
+		business_id BIGINT,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"reservations": `CREATE TABLE IF NOT EXISTS reservations (
+		id BIGSERIAL PRIMARY KEY,
+		status VARCHAR(50) DEFAULT 'reserved',
+		start_time TIMESTAMPTZ NOT NULL,
+		end_time TIMESTAMPTZ NOT NULL,
+		user_id BIGINT NOT NULL,
+		product_id BIGINT NOT NULL,
+		business_id BIGINT NOT NULL,
+		meta JSONB,
+		user_usage_count BIGINT DEFAULT 0,
+		seats INT NOT NULL DEFAULT 1,
+		shared BOOLEAN NOT NULL DEFAULT FALSE,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"taxonomies": `CREATE TABLE IF NOT EXISTS taxonomies (
+		id BIGSERIAL PRIMARY KEY,
+		title VARCHAR(255) NOT NULL,
+		slug VARCHAR(255) NOT NULL,
+		type VARCHAR(50) NOT NULL,
+		domain VARCHAR(100) NOT NULL,
+		parent_id BIGINT,
+		business_id BIGINT NOT NULL,
+		description VARCHAR(500),
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"posts_taxonomies": `CREATE TABLE IF NOT EXISTS posts_taxonomies (
+		post_id BIGINT NOT NULL,
+		taxonomy_id BIGINT NOT NULL,
+		PRIMARY KEY (post_id, taxonomy_id)
+	)`,
+	"outbox_messages": `CREATE TABLE IF NOT EXISTS outbox_messages (
+		id BIGSERIAL PRIMARY KEY,
+		business_id BIGINT,
+		purpose VARCHAR(30) NOT NULL,
+		priority BIGINT NOT NULL DEFAULT 1,
+		template VARCHAR(50) NOT NULL DEFAULT '',
+		provider VARCHAR(30),
+		mobile VARCHAR(20) NOT NULL,
+		params TEXT[],
+		channels TEXT[],
+		bale_chat_id BIGINT,
+		status VARCHAR(20) NOT NULL DEFAULT 'queued',
+		attempts BIGINT NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMPTZ NOT NULL,
+		locked_until TIMESTAMPTZ,
+		reference_id VARCHAR(100),
+		last_error VARCHAR(500),
+		sent_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"waitlists": `CREATE TABLE IF NOT EXISTS waitlists (
+		id BIGSERIAL PRIMARY KEY,
+		user_id BIGINT NOT NULL,
+		business_id BIGINT NOT NULL,
+		product_id BIGINT,
+		taxonomy_id BIGINT,
+		start_time TIMESTAMPTZ NOT NULL,
+		end_time TIMESTAMPTZ NOT NULL,
+		status VARCHAR(50) NOT NULL DEFAULT 'waiting',
+		offered_product_id BIGINT,
+		offer_expires_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"calendar_feeds": `CREATE TABLE IF NOT EXISTS calendar_feeds (
+		id BIGSERIAL PRIMARY KEY,
+		token VARCHAR(64) NOT NULL UNIQUE,
+		user_id BIGINT,
+		business_id BIGINT,
+		creator_id BIGINT NOT NULL,
+		last_fetched_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"notification_templates": `CREATE TABLE IF NOT EXISTS notification_templates (
+		id BIGSERIAL PRIMARY KEY,
+		title VARCHAR(255) NOT NULL,
+		content TEXT NOT NULL,
+		business_id BIGINT,
+		tag TEXT[],
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"notifications": `CREATE TABLE IF NOT EXISTS notifications (
+		id BIGSERIAL PRIMARY KEY,
+		receiver_id BIGINT NOT NULL,
+		type TEXT[] NOT NULL,
+		business_id BIGINT NOT NULL,
+		sent_at TIMESTAMPTZ NOT NULL,
+		template_id BIGINT,
+		content TEXT,
+		deliveries JSONB,
+		category VARCHAR(20) NOT NULL DEFAULT 'transactional',
+		read_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"campaigns": `CREATE TABLE IF NOT EXISTS campaigns (
+		id BIGSERIAL PRIMARY KEY,
+		business_id BIGINT NOT NULL,
+		title VARCHAR(100) NOT NULL,
+		template_id BIGINT NOT NULL,
+		channels TEXT[] NOT NULL,
+		audience JSONB,
+		send_at TIMESTAMPTZ NOT NULL,
+		rate BIGINT NOT NULL DEFAULT 0,
+		status VARCHAR(20) NOT NULL DEFAULT 'scheduled',
+		total BIGINT NOT NULL DEFAULT 0,
+		sent BIGINT NOT NULL DEFAULT 0,
+		failed BIGINT NOT NULL DEFAULT 0,
+		skipped BIGINT NOT NULL DEFAULT 0,
+		started_at TIMESTAMPTZ,
+		finished_at TIMESTAMPTZ,
+		created_by BIGINT,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ
+	)`,
+	"campaign_recipients": `CREATE TABLE IF NOT EXISTS campaign_recipients (
+		id BIGSERIAL PRIMARY KEY,
+		campaign_id BIGINT NOT NULL,
+		user_id BIGINT NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		notification_id BIGINT,
+		error VARCHAR(500),
+		sent_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ,
+		updated_at TIMESTAMPTZ,
+		deleted_at TIMESTAMPTZ,
+		UNIQUE (campaign_id, user_id)
+	)`,
+	"campaign_opt_outs": `CREATE TABLE IF NOT EXISTS campaign_opt_outs (
+		id BIGSERIAL PRIMARY KEY,
+		user_id BIGINT NOT NULL,
+		business_id BIGINT NOT NULL,
+		created_at TIMESTAMPTZ,
+		UNIQUE (user_id, business_id)
+	)`,
+}
@@ -0,0 +1,142 @@
+// Package testapp is the test database and the fiber app the e2e tests of the modules run on, with the
+// factories of the records most of them need. The modules register their routes on the app themselves.
+package testapp
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go-fiber-starter/internal"
+	"go-fiber-starter/internal/bootstrap/database"
+	"go-fiber-starter/utils/config"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog"
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+// Fixture holds the test application components
+type Fixture struct {
+	App      *fiber.App
+	DB       *gorm.DB
+	Database *database.Database
+	Config   *config.Config
+	Logger   zerolog.Logger
+
+	tables  []string
+	closers []func()
+}
+
+// testLifecycle collects the hooks of the fx lifecycle, the test app runs them itself
+type testLifecycle struct {
+	hooks []fx.Hook
+}
+
+func (l *testLifecycle) Append(hook fx.Hook) {
+	l.hooks = append(l.hooks, hook)
+}
+
+// getProjectRoot returns the project root directory
+func getProjectRoot() string {
+	_, b, _, _ := runtime.Caller(0)
+	// Navigate from internal/testapp/ to project root
+	return filepath.Join(filepath.Dir(b), "..", "..")
+}
+
+// createTestErrorHandler creates an error handler that properly handles validation errors
+func createTestErrorHandler() fiber.ErrorHandler {
+	return func(c *fiber.Ctx, err error) error {
+		code := fiber.StatusInternalServerError
+
+		// Handle validation errors
+		if _, ok := err.(validator.ValidationErrors); ok {
+			code = fiber.StatusUnprocessableEntity
+		} else if e, ok := err.(*fiber.Error); ok {
+			code = e.Code
+		}
+
+		return c.Status(code).JSON(fiber.Map{
+			"Code":     code,
+			"Messages": []string{err.Error()},
+		})
+	}
+}
+
+// New connects to the test database, creates the tables from scratch and the app with the error handler of the
+// api. The tables are of the ones in tables.go, created with raw SQL because GORM AutoMigrate follows the
+// relationship definitions.
+func New(t *testing.T, tables ...string) *Fixture {
+	t.Helper()
+
+	// Load test config from project root
+	configPath := filepath.Join(getProjectRoot(), "config", "zciti-test.toml")
+	cfg, err := config.ParseConfig(configPath, true)
+	if err != nil {
+		t.Fatalf("failed to load test config: %v", err)
+	}
+
+	logger := zerolog.Nop()
+
+	dbWrapper := database.NewDatabase(cfg, logger)
+	dbWrapper.ConnectDatabase()
+
+	if dbWrapper.Main == nil {
+		t.Fatalf("failed to connect to test database")
+	}
+
+	for _, table := range tables {
+		statement, ok := statements[table]
+		if !ok {
+			t.Fatalf("no statement of the test table %s", table)
+		}
+
+		dbWrapper.Main.Exec("DROP TABLE IF EXISTS " + table + " CASCADE")
+		if err := dbWrapper.Main.Exec(statement).Error; err != nil {
+			t.Fatalf("failed to migrate test models: %v", err)
+		}
+	}
+
+	return &Fixture{
+		App: fiber.New(fiber.Config{
+			ErrorHandler: createTestErrorHandler(),
+		}),
+		DB:       dbWrapper.Main,
+		Database: dbWrapper,
+		Config:   cfg,
+		Logger:   logger,
+		tables:   tables,
+	}
+}
+
+// NotificationHub starts the hub of the live notifications, it is closed by Cleanup
+func (f *Fixture) NotificationHub(t *testing.T) *internal.NotificationHub {
+	t.Helper()
+
+	// the test process is never a prefork child, the hub would not listen otherwise
+	f.Config.App.Prefork = false
+	lifecycle := &testLifecycle{}
+	hub := internal.NewNotificationHub(lifecycle, f.Config, f.Database, f.Logger)
+	for _, hook := range lifecycle.hooks {
+		if err := hook.OnStart(context.Background()); err != nil {
+			t.Fatalf("failed to start the notification hub: %v", err)
+		}
+	}
+	f.closers = append(f.closers, hub.Close)
+
+	return hub
+}
+
+// Cleanup empties the tables and disconnects from the test database
+func (f *Fixture) Cleanup() {
+	for _, closer := range f.closers {
+		closer()
+	}
+	for _, table := range f.tables {
+		f.DB.Exec("DELETE FROM " + table)
+	}
+	f.Database.ShutdownDatabase()
+}
@@ -74,7 +74,73 @@ type services = struct {
 		Debug          bool   `toml:"debug"`
 		LoggerChatID   int64  `toml:"loggerChatID"`
 		LoggerBotToken string `toml:"loggerBotToken"`
+
+		OperatorBotToken string `toml:"operatorBotToken"` // the bot the operators control the machines with, off when empty
+		OperatorBotName  string `toml:"operatorBotName"`  // the username of the bot, for the links
+
+		UserBotToken string `toml:"userBotToken"` // the bot the users get their notifications on, off when empty
+		UserBotName  string `toml:"userBotName"`
+		UserBotOTP   bool   `toml:"userBotOtp"` // send the otps of the linked users on Bale too
+	}
+
+	Device struct {
+		HttpToken      string        `toml:"httpToken"`
+		HttpHosts      []string      `toml:"httpHosts"`      // host or host:port of the http controllers, the token is only sent to them
+		HttpTimeout    time.Duration `toml:"httpTimeout"`    // as seconds
+		Simulator      bool          `toml:"simulator"`      // drive every machine with the in-process simulator
+		SimulatorCycle time.Duration `toml:"simulatorCycle"` // as seconds
+	}
+
+	MachineHealth struct {
+		MaxUndelivered    int           `toml:"maxUndelivered"`    // consecutive undelivered commands
+		MaxFaultReports   int           `toml:"maxFaultReports"`   // users reporting a fault in the window
+		FaultReportWindow time.Duration `toml:"faultReportWindow"` // as seconds
+		NoDeliveryGap     time.Duration `toml:"noDeliveryGap"`     // as seconds, of failing commands without a delivered one
 	}
+
+	NoShow struct {
+		MaxStrikes   int           `toml:"maxStrikes"`   // strikes in the window that suspend booking
+		StrikeWindow time.Duration `toml:"strikeWindow"` // as seconds
+		Suspension   time.Duration `toml:"suspension"`   // as seconds
+	}
+
+	Reschedule struct {
+		Deadline       time.Duration `toml:"deadline"`       // as seconds before the start of the reservation
+		MaxReschedules int           `toml:"maxReschedules"` // per reservation
+	}
+
+	// the sms providers, each message is sent by the first of them that accepts it
+	SMS struct {
+		Providers []string                          `toml:"providers"` // messageway, kavenegar or fake, the first one is the primary
+		Templates map[string]map[string]smsTemplate `toml:"templates"` // of each provider by the logical name of the message
+
+		OptOutKeyword string `toml:"optOutKeyword"` // the reply that stops the marketing sms
+		InboundSecret string `toml:"inboundSecret"` // of the url the provider posts the replies to
+		OtpSecret     string `toml:"otpSecret"`     // of the otp codes made by the app, for kavenegar, bale and the fake one
+
+		Kavenegar struct {
+			ApiKey string `toml:"apiKey"`
+		}
+
+		// the fake provider of the development, it logs the messages and keeps them in the file or posts them to the url
+		Fake struct {
+			File string `toml:"file"`
+			Url  string `toml:"url"`
+		}
+	}
+
+	// the queued sms, the machine commands are not queued and are sent right away
+	Outbox struct {
+		Rate        int           `toml:"rate"`        // messages per second of the provider
+		MaxAttempts int           `toml:"maxAttempts"` // before a message is dead
+		Backoff     time.Duration `toml:"backoff"`     // as seconds, doubled after each failed attempt
+	}
+}
+
+// smsTemplate is a template of an sms provider
+type smsTemplate = struct {
+	ID   string `toml:"id"`
+	Line string `toml:"line"` // the sender, e.g. the provider number of MessageWay
 }
 
 // middleware
@@ -167,6 +233,10 @@ func NewConfig() *Config {
 		panic("JWT secret is not set")
 	}
 
+	if config.Services.SMS.OtpSecret == "" {
+		panic("OTP secret is not set")
+	}
+
 	return config
 }
 
@@ -0,0 +1,71 @@
+package deeplink
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the bots take only letters, digits, _ and - in the start parameter, 64 of them at most
+const separator = "_"
+
+// the length of the signature, short to fit the start parameter
+const signatureLength = 16
+
+var ErrInvalidToken = errors.New("لینک اتصال معتبر نیست یا منقضی شده است، لینک جدید بگیرید")
+
+// Token signs the ids for the purpose until expiresAt, e.g. the user and the business a bot chat is linked to.
+func Token(secret string, purpose string, expiresAt time.Time, ids ...uint64) string {
+	parts := make([]string, 0, len(ids)+1)
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatUint(id, 36))
+	}
+	parts = append(parts, strconv.FormatInt(expiresAt.Unix(), 36))
+
+	payload := strings.Join(parts, separator)
+	return payload + separator + sign(secret, purpose, payload)
+}
+
+// Parse gives the ids of a token of the purpose that has not expired.
+func Parse(secret string, purpose string, token string, now time.Time) (ids []uint64, err error) {
+	i := strings.LastIndex(token, separator)
+	if i < 0 {
+		return nil, ErrInvalidToken
+	}
+	payload, signature := token[:i], token[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(sign(secret, purpose, payload))) {
+		return nil, ErrInvalidToken
+	}
+
+	parts := strings.Split(payload, separator)
+	expiresAt, err := strconv.ParseInt(parts[len(parts)-1], 36, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return nil, ErrInvalidToken
+	}
+
+	for _, part := range parts[:len(parts)-1] {
+		id, err := strconv.ParseUint(part, 36, 64)
+		if err != nil {
+			return nil, ErrInvalidToken
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// Link opens the bot with the token as its start parameter.
+func Link(botName string, token string) string {
+	return "https://ble.ir/" + strings.TrimPrefix(botName, "@") + "?start=" + token
+}
+
+func sign(secret string, purpose string, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose + ":" + payload))
+
+	return hex.EncodeToString(mac.Sum(nil))[:signatureLength]
+}
@@ -0,0 +1,112 @@
+package ical
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusCancelled = "CANCELLED"
+)
+
+const ContentType = "text/calendar; charset=utf-8"
+
+// Calendar is an iCalendar (RFC 5545) file, a feed the calendar apps subscribe to or a single event to download.
+type Calendar struct {
+	Name            string
+	RefreshInterval time.Duration // how often the subscribed apps should fetch the feed again
+	Events          []Event
+}
+
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Status      string
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time
+}
+
+// Bytes writes the calendar with the times in UTC, so it does not need the timezone definitions.
+func (c Calendar) Bytes() []byte {
+	var b bytes.Buffer
+
+	line(&b, "BEGIN:VCALENDAR")
+	line(&b, "VERSION:2.0")
+	line(&b, "PRODID:-//ZCiti//Reservations//FA")
+	line(&b, "CALSCALE:GREGORIAN")
+	line(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		line(&b, "X-WR-CALNAME:"+escape(c.Name))
+	}
+	if c.RefreshInterval > 0 {
+		interval := duration(c.RefreshInterval)
+		line(&b, "REFRESH-INTERVAL;VALUE=DURATION:"+interval)
+		line(&b, "X-PUBLISHED-TTL:"+interval)
+	}
+
+	for _, event := range c.Events {
+		line(&b, "BEGIN:VEVENT")
+		line(&b, "UID:"+event.UID)
+		line(&b, "DTSTAMP:"+stamp(event.Stamp))
+		line(&b, "DTSTART:"+stamp(event.Start))
+		line(&b, "DTEND:"+stamp(event.End))
+		line(&b, "SUMMARY:"+escape(event.Summary))
+		if event.Description != "" {
+			line(&b, "DESCRIPTION:"+escape(event.Description))
+		}
+		if event.Location != "" {
+			line(&b, "LOCATION:"+escape(event.Location))
+		}
+		if event.Status != "" {
+			line(&b, "STATUS:"+event.Status)
+		}
+		line(&b, "END:VEVENT")
+	}
+
+	line(&b, "END:VCALENDAR")
+
+	return b.Bytes()
+}
+
+func stamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func duration(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return "PT" + strconv.Itoa(int(d/time.Hour)) + "H"
+	}
+	return "PT" + strconv.Itoa(int(d/time.Minute)) + "M"
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// line folds the content lines longer than 75 octets without breaking the multibyte letters,
+// the space starting a continuation line counts too.
+func line(b *bytes.Buffer, content string) {
+	limit := 75
+
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,74 @@
+package optout
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultKeyword is what the user replies to the marketing sms to get no more of them
+const DefaultKeyword = "لغو"
+
+// the length of the signature in the links, short to keep the sms short
+const signatureLength = 12
+
+var ErrInvalidToken = errors.New("لینک لغو اشتراک معتبر نیست")
+
+// Token is the part of the opt-out link of the user, signed so only the user gets it.
+func Token(secret string, userID uint64) string {
+	id := strconv.FormatUint(userID, 36)
+	return id + "." + sign(secret, id)
+}
+
+// Parse finds the user of the token
+func Parse(secret string, token string) (userID uint64, err error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(sign(secret, id))) {
+		return 0, ErrInvalidToken
+	}
+
+	userID, err = strconv.ParseUint(id, 36, 64)
+	if err != nil {
+		return 0, ErrInvalidToken
+	}
+
+	return userID, nil
+}
+
+// Link opens the opt-out of the user, it needs no login.
+func Link(domain string, secret string, userID uint64) string {
+	return strings.TrimRight(domain, "/") + "/v1/unsubscribe/" + Token(secret, userID)
+}
+
+// Text is the opt-out the marketing sms carry, the keyword or the link of the user. There is no link when
+// the mobile is of no user.
+func Text(domain string, secret string, keyword string, userID uint64) string {
+	if keyword == "" {
+		keyword = DefaultKeyword
+	}
+	if userID == 0 {
+		return fmt.Sprintf("لغو: ارسال «%s»", keyword)
+	}
+
+	return fmt.Sprintf("لغو: ارسال «%s» یا %s", keyword, Link(domain, secret, userID))
+}
+
+// IsKeyword tells if the reply of the user is the opt-out keyword
+func IsKeyword(keyword string, text string) bool {
+	if keyword == "" {
+		keyword = DefaultKeyword
+	}
+
+	return strings.EqualFold(strings.TrimSpace(text), keyword)
+}
+
+func sign(secret string, id string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("optout:" + id))
+
+	return hex.EncodeToString(mac.Sum(nil))[:signatureLength]
+}
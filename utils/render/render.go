@@ -0,0 +1,53 @@
+package render
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Keywords are the placeholders a notification template can use, written as {{FirstName}}.
+var Keywords = map[string]string{
+	"FirstName":     "نام کاربر",
+	"LastName":      "نام خانوادگی کاربر",
+	"FullName":      "نام و نام خانوادگی کاربر",
+	"Mobile":        "موبایل کاربر",
+	"BusinessName":  "نام کسب و کار",
+	"BusinessPhone": "تلفن کسب و کار",
+}
+
+// Values are the keywords of a receiver, the missing ones render empty.
+type Values map[string]string
+
+var (
+	placeholder = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+	// what is left of the braces after the placeholders are taken out
+	brokenPlaceholder = regexp.MustCompile(`\{\{|\}\}`)
+)
+
+// Invalid lists the placeholders of the content that are not keywords, and the unbalanced braces.
+func Invalid(content string) (invalid []string) {
+	for _, match := range placeholder.FindAllStringSubmatch(content, -1) {
+		if _, ok := Keywords[match[1]]; !ok && !slices.Contains(invalid, match[0]) {
+			invalid = append(invalid, match[0])
+		}
+	}
+
+	if rest := placeholder.ReplaceAllString(content, ""); brokenPlaceholder.MatchString(rest) {
+		invalid = append(invalid, strings.Join(brokenPlaceholder.FindAllString(rest, -1), " "))
+	}
+
+	return invalid
+}
+
+// Text puts the values in the placeholders of the content in one pass, so a value that looks like
+// a placeholder is kept as it is. The placeholders that are not keywords are left untouched.
+func Text(content string, values Values) string {
+	return placeholder.ReplaceAllStringFunc(content, func(match string) string {
+		keyword := placeholder.FindStringSubmatch(match)[1]
+		if _, ok := Keywords[keyword]; !ok {
+			return match
+		}
+		return values[keyword]
+	})
+}
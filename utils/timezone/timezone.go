@@ -0,0 +1,135 @@
+package timezone
+
+import (
+	"sync"
+	"time"
+	_ "time/tzdata" // the containers do not always ship the zone database
+)
+
+const (
+	DefaultName           = "Asia/Tehran"
+	DefaultFirstDayOfWeek = time.Saturday
+)
+
+// how long the settings of a business are kept, with prefork Forget reaches only the process that changed them
+// and the others read the change again after this
+const keepFor = time.Minute
+
+// Settings is the calendar a business works with, every date it receives or shows is in Location.
+type Settings struct {
+	Location       *time.Location
+	FirstDayOfWeek time.Weekday
+}
+
+// kept is the settings of a business until they are read again
+type kept struct {
+	settings Settings
+	until    time.Time
+}
+
+// Loader reads the timezone name and the first day of week of a business, empty values mean the defaults.
+type Loader func(businessID uint64) (name string, firstDayOfWeek *time.Weekday, err error)
+
+var (
+	locations  sync.Map // name => *time.Location
+	businesses sync.Map // business id => kept
+	loader     Loader
+	loaderMu   sync.RWMutex
+)
+
+// Use sets how the settings of the businesses are read, until then every business gets the defaults.
+func Use(l Loader) {
+	loaderMu.Lock()
+	defer loaderMu.Unlock()
+
+	loader = l
+	businesses.Clear()
+}
+
+// Default is the location of the businesses that did not choose one.
+func Default() *time.Location {
+	return Location(DefaultName)
+}
+
+// Location loads the IANA zone once, an empty or unknown name gives the default one.
+func Location(name string) *time.Location {
+	if name == "" {
+		name = DefaultName
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		if name != DefaultName {
+			return Default()
+		}
+		// Tehran has not had daylight saving time since 2022
+		loc = time.FixedZone("+0330", 3*60*60+30*60)
+	}
+	locations.Store(name, loc)
+
+	return loc
+}
+
+// Valid reports whether the name is a zone this server knows.
+func Valid(name string) bool {
+	_, err := time.LoadLocation(name)
+	return name != "" && err == nil
+}
+
+// ForBusiness gives the settings of the business, they are kept for a minute or until Forget.
+// A failed read is not kept, so the next call tries again.
+func ForBusiness(businessID uint64) Settings {
+	if k, ok := businesses.Load(businessID); ok && time.Now().Before(k.(kept).until) {
+		return k.(kept).settings
+	}
+
+	loaderMu.RLock()
+	l := loader
+	loaderMu.RUnlock()
+
+	settings := Settings{Location: Default(), FirstDayOfWeek: DefaultFirstDayOfWeek}
+	if l == nil || businessID == 0 {
+		return settings
+	}
+
+	name, firstDayOfWeek, err := l(businessID)
+	if err != nil {
+		return settings
+	}
+	settings.Location = Location(name)
+	if firstDayOfWeek != nil {
+		settings.FirstDayOfWeek = *firstDayOfWeek
+	}
+	businesses.Store(businessID, kept{settings: settings, until: time.Now().Add(keepFor)})
+
+	return settings
+}
+
+// In is the location of the business.
+func In(businessID uint64) *time.Location {
+	return ForBusiness(businessID).Location
+}
+
+// Forget drops the kept settings of the business after they are changed.
+func Forget(businessID uint64) {
+	businesses.Delete(businessID)
+}
+
+// Today is the start of the current day in the location.
+func (s Settings) Today() time.Time {
+	return startOfDay(time.Now().In(s.Location))
+}
+
+// StartOfWeek is the start of the first day of the week t is in.
+func (s Settings) StartOfWeek(t time.Time) time.Time {
+	day := startOfDay(t.In(s.Location))
+	offset := (int(day.Weekday()) - int(s.FirstDayOfWeek) + 7) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
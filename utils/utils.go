@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"go-fiber-starter/app/database/schema"
+	"go-fiber-starter/utils/timezone"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -85,15 +86,20 @@ func GetIntInQueries(c *fiber.Ctx, key string) (int64, error) {
 	return strconv.ParseInt(c.Query(key), 10, 64)
 }
 
-// GetDateInQueries parses a date from the query string by key using Asia/Tehran timezone.
+// GetLocation returns the timezone of the business in the path, or the default one on the other routes.
+func GetLocation(c *fiber.Ctx) *time.Location {
+	businessID, _ := GetIntInParams(c, "businessID")
+	return timezone.In(businessID)
+}
+
+// GetDateInQueries parses a date from the query string by key in the timezone of GetLocation.
 // Returns a zero time if the query parameter is empty or parsing fails.
 // Expected format: YYYY-MM-DD (time.DateOnly).
 func GetDateInQueries(c *fiber.Ctx, key string) *time.Time {
 	if c.Query(key) == "" {
 		return &time.Time{}
 	}
-	loc, _ := time.LoadLocation("Asia/Tehran")
-	date, err := time.ParseInLocation(time.DateOnly, c.Query(key), loc)
+	date, err := time.ParseInLocation(time.DateOnly, c.Query(key), GetLocation(c))
 	if err != nil {
 		return &time.Time{}
 	}
@@ -396,10 +402,10 @@ func StartOfDayString(t time.Time) string {
 	return StartOfDay(t).Format(time.RFC3339)
 }
 
-// EndOfDay returns a new time.Time set to 23:59:59.999999999 of the given day.
+// EndOfDay returns the start of the next day, so the 23 and 25 hour days of DST end right.
 // Preserves the original timezone.
 func EndOfDay(t time.Time) time.Time {
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).Add(time.Hour * 24)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, 1)
 }
 
 // EndOfDayString returns the end of day as an RFC3339 formatted string.